@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+
 	"github.com/swi/repeater/pkg/cli"
 	configpkg "github.com/swi/repeater/pkg/config"
 )
@@ -26,5 +28,30 @@ func applyConfigFile(config *cli.Config) error {
 	config.HealthEnabled = fileConfig.Observability.HealthEnabled
 	config.HealthPort = fileConfig.Observability.HealthCheckPort
 
+	// Phases given with --phase take precedence over the file's [[phases]]
+	if len(config.Phases) == 0 {
+		for _, phase := range fileConfig.Phases {
+			config.Phases = append(config.Phases, cli.PhaseConfig{
+				Name:           phase.Name,
+				Mode:           phase.Mode,
+				Times:          phase.Times,
+				For:            phase.For,
+				Every:          phase.Every,
+				BaseDelay:      phase.BaseDelay,
+				Multiplier:     phase.Multiplier,
+				MaxDelay:       phase.MaxDelay,
+				Attempts:       phase.Attempts,
+				BaseInterval:   phase.BaseInterval,
+				MinInterval:    phase.MinInterval,
+				MaxInterval:    phase.MaxInterval,
+				CronExpression: phase.CronExpression,
+				Timezone:       phase.Timezone,
+			})
+		}
+	}
+	if config.Subcommand == "phases" && len(config.Phases) == 0 {
+		return errors.New("phases subcommand requires --phase or [[phases]] in the config file")
+	}
+
 	return nil
 }
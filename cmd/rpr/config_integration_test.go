@@ -172,6 +172,58 @@ health_check_port = 8082
 	require.NoError(t, err, "Runner should be created successfully with config file settings")
 }
 
+func TestConfigFilePhases(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "phases.toml")
+
+	configContent := `
+[[phases]]
+name = "warmup"
+mode = "interval"
+every = "1s"
+times = 10
+
+[[phases]]
+name = "steady"
+mode = "interval"
+every = "5m"
+`
+	require.NoError(t, os.WriteFile(configFile, []byte(configContent), 0644))
+
+	t.Run("phases from file", func(t *testing.T) {
+		config, err := cli.ParseArgs([]string{"--config", configFile, "phases", "--", "echo", "test"})
+		require.NoError(t, err)
+		require.NoError(t, applyConfigFile(config))
+		require.NoError(t, cli.ValidateConfig(config))
+
+		require.Len(t, config.Phases, 2)
+		assert.Equal(t, "warmup", config.Phases[0].Name)
+		assert.Equal(t, int64(10), config.Phases[0].Times)
+		assert.Equal(t, 5*time.Minute, config.Phases[1].Every)
+	})
+
+	t.Run("phase flags take precedence", func(t *testing.T) {
+		config, err := cli.ParseArgs([]string{"--config", configFile, "phases",
+			"--phase", "cron; cron=@daily", "--", "echo", "test"})
+		require.NoError(t, err)
+		require.NoError(t, applyConfigFile(config))
+
+		require.Len(t, config.Phases, 1)
+		assert.Equal(t, "cron", config.Phases[0].Mode)
+	})
+
+	t.Run("no phases anywhere", func(t *testing.T) {
+		emptyFile := filepath.Join(tmpDir, "empty.toml")
+		require.NoError(t, os.WriteFile(emptyFile, []byte("[defaults]\n"), 0644))
+
+		config, err := cli.ParseArgs([]string{"--config", emptyFile, "phases", "--", "echo", "test"})
+		require.NoError(t, err)
+		err = applyConfigFile(config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires --phase")
+	})
+}
+
 // createRunnerWithConfig creates a runner with config file settings applied
 func createRunnerWithConfig(config *cli.Config) (interface{}, error) {
 	// Import runner package at runtime to avoid circular imports
@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"syscall"
 	"time"
 
@@ -61,6 +64,15 @@ func main() {
 		os.Exit(2) // Usage error
 	}
 
+	// Dry run: print the projected schedule without executing anything
+	if config.DryRun {
+		if err := previewSchedule(config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Execute using the integrated runner system
 	if err := executeCommand(config); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -79,6 +91,7 @@ func showHelp() {
 	fmt.Println()
 	fmt.Println("USAGE:")
 	fmt.Println("  rpr [GLOBAL OPTIONS] <SUBCOMMAND> [OPTIONS] -- <COMMAND>")
+	fmt.Println("  rpr [GLOBAL OPTIONS] <SUBCOMMAND> [OPTIONS] <PROBE>")
 	fmt.Println()
 	fmt.Println("GLOBAL OPTIONS:")
 	fmt.Println("  --help, -h     Show help")
@@ -101,6 +114,7 @@ func showHelp() {
 	fmt.Println("ADAPTIVE SCHEDULING:")
 	fmt.Println("  adaptive, adapt, a     Execute command with adaptive scheduling")
 	fmt.Println("  load-adaptive, load, la Execute command with load-aware adaptive scheduling")
+	fmt.Println("  phases, ph             Run a sequence of schedules, one phase after another")
 	fmt.Println()
 	fmt.Println("RATE CONTROL:")
 	fmt.Println("  rate-limit, rate, rl   Execute command with server-friendly rate limiting")
@@ -114,6 +128,11 @@ func showHelp() {
 	fmt.Println("  --for, -f DURATION         Duration to keep running")
 	fmt.Println("  --cron EXPRESSION          Cron expression for scheduling (e.g., '0 9 * * *', '@daily')")
 	fmt.Println("  --timezone TZ              Timezone for cron scheduling (default: UTC)")
+	fmt.Println("  --mode MODE                fixed-rate (default) or fixed-delay (wait --every after each run)")
+	fmt.Println("  --align                    Fire on wall-clock multiples of --every (e.g., :00/:15/:30/:45)")
+	fmt.Println("  --jitter FLOAT             Randomize each interval by up to FLOAT x --every (0.0-1.0)")
+	fmt.Println("  --start-at TIME            Wait until TIME (e.g., 09:00, '2025-03-10 09:00', +30m)")
+	fmt.Println("  --deadline TIME            Stop at TIME, cancelling a running command")
 	fmt.Println()
 	fmt.Println("RETRY STRATEGY OPTIONS:")
 	fmt.Println("  --base-delay DURATION      Base delay for mathematical strategies (default: 1s)")
@@ -129,16 +148,67 @@ func showHelp() {
 	fmt.Println("  --target-cpu FLOAT         Target CPU usage % for load-adaptive (default: 70)")
 	fmt.Println("  --target-memory FLOAT      Target memory usage % for load-adaptive (default: 80)")
 	fmt.Println("  --target-load FLOAT        Target load average for load-adaptive (default: 1.0)")
+	fmt.Println("  --phase SPEC               Add a phase, e.g. 'interval; every=1s; for=1m' (repeatable)")
 	fmt.Println()
 	fmt.Println("RATE CONTROL OPTIONS:")
-	fmt.Println("  --rate, -r SPEC            Rate specification (e.g., 10/1h, 100/1m)")
+	fmt.Println("  --rate, -r SPEC            Rate windows (e.g., 10/1h, '10/s,1000/hour', '5 per minute'); throttles any subcommand")
+	fmt.Println("  --burst N                  Most executions admitted back to back (default: whole window)")
+	fmt.Println("  --rate-algorithm NAME      diophantine (default), token-bucket, gcra, sliding-log, sliding-counter")
 	fmt.Println("  --retry-pattern, -p SPEC   Retry pattern (e.g., 0,10m,30m)")
 	fmt.Println("  --show-next, -n            Show next allowed execution time")
+	fmt.Println("  --delay DURATION           Delay every scheduled execution (e.g., offset cron firings)")
+	fmt.Println("  --or-every DURATION        Also run every DURATION alongside the schedule")
+	fmt.Println("  --or-cron EXPRESSION       Also run on a cron schedule (in --timezone)")
+	fmt.Println("  --and-cron EXPRESSION      Run only once the cron schedule has fired too")
+	fmt.Println()
+	fmt.Println("RESPONSE-AWARE OPTIONS:")
+	fmt.Println("  --http-aware               Delay executions by Retry-After, JSON and quota timing in HTTP output")
+	fmt.Println("  --aware LIST               Response parsers to apply, e.g. grpc,aws,http (also: gcloud)")
+	fmt.Println("  --http-custom-fields LIST  JSON timing fields, e.g. 'error.details[*].retryDelay:duration'")
+	fmt.Println("  --http-success-codes LIST  HTTP statuses that succeed, others fail (e.g., 2xx,304)")
+	fmt.Println("  --http-retry-codes LIST    HTTP statuses that fail and keep retrying (e.g., 429,5xx)")
+	fmt.Println("  --http-fatal-codes LIST    HTTP statuses that abort the run (e.g., 401,403)")
+	fmt.Println()
+	fmt.Println("PROBES (run in place of a command):")
+	fmt.Println("  --http METHOD URL          Send an HTTP request in-process, e.g. --http GET https://svc/health")
+	fmt.Println("  --header 'NAME: VALUE'     Request header (repeatable)")
+	fmt.Println("  --data BODY                Request body")
+	fmt.Println("  --http-timeout DURATION    Per-request timeout")
+	fmt.Println("  --insecure                 Skip TLS certificate verification")
+	fmt.Println("  --follow                   Follow redirects")
+	fmt.Println("  --expect-status LIST       Statuses that pass (default: 2xx,3xx)")
+	fmt.Println("  --tcp HOST:PORT            Connect over TCP")
+	fmt.Println("  --udp HOST:PORT            Send a UDP datagram (use with --send)")
+	fmt.Println("  --unix PATH                Connect to a Unix socket")
+	fmt.Println("  --send DATA                Payload for --tcp/--udp/--unix (escapes like \\r\\n allowed)")
+	fmt.Println("  --expect TEXT              Reply, banner or DNS answer that must arrive")
+	fmt.Println("  --dns NAME                 Resolve NAME")
+	fmt.Println("  --dns-server HOST[:PORT]   DNS server to query (default: system resolver)")
+	fmt.Println("  --dns-type TYPE            A (default), AAAA or SRV")
+	fmt.Println("  --probe-timeout DURATION   Timeout for socket and DNS probes")
 	fmt.Println()
 	fmt.Println("LEGACY OPTIONS (DEPRECATED):")
 	fmt.Println("  --initial-delay, -i DUR    Initial interval for backoff (use --base-delay)")
 	fmt.Println("  --max, -x DUR              Maximum backoff interval (use --max-delay)")
-	fmt.Println("  --jitter FLOAT             Jitter factor 0.0-1.0 (default: 0.0)")
+	fmt.Println()
+	fmt.Println("SUCCESS CRITERIA:")
+	fmt.Println("  Pattern flags may be repeated; prefix a REGEX with stdout: or stderr: to match one stream")
+	fmt.Println("  --success-pattern REGEX    Output matching REGEX counts as success")
+	fmt.Println("  --failure-pattern REGEX    Output matching REGEX counts as failure")
+	fmt.Println("  --require-absent REGEX     Fail if REGEX appears at all, whatever --min-matches")
+	fmt.Println("  --fatal-pattern REGEX      Output matching REGEX aborts the run")
+	fmt.Println("  --match-mode any|all       Any or all success/failure patterns must match (default: any)")
+	fmt.Println("  --min-matches N            Success/failure patterns need N matches (default: 1)")
+	fmt.Println("  --fail-fast-on-pattern     Stop the command as soon as a failure or fatal pattern appears")
+	fmt.Println("  --succeed-on-pattern       Stop the command as a success once the success patterns appear")
+	fmt.Println("  --case-insensitive         Patterns ignore case")
+	fmt.Println("  --success-expr EXPR        Succeed only if EXPR holds, e.g. 'exit in [0,3] && duration < 2s'")
+	fmt.Println("  --failure-expr EXPR        Fail if EXPR holds, e.g. 'stderr =~ \"WARN\"'")
+	fmt.Println("  --expect-json PATH=VALUE   Succeed only if the JSON output holds VALUE at PATH (repeatable)")
+	fmt.Println("  --expect-json-expr EXPR    Succeed only if jq-style EXPR holds, e.g. '.items | length > 0'")
+	fmt.Println("  --success-codes LIST       Exit codes that succeed, others fail (e.g., 0,1 for grep)")
+	fmt.Println("  --retry-codes LIST         Exit codes that fail whatever the output (e.g., 75,111)")
+	fmt.Println("  --abort-codes LIST         Exit codes that abort the run (e.g., 2,64-78)")
 	fmt.Println()
 	fmt.Println("OUTPUT CONTROL:")
 	fmt.Println("  --quiet, -q                Suppress command output, show only tool errors")
@@ -146,6 +216,11 @@ func showHelp() {
 	fmt.Println("  --stats-only               Show only execution statistics")
 	fmt.Println("  --stream, -s               Force streaming output (default for pipeline mode)")
 	fmt.Println()
+	fmt.Println("DRY RUN OPTIONS:")
+	fmt.Println("  --dry-run                  Print the projected schedule without executing")
+	fmt.Println("  --preview N                Number of executions to preview (default: 10)")
+	fmt.Println("  --seed N                   Seed for randomized strategies (reproducible previews)")
+	fmt.Println()
 	fmt.Println("EXAMPLES:")
 	fmt.Println("  # Basic usage")
 	fmt.Println("  rpr interval --every 30s --times 10 -- curl http://example.com")
@@ -173,6 +248,18 @@ func showHelp() {
 	fmt.Println("  rpr load-adaptive --base-interval 1s --target-cpu 70 -- ./task.sh")
 	fmt.Println("  rpr cron --cron '0 9 * * *' -- ./daily-backup.sh  # Every day at 9 AM")
 	fmt.Println("  rpr cron --cron '@hourly' --timezone America/New_York -- curl api.com")
+	fmt.Println("  rpr cron --cron '*/5 * * * *' --rate 10/1h -- ./sync.sh  # Cron, never faster than 10/h")
+	fmt.Println("  rpr adaptive --base-interval 1s --or-every 5m -- ./check.sh  # Adaptive, and at least every 5m")
+	fmt.Println("  rpr phases --phase 'interval; every=1s; for=1m' --phase 'interval; every=5m' -- ./check.sh")
+	fmt.Println()
+	fmt.Println("  # Built-in probes")
+	fmt.Println("  rpr i -e 10s --http GET https://svc/health --expect-status 200 --http-timeout 2s")
+	fmt.Println("  rpr i -e 2s --tcp db:5432 --probe-timeout 1s  # Wait for a port during a deploy")
+	fmt.Println("  rpr i -e 30s --dns api.internal --dns-server 10.0.0.2 --expect 10.0.0.7")
+	fmt.Println()
+	fmt.Println("  # Schedule preview")
+	fmt.Println("  rpr cron --cron '0 9 * * 1-5' --timezone Europe/Berlin --preview 5")
+	fmt.Println("  rpr dj --base-delay 1s --attempts 6 --seed 42 --dry-run -- ./deploy.sh")
 	fmt.Println()
 	fmt.Println("  # Output modes")
 	fmt.Println("  rpr i -e 5s -t 3 --quiet -- curl https://api.com  # Silent")
@@ -425,13 +512,44 @@ func showSubcommandHelp(subcommand string) {
 		fmt.Println("  Executes command with mathematical rate limiting and burst support.")
 		fmt.Println()
 		fmt.Println("OPTIONS:")
-		fmt.Println("  --rate, -r SPEC              Rate specification (e.g., 10/1h, 100/1m)")
-		fmt.Println("  --retry-pattern, -p SPEC     Retry pattern (e.g., 0,10m,30m)")
+		fmt.Println("  --rate, -r SPEC              Rate windows, comma-separated (e.g., 10/1h, '10/s,1000/hour', '5 per minute')")
+		fmt.Println("  --burst N                    Most executions admitted back to back (default: whole window)")
+		fmt.Println("  --rate-algorithm NAME        diophantine (default), token-bucket, gcra, sliding-log, sliding-counter")
+		fmt.Println("  --retry-pattern, -p SPEC     Retry failed requests at these offsets (e.g., 0,10m,30m)")
 		fmt.Println("  --show-next, -n              Show next allowed execution time")
 		fmt.Println()
 		fmt.Println("EXAMPLES:")
 		fmt.Println("  rpr rate-limit --rate 100/1h -- curl https://api.github.com/user")
 		fmt.Println("  rpr rl -r 10/1m --show-next -- curl rate-limited-api.com")
+		fmt.Println("  rpr rl -r '10/s,1000/hour,10000/day' --burst 5 -- curl api.example.com")
+		fmt.Println("  rpr rl -r 100/1h -p 0,10m,30m -- curl -f api.example.com")
+
+	case "phases":
+		fmt.Println("Phased Execution Mode - Sequential multi-phase schedules")
+		fmt.Println()
+		fmt.Println("USAGE:")
+		fmt.Println("  rpr phases --phase SPEC [--phase SPEC...] [OPTIONS] -- <COMMAND>")
+		fmt.Println("  rpr ph --phase SPEC [--phase SPEC...] [OPTIONS] -- <COMMAND>")
+		fmt.Println()
+		fmt.Println("DESCRIPTION:")
+		fmt.Println("  Runs each phase's schedule in order. A phase ends after its own times or")
+		fmt.Println("  for limit; only the last phase may run forever. Phases can also be given")
+		fmt.Println("  as [[phases]] tables in the --config file.")
+		fmt.Println()
+		fmt.Println("PHASE SPEC:")
+		fmt.Println("  MODE; key=value; ...        MODE is interval, exponential, adaptive or cron")
+		fmt.Println("  name=NAME                   Phase name shown in verbose output and metrics")
+		fmt.Println("  times=N, for=DURATION       When the phase ends")
+		fmt.Println("  every=DURATION              interval")
+		fmt.Println("  base-delay, multiplier, max-delay, attempts   exponential")
+		fmt.Println("  base-interval, min-interval, max-interval     adaptive")
+		fmt.Println("  cron=EXPR, timezone=TZ      cron")
+		fmt.Println()
+		fmt.Println("EXAMPLES:")
+		fmt.Println("  rpr phases --phase 'interval; name=warmup; every=1s; for=1m' \\")
+		fmt.Println("             --phase 'interval; name=steady; every=10s; for=1h' \\")
+		fmt.Println("             --phase 'cron; cron=*/5 * * * *' -- ./check.sh")
+		fmt.Println("  rpr ph --phase 'exponential; base-delay=1s; attempts=5' --phase 'interval; every=1m' -- ./sync.sh")
 
 	default:
 		fmt.Printf("Help not available for subcommand: %s\n", subcommand)
@@ -478,6 +596,13 @@ func executeCommand(config *cli.Config) error {
 			// Interrupted by signal (Ctrl+C)
 			return &ExitError{Code: 130, Message: "interrupted"}
 		}
+		if errors.Is(err, runner.ErrAborted) {
+			// A fatal result stopped the run; still report what ran
+			if config.Verbose || config.StatsOnly {
+				showExecutionResults(stats)
+			}
+			return &ExitError{Code: 1, Message: err.Error()}
+		}
 		// Other execution errors
 		return &ExitError{Code: 1, Message: fmt.Sprintf("execution failed: %v", err)}
 	}
@@ -487,14 +612,75 @@ func executeCommand(config *cli.Config) error {
 		showExecutionResults(stats)
 	}
 
-	// Check if any commands failed
-	if stats != nil && stats.FailedExecutions > 0 {
+	// Check if any commands failed. Rate-limited requests with retries only
+	// fail once every attempt has.
+	if stats != nil && len(stats.Requests) > 0 {
+		for _, request := range stats.Requests {
+			if !request.Succeeded {
+				return &ExitError{Code: 1, Message: "some requests exhausted their retries"}
+			}
+		}
+	} else if stats != nil && stats.FailedExecutions > 0 {
 		return &ExitError{Code: 1, Message: "some commands failed"}
 	}
 
 	return nil
 }
 
+func previewSchedule(config *cli.Config) error {
+	r, err := runner.NewRunner(config)
+	if err != nil {
+		return fmt.Errorf("failed to create runner: %w", err)
+	}
+
+	start := time.Now()
+	entries, err := r.Preview(start, config.PreviewCount)
+	if err != nil {
+		return fmt.Errorf("preview failed: %w", err)
+	}
+
+	showSchedulePreview(config, start, entries)
+	return nil
+}
+
+// hasAdaptivePhase reports whether any phase adapts its interval at runtime
+func hasAdaptivePhase(phases []cli.PhaseConfig) bool {
+	for _, phase := range phases {
+		if phase.Mode == "adaptive" {
+			return true
+		}
+	}
+	return false
+}
+
+func showSchedulePreview(config *cli.Config, start time.Time, entries []runner.PreviewEntry) {
+	fmt.Printf("🔍 Dry run: next %d executions for %s (nothing will be executed)\n", len(entries), config.Subcommand)
+	switch config.Subcommand {
+	case "adaptive", "load-adaptive":
+		fmt.Printf("   Note: intervals adapt at runtime; showing the base interval %v\n", config.BaseInterval)
+	case "rate-limit":
+		fmt.Printf("   Assuming a request is ready whenever the limiter allows one\n")
+	case "phases":
+		if hasAdaptivePhase(config.Phases) {
+			fmt.Printf("   Note: adaptive phases are shown at their base interval\n")
+		}
+	}
+	fmt.Printf("   Starting from %s\n", start.Format("2006-01-02 15:04:05 MST"))
+
+	for _, entry := range entries {
+		fmt.Printf("   #%-4d %s  (+%v)", entry.ExecutionNumber,
+			entry.Time.Format("2006-01-02 15:04:05 MST"), entry.Delay.Round(time.Millisecond))
+		if entry.Phase != "" {
+			fmt.Printf("  [%s]", entry.Phase)
+		}
+		fmt.Println()
+	}
+
+	if len(config.Command) > 0 || config.HasProbe() {
+		fmt.Printf("📋 %s\n", describeCommand(config))
+	}
+}
+
 func showExecutionInfo(config *cli.Config) {
 	switch config.Subcommand {
 	case "interval":
@@ -505,6 +691,15 @@ func showExecutionInfo(config *cli.Config) {
 		if config.For > 0 {
 			fmt.Printf(", for %v", config.For)
 		}
+		if config.IntervalMode != "" {
+			fmt.Printf(", %s", config.IntervalMode)
+		}
+		if config.Align {
+			fmt.Printf(", aligned")
+		}
+		if config.Jitter > 0 {
+			fmt.Printf(", jitter %.0f%%", config.Jitter*100)
+		}
 	case "count":
 		fmt.Printf("🔢 Count execution: %d times", config.Times)
 		if config.Every > 0 {
@@ -557,6 +752,11 @@ func showExecutionInfo(config *cli.Config) {
 		if config.MaxDelay > 0 {
 			fmt.Printf(", max %v", config.MaxDelay)
 		}
+	case "phases":
+		fmt.Printf("🔀 Phased execution: %d phases", len(config.Phases))
+		for i, phase := range config.Phases {
+			fmt.Printf("\n   %d. %s: %s", i+1, phase.Name, phase)
+		}
 	case "load-adaptive":
 		fmt.Printf("⚖️  Load-adaptive execution: base interval %v", config.BaseInterval)
 		if config.TargetCPU > 0 {
@@ -569,10 +769,47 @@ func showExecutionInfo(config *cli.Config) {
 			fmt.Printf(", load %.1f", config.TargetLoad)
 		}
 	}
-	fmt.Printf("\n📋 Command: %v\n", config.Command)
+	if !config.StartAt.IsZero() {
+		fmt.Printf("\n⏰ Starting at %s", config.StartAt.Format(time.RFC3339))
+	}
+	if !config.Deadline.IsZero() {
+		fmt.Printf("\n🛑 Stopping at %s", config.Deadline.Format(time.RFC3339))
+	}
+	fmt.Printf("\n📋 %s\n", describeCommand(config))
 	fmt.Println("🚀 Starting execution...")
 }
 
+// formatExitCodes lists how many executions exited with each code, lowest
+// first, e.g. "0 ×7, 1 ×2, signal ×1"
+func formatExitCodes(counts map[int]int) string {
+	codes := make([]int, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	if len(codes) > 0 && codes[0] < 0 {
+		codes = append(codes[1:], codes[0]) // commands ended by a signal last
+	}
+
+	parts := make([]string, 0, len(codes))
+	for _, code := range codes {
+		name := fmt.Sprint(code)
+		if code < 0 {
+			name = "signal"
+		}
+		parts = append(parts, fmt.Sprintf("%s ×%d", name, counts[code]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeCommand names what each execution runs: the command or a built-in probe
+func describeCommand(config *cli.Config) string {
+	if config.HasProbe() {
+		return "Probe: " + config.ProbeTarget()
+	}
+	return fmt.Sprintf("Command: %v", config.Command)
+}
+
 func showExecutionResults(stats *runner.ExecutionStats) {
 	if stats == nil {
 		return
@@ -584,6 +821,20 @@ func showExecutionResults(stats *runner.ExecutionStats) {
 	fmt.Printf("   Successful: %d\n", stats.SuccessfulExecutions)
 	fmt.Printf("   Failed: %d\n", stats.FailedExecutions)
 	fmt.Printf("   Duration: %v\n", stats.Duration.Round(time.Millisecond))
+	if len(stats.ExitCodes) > 0 {
+		fmt.Printf("   Exit codes: %s\n", formatExitCodes(stats.ExitCodes))
+	}
+
+	if len(stats.Requests) > 0 {
+		fmt.Printf("   Requests: %d\n", len(stats.Requests))
+		for _, request := range stats.Requests {
+			if request.Succeeded {
+				fmt.Printf("     #%d succeeded on attempt %d\n", request.RequestNumber, request.Attempts)
+			} else {
+				fmt.Printf("     #%d exhausted after %d attempts\n", request.RequestNumber, request.Attempts)
+			}
+		}
+	}
 
 	if stats.FailedExecutions > 0 {
 		fmt.Printf("\n⚠️  Some executions failed. Check command output above.\n")
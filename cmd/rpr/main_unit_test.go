@@ -168,6 +168,18 @@ func TestShowExecutionResults(t *testing.T) {
 				"⚠️  Some executions failed. Check command output above.",
 			},
 		},
+		{
+			name: "exit code breakdown",
+			stats: &runner.ExecutionStats{
+				TotalExecutions:      6,
+				SuccessfulExecutions: 5,
+				FailedExecutions:     1,
+				ExitCodes:            map[int]int{1: 2, 0: 3, -1: 1},
+			},
+			expected: []string{
+				"Exit codes: 0 ×3, 1 ×2, signal ×1",
+			},
+		},
 		{
 			name: "all failed execution stats",
 			stats: &runner.ExecutionStats{
@@ -156,6 +156,31 @@ func TestConfig_GetHTTPAwareConfig(t *testing.T) {
 				JSONFields:        []string{"custom_retry", "backoff_delay"},
 			},
 		},
+		{
+			name: "aware parsers enable response analysis",
+			config: Config{
+				Aware: []string{"grpc", "aws"},
+			},
+			expected: &httpaware.HTTPAwareConfig{
+				MaxDelay:   30 * time.Minute,
+				MinDelay:   1 * time.Second,
+				JSONFields: []string{"retry_after", "retryAfter"},
+				Parsers:    []string{"grpc", "aws"},
+			},
+		},
+		{
+			name: "aware parsers with http-aware add http",
+			config: Config{
+				HTTPAware: true,
+				Aware:     []string{"grpc"},
+			},
+			expected: &httpaware.HTTPAwareConfig{
+				MaxDelay:   30 * time.Minute,
+				MinDelay:   1 * time.Second,
+				JSONFields: []string{"retry_after", "retryAfter"},
+				Parsers:    []string{"grpc", "http"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -172,6 +197,7 @@ func TestConfig_GetHTTPAwareConfig(t *testing.T) {
 				assert.Equal(t, tt.expected.ParseHeaders, result.ParseHeaders)
 				assert.Equal(t, tt.expected.TrustClientErrors, result.TrustClientErrors)
 				assert.Equal(t, tt.expected.JSONFields, result.JSONFields)
+				assert.Equal(t, tt.expected.Parsers, result.Parsers)
 			}
 		})
 	}
@@ -206,11 +232,42 @@ func TestCLI_HTTPAwareFlagValidation(t *testing.T) {
 			args:        []string{"interval", "--every", "30s", "--http-aware", "--http-custom-fields", "", "--", "curl", "api.com"},
 			expectError: false,
 		},
+		{
+			name:        "custom field selectors with units",
+			args:        []string{"interval", "--every", "30s", "--http-aware", "--http-custom-fields", "error.details[*].retryDelay:duration, meta.rateLimit.resetAt:rfc3339", "--", "curl", "api.com"},
+			expectError: false,
+		},
+		{
+			name:        "custom field with unknown unit",
+			args:        []string{"interval", "--every", "30s", "--http-aware", "--http-custom-fields", "retry:fortnights", "--", "curl", "api.com"},
+			expectError: true,
+			errorMsg:    "unknown unit",
+		},
+		{
+			name:        "aware parsers",
+			args:        []string{"interval", "--every", "30s", "--aware", "grpc,aws,http", "--", "grpcurl", "api.com:443", "svc/Method"},
+			expectError: false,
+		},
+		{
+			name:        "unknown aware parser",
+			args:        []string{"interval", "--every", "30s", "--aware", "grpc,soap", "--", "grpcurl", "api.com:443", "svc/Method"},
+			expectError: true,
+			errorMsg:    `unknown parser "soap"`,
+		},
+		{
+			name:        "custom field with invalid index",
+			args:        []string{"interval", "--every", "30s", "--http-aware", "--http-custom-fields", "error.details[x].retryDelay", "--", "curl", "api.com"},
+			expectError: true,
+			errorMsg:    "invalid --http-custom-fields",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, err := ParseArgs(tt.args)
+			config, err := ParseArgs(tt.args)
+			if err == nil {
+				err = ValidateConfig(config)
+			}
 
 			if tt.expectError {
 				require.Error(t, err)
@@ -47,6 +47,70 @@ func TestCLI_PatternMatchingFlags(t *testing.T) {
 				Command:         []string{"echo", "test"},
 			},
 		},
+		{
+			name: "http status code flags",
+			args: []string{"interval", "--every", "30s", "--http-success-codes", "2xx,304", "--http-retry-codes", "429,5xx", "--http-fatal-codes", "401,403", "--", "curl", "-i", "example.com"},
+			expected: Config{
+				Subcommand:       "interval",
+				Every:            30 * time.Second,
+				HTTPSuccessCodes: "2xx,304",
+				HTTPRetryCodes:   "429,5xx",
+				HTTPFatalCodes:   "401,403",
+				Command:          []string{"curl", "-i", "example.com"},
+			},
+		},
+		{
+			name: "repeated and targeted patterns",
+			args: []string{"interval", "--every", "30s", "--success-pattern", "stdout:migrated", "--success-pattern", "stdout:seeded", "--match-mode", "all",
+				"--failure-pattern", "stderr:ERROR", "--min-matches", "3", "--require-absent", "deprecated", "--fatal-pattern", "stderr:panic:", "--fatal-pattern", "OOMKilled", "--", "./migrate.sh"},
+			expected: Config{
+				Subcommand:      "interval",
+				Every:           30 * time.Second,
+				SuccessPattern:  "stdout:migrated",
+				SuccessPatterns: []string{"stdout:seeded"},
+				FailurePattern:  "stderr:ERROR",
+				RequireAbsent:   []string{"deprecated"},
+				FatalPatterns:   []string{"stderr:panic:", "OOMKilled"},
+				MatchMode:       "all",
+				MinMatches:      3,
+				Command:         []string{"./migrate.sh"},
+			},
+		},
+		{
+			name: "early decisions",
+			args: []string{"count", "--times", "1", "--success-pattern", "listening", "--failure-pattern", "stderr:FATAL", "--fail-fast-on-pattern", "--succeed-on-pattern", "--", "./server"},
+			expected: Config{
+				Subcommand:        "count",
+				SuccessPattern:    "listening",
+				FailurePattern:    "stderr:FATAL",
+				FailFastOnPattern: true,
+				SucceedOnPattern:  true,
+				Command:           []string{"./server"},
+			},
+		},
+		{
+			name: "json expectations",
+			args: []string{"interval", "--every", "30s", "--expect-json", ".status=ok", "--expect-json", ".replicas.ready=3", "--expect-json-expr", ".items | length > 0", "--", "curl", "-s", "example.com"},
+			expected: Config{
+				Subcommand:     "interval",
+				Every:          30 * time.Second,
+				ExpectJSON:     []string{".status=ok", ".replicas.ready=3"},
+				ExpectJSONExpr: ".items | length > 0",
+				Command:        []string{"curl", "-s", "example.com"},
+			},
+		},
+		{
+			name: "exit code mapping",
+			args: []string{"exponential", "--base-delay", "1s", "--success-codes", "0,1", "--retry-codes", "75,111", "--abort-codes", "2,64-74", "--", "grep", "-q", "ready", "status.txt"},
+			expected: Config{
+				Subcommand:   "exponential",
+				BaseDelay:    time.Second,
+				SuccessCodes: "0,1",
+				RetryCodes:   "75,111",
+				AbortCodes:   "2,64-74",
+				Command:      []string{"grep", "-q", "ready", "status.txt"},
+			},
+		},
 		{
 			name: "adaptive with patterns",
 			args: []string{"adaptive", "--base-interval", "1s", "--success-pattern", "completed", "--", "build.sh"},
@@ -70,6 +134,22 @@ func TestCLI_PatternMatchingFlags(t *testing.T) {
 			assert.Equal(t, tt.expected.SuccessPattern, config.SuccessPattern)
 			assert.Equal(t, tt.expected.FailurePattern, config.FailurePattern)
 			assert.Equal(t, tt.expected.CaseInsensitive, config.CaseInsensitive)
+			assert.Equal(t, tt.expected.SuccessPatterns, config.SuccessPatterns)
+			assert.Equal(t, tt.expected.FailurePatterns, config.FailurePatterns)
+			assert.Equal(t, tt.expected.RequireAbsent, config.RequireAbsent)
+			assert.Equal(t, tt.expected.FatalPatterns, config.FatalPatterns)
+			assert.Equal(t, tt.expected.MatchMode, config.MatchMode)
+			assert.Equal(t, tt.expected.MinMatches, config.MinMatches)
+			assert.Equal(t, tt.expected.FailFastOnPattern, config.FailFastOnPattern)
+			assert.Equal(t, tt.expected.SucceedOnPattern, config.SucceedOnPattern)
+			assert.Equal(t, tt.expected.HTTPSuccessCodes, config.HTTPSuccessCodes)
+			assert.Equal(t, tt.expected.HTTPRetryCodes, config.HTTPRetryCodes)
+			assert.Equal(t, tt.expected.HTTPFatalCodes, config.HTTPFatalCodes)
+			assert.Equal(t, tt.expected.ExpectJSON, config.ExpectJSON)
+			assert.Equal(t, tt.expected.ExpectJSONExpr, config.ExpectJSONExpr)
+			assert.Equal(t, tt.expected.SuccessCodes, config.SuccessCodes)
+			assert.Equal(t, tt.expected.RetryCodes, config.RetryCodes)
+			assert.Equal(t, tt.expected.AbortCodes, config.AbortCodes)
 			assert.Equal(t, tt.expected.Command, config.Command)
 		})
 	}
@@ -99,6 +179,112 @@ func TestCLI_PatternValidation(t *testing.T) {
 			args:        []string{"interval", "--every", "30s", "--success-pattern", "success", "--failure-pattern", "(?i)error", "--", "echo", "test"},
 			expectError: false,
 		},
+		{
+			name:        "invalid repeated success pattern",
+			args:        []string{"interval", "--every", "30s", "--success-pattern", "ok", "--success-pattern", "stdout:(", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    "invalid success pattern",
+		},
+		{
+			name:        "invalid fatal pattern",
+			args:        []string{"interval", "--every", "30s", "--fatal-pattern", "stderr:[", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    "invalid fatal pattern",
+		},
+		{
+			name:        "invalid match mode",
+			args:        []string{"interval", "--every", "30s", "--success-pattern", "ok", "--match-mode", "most", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    `invalid --match-mode: invalid match mode "most"`,
+		},
+		{
+			name:        "min matches without patterns",
+			args:        []string{"interval", "--every", "30s", "--require-absent", "WARN", "--min-matches", "3", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    "--min-matches requires --success-pattern or --failure-pattern",
+		},
+		{
+			name:        "fail fast without failure patterns",
+			args:        []string{"interval", "--every", "30s", "--success-pattern", "ok", "--fail-fast-on-pattern", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    "--fail-fast-on-pattern requires --failure-pattern, --require-absent or --fatal-pattern",
+		},
+		{
+			name:        "succeed on pattern without success patterns",
+			args:        []string{"interval", "--every", "30s", "--fatal-pattern", "panic", "--succeed-on-pattern", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    "--succeed-on-pattern requires --success-pattern",
+		},
+		{
+			name:        "succeed on pattern with an expression",
+			args:        []string{"interval", "--every", "30s", "--success-pattern", "ok", "--success-expr", "exit == 0", "--succeed-on-pattern", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    "--succeed-on-pattern cannot be combined with expressions",
+		},
+		{
+			name:        "invalid success expression",
+			args:        []string{"interval", "--every", "30s", "--success-expr", "exit == 0 && duration <", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    "invalid --success-expr: unexpected end of expression at column 24",
+		},
+		{
+			name:        "invalid failure expression",
+			args:        []string{"interval", "--every", "30s", "--failure-expr", `stderr =~ "("`, "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    "invalid --failure-expr: invalid regular expression",
+		},
+		{
+			name:        "invalid json expectation",
+			args:        []string{"interval", "--every", "30s", "--expect-json", "status=ok", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    `invalid --expect-json: invalid path "status"`,
+		},
+		{
+			name:        "invalid json expression",
+			args:        []string{"interval", "--every", "30s", "--expect-json-expr", ".items | keys", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    "invalid --expect-json-expr: unsupported filter",
+		},
+		{
+			name:        "valid expressions",
+			args:        []string{"interval", "--every", "30s", "--success-expr", "exit in [0,3] && duration < 2s", "--failure-expr", `json(stdout).status != "green"`, "--", "echo", "test"},
+			expectError: false,
+		},
+		{
+			name:        "invalid exit code range",
+			args:        []string{"interval", "--every", "30s", "--abort-codes", "78-64", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    `invalid --abort-codes: invalid exit code range "78-64"`,
+		},
+		{
+			name:        "exit code out of range",
+			args:        []string{"interval", "--every", "30s", "--success-codes", "0,256", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    `invalid --success-codes: invalid exit code "256"`,
+		},
+		{
+			name:        "overlapping exit codes",
+			args:        []string{"interval", "--every", "30s", "--retry-codes", "75", "--abort-codes", "64-78", "--", "echo", "test"},
+			expectError: true,
+			errorMsg:    "conflicting exit codes: exit code 75 is in both retry and abort codes",
+		},
+		{
+			name:        "invalid http success codes",
+			args:        []string{"interval", "--every", "30s", "--http-success-codes", "2xx,abc", "--", "curl", "-i", "example.com"},
+			expectError: true,
+			errorMsg:    "invalid --http-success-codes",
+		},
+		{
+			name:        "invalid http fatal codes",
+			args:        []string{"interval", "--every", "30s", "--http-fatal-codes", "7xx", "--", "curl", "-i", "example.com"},
+			expectError: true,
+			errorMsg:    "invalid --http-fatal-codes",
+		},
+		{
+			name:        "valid http status codes",
+			args:        []string{"interval", "--every", "30s", "--http-success-codes", "2xx,304", "--http-retry-codes", "429,5xx", "--http-fatal-codes", "401,403", "--", "curl", "-i", "example.com"},
+			expectError: false,
+		},
 		{
 			name:        "case insensitive with valid patterns",
 			args:        []string{"interval", "--every", "30s", "--success-pattern", "SUCCESS", "--case-insensitive", "--", "echo", "test"},
@@ -171,6 +357,17 @@ func TestConfig_GetPatternConfig(t *testing.T) {
 				CaseInsensitive: true,
 			},
 		},
+		{
+			name: "http status codes only",
+			config: Config{
+				Subcommand:     "interval",
+				Every:          30 * time.Second,
+				HTTPFatalCodes: "401,403",
+			},
+			expected: &patterns.PatternConfig{
+				HTTPFatalCodes: "401,403",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -184,6 +381,7 @@ func TestConfig_GetPatternConfig(t *testing.T) {
 				assert.Equal(t, tt.expected.SuccessPattern, result.SuccessPattern)
 				assert.Equal(t, tt.expected.FailurePattern, result.FailurePattern)
 				assert.Equal(t, tt.expected.CaseInsensitive, result.CaseInsensitive)
+				assert.Equal(t, tt.expected.HTTPFatalCodes, result.HTTPFatalCodes)
 			}
 		})
 	}
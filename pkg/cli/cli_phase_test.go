@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePhaseSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    PhaseConfig
+		wantErr string
+	}{
+		{
+			name: "interval with limits",
+			spec: "interval; name=warmup; every=1s; for=1m; times=30",
+			want: PhaseConfig{Name: "warmup", Mode: "interval", Every: time.Second, For: time.Minute, Times: 30},
+		},
+		{
+			name: "exponential",
+			spec: "exponential; base-delay=500ms; multiplier=3; max-delay=10s; attempts=4",
+			want: PhaseConfig{Mode: "exponential", BaseDelay: 500 * time.Millisecond, Multiplier: 3, MaxDelay: 10 * time.Second, Attempts: 4},
+		},
+		{
+			name: "cron with spaces and mode key",
+			spec: "mode=cron; cron=*/5 * * * *; tz=Europe/Berlin",
+			want: PhaseConfig{Mode: "cron", CronExpression: "*/5 * * * *", Timezone: "Europe/Berlin"},
+		},
+		{name: "missing mode", spec: "every=1s", wantErr: "phase mode required"},
+		{name: "unknown key", spec: "interval; often=1s", wantErr: "unknown phase key"},
+		{name: "bad duration", spec: "interval; every=soon", wantErr: "invalid every value"},
+		{name: "bare word after mode", spec: "interval; forever", wantErr: "expected key=value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			phase, err := ParsePhaseSpec(tt.spec)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, phase)
+		})
+	}
+}
+
+func TestPhasesSubcommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name: "interval then cron",
+			args: []string{"phases", "--phase", "interval; every=1s; for=1m", "--phase", "cron; cron=@hourly", "--", "echo", "test"},
+		},
+		{
+			name: "alias with adaptive",
+			args: []string{"ph", "--phase", "exponential; base-delay=1s", "--phase", "adaptive; base-interval=1s", "--", "echo", "test"},
+		},
+		{
+			name:    "no phases",
+			args:    []string{"phases", "--", "echo", "test"},
+			wantErr: "at least one --phase",
+		},
+		{
+			name:    "endless first phase",
+			args:    []string{"phases", "--phase", "interval; every=1s", "--phase", "interval; every=1m", "--", "echo", "test"},
+			wantErr: "never ends",
+		},
+		{
+			name:    "unknown mode",
+			args:    []string{"phases", "--phase", "fibonacci; base-delay=1s", "--", "echo", "test"},
+			wantErr: "unknown phase mode",
+		},
+		{
+			name:    "interval without every",
+			args:    []string{"phases", "--phase", "interval; times=3", "--", "echo", "test"},
+			wantErr: "requires every",
+		},
+		{
+			name:    "invalid cron",
+			args:    []string{"phases", "--phase", "cron; cron=* *", "--", "echo", "test"},
+			wantErr: "5 fields",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseArgs(tt.args)
+			if err == nil {
+				err = ValidateConfig(config)
+			}
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "phases", config.Subcommand)
+		})
+	}
+}
+
+func TestValidatePhasesAppliesDefaults(t *testing.T) {
+	config, err := ParseArgs([]string{"phases",
+		"--phase", "exponential; base-delay=1s",
+		"--phase", "adaptive; name=tail; base-interval=1s",
+		"--", "echo", "test"})
+	require.NoError(t, err)
+	require.NoError(t, ValidateConfig(config))
+
+	retry := config.Phases[0]
+	assert.Equal(t, "phase-1", retry.Name)
+	assert.Equal(t, 3, retry.Attempts)
+	assert.Equal(t, int64(3), retry.Times, "a retry phase ends after its attempts")
+	assert.Equal(t, 2.0, retry.Multiplier)
+
+	tail := config.Phases[1]
+	assert.Equal(t, "tail", tail.Name)
+	assert.Equal(t, 100*time.Millisecond, tail.MinInterval)
+	assert.Equal(t, 30*time.Second, tail.MaxInterval)
+	assert.Equal(t, 2.0, config.SlowThreshold)
+
+	derived := config.ForPhase(tail)
+	assert.Equal(t, "adaptive", derived.Subcommand)
+	assert.Equal(t, time.Second, derived.BaseInterval)
+	assert.Equal(t, int64(0), derived.Times)
+}
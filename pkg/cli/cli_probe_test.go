@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCLI_HTTPProbeFlags(t *testing.T) {
+	config, err := ParseArgs([]string{
+		"i", "-e", "10s", "--http", "POST", "https://svc/health",
+		"--header", "Authorization: Bearer token", "--header", "Accept: application/json",
+		"--data", `{"ping": true}`, "--http-timeout", "2s", "--insecure", "--follow", "--expect-status", "2xx,304",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "interval", config.Subcommand)
+	assert.Empty(t, config.Command)
+	assert.True(t, config.HasProbe())
+
+	probe := config.GetHTTPProbeConfig()
+	require.NotNil(t, probe)
+	assert.Equal(t, "POST", probe.Method)
+	assert.Equal(t, "https://svc/health", probe.URL)
+	assert.Equal(t, []string{"Authorization: Bearer token", "Accept: application/json"}, probe.Headers)
+	assert.Equal(t, `{"ping": true}`, probe.Data)
+	assert.Equal(t, 2*time.Second, probe.Timeout)
+	assert.True(t, probe.Insecure)
+	assert.True(t, probe.Follow)
+	assert.Equal(t, "2xx,304", probe.ExpectStatus)
+}
+
+func TestCLI_HTTPProbeValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		errorMsg string
+	}{
+		{
+			name: "probe with dry run",
+			args: []string{"interval", "--every", "10s", "--http", "GET", "http://localhost:8080/", "--dry-run"},
+		},
+		{
+			name:     "missing URL",
+			args:     []string{"interval", "--every", "10s", "--http", "GET"},
+			errorMsg: "--http requires a method and a URL",
+		},
+		{
+			name:     "probe and command",
+			args:     []string{"interval", "--every", "10s", "--http", "GET", "http://localhost/", "--", "curl", "localhost"},
+			errorMsg: "in place of a command",
+		},
+		{
+			name:     "invalid URL",
+			args:     []string{"interval", "--every", "10s", "--http", "GET", "localhost:8080"},
+			errorMsg: "invalid --http",
+		},
+		{
+			name:     "invalid header",
+			args:     []string{"interval", "--every", "10s", "--http", "GET", "http://localhost/", "--header", "no-colon"},
+			errorMsg: "invalid header",
+		},
+		{
+			name:     "invalid expected status",
+			args:     []string{"interval", "--every", "10s", "--http", "GET", "http://localhost/", "--expect-status", "2yy"},
+			errorMsg: "invalid expected status",
+		},
+		{
+			name:     "probe flag without --http",
+			args:     []string{"interval", "--every", "10s", "--follow", "--", "curl", "localhost"},
+			errorMsg: "require --http",
+		},
+		{
+			name:     "no command and no probe",
+			args:     []string{"interval", "--every", "10s"},
+			errorMsg: "command required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseArgs(tt.args)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestCLI_SocketAndDNSProbeFlags(t *testing.T) {
+	config, err := ParseArgs([]string{
+		"i", "-e", "5s", "--tcp", "localhost:25", "--send", `EHLO test\r\n`, "--expect", "250", "--probe-timeout", "2s",
+	})
+	require.NoError(t, err)
+
+	assert.True(t, config.HasProbe())
+	assert.Equal(t, "tcp localhost:25", config.ProbeTarget())
+	assert.Nil(t, config.GetHTTPProbeConfig())
+	assert.Nil(t, config.GetDNSProbeConfig())
+
+	socket := config.GetSocketProbeConfig()
+	require.NotNil(t, socket)
+	assert.Equal(t, "tcp", socket.Network)
+	assert.Equal(t, "localhost:25", socket.Address)
+	assert.Equal(t, `EHLO test\r\n`, socket.Send)
+	assert.Equal(t, "250", socket.Expect)
+	assert.Equal(t, 2*time.Second, socket.Timeout)
+
+	config, err = ParseArgs([]string{
+		"c", "-t", "3", "--dns", "_db._tcp.internal", "--dns-server", "10.0.0.2", "--dns-type", "SRV", "--expect", "db1.internal",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "dns _db._tcp.internal", config.ProbeTarget())
+	assert.Nil(t, config.GetSocketProbeConfig())
+
+	dns := config.GetDNSProbeConfig()
+	require.NotNil(t, dns)
+	assert.Equal(t, "_db._tcp.internal", dns.Name)
+	assert.Equal(t, "10.0.0.2", dns.Server)
+	assert.Equal(t, "SRV", dns.Type)
+	assert.Equal(t, "db1.internal", dns.Expect)
+}
+
+func TestCLI_SocketAndDNSProbeValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		errorMsg string
+	}{
+		{
+			name: "unix socket",
+			args: []string{"interval", "--every", "1s", "--unix", "/run/app.sock", "--send", `PING\n`, "--expect", "PONG"},
+		},
+		{
+			name: "udp with payload",
+			args: []string{"interval", "--every", "1s", "--udp", "127.0.0.1:514", "--send", "hello"},
+		},
+		{
+			name:     "two probes",
+			args:     []string{"interval", "--every", "1s", "--tcp", "localhost:80", "--dns", "example.com"},
+			errorMsg: "only one probe can run at a time, got --tcp and --dns",
+		},
+		{
+			name:     "probe and command",
+			args:     []string{"interval", "--every", "1s", "--tcp", "localhost:80", "--", "nc", "-z", "localhost", "80"},
+			errorMsg: "--tcp runs in place of a command",
+		},
+		{
+			name:     "invalid address",
+			args:     []string{"interval", "--every", "1s", "--tcp", "localhost"},
+			errorMsg: "invalid --tcp",
+		},
+		{
+			name:     "udp expect without payload",
+			args:     []string{"interval", "--every", "1s", "--udp", "localhost:53", "--expect", "x"},
+			errorMsg: "needs a payload",
+		},
+		{
+			name:     "send with dns",
+			args:     []string{"interval", "--every", "1s", "--dns", "example.com", "--send", "x"},
+			errorMsg: "--send requires --tcp, --udp or --unix",
+		},
+		{
+			name:     "expect without probe",
+			args:     []string{"interval", "--every", "1s", "--expect", "ok", "--", "echo", "ok"},
+			errorMsg: "require --tcp, --udp, --unix or --dns",
+		},
+		{
+			name:     "dns server without dns",
+			args:     []string{"interval", "--every", "1s", "--tcp", "localhost:80", "--dns-server", "10.0.0.2"},
+			errorMsg: "require --dns",
+		},
+		{
+			name:     "unsupported record type",
+			args:     []string{"interval", "--every", "1s", "--dns", "example.com", "--dns-type", "MX"},
+			errorMsg: "unsupported record type",
+		},
+		{
+			name:     "negative timeout",
+			args:     []string{"interval", "--every", "1s", "--tcp", "localhost:80", "--probe-timeout", "-1s"},
+			errorMsg: "--probe-timeout must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseArgs(tt.args)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
@@ -891,3 +891,161 @@ func TestStatsOnlyFlag(t *testing.T) {
 		})
 	}
 }
+
+// TestDryRunFlags tests the schedule preview flags
+func TestDryRunFlags(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantDryRun  bool
+		wantPreview int
+		wantSeed    int64
+		wantCommand []string
+		wantErr     bool
+	}{
+		{
+			name:        "dry run without command",
+			args:        []string{"cron", "--cron", "@daily", "--dry-run"},
+			wantDryRun:  true,
+			wantPreview: 10,
+		},
+		{
+			name:        "preview implies dry run",
+			args:        []string{"interval", "--every", "5s", "--preview", "3", "--", "echo", "test"},
+			wantDryRun:  true,
+			wantPreview: 3,
+			wantCommand: []string{"echo", "test"},
+		},
+		{
+			name:        "seed with strategy",
+			args:        []string{"dj", "--base-delay", "1s", "--seed", "42", "--dry-run"},
+			wantDryRun:  true,
+			wantPreview: 10,
+			wantSeed:    42,
+		},
+		{
+			name:        "seed without dry run",
+			args:        []string{"dj", "--base-delay", "1s", "--seed", "7", "--", "echo", "test"},
+			wantSeed:    7,
+			wantCommand: []string{"echo", "test"},
+		},
+		{
+			name:    "invalid preview count",
+			args:    []string{"interval", "--every", "5s", "--preview", "many"},
+			wantErr: true,
+		},
+		{
+			name:    "negative preview count",
+			args:    []string{"interval", "--every", "5s", "--preview", "-1"},
+			wantErr: true,
+		},
+		{
+			name:    "command still required without dry run",
+			args:    []string{"interval", "--every", "5s"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseArgs(tt.args)
+			if err == nil {
+				err = ValidateConfig(config)
+			}
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantDryRun, config.DryRun)
+			assert.Equal(t, tt.wantPreview, config.PreviewCount)
+			assert.Equal(t, tt.wantSeed, config.Seed)
+			assert.Equal(t, tt.wantCommand, config.Command)
+		})
+	}
+}
+
+// TestScheduleModifierFlags tests --rate and --delay outside the rate-limit subcommand
+func TestScheduleModifierFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantRate  string
+		wantDelay time.Duration
+		wantErr   bool
+	}{
+		{
+			name:     "rate on cron",
+			args:     []string{"cron", "--cron", "*/5 * * * *", "--rate", "10/1h", "--", "echo", "test"},
+			wantRate: "10/1h",
+		},
+		{
+			name:      "delay and rate on interval",
+			args:      []string{"interval", "--every", "1m", "--delay", "30s", "-r", "2/1m", "--", "echo", "test"},
+			wantRate:  "2/1m",
+			wantDelay: 30 * time.Second,
+		},
+		{
+			name:    "invalid rate on exponential",
+			args:    []string{"exponential", "--base-delay", "1s", "--rate", "fast", "--", "echo", "test"},
+			wantErr: true,
+		},
+		{
+			name:    "negative delay",
+			args:    []string{"interval", "--every", "1m", "--delay", "-5s", "--", "echo", "test"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseArgs(tt.args)
+			if err == nil {
+				err = ValidateConfig(config)
+			}
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRate, config.RateSpec)
+			assert.Equal(t, tt.wantDelay, config.Delay)
+		})
+	}
+}
+
+// TestScheduleOperandFlags tests --or-every, --or-cron and --and-cron
+func TestScheduleOperandFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "adaptive or every", args: []string{"adaptive", "--base-interval", "1s", "--or-every", "5m", "--", "echo"}},
+		{name: "interval or cron", args: []string{"interval", "--every", "10m", "--or-cron", "0 9 * * *", "--", "echo"}},
+		{name: "interval and cron", args: []string{"interval", "--every", "1m", "--and-cron", "*/15 9-17 * * 1-5", "--", "echo"}},
+		{name: "negative or every", args: []string{"cron", "--cron", "@hourly", "--or-every", "-5m", "--", "echo"}, wantErr: "--or-every must be positive"},
+		{name: "bad or cron", args: []string{"interval", "--every", "1m", "--or-cron", "every day", "--", "echo"}, wantErr: "invalid --or-cron"},
+		{name: "bad and cron", args: []string{"interval", "--every", "1m", "--and-cron", "61 * * * *", "--", "echo"}, wantErr: "invalid --and-cron"},
+		{name: "rate-limit", args: []string{"rate-limit", "--rate", "10/1h", "--or-every", "5m", "--", "echo"}, wantErr: "do not apply to rate-limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseArgs(tt.args)
+			if err == nil {
+				err = ValidateConfig(config)
+			}
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"offset", "+30m", now.Add(30 * time.Minute), false},
+		{"rfc3339", "2025-03-11T09:00:00+01:00", time.Date(2025, 3, 11, 8, 0, 0, 0, time.UTC), false},
+		{"date and time", "2025-03-11 09:30", time.Date(2025, 3, 11, 9, 30, 0, 0, time.UTC), false},
+		{"date only", "2025-03-11", time.Date(2025, 3, 11, 0, 0, 0, 0, time.UTC), false},
+		{"later today", "17:00", time.Date(2025, 3, 10, 17, 0, 0, 0, time.UTC), false},
+		{"already passed today", "09:00", time.Date(2025, 3, 11, 9, 0, 0, 0, time.UTC), false},
+		{"bad offset", "+soon", time.Time{}, true},
+		{"garbage", "tomorrow-ish", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTime(tt.value, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "want %v, got %v", tt.want, got)
+		})
+	}
+}
+
+func TestIntervalTimingFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "fixed delay", args: []string{"interval", "--every", "10s", "--mode", "fixed-delay", "--", "echo"}},
+		{name: "aligned count", args: []string{"count", "--times", "3", "--every", "15m", "--align", "--", "echo"}},
+		{name: "start and deadline", args: []string{"cron", "--cron", "@hourly", "--start-at", "+1h", "--deadline", "+2h", "--", "echo"}},
+		{name: "unknown mode", args: []string{"interval", "--every", "1s", "--mode", "fixed-ish", "--", "echo"}, wantErr: "invalid --mode"},
+		{name: "mode on cron", args: []string{"cron", "--cron", "@hourly", "--mode", "fixed-delay", "--", "echo"}, wantErr: "apply only to interval"},
+		{name: "jitter", args: []string{"interval", "--every", "10s", "--jitter", "0.2", "--seed", "7", "--", "echo"}},
+		{name: "jitter too large", args: []string{"interval", "--every", "10s", "--jitter", "1.5", "--", "echo"}, wantErr: "--jitter must be between 0 and 1"},
+		{name: "jitter on cron", args: []string{"cron", "--cron", "@hourly", "--jitter", "0.1", "--", "echo"}, wantErr: "apply only to interval"},
+		{name: "align without every", args: []string{"count", "--times", "3", "--align", "--", "echo"}, wantErr: "--align requires --every"},
+		{name: "deadline before start", args: []string{"interval", "--every", "1s", "--start-at", "+2h", "--deadline", "+1h", "--", "echo"}, wantErr: "after --start-at"},
+		{name: "bad time", args: []string{"interval", "--every", "1s", "--deadline", "later", "--", "echo"}, wantErr: "invalid time for --deadline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseArgs(tt.args)
+			if err == nil {
+				err = ValidateConfig(config)
+			}
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -361,35 +361,55 @@ func TestRateSpecValidation(t *testing.T) {
 			rateSpec:      "",
 			expectedError: "rate spec cannot be empty",
 		},
+		{
+			name:          "valid_human_syntax",
+			rateSpec:      "5 per minute",
+			expectedError: "",
+		},
+		{
+			name:          "valid_unit_word",
+			rateSpec:      "100/hour",
+			expectedError: "",
+		},
+		{
+			name:          "valid_multi_window",
+			rateSpec:      "10/s, 1000/hour, 10000/day",
+			expectedError: "",
+		},
 		{
 			name:          "missing_slash",
 			rateSpec:      "100",
-			expectedError: "rate spec must be in format 'rate/period' (e.g., '10/1h')",
+			expectedError: "invalid rate spec format: 100",
 		},
 		{
 			name:          "multiple_slashes",
 			rateSpec:      "100/1h/extra",
-			expectedError: "rate spec must be in format 'rate/period' (e.g., '10/1h')",
+			expectedError: "invalid period '1h/extra'",
 		},
 		{
 			name:          "invalid_rate_number",
 			rateSpec:      "abc/1h",
-			expectedError: "invalid rate number: abc",
+			expectedError: "invalid rate 'abc'",
 		},
 		{
 			name:          "invalid_period_duration",
 			rateSpec:      "100/invalid",
-			expectedError: "invalid period duration: invalid",
+			expectedError: "invalid period 'invalid'",
+		},
+		{
+			name:          "invalid_second_window",
+			rateSpec:      "10/s,1000",
+			expectedError: "invalid rate spec format: 1000",
 		},
 		{
 			name:          "negative_rate",
 			rateSpec:      "-100/1h",
-			expectedError: "",
+			expectedError: "rate must be positive",
 		},
 		{
 			name:          "zero_rate",
 			rateSpec:      "0/1h",
-			expectedError: "",
+			expectedError: "rate must be positive",
 		},
 	}
 
@@ -495,3 +515,94 @@ func indexOfSubstring(s, substr string) int {
 	}
 	return -1
 }
+
+// TestBurstValidation tests --burst parsing and its dependency on --rate
+func TestBurstValidation(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name: "burst_with_multi_window_rate",
+			args: []string{"interval", "--every", "1s", "--rate", "10/s,1000/hour", "--burst", "5", "--", "echo"},
+		},
+		{
+			name:          "burst_without_rate",
+			args:          []string{"interval", "--every", "1s", "--burst", "5", "--", "echo"},
+			expectedError: "--burst requires --rate",
+		},
+		{
+			name:          "negative_burst",
+			args:          []string{"rate-limit", "--rate", "10/m", "--burst", "-1", "--", "echo"},
+			expectedError: "--burst must be positive",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseArgs(tt.args)
+			if err == nil {
+				err = ValidateConfig(config)
+			}
+
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing '%s', but got '%v'", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+// TestRateAlgorithmValidation tests --rate-algorithm selection
+func TestRateAlgorithmValidation(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expectedError string
+	}{
+		{
+			name: "gcra_on_interval",
+			args: []string{"interval", "--every", "1s", "--rate", "10/m", "--rate-algorithm", "gcra", "--", "echo"},
+		},
+		{
+			name: "diophantine_with_retry_pattern",
+			args: []string{"rate-limit", "--rate", "10/h", "--rate-algorithm", "diophantine", "--retry-pattern", "0,10m", "--", "echo"},
+		},
+		{
+			name:          "unknown_algorithm",
+			args:          []string{"rate-limit", "--rate", "10/m", "--rate-algorithm", "leaky-bucket", "--", "echo"},
+			expectedError: "invalid --rate-algorithm: leaky-bucket",
+		},
+		{
+			name:          "algorithm_without_rate",
+			args:          []string{"interval", "--every", "1s", "--rate-algorithm", "gcra", "--", "echo"},
+			expectedError: "--rate-algorithm requires --rate",
+		},
+		{
+			name:          "retry_pattern_with_sliding_log",
+			args:          []string{"rate-limit", "--rate", "10/h", "--rate-algorithm", "sliding-log", "--retry-pattern", "0,10m", "--", "echo"},
+			expectedError: "--retry-pattern requires --rate-algorithm diophantine",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := ParseArgs(tt.args)
+			if err == nil {
+				err = ValidateConfig(config)
+			}
+
+			if tt.expectedError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, but got: %v", err)
+				}
+			} else if err == nil || !contains(err.Error(), tt.expectedError) {
+				t.Errorf("Expected error containing '%s', but got '%v'", tt.expectedError, err)
+			}
+		})
+	}
+}
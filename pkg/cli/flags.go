@@ -37,10 +37,57 @@ func (p *argParser) parseSubcommandFlags() error {
 			if err := p.parseStringFlag(&p.config.RateSpec); err != nil {
 				return err
 			}
+		case "--rate-algorithm":
+			if err := p.parseStringFlag(&p.config.RateAlgorithm); err != nil {
+				return err
+			}
+		case "--burst":
+			if err := p.parseInt64Flag(&p.config.Burst); err != nil {
+				return err
+			}
 		case "--retry-pattern", "-p":
 			if err := p.parseStringFlag(&p.config.RetryPattern); err != nil {
 				return err
 			}
+		case "--delay":
+			if err := p.parseDurationFlag(&p.config.Delay); err != nil {
+				return err
+			}
+		case "--or-every":
+			if err := p.parseDurationFlag(&p.config.OrEvery); err != nil {
+				return err
+			}
+		case "--or-cron":
+			if err := p.parseStringFlag(&p.config.OrCron); err != nil {
+				return err
+			}
+		case "--and-cron":
+			if err := p.parseStringFlag(&p.config.AndCron); err != nil {
+				return err
+			}
+		case "--start-at":
+			if err := p.parseTimeFlag(&p.config.StartAt); err != nil {
+				return err
+			}
+		case "--deadline":
+			if err := p.parseTimeFlag(&p.config.Deadline); err != nil {
+				return err
+			}
+		case "--mode":
+			if err := p.parseStringFlag(&p.config.IntervalMode); err != nil {
+				return err
+			}
+		case "--align":
+			p.config.Align = true
+			p.pos++
+		case "--jitter":
+			if err := p.parseFloatFlag(&p.config.Jitter); err != nil {
+				return err
+			}
+		case "--phase":
+			if err := p.parsePhaseFlag(); err != nil {
+				return err
+			}
 		case "--show-next", "-n":
 			p.config.ShowNext = true
 			p.pos++
@@ -112,16 +159,142 @@ func (p *argParser) parseSubcommandFlags() error {
 				return err
 			}
 		case "--success-pattern":
-			if err := p.parseStringFlag(&p.config.SuccessPattern); err != nil {
+			if err := p.parsePatternFlag(&p.config.SuccessPattern, &p.config.SuccessPatterns); err != nil {
 				return err
 			}
 		case "--failure-pattern":
-			if err := p.parseStringFlag(&p.config.FailurePattern); err != nil {
+			if err := p.parsePatternFlag(&p.config.FailurePattern, &p.config.FailurePatterns); err != nil {
+				return err
+			}
+		case "--require-absent":
+			if err := p.parseRepeatedFlag(&p.config.RequireAbsent); err != nil {
+				return err
+			}
+		case "--fatal-pattern":
+			if err := p.parseRepeatedFlag(&p.config.FatalPatterns); err != nil {
 				return err
 			}
+		case "--match-mode":
+			if err := p.parseStringFlag(&p.config.MatchMode); err != nil {
+				return err
+			}
+		case "--min-matches":
+			if err := p.parseIntFlag(&p.config.MinMatches); err != nil {
+				return err
+			}
+		case "--success-expr":
+			if err := p.parseStringFlag(&p.config.SuccessExpr); err != nil {
+				return err
+			}
+		case "--failure-expr":
+			if err := p.parseStringFlag(&p.config.FailureExpr); err != nil {
+				return err
+			}
+		case "--expect-json":
+			if err := p.parseRepeatedFlag(&p.config.ExpectJSON); err != nil {
+				return err
+			}
+		case "--expect-json-expr":
+			if err := p.parseStringFlag(&p.config.ExpectJSONExpr); err != nil {
+				return err
+			}
+		case "--fail-fast-on-pattern":
+			p.config.FailFastOnPattern = true
+			p.pos++
+		case "--succeed-on-pattern":
+			p.config.SucceedOnPattern = true
+			p.pos++
 		case "--case-insensitive":
 			p.config.CaseInsensitive = true
 			p.pos++
+		case "--success-codes":
+			if err := p.parseStringFlag(&p.config.SuccessCodes); err != nil {
+				return err
+			}
+		case "--retry-codes":
+			if err := p.parseStringFlag(&p.config.RetryCodes); err != nil {
+				return err
+			}
+		case "--abort-codes":
+			if err := p.parseStringFlag(&p.config.AbortCodes); err != nil {
+				return err
+			}
+		case "--http-success-codes":
+			if err := p.parseStringFlag(&p.config.HTTPSuccessCodes); err != nil {
+				return err
+			}
+		case "--http-retry-codes":
+			if err := p.parseStringFlag(&p.config.HTTPRetryCodes); err != nil {
+				return err
+			}
+		case "--http-fatal-codes":
+			if err := p.parseStringFlag(&p.config.HTTPFatalCodes); err != nil {
+				return err
+			}
+		// NATIVE HTTP PROBE
+		case "--http":
+			if err := p.parseHTTPFlag(); err != nil {
+				return err
+			}
+		case "--header":
+			if err := p.parseRepeatedFlag(&p.config.HTTPHeaders); err != nil {
+				return err
+			}
+		case "--data":
+			if err := p.parseStringFlag(&p.config.HTTPData); err != nil {
+				return err
+			}
+		case "--http-timeout":
+			if err := p.parseDurationFlag(&p.config.HTTPTimeout); err != nil {
+				return err
+			}
+		case "--insecure":
+			p.config.HTTPInsecure = true
+			p.pos++
+		case "--follow":
+			p.config.HTTPFollow = true
+			p.pos++
+		case "--expect-status":
+			if err := p.parseStringFlag(&p.config.HTTPExpectStatus); err != nil {
+				return err
+			}
+		// SOCKET AND DNS PROBES
+		case "--tcp":
+			if err := p.parseStringFlag(&p.config.TCPAddress); err != nil {
+				return err
+			}
+		case "--udp":
+			if err := p.parseStringFlag(&p.config.UDPAddress); err != nil {
+				return err
+			}
+		case "--unix":
+			if err := p.parseStringFlag(&p.config.UnixSocket); err != nil {
+				return err
+			}
+		case "--dns":
+			if err := p.parseStringFlag(&p.config.DNSName); err != nil {
+				return err
+			}
+		case "--dns-server":
+			if err := p.parseStringFlag(&p.config.DNSServer); err != nil {
+				return err
+			}
+		case "--dns-type":
+			if err := p.parseStringFlag(&p.config.DNSType); err != nil {
+				return err
+			}
+		case "--send":
+			if err := p.parseStringFlag(&p.config.ProbeSend); err != nil {
+				return err
+			}
+		case "--expect":
+			if err := p.parseStringFlag(&p.config.ProbeExpect); err != nil {
+				return err
+			}
+		case "--probe-timeout":
+			if err := p.parseDurationFlag(&p.config.ProbeTimeout); err != nil {
+				return err
+			}
 		case "--http-aware":
 			p.config.HTTPAware = true
 			p.pos++
@@ -152,6 +325,10 @@ func (p *argParser) parseSubcommandFlags() error {
 			if err := p.parseStringSliceFlag(&p.config.HTTPCustomFields); err != nil {
 				return err
 			}
+		case "--aware":
+			if err := p.parseStringSliceFlag(&p.config.Aware); err != nil {
+				return err
+			}
 		case "--attempts", "-a":
 			if err := p.parseIntFlag(&p.config.MaxRetries); err != nil {
 				return err
@@ -174,6 +351,20 @@ func (p *argParser) parseSubcommandFlags() error {
 			if err := p.parseDurationFlag(&p.config.MaxDelay); err != nil {
 				return err
 			}
+
+		// DRY-RUN PREVIEW
+		case "--dry-run":
+			p.config.DryRun = true
+			p.pos++
+		case "--preview":
+			if err := p.parseIntFlag(&p.config.PreviewCount); err != nil {
+				return err
+			}
+			p.config.DryRun = true // a preview never executes the command
+		case "--seed":
+			if err := p.parseInt64Flag(&p.config.Seed); err != nil {
+				return err
+			}
 		// Note: --multiplier already exists at line 356, update it to use new field
 		default:
 			return fmt.Errorf("unknown flag: %s", arg)
@@ -257,6 +448,85 @@ func (p *argParser) parseIntFlag(target *int) error {
 	return nil
 }
 
+// parseInt64Flag parses a 64-bit integer flag value
+func (p *argParser) parseInt64Flag(target *int64) error {
+	if p.pos+1 >= len(p.args) {
+		return fmt.Errorf("%s requires a value", p.args[p.pos])
+	}
+
+	value, err := strconv.ParseInt(p.args[p.pos+1], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer value: %s", p.args[p.pos+1])
+	}
+
+	*target = value
+	p.pos += 2
+	return nil
+}
+
+// parseTimeFlag parses an absolute time flag value
+func (p *argParser) parseTimeFlag(target *time.Time) error {
+	if p.pos+1 >= len(p.args) {
+		return fmt.Errorf("%s requires a value", p.args[p.pos])
+	}
+
+	t, err := ParseTime(p.args[p.pos+1], time.Now())
+	if err != nil {
+		return fmt.Errorf("invalid time for %s: %w", p.args[p.pos], err)
+	}
+
+	*target = t
+	p.pos += 2
+	return nil
+}
+
+// parsePhaseFlag parses a --phase value and appends it to the phase list
+func (p *argParser) parsePhaseFlag() error {
+	if p.pos+1 >= len(p.args) {
+		return fmt.Errorf("%s requires a value", p.args[p.pos])
+	}
+
+	phase, err := ParsePhaseSpec(p.args[p.pos+1])
+	if err != nil {
+		return fmt.Errorf("invalid phase %q: %w", p.args[p.pos+1], err)
+	}
+
+	p.config.Phases = append(p.config.Phases, phase)
+	p.pos += 2
+	return nil
+}
+
+// parseHTTPFlag parses --http METHOD URL
+func (p *argParser) parseHTTPFlag() error {
+	if p.pos+2 >= len(p.args) {
+		return fmt.Errorf("--http requires a method and a URL")
+	}
+
+	p.config.HTTPMethod = p.args[p.pos+1]
+	p.config.HTTPURL = p.args[p.pos+2]
+	p.pos += 3
+	return nil
+}
+
+// parseRepeatedFlag appends the value of a flag that may be given more than once
+func (p *argParser) parseRepeatedFlag(target *[]string) error {
+	if p.pos+1 >= len(p.args) {
+		return fmt.Errorf("%s requires a value", p.args[p.pos])
+	}
+	*target = append(*target, p.args[p.pos+1])
+	p.pos += 2
+	return nil
+}
+
+// parsePatternFlag parses a repeatable pattern flag: the first value fills
+// first, later ones are appended to more
+func (p *argParser) parsePatternFlag(first *string, more *[]string) error {
+	if *first == "" {
+		return p.parseStringFlag(first)
+	}
+	return p.parseRepeatedFlag(more)
+}
+
 // parseStringSliceFlag parses a comma-separated string slice flag value
 func (p *argParser) parseStringSliceFlag(target *[]string) error {
 	if p.pos+1 >= len(p.args) {
@@ -142,6 +142,8 @@ func normalizeSubcommand(cmd string) string {
 		return "cron"
 	case "adaptive", "adapt", "a":
 		return "adaptive"
+	case "phases", "ph":
+		return "phases"
 
 	// EXISTING RATE CONTROL (Resource Management)
 	case "rate-limit", "rate", "rl", "r":
@@ -156,6 +158,12 @@ func normalizeSubcommand(cmd string) string {
 // parseCommand parses the command after the -- separator
 func (p *argParser) parseCommand() error {
 	if p.pos >= len(p.args) {
+		if p.config.DryRun {
+			return nil // A dry run only previews the schedule
+		}
+		if p.config.HasProbe() {
+			return nil // A built-in probe runs instead of a command
+		}
 		return errors.New("command required after --")
 	}
 
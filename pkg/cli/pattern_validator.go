@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/swi/repeater/pkg/executor"
+	"github.com/swi/repeater/pkg/expr"
 	"github.com/swi/repeater/pkg/patterns"
 )
 
@@ -37,29 +39,105 @@ func validateOutputFlags(config *Config) error {
 
 // validatePatterns validates regex patterns for success/failure matching
 func validatePatterns(config *Config) error {
-	// Validate success pattern if provided
-	if config.SuccessPattern != "" {
-		pattern := config.SuccessPattern
-		if config.CaseInsensitive {
-			pattern = "(?i)" + pattern
+	// Validate patterns if provided
+	for _, group := range []struct {
+		kind  string
+		specs []string
+	}{
+		{"success", append([]string{config.SuccessPattern}, config.SuccessPatterns...)},
+		{"failure", append([]string{config.FailurePattern}, config.FailurePatterns...)},
+		{"required-absent", config.RequireAbsent},
+		{"fatal", config.FatalPatterns},
+	} {
+		for _, spec := range group.specs {
+			if spec == "" {
+				continue
+			}
+			if _, err := patterns.ParsePattern(spec, config.CaseInsensitive); err != nil {
+				return fmt.Errorf("invalid %s pattern: %w", group.kind, err)
+			}
 		}
-		if _, err := patterns.NewPatternMatcher(patterns.PatternConfig{
-			SuccessPattern: pattern,
-		}); err != nil {
-			return fmt.Errorf("invalid success pattern: %w", err)
+	}
+
+	// Validate pattern thresholds if provided
+	if _, err := patterns.ParseMatchMode(config.MatchMode); err != nil {
+		return fmt.Errorf("invalid --match-mode: %w", err)
+	}
+	if config.MinMatches < 0 {
+		return errors.New("--min-matches must not be negative")
+	}
+	if config.MinMatches > 0 && config.SuccessPattern == "" && config.FailurePattern == "" {
+		return errors.New("--min-matches requires --success-pattern or --failure-pattern")
+	}
+
+	// Validate early decisions, which need patterns to decide on
+	if config.FailFastOnPattern && config.FailurePattern == "" && len(config.RequireAbsent) == 0 && len(config.FatalPatterns) == 0 {
+		return errors.New("--fail-fast-on-pattern requires --failure-pattern, --require-absent or --fatal-pattern")
+	}
+	if config.SucceedOnPattern {
+		if config.SuccessPattern == "" {
+			return errors.New("--succeed-on-pattern requires --success-pattern")
+		}
+		if config.SuccessExpr != "" || config.FailureExpr != "" || len(config.ExpectJSON) > 0 || config.ExpectJSONExpr != "" ||
+			config.HTTPSuccessCodes != "" || config.HTTPRetryCodes != "" || config.HTTPFatalCodes != "" {
+			return errors.New("--succeed-on-pattern cannot be combined with expressions, --expect-json or HTTP status codes")
 		}
 	}
 
-	// Validate failure pattern if provided
-	if config.FailurePattern != "" {
-		pattern := config.FailurePattern
-		if config.CaseInsensitive {
-			pattern = "(?i)" + pattern
+	// Validate expressions if provided
+	for _, condition := range []struct {
+		flag   string
+		source string
+	}{
+		{"--success-expr", config.SuccessExpr},
+		{"--failure-expr", config.FailureExpr},
+		{"--expect-json-expr", config.ExpectJSONExpr},
+	} {
+		if condition.source == "" {
+			continue
 		}
-		if _, err := patterns.NewPatternMatcher(patterns.PatternConfig{
-			FailurePattern: pattern,
-		}); err != nil {
-			return fmt.Errorf("invalid failure pattern: %w", err)
+		if _, err := expr.Compile(condition.source); err != nil {
+			return fmt.Errorf("invalid %s: %w", condition.flag, err)
+		}
+	}
+
+	// Validate JSON assertions if provided
+	for _, spec := range config.ExpectJSON {
+		if _, err := patterns.ParseJSONExpectation(spec); err != nil {
+			return fmt.Errorf("invalid --expect-json: %w", err)
+		}
+	}
+
+	// Validate HTTP status code lists if provided
+	for _, codes := range []struct {
+		flag string
+		spec string
+	}{
+		{"--http-success-codes", config.HTTPSuccessCodes},
+		{"--http-retry-codes", config.HTTPRetryCodes},
+		{"--http-fatal-codes", config.HTTPFatalCodes},
+	} {
+		if _, err := patterns.ParseStatusCodes(codes.spec); err != nil {
+			return fmt.Errorf("invalid %s: %w", codes.flag, err)
+		}
+	}
+
+	// Validate exit code lists if provided, which must not share codes
+	for _, codes := range []struct {
+		flag string
+		spec string
+	}{
+		{"--success-codes", config.SuccessCodes},
+		{"--retry-codes", config.RetryCodes},
+		{"--abort-codes", config.AbortCodes},
+	} {
+		if _, err := executor.ParseExitCodes(codes.spec); err != nil {
+			return fmt.Errorf("invalid %s: %w", codes.flag, err)
+		}
+	}
+	if exitCodes := config.GetExitCodeConfig(); exitCodes != nil {
+		if _, err := executor.NewExitCodeMap(*exitCodes); err != nil {
+			return fmt.Errorf("conflicting exit codes: %w", err)
 		}
 	}
 
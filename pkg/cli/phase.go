@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// phaseModes lists the schedulers a phase can use
+var phaseModes = []string{"interval", "exponential", "adaptive", "cron"}
+
+// ParsePhaseSpec parses a --phase value of the form
+// "MODE; key=value; key=value", e.g. "interval; every=1s; for=1m"
+func ParsePhaseSpec(spec string) (PhaseConfig, error) {
+	var phase PhaseConfig
+
+	for i, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		key, value, hasValue := strings.Cut(part, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !hasValue {
+			if i != 0 {
+				return phase, fmt.Errorf("expected key=value, got %q", part)
+			}
+			key, value = "mode", part
+		}
+
+		if err := setPhaseKey(&phase, key, value); err != nil {
+			return phase, err
+		}
+	}
+
+	if phase.Mode == "" {
+		return phase, fmt.Errorf("phase mode required (one of: %s)", strings.Join(phaseModes, ", "))
+	}
+
+	return phase, nil
+}
+
+// setPhaseKey applies one key=value pair of a phase spec
+func setPhaseKey(p *PhaseConfig, key, value string) error {
+	var err error
+
+	switch key {
+	case "mode":
+		p.Mode = value
+	case "name":
+		p.Name = value
+	case "times":
+		p.Times, err = strconv.ParseInt(value, 10, 64)
+	case "for":
+		p.For, err = time.ParseDuration(value)
+	case "every":
+		p.Every, err = time.ParseDuration(value)
+	case "base-delay":
+		p.BaseDelay, err = time.ParseDuration(value)
+	case "multiplier":
+		p.Multiplier, err = strconv.ParseFloat(value, 64)
+	case "max-delay":
+		p.MaxDelay, err = time.ParseDuration(value)
+	case "attempts":
+		p.Attempts, err = strconv.Atoi(value)
+	case "base-interval":
+		p.BaseInterval, err = time.ParseDuration(value)
+	case "min-interval":
+		p.MinInterval, err = time.ParseDuration(value)
+	case "max-interval":
+		p.MaxInterval, err = time.ParseDuration(value)
+	case "cron":
+		p.CronExpression = value
+	case "timezone", "tz":
+		p.Timezone = value
+	default:
+		return fmt.Errorf("unknown phase key: %s", key)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid %s value: %s", key, value)
+	}
+	return nil
+}
+
+// validatePhases validates the phases subcommand, naming unnamed phases and
+// filling in each phase's scheduler defaults
+func validatePhases(config *Config) error {
+	if len(config.Phases) == 0 {
+		if config.ConfigFile != "" {
+			return nil // Phases may come from the config file, checked when it is applied
+		}
+		return errors.New("at least one --phase is required for phases subcommand")
+	}
+
+	for i := range config.Phases {
+		phase := &config.Phases[i]
+		if phase.Name == "" {
+			phase.Name = fmt.Sprintf("phase-%d", i+1)
+		}
+
+		if err := validatePhase(config, phase); err != nil {
+			return fmt.Errorf("phase %d (%s): %w", i+1, phase.Name, err)
+		}
+
+		if i < len(config.Phases)-1 && phase.Times == 0 && phase.For == 0 {
+			return fmt.Errorf("phase %d (%s) never ends: set times or for (only the last phase may run forever)", i+1, phase.Name)
+		}
+	}
+
+	return nil
+}
+
+// validatePhase validates a single phase against its mode's rules
+func validatePhase(config *Config, phase *PhaseConfig) error {
+	if phase.Times < 0 {
+		return errors.New("times must not be negative")
+	}
+	if phase.For < 0 {
+		return errors.New("for must not be negative")
+	}
+
+	derived := config.ForPhase(*phase)
+
+	switch phase.Mode {
+	case "interval":
+		if phase.Every <= 0 {
+			return errors.New("interval phase requires every")
+		}
+	case "exponential":
+		if phase.BaseDelay <= 0 {
+			return errors.New("exponential phase requires base-delay")
+		}
+		if err := validateExponentialConfig(derived); err != nil {
+			return err
+		}
+		if phase.Attempts < 0 {
+			return errors.New("attempts must not be negative")
+		}
+		if phase.Attempts == 0 {
+			phase.Attempts = 3 // Default retry attempts
+		}
+		// A retry schedule ends after its attempts
+		if phase.Times == 0 || phase.Times > int64(phase.Attempts) {
+			phase.Times = int64(phase.Attempts)
+		}
+		phase.Multiplier, phase.MaxDelay = derived.Multiplier, derived.MaxDelay
+	case "adaptive":
+		if phase.BaseInterval <= 0 {
+			return errors.New("adaptive phase requires base-interval")
+		}
+		if err := validateAdaptiveConfig(derived); err != nil {
+			return err
+		}
+		phase.MinInterval, phase.MaxInterval = derived.MinInterval, derived.MaxInterval
+		// Thresholds are run-wide flags shared by every adaptive phase
+		config.SlowThreshold = derived.SlowThreshold
+		config.FastThreshold = derived.FastThreshold
+		config.FailureThreshold = derived.FailureThreshold
+	case "cron":
+		if err := validateCronConfig(derived); err != nil {
+			return err
+		}
+		phase.Timezone = derived.Timezone
+	default:
+		return fmt.Errorf("unknown phase mode: %s (valid modes: %s)", phase.Mode, strings.Join(phaseModes, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/swi/repeater/pkg/probe"
+)
+
+// validateProbes validates the built-in probe flags
+func validateProbes(config *Config) error {
+	probes := probeFlags(config)
+	if len(probes) > 1 {
+		return fmt.Errorf("only one probe can run at a time, got %s", strings.Join(probes, " and "))
+	}
+
+	if err := validateHTTPProbe(config); err != nil {
+		return err
+	}
+	if err := validateSocketProbes(config); err != nil {
+		return err
+	}
+
+	if len(probes) == 1 && len(config.Command) > 0 {
+		return fmt.Errorf("%s runs in place of a command; remove the command after --", probes[0])
+	}
+	return nil
+}
+
+// validateHTTPProbe validates --http and the flags that shape its request
+func validateHTTPProbe(config *Config) error {
+	if config.HTTPURL == "" {
+		if len(config.HTTPHeaders) > 0 || config.HTTPData != "" || config.HTTPTimeout != 0 ||
+			config.HTTPInsecure || config.HTTPFollow || config.HTTPExpectStatus != "" {
+			return errors.New("--header, --data, --http-timeout, --insecure, --follow and --expect-status require --http")
+		}
+		return nil
+	}
+
+	if config.HTTPTimeout < 0 {
+		return errors.New("--http-timeout must be positive")
+	}
+	if _, err := probe.NewHTTPProbe(*config.GetHTTPProbeConfig()); err != nil {
+		return fmt.Errorf("invalid --http: %w", err)
+	}
+	return nil
+}
+
+// validateSocketProbes validates --tcp, --udp, --unix and --dns with the
+// flags they share
+func validateSocketProbes(config *Config) error {
+	socket := config.GetSocketProbeConfig()
+	dns := config.GetDNSProbeConfig()
+
+	if config.ProbeSend != "" && socket == nil {
+		return errors.New("--send requires --tcp, --udp or --unix")
+	}
+	if (config.ProbeExpect != "" || config.ProbeTimeout != 0) && socket == nil && dns == nil {
+		return errors.New("--expect and --probe-timeout require --tcp, --udp, --unix or --dns")
+	}
+	if (config.DNSServer != "" || config.DNSType != "") && dns == nil {
+		return errors.New("--dns-server and --dns-type require --dns")
+	}
+	if config.ProbeTimeout < 0 {
+		return errors.New("--probe-timeout must be positive")
+	}
+
+	if socket != nil {
+		if _, err := probe.NewSocketProbe(*socket); err != nil {
+			return fmt.Errorf("invalid --%s: %w", socket.Network, err)
+		}
+	}
+	if dns != nil {
+		if _, err := probe.NewDNSProbe(*dns); err != nil {
+			return fmt.Errorf("invalid --dns: %w", err)
+		}
+	}
+	return nil
+}
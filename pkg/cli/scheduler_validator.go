@@ -4,40 +4,21 @@ import (
 	"errors"
 	"fmt"
 	"slices"
-	"strconv"
 	"strings"
 	"time"
+
+	"github.com/swi/repeater/pkg/ratelimit"
 )
 
-// validateRateSpec validates the rate specification format
+// validateRateSpec validates a --rate value: one or more comma-separated
+// windows such as "10/1h" or "5 per minute"
 func validateRateSpec(spec string) error {
-	// Use the ParseRateSpec function from ratelimit package to validate
-	// For now, do basic validation here to avoid circular imports
 	if spec == "" {
 		return errors.New("rate spec cannot be empty")
 	}
 
-	// Basic format check: should contain "/"
-	if !strings.Contains(spec, "/") {
-		return errors.New("rate spec must be in format 'rate/period' (e.g., '10/1h')")
-	}
-
-	parts := strings.Split(spec, "/")
-	if len(parts) != 2 {
-		return errors.New("rate spec must be in format 'rate/period' (e.g., '10/1h')")
-	}
-
-	// Validate rate part is a number
-	if _, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64); err != nil {
-		return fmt.Errorf("invalid rate number: %s", parts[0])
-	}
-
-	// Validate period part is a valid duration
-	if _, err := time.ParseDuration(strings.TrimSpace(parts[1])); err != nil {
-		return fmt.Errorf("invalid period duration: %s", parts[1])
-	}
-
-	return nil
+	_, err := ratelimit.ParseRateWindows(spec)
+	return err
 }
 
 // validateAdaptiveConfig validates the adaptive configuration
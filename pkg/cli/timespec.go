@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dateTimeLayouts are the absolute formats accepted by --start-at and --deadline.
+// Layouts without a zone are read in the local time zone.
+var dateTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// timeOfDayLayouts are the clock-only formats, meaning the next occurrence
+var timeOfDayLayouts = []string{"15:04:05", "15:04"}
+
+// ParseTime parses a --start-at or --deadline value relative to now. It
+// accepts an absolute date and time (e.g. "2025-03-10 09:00" or RFC 3339), a
+// time of day meaning its next occurrence (e.g. "09:00"), or an offset from
+// now (e.g. "+30m").
+func ParseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if offset, ok := strings.CutPrefix(value, "+"); ok {
+		d, err := time.ParseDuration(offset)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid offset: %s", value)
+		}
+		return now.Add(d), nil
+	}
+
+	for _, layout := range dateTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	for _, layout := range timeOfDayLayouts {
+		clock, err := time.ParseInLocation(layout, value, now.Location())
+		if err != nil {
+			continue
+		}
+		t := time.Date(now.Year(), now.Month(), now.Day(),
+			clock.Hour(), clock.Minute(), clock.Second(), 0, now.Location())
+		if !t.After(now) {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time %q (use e.g. 2025-03-10T09:00:00Z, '2025-03-10 09:00', 09:00 or +30m)", value)
+}
@@ -3,6 +3,13 @@ package cli
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/swi/repeater/pkg/cron"
+	"github.com/swi/repeater/pkg/httpaware"
+	"github.com/swi/repeater/pkg/ratelimit"
 )
 
 // ValidateConfig validates the parsed configuration
@@ -15,10 +22,20 @@ func ValidateConfig(config *Config) error {
 		return errors.New("subcommand required")
 	}
 
-	if len(config.Command) == 0 {
+	if len(config.Command) == 0 && !config.DryRun && !config.HasProbe() {
 		return errors.New("command required after --")
 	}
 
+	// Validate built-in probes, which run in place of a command
+	if err := validateProbes(config); err != nil {
+		return err
+	}
+
+	// Validate dry-run preview flags
+	if err := validatePreviewFlags(config); err != nil {
+		return err
+	}
+
 	// Validate output control flags
 	if err := validateOutputFlags(config); err != nil {
 		return err
@@ -29,6 +46,16 @@ func ValidateConfig(config *Config) error {
 		return err
 	}
 
+	// Validate schedule modifiers shared by all subcommands
+	if err := validateScheduleModifiers(config); err != nil {
+		return err
+	}
+
+	// Validate HTTP-aware field selectors
+	if err := validateHTTPAware(config); err != nil {
+		return err
+	}
+
 	// Validate subcommand-specific requirements
 	switch config.Subcommand {
 	case "interval":
@@ -59,6 +86,10 @@ func ValidateConfig(config *Config) error {
 		if err := validateAdaptiveConfig(config); err != nil {
 			return fmt.Errorf("invalid adaptive config: %w", err)
 		}
+	case "phases":
+		if err := validatePhases(config); err != nil {
+			return err
+		}
 	case "load-adaptive":
 		if config.BaseInterval == 0 {
 			return errors.New("--base-interval is required for load-adaptive subcommand")
@@ -119,3 +150,158 @@ func ValidateConfig(config *Config) error {
 
 	return nil
 }
+
+// validateScheduleModifiers validates the flags that compose with any subcommand's scheduler
+func validateScheduleModifiers(config *Config) error {
+	if config.Delay < 0 {
+		return errors.New("--delay must not be negative")
+	}
+
+	if err := validateScheduleOperands(config); err != nil {
+		return err
+	}
+
+	// rate-limit validates its own required --rate below
+	if config.RateSpec != "" && config.Subcommand != "rate-limit" {
+		if err := validateRateSpec(config.RateSpec); err != nil {
+			return fmt.Errorf("invalid rate spec: %w", err)
+		}
+	}
+
+	if config.Burst < 0 {
+		return errors.New("--burst must be positive")
+	}
+	if config.Burst > 0 && config.RateSpec == "" {
+		return errors.New("--burst requires --rate")
+	}
+
+	if err := validateRateAlgorithm(config); err != nil {
+		return err
+	}
+
+	if !config.Deadline.IsZero() {
+		if !config.Deadline.After(time.Now()) {
+			return errors.New("--deadline is in the past")
+		}
+		if !config.StartAt.IsZero() && !config.Deadline.After(config.StartAt) {
+			return errors.New("--deadline must be after --start-at")
+		}
+	}
+
+	return validateIntervalTiming(config)
+}
+
+// validateScheduleOperands validates --or-every, --or-cron and --and-cron,
+// which combine the subcommand's schedule with another one
+func validateScheduleOperands(config *Config) error {
+	if config.OrEvery == 0 && config.OrCron == "" && config.AndCron == "" {
+		return nil
+	}
+
+	if config.Subcommand == "rate-limit" {
+		return errors.New("--or-every, --or-cron and --and-cron do not apply to rate-limit; use --rate on another subcommand")
+	}
+
+	if config.OrEvery < 0 {
+		return errors.New("--or-every must be positive")
+	}
+
+	for _, operand := range []struct{ flag, expr string }{
+		{"--or-cron", config.OrCron},
+		{"--and-cron", config.AndCron},
+	} {
+		if operand.expr == "" {
+			continue
+		}
+		if _, err := cron.ParseCron(operand.expr); err != nil {
+			return fmt.Errorf("invalid %s: %w", operand.flag, err)
+		}
+	}
+
+	return nil
+}
+
+// validateHTTPAware validates the --http-custom-fields selectors and the
+// --aware parser names
+func validateHTTPAware(config *Config) error {
+	for _, field := range config.HTTPCustomFields {
+		if field == "" {
+			continue
+		}
+		if _, err := httpaware.ParseFieldSelector(field); err != nil {
+			return fmt.Errorf("invalid --http-custom-fields: %w", err)
+		}
+	}
+
+	for _, name := range config.Aware {
+		if !httpaware.DefaultParsers.Has(name) {
+			return fmt.Errorf("invalid --aware: unknown parser %q (available: %s)",
+				name, strings.Join(httpaware.DefaultParsers.Names(), ", "))
+		}
+	}
+	return nil
+}
+
+// validateRateAlgorithm validates --rate-algorithm and the flags it constrains
+func validateRateAlgorithm(config *Config) error {
+	if config.RateAlgorithm == "" {
+		return nil
+	}
+
+	if config.RateSpec == "" {
+		return errors.New("--rate-algorithm requires --rate")
+	}
+	if !slices.Contains(ratelimit.Algorithms, config.RateAlgorithm) {
+		return fmt.Errorf("invalid --rate-algorithm: %s (valid algorithms: %s)",
+			config.RateAlgorithm, strings.Join(ratelimit.Algorithms, ", "))
+	}
+	if config.RetryPattern != "" && config.RateAlgorithm != ratelimit.AlgorithmDiophantine {
+		return fmt.Errorf("--retry-pattern requires --rate-algorithm %s", ratelimit.AlgorithmDiophantine)
+	}
+
+	return nil
+}
+
+// validateIntervalTiming validates --mode, --align and --jitter, which shape
+// interval schedules
+func validateIntervalTiming(config *Config) error {
+	if config.IntervalMode == "" && !config.Align && config.Jitter == 0 {
+		return nil
+	}
+
+	switch config.Subcommand {
+	case "interval", "count", "duration":
+	default:
+		return errors.New("--mode, --align and --jitter apply only to interval, count and duration")
+	}
+
+	switch config.IntervalMode {
+	case "", "fixed-rate", "fixed-delay":
+	default:
+		return fmt.Errorf("invalid --mode: %s (valid modes: fixed-rate, fixed-delay)", config.IntervalMode)
+	}
+
+	if config.Align && config.Every <= 0 {
+		return errors.New("--align requires --every")
+	}
+
+	if config.Jitter < 0 || config.Jitter > 1 {
+		return errors.New("--jitter must be between 0 and 1")
+	}
+
+	return nil
+}
+
+// validatePreviewFlags validates the dry-run preview configuration
+func validatePreviewFlags(config *Config) error {
+	if config.PreviewCount < 0 {
+		return errors.New("--preview must be positive")
+	}
+
+	// Set default preview length for a bare --dry-run
+	if config.DryRun && config.PreviewCount == 0 {
+		config.PreviewCount = 10
+	}
+
+	return nil
+}
@@ -16,6 +16,7 @@ type Config struct {
 	Defaults      DefaultsConfig      `toml:"defaults"`
 	Scheduling    SchedulingConfig    `toml:"scheduling"`
 	Observability ObservabilityConfig `toml:"observability"`
+	Phases        []PhaseConfig       `toml:"phases"`
 }
 
 // DefaultsConfig contains default execution parameters
@@ -31,6 +32,24 @@ type SchedulingConfig struct {
 	JitterPercent   float64       `toml:"jitter_percent"`
 }
 
+// PhaseConfig describes one phase of a phased schedule ([[phases]] tables)
+type PhaseConfig struct {
+	Name           string        `toml:"name"`
+	Mode           string        `toml:"mode"`
+	Times          int64         `toml:"times"`
+	For            time.Duration `toml:"for"`
+	Every          time.Duration `toml:"every"`
+	BaseDelay      time.Duration `toml:"base_delay"`
+	Multiplier     float64       `toml:"multiplier"`
+	MaxDelay       time.Duration `toml:"max_delay"`
+	Attempts       int           `toml:"attempts"`
+	BaseInterval   time.Duration `toml:"base_interval"`
+	MinInterval    time.Duration `toml:"min_interval"`
+	MaxInterval    time.Duration `toml:"max_interval"`
+	CronExpression string        `toml:"cron"`
+	Timezone       string        `toml:"timezone"`
+}
+
 // ObservabilityConfig contains monitoring and metrics configuration
 type ObservabilityConfig struct {
 	MetricsEnabled  bool `toml:"metrics_enabled"`
@@ -197,5 +216,15 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("health_check_port must be between 1 and 65535: %d", c.Observability.HealthCheckPort)
 	}
 
+	// Validate phases; mode-specific rules are checked with the CLI flags
+	for i, phase := range c.Phases {
+		if phase.Mode == "" {
+			return fmt.Errorf("phases[%d]: mode is required", i)
+		}
+		if phase.Times < 0 || phase.For < 0 {
+			return fmt.Errorf("phases[%d]: times and for cannot be negative", i)
+		}
+	}
+
 	return nil
 }
@@ -73,6 +73,48 @@ health_check_port = 8080
 	}
 }
 
+func TestConfigLoad_Phases(t *testing.T) {
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "phases.toml")
+
+	tomlContent := `
+[[phases]]
+name = "warmup"
+mode = "interval"
+every = "1s"
+for = "1m"
+
+[[phases]]
+name = "nightly"
+mode = "cron"
+cron = "0 2 * * *"
+timezone = "Europe/Berlin"
+`
+
+	if err := os.WriteFile(configFile, []byte(tomlContent), 0644); err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	config, err := LoadConfig(configFile)
+	if err != nil {
+		t.Fatalf("Expected no error loading config, got: %v", err)
+	}
+
+	if len(config.Phases) != 2 {
+		t.Fatalf("Expected 2 phases, got %d", len(config.Phases))
+	}
+
+	warmup := config.Phases[0]
+	if warmup.Name != "warmup" || warmup.Mode != "interval" || warmup.Every != time.Second || warmup.For != time.Minute {
+		t.Errorf("Unexpected first phase: %+v", warmup)
+	}
+
+	nightly := config.Phases[1]
+	if nightly.CronExpression != "0 2 * * *" || nightly.Timezone != "Europe/Berlin" {
+		t.Errorf("Unexpected second phase: %+v", nightly)
+	}
+}
+
 func TestConfigLoad_WithEnvironmentOverrides(t *testing.T) {
 	// Set environment variables
 	_ = os.Setenv("RPR_TIMEOUT", "60s")
@@ -14,6 +14,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/swi/repeater/pkg/httpaware"
 	"github.com/swi/repeater/pkg/patterns"
 )
 
@@ -23,9 +24,19 @@ type ExecutionResult struct {
 	Stdout   string
 	Stderr   string
 	Duration time.Duration
-	Success  bool   // Whether the command was considered successful (after pattern matching)
-	Reason   string // Reason for the success/failure determination
-	Output   string // Combined stdout and stderr for convenience
+	Success  bool     // Whether the command was considered successful (after pattern matching)
+	Reason   string   // Reason for the success/failure determination
+	Output   string   // Combined stdout and stderr for convenience
+	Fatal    bool     // Whether the result should stop the run, e.g. an HTTP fatal code
+	Matched  []string // Patterns found in the output, as written
+
+	// CommandExitCode is the code the command exited with, before exit-code
+	// mapping and pattern matching; -1 if a signal ended it
+	CommandExitCode int
+
+	// Set by probes only
+	HTTP   *httpaware.Response // Final response of the native HTTP probe
+	Phases *PhaseTiming        // Network phases of the probe
 }
 
 // ExecutorConfig holds configuration for the executor
@@ -37,8 +48,15 @@ type ExecutorConfig struct {
 	VerboseMode   bool
 	OutputPrefix  string
 	PatternConfig *patterns.PatternConfig
+	ExitCodes     *ExitCodeConfig           // mapped before pattern matching when set
+	Probe         Probe                     // runs in place of the command when set
+	OnLine        func(stream, line string) // sees every output line; see WithLineHandler
 }
 
+// stopWaitDelay bounds the wait for a command's output once a pattern watch
+// has stopped it
+const stopWaitDelay = time.Second
+
 // Executor handles command execution with configurable options
 type Executor struct {
 	timeout        time.Duration
@@ -47,6 +65,9 @@ type Executor struct {
 	verboseMode    bool
 	outputPrefix   string
 	patternMatcher *patterns.PatternMatcher
+	exitCodes      *ExitCodeMap
+	probe          Probe
+	onLine         func(stream, line string)
 }
 
 // Option represents a configuration option for the executor
@@ -95,6 +116,32 @@ func WithOutputPrefix(prefix string) Option {
 	}
 }
 
+// WithLineHandler calls handler with each line of output as it is produced,
+// stream being "stdout" or "stderr", whether or not output is streamed. The
+// stdout and stderr lines of a command arrive from separate goroutines.
+func WithLineHandler(handler func(stream, line string)) Option {
+	return func(e *Executor) error {
+		if handler == nil {
+			return errors.New("line handler cannot be nil")
+		}
+		e.onLine = handler
+		return nil
+	}
+}
+
+// WithExitCodes classifies exit codes as success, retryable or abort before
+// pattern matching
+func WithExitCodes(config ExitCodeConfig) Option {
+	return func(e *Executor) error {
+		exitCodes, err := NewExitCodeMap(config)
+		if err != nil {
+			return err
+		}
+		e.exitCodes = exitCodes
+		return nil
+	}
+}
+
 // NewExecutor creates a new command executor with the given options
 func NewExecutor(options ...Option) (*Executor, error) {
 	executor := &Executor{
@@ -117,6 +164,8 @@ func NewExecutorWithConfig(config ExecutorConfig) (*Executor, error) {
 		quietMode:    config.QuietMode,
 		verboseMode:  config.VerboseMode,
 		outputPrefix: config.OutputPrefix,
+		probe:        config.Probe,
+		onLine:       config.OnLine,
 	}
 
 	// Set default timeout if not specified
@@ -138,11 +187,24 @@ func NewExecutorWithConfig(config ExecutorConfig) (*Executor, error) {
 		executor.patternMatcher = matcher
 	}
 
+	// Map exit codes if configured
+	if config.ExitCodes != nil {
+		exitCodes, err := NewExitCodeMap(*config.ExitCodes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create exit code map: %w", err)
+		}
+		executor.exitCodes = exitCodes
+	}
+
 	return executor, nil
 }
 
-// Execute runs a command and returns the execution result
+// Execute runs a command and returns the execution result. With a probe
+// configured the probe runs instead and command is passed to it unchanged.
 func (e *Executor) Execute(ctx context.Context, command []string) (*ExecutionResult, error) {
+	if e.probe != nil {
+		return e.executeProbe(ctx, command)
+	}
 	if len(command) == 0 {
 		return nil, errors.New("command cannot be empty")
 	}
@@ -157,8 +219,32 @@ func (e *Executor) Execute(ctx context.Context, command []string) (*ExecutionRes
 		defer cancel()
 	}
 
+	// A pattern watch may decide the execution from its output and stop the
+	// command before it exits
+	var watch *patterns.Watch
+	if e.patternMatcher != nil {
+		watch = e.patternMatcher.Watch()
+	}
+	runCtx, stop := context.WithCancel(execCtx)
+	defer stop()
+	onLine := e.onLine
+	if watch != nil {
+		onLine = func(stream, line string) {
+			if e.onLine != nil {
+				e.onLine(stream, line)
+			}
+			if watch.Line(stream, line) {
+				stop()
+			}
+		}
+	}
+
 	// Create the command
-	cmd := exec.CommandContext(execCtx, command[0], command[1:]...)
+	cmd := exec.CommandContext(runCtx, command[0], command[1:]...)
+	if watch != nil {
+		// Processes started by a stopped command may hold its output open
+		cmd.WaitDelay = stopWaitDelay
+	}
 
 	// Prepare output buffers
 	var stdout, stderr bytes.Buffer
@@ -181,34 +267,81 @@ func (e *Executor) Execute(ctx context.Context, command []string) (*ExecutionRes
 			return nil, fmt.Errorf("failed to start command: %w", err)
 		}
 
+		// Once stopped, stop reading too: processes the command started may
+		// hold the pipes open
+		if watch != nil {
+			closePipes := context.AfterFunc(runCtx, func() {
+				if _, decided := watch.Decision(); decided {
+					stdoutPipe.Close()
+					stderrPipe.Close()
+				}
+			})
+			defer closePipes()
+		}
+
 		// Stream output in real-time while capturing
 		var wg sync.WaitGroup
 		wg.Add(2)
 
 		go func() {
 			defer wg.Done()
-			e.streamOutput(stdoutPipe, &stdout, "stdout", command)
+			e.streamOutput(stdoutPipe, &stdout, "stdout", command, onLine)
 		}()
 
 		go func() {
 			defer wg.Done()
-			e.streamOutput(stderrPipe, &stderr, "stderr", command)
+			e.streamOutput(stderrPipe, &stderr, "stderr", command, onLine)
 		}()
 
+		// Drain the pipes before waiting: Wait closes them, and reading after
+		// that loses any output still buffered in the pipe
+		wg.Wait()
+
 		// Wait for command to complete
 		err = cmd.Wait()
-
-		// Wait for streaming to complete
-		wg.Wait()
 	} else {
 		// Standard execution without streaming
 		cmd.Stdout = &stdout
 		cmd.Stderr = &stderr
+		var lines []*lineWriter
+		if onLine != nil {
+			lines = []*lineWriter{{stream: "stdout", emit: onLine}, {stream: "stderr", emit: onLine}}
+			cmd.Stdout = io.MultiWriter(&stdout, lines[0])
+			cmd.Stderr = io.MultiWriter(&stderr, lines[1])
+		}
 		err = cmd.Run()
+		for _, w := range lines {
+			w.Flush()
+		}
 	}
 
 	duration := time.Since(start)
 
+	// A watched pattern decided the execution, whether or not the command
+	// exited before it was stopped. If it exited, its exit code is mapped
+	// first, as for any other execution.
+	if watch != nil {
+		if decision, decided := watch.Decision(); decided {
+			if e.exitCodes != nil && cmd.ProcessState != nil && cmd.ProcessState.Exited() {
+				if result, byCode, _ := e.exitCodes.Classify(cmd.ProcessState.ExitCode()); byCode {
+					decision = overrule(result, decision)
+				}
+			}
+			return &ExecutionResult{
+				ExitCode:        decision.ExitCode,
+				Stdout:          stdout.String(),
+				Stderr:          stderr.String(),
+				Duration:        duration,
+				Success:         decision.Success,
+				Reason:          decision.Reason,
+				Output:          stdout.String() + stderr.String(),
+				Fatal:           decision.Fatal,
+				Matched:         decision.Matched,
+				CommandExitCode: cmd.ProcessState.ExitCode(),
+			}, nil
+		}
+	}
+
 	// Get original exit code
 	originalExitCode := 0
 	if err != nil {
@@ -231,40 +364,81 @@ func (e *Executor) Execute(ctx context.Context, command []string) (*ExecutionRes
 		}
 	}
 
+	return e.evaluate(&ExecutionResult{
+		ExitCode: originalExitCode,
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: duration,
+	}), nil
+}
+
+// evaluate fills in a raw result's success, reason and combined output,
+// applying exit-code mapping and pattern matching if configured
+func (e *Executor) evaluate(result *ExecutionResult) *ExecutionResult {
 	// Combine stdout and stderr for pattern matching
-	combinedOutput := stdout.String() + stderr.String()
+	combinedOutput := result.Stdout + result.Stderr
+
+	result.CommandExitCode = result.ExitCode
+	finalResult := e.judge(patterns.Execution{
+		Stdout:   result.Stdout,
+		Stderr:   result.Stderr,
+		ExitCode: result.ExitCode,
+		Duration: result.Duration,
+	})
+
+	result.ExitCode = finalResult.ExitCode
+	result.Success = finalResult.Success
+	result.Reason = finalResult.Reason
+	result.Fatal = finalResult.Fatal
+	result.Matched = finalResult.Matched
+	result.Output = combinedOutput
+	return result
+}
 
-	// Apply pattern matching if configured
-	var finalResult patterns.EvaluationResult
-	if e.patternMatcher != nil {
-		finalResult = e.patternMatcher.EvaluateResult(combinedOutput, originalExitCode)
-	} else {
-		// No pattern matching - use original exit code
-		finalResult = patterns.EvaluationResult{
-			Success:  originalExitCode == 0,
-			ExitCode: originalExitCode,
-			Reason:   "exit code used",
+// judge decides an execution: mapped exit codes first, then the patterns
+// with the mapped code, then the exit code alone. The patterns still see an
+// execution the exit codes decided, so a fatal pattern can stop the run and
+// the matches are reported.
+func (e *Executor) judge(execution patterns.Execution) patterns.EvaluationResult {
+	if e.exitCodes != nil {
+		result, decided, mapped := e.exitCodes.Classify(execution.ExitCode)
+		if decided {
+			if e.patternMatcher == nil {
+				return result
+			}
+			return overrule(result, e.patternMatcher.EvaluateExecution(execution))
 		}
+		execution.ExitCode = mapped
 	}
 
-	// Create result with all fields
-	result := &ExecutionResult{
-		ExitCode: finalResult.ExitCode,
-		Stdout:   stdout.String(),
-		Stderr:   stderr.String(),
-		Duration: duration,
-		Success:  finalResult.Success,
-		Reason:   finalResult.Reason,
-		Output:   combinedOutput,
+	if e.patternMatcher != nil {
+		return e.patternMatcher.EvaluateExecution(execution)
 	}
 
-	return result, nil
+	// No pattern matching - use the exit code
+	return patterns.EvaluationResult{
+		Success:  execution.ExitCode == 0,
+		ExitCode: execution.ExitCode,
+		Reason:   "exit code used",
+	}
 }
 
-// streamOutput handles real-time streaming of command output
-func (e *Executor) streamOutput(pipe io.ReadCloser, buffer *bytes.Buffer, streamType string, command []string) {
+// overrule combines an execution decided by its exit code with what the
+// patterns made of it: a fatal pattern still stops the run, and the matches
+// are reported either way
+func overrule(byCode, matched patterns.EvaluationResult) patterns.EvaluationResult {
+	if matched.Fatal && !byCode.Fatal {
+		return matched
+	}
+	byCode.Matched = matched.Matched
+	return byCode
+}
+
+// streamOutput handles real-time streaming of command output, passing each
+// line to onLine when set
+func (e *Executor) streamOutput(pipe io.ReadCloser, buffer *bytes.Buffer, streamType string, command []string, onLine func(stream, line string)) {
 	defer func() {
-		if err := pipe.Close(); err != nil {
+		if err := pipe.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
 			// Log pipe close error but don't fail the execution
 			fmt.Fprintf(os.Stderr, "Warning: failed to close %s pipe: %v\n", streamType, err)
 		}
@@ -277,6 +451,10 @@ func (e *Executor) streamOutput(pipe io.ReadCloser, buffer *bytes.Buffer, stream
 		// Write to buffer for result capture
 		buffer.WriteString(line + "\n")
 
+		if onLine != nil {
+			onLine(streamType, line)
+		}
+
 		// Stream to writer if enabled
 		if e.streamWriter != nil && !e.quietMode {
 			output := strings.TrimSpace(line)
@@ -301,3 +479,33 @@ func (e *Executor) streamOutput(pipe io.ReadCloser, buffer *bytes.Buffer, stream
 		}
 	}
 }
+
+// lineWriter passes each complete line written to it to emit, without the
+// line ending; Flush passes on a final unterminated line
+type lineWriter struct {
+	stream  string
+	emit    func(stream, line string)
+	pending []byte
+}
+
+// Write implements io.Writer
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(w.stream, strings.TrimSuffix(string(w.pending[:i]), "\r"))
+		w.pending = w.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush emits any unterminated last line
+func (w *lineWriter) Flush() {
+	if len(w.pending) > 0 {
+		w.emit(w.stream, strings.TrimSuffix(string(w.pending), "\r"))
+		w.pending = nil
+	}
+}
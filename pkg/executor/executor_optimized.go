@@ -93,31 +93,14 @@ func (e *OptimizedExecutor) ExecuteOptimized(ctx context.Context, command []stri
 	stdoutStr := stdout.String()
 	stderrStr := stderr.String()
 
-	// Apply pattern matching if configured (avoid string concatenation)
-	var finalResult patterns.EvaluationResult
-	if e.patternMatcher != nil {
-		// Create combined output only if pattern matching is needed
-		var combinedOutput string
-		if len(stdoutStr) > 0 && len(stderrStr) > 0 {
-			// Pre-allocate with known size to avoid reallocations
-			combined := make([]byte, 0, len(stdoutStr)+len(stderrStr))
-			combined = append(combined, stdoutStr...)
-			combined = append(combined, stderrStr...)
-			combinedOutput = string(combined)
-		} else if len(stdoutStr) > 0 {
-			combinedOutput = stdoutStr
-		} else {
-			combinedOutput = stderrStr
-		}
-		finalResult = e.patternMatcher.EvaluateResult(combinedOutput, originalExitCode)
-	} else {
-		// No pattern matching - use original exit code
-		finalResult = patterns.EvaluationResult{
-			Success:  originalExitCode == 0,
-			ExitCode: originalExitCode,
-			Reason:   "exit code used",
-		}
-	}
+	// Apply exit-code mapping and pattern matching if configured; patterns
+	// see the streams apart
+	finalResult := e.judge(patterns.Execution{
+		Stdout:   stdoutStr,
+		Stderr:   stderrStr,
+		ExitCode: originalExitCode,
+		Duration: duration,
+	})
 
 	// Create result with optimized output handling
 	var combinedOutput string
@@ -131,13 +114,16 @@ func (e *OptimizedExecutor) ExecuteOptimized(ctx context.Context, command []stri
 	}
 
 	result := &ExecutionResult{
-		ExitCode: finalResult.ExitCode,
-		Stdout:   stdoutStr,
-		Stderr:   stderrStr,
-		Duration: duration,
-		Success:  finalResult.Success,
-		Reason:   finalResult.Reason,
-		Output:   combinedOutput,
+		ExitCode:        finalResult.ExitCode,
+		Stdout:          stdoutStr,
+		Stderr:          stderrStr,
+		Duration:        duration,
+		Success:         finalResult.Success,
+		Reason:          finalResult.Reason,
+		Fatal:           finalResult.Fatal,
+		Matched:         finalResult.Matched,
+		Output:          combinedOutput,
+		CommandExitCode: originalExitCode,
 	}
 
 	return result, nil
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"context"
+	"io"
 	"testing"
 	"time"
 
@@ -182,3 +183,71 @@ func TestExecutor_NoPatternConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestExecutor_StopOnPattern(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    patterns.PatternConfig
+		command   string
+		streaming bool
+		success   bool
+		fatal     bool
+		reason    string
+	}{
+		{
+			name:    "failure pattern stops the command",
+			config:  patterns.PatternConfig{FailurePattern: "stderr:ERROR", FailFastOnPattern: true},
+			command: "echo working; echo 'ERROR disk full' >&2; exec sleep 10",
+			reason:  "failure pattern matched; command stopped at stderr line 1",
+		},
+		{
+			name:      "failure pattern stops a streamed command",
+			config:    patterns.PatternConfig{FailurePattern: "ERROR", MinMatches: 2, FailFastOnPattern: true},
+			command:   "echo ERROR a; echo ok; echo ERROR b; exec sleep 10",
+			streaming: true,
+			reason:    "failure pattern matched; command stopped at stdout line 3",
+		},
+		{
+			name:    "fatal pattern stops the command",
+			config:  patterns.PatternConfig{FatalPatterns: []string{"panic:"}, FailFastOnPattern: true},
+			command: "echo 'panic: nil map'; exec sleep 10",
+			fatal:   true,
+			reason:  `fatal pattern "panic:" matched; command stopped at stdout line 1`,
+		},
+		{
+			name:      "success pattern ends a running command",
+			config:    patterns.PatternConfig{SuccessPattern: "listening on", SucceedOnPattern: true},
+			command:   "echo booting; echo 'listening on :8080'; exec sleep 10",
+			streaming: true,
+			success:   true,
+			reason:    "success pattern matched; command stopped at stdout line 2",
+		},
+		{
+			name:    "a failure seen first rules out succeeding early",
+			config:  patterns.PatternConfig{SuccessPattern: "ready", FailurePattern: "degraded", SucceedOnPattern: true},
+			command: "echo degraded; echo ready",
+			reason:  "failure pattern matched",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor, err := NewExecutorWithConfig(ExecutorConfig{
+				Timeout:       30 * time.Second,
+				PatternConfig: &tt.config,
+				Streaming:     tt.streaming,
+				StreamWriter:  io.Discard,
+			})
+			require.NoError(t, err)
+
+			start := time.Now()
+			result, err := executor.Execute(context.Background(), []string{"sh", "-c", tt.command})
+			require.NoError(t, err)
+
+			assert.Less(t, time.Since(start), 5*time.Second, "the command should be stopped")
+			assert.Equal(t, tt.success, result.Success)
+			assert.Equal(t, tt.fatal, result.Fatal)
+			assert.Equal(t, tt.reason, result.Reason)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/swi/repeater/pkg/patterns"
+)
+
+// ExitCodeConfig maps a command's exit codes before pattern evaluation. Each
+// field is a comma-separated list of codes and ranges; see ParseExitCodes.
+type ExitCodeConfig struct {
+	SuccessCodes string // codes counted as success, e.g. "0,1" for grep or diff
+	RetryCodes   string // codes worth retrying, e.g. "75,111"
+	AbortCodes   string // codes that abort the run, e.g. "2,64-78"
+}
+
+// ExitCodes is a set of exit codes between 0 and 255
+type ExitCodes map[int]bool
+
+// ParseExitCodes parses an exit code list such as "0,1" or "2,64-78"
+func ParseExitCodes(spec string) (ExitCodes, error) {
+	codes := make(ExitCodes)
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		// Range: 64-78
+		if low, high, ok := strings.Cut(item, "-"); ok && low != "" {
+			from, err := parseExitCode(low)
+			if err != nil {
+				return nil, err
+			}
+			to, err := parseExitCode(high)
+			if err != nil {
+				return nil, err
+			}
+			if to < from {
+				return nil, fmt.Errorf("invalid exit code range %q", item)
+			}
+			for code := from; code <= to; code++ {
+				codes[code] = true
+			}
+			continue
+		}
+
+		code, err := parseExitCode(item)
+		if err != nil {
+			return nil, err
+		}
+		codes[code] = true
+	}
+
+	return codes, nil
+}
+
+// parseExitCode parses a single exit code between 0 and 255
+func parseExitCode(value string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || code < 0 || code > 255 {
+		return 0, fmt.Errorf("invalid exit code %q", value)
+	}
+	return code, nil
+}
+
+// Contains reports whether code is in the set
+func (c ExitCodes) Contains(code int) bool {
+	return c[code]
+}
+
+// ExitCodeMap classifies exit codes by an ExitCodeConfig
+type ExitCodeMap struct {
+	success ExitCodes
+	retry   ExitCodes
+	abort   ExitCodes
+}
+
+// NewExitCodeMap parses the code lists of config, which must not share codes
+func NewExitCodeMap(config ExitCodeConfig) (*ExitCodeMap, error) {
+	m := &ExitCodeMap{}
+	sets := []struct {
+		name string
+		spec string
+		set  *ExitCodes
+	}{
+		{"success", config.SuccessCodes, &m.success},
+		{"retry", config.RetryCodes, &m.retry},
+		{"abort", config.AbortCodes, &m.abort},
+	}
+
+	for _, s := range sets {
+		codes, err := ParseExitCodes(s.spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s codes: %w", s.name, err)
+		}
+		*s.set = codes
+	}
+
+	// A code may only be in one set
+	for code := 0; code <= 255; code++ {
+		var in []string
+		for _, s := range sets {
+			if s.set.Contains(code) {
+				in = append(in, s.name)
+			}
+		}
+		if len(in) > 1 {
+			return nil, fmt.Errorf("exit code %d is in both %s and %s codes", code, in[0], in[1])
+		}
+	}
+
+	return m, nil
+}
+
+// Classify maps an exit code ahead of pattern evaluation. Abort codes fail
+// and stop the run and retry codes fail; those are decided here. A success
+// code is passed on to the patterns as 0, and with success codes set any
+// other code fails; without them 0 is the only success, as usual.
+func (m *ExitCodeMap) Classify(code int) (result patterns.EvaluationResult, decided bool, mapped int) {
+	switch {
+	case m.abort.Contains(code):
+		return patterns.EvaluationResult{
+			Success:  false,
+			ExitCode: code,
+			Reason:   fmt.Sprintf("exit code %d matched abort codes", code),
+			Fatal:    true,
+		}, true, code
+	case m.retry.Contains(code):
+		return patterns.EvaluationResult{
+			Success:  false,
+			ExitCode: code,
+			Reason:   fmt.Sprintf("exit code %d matched retry codes", code),
+		}, true, code
+	case m.success.Contains(code):
+		return patterns.EvaluationResult{}, false, 0
+	case len(m.success) == 0 && code == 0:
+		return patterns.EvaluationResult{}, false, 0
+	case len(m.success) > 0:
+		return patterns.EvaluationResult{
+			Success:  false,
+			ExitCode: max(code, 1),
+			Reason:   fmt.Sprintf("exit code %d not in success codes", code),
+		}, true, code
+	}
+	return patterns.EvaluationResult{}, false, code
+}
@@ -0,0 +1,206 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swi/repeater/pkg/patterns"
+)
+
+func TestParseExitCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		contains []int
+		excludes []int
+		errorMsg string
+	}{
+		{name: "empty", spec: "", excludes: []int{0, 1}},
+		{name: "list", spec: "0, 1", contains: []int{0, 1}, excludes: []int{2}},
+		{name: "range", spec: "2,64-78", contains: []int{2, 64, 70, 78}, excludes: []int{63, 79}},
+		{name: "highest code", spec: "255", contains: []int{255}},
+		{name: "not a number", spec: "0,ok", errorMsg: `invalid exit code "ok"`},
+		{name: "negative", spec: "-1", errorMsg: `invalid exit code "-1"`},
+		{name: "too large", spec: "256", errorMsg: `invalid exit code "256"`},
+		{name: "reversed range", spec: "78-64", errorMsg: `invalid exit code range "78-64"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes, err := ParseExitCodes(tt.spec)
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.errorMsg, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			for _, code := range tt.contains {
+				assert.True(t, codes.Contains(code), "code %d", code)
+			}
+			for _, code := range tt.excludes {
+				assert.False(t, codes.Contains(code), "code %d", code)
+			}
+		})
+	}
+}
+
+func TestExitCodeMap_Classify(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  ExitCodeConfig
+		code    int
+		decided bool
+		mapped  int
+		success bool
+		fatal   bool
+		reason  string
+	}{
+		{name: "success code maps to 0", config: ExitCodeConfig{SuccessCodes: "0,1"}, code: 1, mapped: 0},
+		{name: "code outside success codes fails", config: ExitCodeConfig{SuccessCodes: "1"}, code: 0, decided: true, reason: "exit code 0 not in success codes"},
+		{name: "retry code fails", config: ExitCodeConfig{RetryCodes: "75,111"}, code: 75, decided: true, mapped: 75, reason: "exit code 75 matched retry codes"},
+		{name: "other failure passes through with retry codes", config: ExitCodeConfig{RetryCodes: "75"}, code: 1, mapped: 1},
+		{name: "zero passes with retry codes", config: ExitCodeConfig{RetryCodes: "75"}, code: 0, mapped: 0},
+		{name: "abort code aborts", config: ExitCodeConfig{AbortCodes: "2,64-78"}, code: 70, decided: true, mapped: 70, fatal: true, reason: "exit code 70 matched abort codes"},
+		{name: "unmapped code passes through", config: ExitCodeConfig{AbortCodes: "2"}, code: 1, mapped: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewExitCodeMap(tt.config)
+			require.NoError(t, err)
+
+			result, decided, mapped := m.Classify(tt.code)
+			assert.Equal(t, tt.decided, decided)
+			assert.Equal(t, tt.mapped, mapped)
+			if decided {
+				assert.Equal(t, tt.success, result.Success)
+				assert.Equal(t, tt.fatal, result.Fatal)
+				assert.Equal(t, tt.reason, result.Reason)
+			}
+		})
+	}
+}
+
+func TestNewExitCodeMap_Overlap(t *testing.T) {
+	_, err := NewExitCodeMap(ExitCodeConfig{SuccessCodes: "0,1", AbortCodes: "1-3"})
+	require.Error(t, err)
+	assert.Equal(t, "exit code 1 is in both success and abort codes", err.Error())
+}
+
+func TestExecutor_ExitCodes(t *testing.T) {
+	tests := []struct {
+		name          string
+		command       []string
+		exitCodes     ExitCodeConfig
+		patternConfig *patterns.PatternConfig
+		success       bool
+		fatal         bool
+		exitCode      int
+		reason        string
+		matched       []string
+	}{
+		{
+			name:      "grep without a match succeeds",
+			command:   []string{"sh", "-c", "exit 1"},
+			exitCodes: ExitCodeConfig{SuccessCodes: "0,1"},
+			success:   true,
+			exitCode:  0,
+			reason:    "exit code used",
+		},
+		{
+			name:          "patterns see the mapped code",
+			command:       []string{"sh", "-c", "echo 'no differences'; exit 1"},
+			exitCodes:     ExitCodeConfig{SuccessCodes: "0,1"},
+			patternConfig: &patterns.PatternConfig{SuccessExpr: "exit == 0"},
+			success:       true,
+			exitCode:      0,
+			reason:        "success expression matched",
+		},
+		{
+			name:          "mapping precedes patterns",
+			command:       []string{"sh", "-c", "echo done; exit 2"},
+			exitCodes:     ExitCodeConfig{AbortCodes: "2"},
+			patternConfig: &patterns.PatternConfig{SuccessPattern: "done"},
+			fatal:         true,
+			exitCode:      2,
+			reason:        "exit code 2 matched abort codes",
+			matched:       []string{"done"},
+		},
+		{
+			name:          "fatal pattern overrides retry code",
+			command:       []string{"sh", "-c", "echo 'license expired'; exit 75"},
+			exitCodes:     ExitCodeConfig{RetryCodes: "75"},
+			patternConfig: &patterns.PatternConfig{FatalPatterns: []string{"license expired"}},
+			fatal:         true,
+			exitCode:      1,
+			reason:        `fatal pattern "license expired" matched`,
+			matched:       []string{"license expired"},
+		},
+		{
+			name:          "fatal pattern overrides code outside success codes",
+			command:       []string{"sh", "-c", "echo 'disk full' >&2; exit 2"},
+			exitCodes:     ExitCodeConfig{SuccessCodes: "0,1"},
+			patternConfig: &patterns.PatternConfig{FatalPatterns: []string{"stderr:disk full"}},
+			fatal:         true,
+			exitCode:      1,
+			reason:        `fatal pattern "stderr:disk full" matched`,
+			matched:       []string{"stderr:disk full"},
+		},
+		{
+			name:          "mapping precedes a watched pattern",
+			command:       []string{"sh", "-c", "printf ready; exit 75"},
+			exitCodes:     ExitCodeConfig{RetryCodes: "75"},
+			patternConfig: &patterns.PatternConfig{SuccessPattern: "ready", SucceedOnPattern: true},
+			exitCode:      75,
+			reason:        "exit code 75 matched retry codes",
+			matched:       []string{"ready"},
+		},
+		{
+			name:      "other failure with retry codes does not abort",
+			command:   []string{"sh", "-c", "exit 1"},
+			exitCodes: ExitCodeConfig{RetryCodes: "75"},
+			exitCode:  1,
+			reason:    "exit code used",
+		},
+		{
+			name:      "retry code fails without aborting",
+			command:   []string{"sh", "-c", "exit 75"},
+			exitCodes: ExitCodeConfig{RetryCodes: "75", AbortCodes: "64-74"},
+			exitCode:  75,
+			reason:    "exit code 75 matched retry codes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor, err := NewExecutorWithConfig(ExecutorConfig{
+				Timeout:       5 * time.Second,
+				PatternConfig: tt.patternConfig,
+				ExitCodes:     &tt.exitCodes,
+			})
+			require.NoError(t, err)
+
+			result, err := executor.Execute(context.Background(), tt.command)
+			require.NoError(t, err)
+			assert.Equal(t, tt.success, result.Success)
+			assert.Equal(t, tt.fatal, result.Fatal)
+			assert.Equal(t, tt.exitCode, result.ExitCode)
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, tt.matched, result.Matched)
+		})
+	}
+}
+
+func TestExecutor_CommandExitCode(t *testing.T) {
+	executor, err := NewExecutor(WithExitCodes(ExitCodeConfig{SuccessCodes: "0,3"}))
+	require.NoError(t, err)
+
+	result, err := executor.Execute(context.Background(), []string{"sh", "-c", "exit 3"})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, 3, result.CommandExitCode)
+}
@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Probe runs a check in-process in place of a command, such as an HTTP request
+// or a TCP connect. It is shaped like plugin.ExecutorPlugin but reports an
+// ExecutionResult, so the executor applies the timeout, streaming and pattern
+// matching to it exactly as it does for commands.
+type Probe interface {
+	// Name identifies the probe in verbose output, e.g. "http"
+	Name() string
+
+	// Execute runs the probe once. The result needs ExitCode, Stdout, Stderr
+	// and Duration; the executor fills in the rest. A failed check is a
+	// result with a non-zero exit code, not an error.
+	Execute(ctx context.Context, command []string) (*ExecutionResult, error)
+
+	SupportsStreaming() bool
+	SupportedPlatforms() []string
+}
+
+// PhaseTiming breaks down where a probe spent its time; phases that did not
+// happen, such as TLS for plain HTTP, are zero
+type PhaseTiming struct {
+	DNS     time.Duration // name resolution
+	Connect time.Duration // TCP connect, or the socket dial for UDP and Unix probes
+	TLS     time.Duration // TLS handshake
+	TTFB    time.Duration // request written until the first response byte
+}
+
+// String formats the phases for verbose output
+func (t PhaseTiming) String() string {
+	return fmt.Sprintf("dns=%v connect=%v tls=%v ttfb=%v", t.DNS, t.Connect, t.TLS, t.TTFB)
+}
+
+// WithProbe runs the given probe in place of commands
+func WithProbe(probe Probe) Option {
+	return func(e *Executor) error {
+		if probe == nil {
+			return errors.New("probe cannot be nil")
+		}
+		e.probe = probe
+		return nil
+	}
+}
+
+// executeProbe runs the configured probe under the executor's timeout
+func (e *Executor) executeProbe(ctx context.Context, command []string) (*ExecutionResult, error) {
+	start := time.Now()
+
+	execCtx := ctx
+	if e.timeout > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, e.timeout)
+		defer cancel()
+	}
+
+	result, err := e.probe.Execute(execCtx, command)
+	if execCtx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s probe timeout after %v", e.probe.Name(), e.timeout)
+	}
+	if execCtx.Err() == context.Canceled {
+		return nil, fmt.Errorf("%s probe canceled: %w", e.probe.Name(), context.Canceled)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s probe failed: %w", e.probe.Name(), err)
+	}
+
+	if result.Duration == 0 {
+		result.Duration = time.Since(start)
+	}
+
+	// Stream the probe's output as if a command had printed it
+	if e.streamWriter != nil && !e.quietMode {
+		name := []string{e.probe.Name()}
+		e.streamOutput(io.NopCloser(strings.NewReader(result.Stdout)), &bytes.Buffer{}, "stdout", name, e.onLine)
+		e.streamOutput(io.NopCloser(strings.NewReader(result.Stderr)), &bytes.Buffer{}, "stderr", name, e.onLine)
+	} else if e.onLine != nil {
+		for _, output := range []struct{ stream, text string }{{"stdout", result.Stdout}, {"stderr", result.Stderr}} {
+			lines := &lineWriter{stream: output.stream, emit: e.onLine}
+			_, _ = io.WriteString(lines, output.text)
+			lines.Flush()
+		}
+	}
+
+	return e.evaluate(result), nil
+}
@@ -0,0 +1,125 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swi/repeater/pkg/patterns"
+)
+
+// fakeProbe returns a fixed result, or blocks until the context ends
+type fakeProbe struct {
+	result *ExecutionResult
+	block  bool
+}
+
+func (p *fakeProbe) Name() string                 { return "fake" }
+func (p *fakeProbe) SupportsStreaming() bool      { return false }
+func (p *fakeProbe) SupportedPlatforms() []string { return []string{"linux", "darwin", "windows"} }
+
+func (p *fakeProbe) Execute(ctx context.Context, command []string) (*ExecutionResult, error) {
+	if p.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	result := *p.result
+	return &result, nil
+}
+
+func TestExecutor_Probe(t *testing.T) {
+	tests := []struct {
+		name            string
+		result          *ExecutionResult
+		patternConfig   *patterns.PatternConfig
+		expectedSuccess bool
+		expectedCode    int
+		expectedReason  string
+	}{
+		{
+			name:            "passing probe",
+			result:          &ExecutionResult{Stdout: "HTTP/1.1 200 OK\r\n\r\nok", Duration: 5 * time.Millisecond},
+			expectedSuccess: true,
+			expectedReason:  "exit code used",
+		},
+		{
+			name:            "failing probe",
+			result:          &ExecutionResult{ExitCode: 1, Stderr: "connection refused"},
+			expectedSuccess: false,
+			expectedCode:    1,
+			expectedReason:  "exit code used",
+		},
+		{
+			name:            "patterns apply to probe output",
+			result:          &ExecutionResult{Stdout: "HTTP/1.1 200 OK\r\n\r\n{\"status\": \"degraded\"}"},
+			patternConfig:   &patterns.PatternConfig{FailurePattern: "degraded"},
+			expectedSuccess: false,
+			expectedCode:    1,
+			expectedReason:  "failure pattern matched",
+		},
+		{
+			name:            "status codes apply to probe output",
+			result:          &ExecutionResult{Stdout: "HTTP/1.1 401 Unauthorized\r\n\r\n"},
+			patternConfig:   &patterns.PatternConfig{HTTPFatalCodes: "401"},
+			expectedSuccess: false,
+			expectedCode:    1,
+			expectedReason:  "HTTP 401 matched fatal codes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executor, err := NewExecutorWithConfig(ExecutorConfig{
+				Timeout:       time.Second,
+				PatternConfig: tt.patternConfig,
+				Probe:         &fakeProbe{result: tt.result},
+			})
+			require.NoError(t, err)
+
+			result, err := executor.Execute(context.Background(), nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedSuccess, result.Success)
+			assert.Equal(t, tt.expectedCode, result.ExitCode)
+			assert.Equal(t, tt.expectedReason, result.Reason)
+			assert.Equal(t, tt.result.Stdout+tt.result.Stderr, result.Output)
+			if tt.result.Duration > 0 {
+				assert.Equal(t, tt.result.Duration, result.Duration)
+			} else {
+				assert.Greater(t, result.Duration, time.Duration(0))
+			}
+		})
+	}
+}
+
+func TestExecutor_ProbeTimeout(t *testing.T) {
+	executor, err := NewExecutor(WithTimeout(20*time.Millisecond), WithProbe(&fakeProbe{block: true}))
+	require.NoError(t, err)
+
+	_, err = executor.Execute(context.Background(), nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "fake probe timeout")
+}
+
+func TestExecutor_ProbeStreaming(t *testing.T) {
+	var buf bytes.Buffer
+	executor, err := NewExecutor(
+		WithStreaming(&buf),
+		WithOutputPrefix("[api]"),
+		WithProbe(&fakeProbe{result: &ExecutionResult{Stdout: "HTTP/1.1 200 OK\n\nhealthy\n"}}),
+	)
+	require.NoError(t, err)
+
+	_, err = executor.Execute(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "[api] HTTP/1.1 200 OK")
+	assert.Contains(t, buf.String(), "[api] healthy")
+}
+
+func TestExecutor_NilProbe(t *testing.T) {
+	_, err := NewExecutor(WithProbe(nil))
+	assert.Error(t, err)
+}
@@ -312,3 +312,34 @@ func TestExecutor_StreamingVsNonStreaming(t *testing.T) {
 		}
 	})
 }
+
+func TestExecutor_LineHandler(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+	}{
+		{name: "captured"},
+		{name: "streamed", options: []Option{WithStreaming(&bytes.Buffer{})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var lines []string
+			handler := WithLineHandler(func(stream, line string) {
+				mu.Lock()
+				defer mu.Unlock()
+				lines = append(lines, stream+": "+line)
+			})
+
+			executor, err := NewExecutor(append(tt.options, handler)...)
+			require.NoError(t, err)
+
+			result, err := executor.Execute(context.Background(), []string{"sh", "-c", "echo one; echo two; echo oops >&2; printf tail"})
+			require.NoError(t, err)
+
+			assert.Contains(t, result.Stdout, "one\ntwo\ntail", "output is still captured")
+			assert.ElementsMatch(t, []string{"stdout: one", "stdout: two", "stdout: tail", "stderr: oops"}, lines)
+		})
+	}
+}
@@ -0,0 +1,365 @@
+package expr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// node is a compiled expression element. Values are float64, time.Duration,
+// string, bool, []any, map[string]any or nil.
+type node interface {
+	eval(s *state) (any, error)
+	condition() bool // whether the node can yield a boolean
+}
+
+// variables maps names to the part of the execution they read
+var variables = map[string]func(env Env) any{
+	"exit":     func(env Env) any { return float64(env.ExitCode) },
+	"duration": func(env Env) any { return env.Duration },
+	"stdout":   func(env Env) any { return env.Stdout },
+	"stderr":   func(env Env) any { return env.Stderr },
+	"output":   func(env Env) any { return env.Stdout + env.Stderr },
+}
+
+// functions maps function names to their implementations
+var functions = map[string]func(s *state, arg any) (any, error){
+	"json": parseJSON,
+	"len":  length,
+}
+
+type literalNode struct{ value any }
+
+func (n *literalNode) eval(*state) (any, error) { return n.value, nil }
+func (n *literalNode) condition() bool {
+	_, ok := n.value.(bool)
+	return ok
+}
+
+type variableNode struct{ name string }
+
+func (n *variableNode) eval(s *state) (any, error) { return variables[n.name](s.env), nil }
+func (n *variableNode) condition() bool            { return false }
+
+// documentNode is the JSON document that paths such as .status start from
+type documentNode struct{}
+
+func (n *documentNode) eval(s *state) (any, error) {
+	if s.env.Body != "" {
+		return parseJSON(s, s.env.Body)
+	}
+	return parseJSON(s, s.env.Stdout)
+}
+func (n *documentNode) condition() bool { return true } // the document may be a boolean
+
+type listNode struct{ items []node }
+
+func (n *listNode) eval(s *state) (any, error) {
+	list := make([]any, len(n.items))
+	for i, item := range n.items {
+		value, err := item.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = value
+	}
+	return list, nil
+}
+func (n *listNode) condition() bool { return false }
+
+type callNode struct {
+	name string
+	fn   func(s *state, arg any) (any, error)
+	arg  node
+}
+
+func (n *callNode) eval(s *state) (any, error) {
+	arg, err := n.arg.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	return n.fn(s, arg)
+}
+func (n *callNode) condition() bool { return false }
+
+// indexNode reads a field of an object or an element of a list; reading from
+// null or past the end of a list yields null, as in jq
+type indexNode struct {
+	operand node
+	index   node
+}
+
+func (n *indexNode) eval(s *state) (any, error) {
+	value, err := n.operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch container := value.(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("cannot index an object with %s", typeName(index))
+		}
+		return container[key], nil
+	case []any:
+		i, ok := index.(float64)
+		if !ok || i != math.Trunc(i) {
+			return nil, fmt.Errorf("cannot index a list with %s", describe(index))
+		}
+		if i < 0 {
+			i += float64(len(container))
+		}
+		if i < 0 || int(i) >= len(container) {
+			return nil, nil
+		}
+		return container[int(i)], nil
+	}
+
+	if key, ok := index.(string); ok {
+		return nil, fmt.Errorf("cannot read field %q of %s", key, typeName(value))
+	}
+	return nil, fmt.Errorf("cannot index %s", typeName(value))
+}
+func (n *indexNode) condition() bool { return true } // JSON fields may be booleans
+
+type notNode struct{ operand node }
+
+func (n *notNode) eval(s *state) (any, error) {
+	value, err := n.operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return nil, fmt.Errorf("! needs a boolean, got %s", typeName(value))
+	}
+	return !b, nil
+}
+func (n *notNode) condition() bool { return true }
+
+type logicalNode struct {
+	op          string // && or ||
+	left, right node
+}
+
+func (n *logicalNode) eval(s *state) (any, error) {
+	left, err := n.boolean(s, n.left)
+	if err != nil {
+		return nil, err
+	}
+	if left == (n.op == "||") {
+		return left, nil // short-circuit
+	}
+	return n.boolean(s, n.right)
+}
+
+func (n *logicalNode) boolean(s *state, operand node) (bool, error) {
+	value, err := operand.eval(s)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s needs booleans, got %s", n.op, typeName(value))
+	}
+	return b, nil
+}
+func (n *logicalNode) condition() bool { return true }
+
+type matchNode struct {
+	negate  bool
+	operand node
+	regex   *regexp.Regexp
+}
+
+func (n *matchNode) eval(s *state) (any, error) {
+	value, err := n.operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("=~ needs a string, got %s", typeName(value))
+	}
+	return n.regex.MatchString(text) != n.negate, nil
+}
+func (n *matchNode) condition() bool { return true }
+
+type compareNode struct {
+	op          string // == != < <= > >= in
+	left, right node
+}
+
+func (n *compareNode) eval(s *state) (any, error) {
+	left, err := n.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		return contains(right, left)
+	}
+
+	order, err := compare(left, right)
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	case ">":
+		return order > 0, nil
+	default:
+		return order >= 0, nil
+	}
+}
+func (n *compareNode) condition() bool { return true }
+
+// equal compares values of any type; values of different types are unequal
+func equal(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// compare orders two numbers, durations or strings
+func compare(a, b any) (int, error) {
+	switch a := a.(type) {
+	case float64:
+		if b, ok := b.(float64); ok {
+			return cmpOrdered(a, b), nil
+		}
+	case time.Duration:
+		if b, ok := b.(time.Duration); ok {
+			return cmpOrdered(a, b), nil
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return strings.Compare(a, b), nil
+		}
+	}
+	return 0, fmt.Errorf("cannot compare %s with %s", typeName(a), typeName(b))
+}
+
+func cmpOrdered[T float64 | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// contains reports whether item is in a list, a key of an object or a
+// substring of a string
+func contains(container, item any) (any, error) {
+	switch container := container.(type) {
+	case []any:
+		for _, element := range container {
+			if equal(element, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]any:
+		key, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("in an object needs a string key, got %s", typeName(item))
+		}
+		_, found := container[key]
+		return found, nil
+	case string:
+		text, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("in a string needs a string, got %s", typeName(item))
+		}
+		return strings.Contains(container, text), nil
+	}
+	return nil, fmt.Errorf("in needs a list, object or string, got %s", typeName(container))
+}
+
+// parseJSON implements json(s), caching the parse for the evaluation
+func parseJSON(s *state, arg any) (any, error) {
+	text, ok := arg.(string)
+	if !ok {
+		return nil, fmt.Errorf("json needs a string, got %s", typeName(arg))
+	}
+	if value, ok := s.json[text]; ok {
+		return value, nil
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return nil, fmt.Errorf("json: invalid JSON: %w", err)
+	}
+	if s.json == nil {
+		s.json = make(map[string]any)
+	}
+	s.json[text] = value
+	return value, nil
+}
+
+// length implements len(x)
+func length(_ *state, arg any) (any, error) {
+	switch value := arg.(type) {
+	case string:
+		return float64(len(value)), nil
+	case []any:
+		return float64(len(value)), nil
+	case map[string]any:
+		return float64(len(value)), nil
+	case nil:
+		return float64(0), nil
+	}
+	return nil, fmt.Errorf("len needs a string, list or object, got %s", typeName(arg))
+}
+
+// typeName names a value's type for error messages
+func typeName(value any) string {
+	switch value.(type) {
+	case float64:
+		return "number"
+	case time.Duration:
+		return "duration"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []any:
+		return "list"
+	case map[string]any:
+		return "object"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// describe formats a value with its type for error messages
+func describe(value any) string {
+	if value == nil {
+		return "null"
+	}
+	return fmt.Sprintf("%s %v", typeName(value), value)
+}
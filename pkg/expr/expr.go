@@ -0,0 +1,146 @@
+// Package expr compiles and evaluates boolean expressions over an execution's
+// exit code, duration and output, such as
+//
+//	exit in [0, 3] && duration < 2s && stdout =~ "ok" && !(stderr =~ "WARN")
+//	json(stdout).status == "green" && len(json(stdout).items) > 0
+//
+//	.replicas.ready >= 3 and (.items | length) > 0
+//
+// Names: exit, duration, stdout, stderr and output (stdout then stderr).
+// Literals: numbers, durations (2s, 150ms, 1m30s), double-quoted or backquoted
+// strings, true, false, null and lists ([0, 3]). Operators, loosest first:
+// || (or), && (and), !, then == != < <= > >= =~ !~ in. Values are read with
+// .field and [index]; json(s) parses a string as JSON and len(x) measures a
+// string, list or object.
+//
+// As in jq, a path starting with a dot reads the execution's JSON document, so
+// .status is json(stdout).status, and "| length" measures the value before it.
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Env is the execution an expression is evaluated against
+type Env struct {
+	ExitCode int
+	Duration time.Duration
+	Stdout   string
+	Stderr   string
+	Body     string // JSON document read by paths such as .status; Stdout if empty
+}
+
+// Expr is a compiled expression, safe for concurrent use
+type Expr struct {
+	source string
+	root   node
+}
+
+// SyntaxError reports where an expression fails to compile
+type SyntaxError struct {
+	Pos int // byte offset in the expression, from 0
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, e.Pos+1)
+}
+
+// Compile parses an expression, checking its names, functions, regular
+// expressions and that it yields a condition
+func Compile(source string) (*Expr, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, &SyntaxError{Msg: "empty expression"}
+	}
+
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok)}
+	}
+	if !root.condition() {
+		return nil, &SyntaxError{Msg: "expression must be a condition, e.g. exit == 0"}
+	}
+
+	return &Expr{source: source, root: root}, nil
+}
+
+// String returns the expression's source
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval reports whether the expression holds for env. It fails when a value
+// has the wrong type, e.g. ordering a string against a number or json() given
+// output that is not JSON.
+func (e *Expr) Eval(env Env) (bool, error) {
+	value, err := e.root.eval(&state{env: env})
+	if err != nil {
+		return false, err
+	}
+	result, ok := value.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression yields %s, not a boolean", typeName(value))
+	}
+	return result, nil
+}
+
+// Query is a compiled jq-style path such as .replicas.ready, .items[0].name or
+// .items | length, safe for concurrent use
+type Query struct {
+	source string
+	root   node
+}
+
+// CompileQuery parses a path, which must start with a dot
+func CompileQuery(source string) (*Query, error) {
+	source = strings.TrimSpace(source)
+	if !strings.HasPrefix(source, ".") {
+		return nil, &SyntaxError{Msg: "path must start with \".\", e.g. .status"}
+	}
+
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok)}
+	}
+
+	return &Query{source: source, root: root}, nil
+}
+
+// String returns the query's source
+func (q *Query) String() string {
+	return q.source
+}
+
+// Eval reads the path from env's JSON document. The value is a float64,
+// string, bool, []any, map[string]any or nil, as from encoding/json; missing
+// fields and elements read as nil.
+func (q *Query) Eval(env Env) (any, error) {
+	return q.root.eval(&state{env: env})
+}
+
+// state carries an evaluation's environment and parsed JSON, so json(stdout)
+// is parsed once however often the expression uses it
+type state struct {
+	env  Env
+	json map[string]any
+}
@@ -0,0 +1,166 @@
+package expr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		source   string
+		errorMsg string
+	}{
+		{name: "empty", source: "  ", errorMsg: "empty expression"},
+		{name: "unknown name", source: "exitcode == 0", errorMsg: `unknown name "exitcode" (want exit, duration, stdout, stderr or output) at column 1`},
+		{name: "unknown function", source: "yaml(stdout).a == 1", errorMsg: `unknown function "yaml"`},
+		{name: "dangling operator", source: "exit == 0 &&", errorMsg: "unexpected end of expression at column 13"},
+		{name: "single ampersand", source: "exit == 0 & duration < 1s", errorMsg: `unexpected character '&' at column 11`},
+		{name: "unterminated string", source: `stdout =~ "ok`, errorMsg: "unterminated string at column 11"},
+		{name: "bad duration", source: "duration < 2parsecs", errorMsg: `invalid duration "2parsecs" at column 12`},
+		{name: "bad regex", source: `stdout =~ "("`, errorMsg: "invalid regular expression"},
+		{name: "regex not literal", source: "stdout =~ stderr", errorMsg: `=~ needs a string literal on the right, found "stderr"`},
+		{name: "unclosed paren", source: "(exit == 0", errorMsg: `expected ")", found end of expression`},
+		{name: "chained comparison", source: "0 < exit < 3", errorMsg: `unexpected "<" at column 10`},
+		{name: "not a condition", source: "stdout", errorMsg: "expression must be a condition"},
+		{name: "unsupported filter", source: ".items | keys == []", errorMsg: `unsupported filter "keys" after "|" (want length) at column 10`},
+		{name: "path without field", source: `. . == 1`, errorMsg: `expected a field name after ".", found "=="`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestExpr_Eval(t *testing.T) {
+	t.Parallel()
+
+	env := Env{
+		ExitCode: 3,
+		Duration: 1500 * time.Millisecond,
+		Stdout:   `{"status": "green", "replicas": {"ready": 2}, "items": [{"name": "a"}, {"name": "b"}], "healthy": true}`,
+		Stderr:   "WARN disk at 91%\n",
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   bool
+	}{
+		{name: "request example", source: `exit in [0,3] && duration < 2s && stdout =~ "green" && !(stderr =~ "ERROR")`, want: true},
+		{name: "exit code", source: "exit == 0", want: false},
+		{name: "duration bound", source: "duration >= 1s500ms", want: true},
+		{name: "negated match", source: `stderr !~ "WARN"`, want: false},
+		{name: "regex escapes", source: `stderr =~ "\d+%"`, want: true},
+		{name: "raw string", source: "stderr =~ `^WARN`", want: true},
+		{name: "combined output", source: `output =~ "green" && output =~ "disk"`, want: true},
+		{name: "json field", source: `json(stdout).status == "green"`, want: true},
+		{name: "json nested", source: "json(stdout).replicas.ready == 3", want: false},
+		{name: "json index", source: `json(stdout).items[1].name == "b"`, want: true},
+		{name: "json negative index", source: `json(stdout).items[-1]["name"] == "b"`, want: true},
+		{name: "json missing field", source: "json(stdout).missing.deeper == null", want: true},
+		{name: "json boolean field", source: "json(stdout).healthy", want: true},
+		{name: "len", source: "len(json(stdout).items) == 2 && len(stderr) > 0", want: true},
+		{name: "in object", source: `"replicas" in json(stdout)`, want: true},
+		{name: "in string", source: `"disk" in stderr`, want: true},
+		{name: "or short-circuits", source: "exit == 3 || json(stderr).x == 1", want: true},
+		{name: "and short-circuits", source: "exit == 0 && json(stderr).x == 1", want: false},
+		{name: "precedence", source: "exit == 0 || exit == 3 && duration < 1s", want: false},
+		{name: "mixed types are unequal", source: `exit == "3"`, want: false},
+		{name: "path", source: `.status == "green" && .replicas.ready == 2`, want: true},
+		{name: "path index", source: `.items[0].name == "a" and .items[-1].name != "a"`, want: true},
+		{name: "path from the document root", source: `.["status"] == "green" or exit == 0`, want: true},
+		{name: "path length", source: ".items | length == 2 && (.replicas | length) > 0", want: true},
+		{name: "path boolean", source: ".healthy", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.source)
+			require.NoError(t, err)
+			got, err := e.Eval(env)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestExpr_EvalErrors(t *testing.T) {
+	t.Parallel()
+
+	env := Env{Stdout: "not json", Stderr: `{"count": "3"}`}
+
+	tests := []struct {
+		name     string
+		source   string
+		errorMsg string
+	}{
+		{name: "invalid json", source: "json(stdout).a == 1", errorMsg: "json: invalid JSON"},
+		{name: "ordering mixed types", source: "json(stderr).count > 2", errorMsg: "cannot compare string with number"},
+		{name: "duration against number", source: "duration < 2", errorMsg: "cannot compare duration with number"},
+		{name: "field of a string", source: "json(stderr).count.value == 1", errorMsg: `cannot read field "value" of string`},
+		{name: "non-boolean operand", source: "json(stderr).count && exit == 0", errorMsg: "&& needs booleans, got string"},
+		{name: "non-boolean result", source: "json(stderr).count", errorMsg: "expression yields string, not a boolean"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := Compile(tt.source)
+			require.NoError(t, err)
+			_, err = e.Eval(env)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	env := Env{
+		Stdout: "HTTP/1.1 200 OK\r\n\r\n",
+		Body:   `{"replicas": {"ready": 2}, "items": ["a", "b", "c"], "name": "web"}`,
+	}
+
+	tests := []struct {
+		name   string
+		source string
+		want   any
+	}{
+		{name: "field", source: ".replicas.ready", want: float64(2)},
+		{name: "index", source: ".items[1]", want: "b"},
+		{name: "length", source: ".items | length", want: float64(3)},
+		{name: "string length", source: ".name | length", want: float64(3)},
+		{name: "missing", source: ".replicas.desired", want: nil},
+		{name: "document", source: " .replicas ", want: map[string]any{"ready": float64(2)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := CompileQuery(tt.source)
+			require.NoError(t, err)
+			got, err := q.Eval(env)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for source, errorMsg := range map[string]string{
+		"status":            `path must start with "."`,
+		".status == \"ok\"": `unexpected "=="`,
+		".items[":           "unexpected end of expression",
+	} {
+		_, err := CompileQuery(source)
+		require.Error(t, err, source)
+		assert.Contains(t, err.Error(), errorMsg)
+	}
+}
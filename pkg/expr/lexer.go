@@ -0,0 +1,146 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// tokenKind classifies a token
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenDuration
+	tokenString
+	tokenIdent
+	tokenOperator // == != < <= > >= =~ !~ && || | ! - . , ( ) [ ]
+)
+
+// token is a lexeme with its position, counted in bytes from 0
+type token struct {
+	kind     tokenKind
+	text     string
+	pos      int
+	number   float64       // value of a tokenNumber
+	duration time.Duration // value of a tokenDuration
+	value    string        // unquoted value of a tokenString
+}
+
+func (t token) String() string {
+	if t.kind == tokenEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// operators lists the operator tokens, longest first so that "<=" wins over "<"
+var operators = []string{"==", "!=", "<=", ">=", "=~", "!~", "&&", "||", "|", "<", ">", "!", "-", ".", ",", "(", ")", "[", "]"}
+
+// lex splits source into tokens, ending with tokenEOF
+func lex(source string) ([]token, error) {
+	var tokens []token
+	for pos := 0; pos < len(source); {
+		c := source[pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			pos++
+
+		case c >= '0' && c <= '9':
+			tok, err := lexNumber(source, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			pos += len(tok.text)
+
+		case c == '"' || c == '`':
+			tok, err := lexString(source, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			pos += len(tok.text)
+
+		case c == '_' || unicode.IsLetter(rune(c)):
+			end := pos
+			for end < len(source) && (source[end] == '_' || unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end]))) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[pos:end], pos: pos})
+			pos = end
+
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(source[pos:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: pos})
+					pos += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("unexpected character %q", c)}
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+// lexNumber reads a number such as 3 or 0.5, or a duration such as 2s, 150ms
+// or 1m30s
+func lexNumber(source string, pos int) (token, error) {
+	end := pos
+	for end < len(source) && (isDigit(source[end]) || source[end] == '.') {
+		end++
+	}
+
+	// Digits followed by a unit make a duration; units and digits may repeat
+	if end < len(source) && unicode.IsLetter(rune(source[end])) {
+		for end < len(source) && (isDigit(source[end]) || source[end] == '.' || unicode.IsLetter(rune(source[end]))) {
+			end++
+		}
+		text := source[pos:end]
+		d, err := time.ParseDuration(text)
+		if err != nil {
+			return token{}, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("invalid duration %q", text)}
+		}
+		return token{kind: tokenDuration, text: text, pos: pos, duration: d}, nil
+	}
+
+	text := source[pos:end]
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return token{}, &SyntaxError{Pos: pos, Msg: fmt.Sprintf("invalid number %q", text)}
+	}
+	return token{kind: tokenNumber, text: text, pos: pos, number: n}, nil
+}
+
+// lexString reads a double-quoted or backquoted string. In double quotes only
+// \" and \\ are escapes, so regular expressions such as "\d+" need no
+// doubling; backquoted strings are raw.
+func lexString(source string, pos int) (token, error) {
+	quote := source[pos]
+	var value strings.Builder
+	for end := pos + 1; end < len(source); end++ {
+		c := source[end]
+		switch {
+		case c == quote:
+			return token{kind: tokenString, text: source[pos : end+1], pos: pos, value: value.String()}, nil
+		case c == '\\' && quote == '"' && end+1 < len(source) && (source[end+1] == '"' || source[end+1] == '\\'):
+			value.WriteByte(source[end+1])
+			end++
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return token{}, &SyntaxError{Pos: pos, Msg: "unterminated string"}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,282 @@
+package expr
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// parser builds a node tree from tokens by recursive descent
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// accept consumes the given operator if it comes next
+func (p *parser) accept(op string) bool {
+	if tok := p.peek(); tok.kind == tokenOperator && tok.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// expect consumes the given operator or fails
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		tok := p.peek()
+		return &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("expected %q, found %s", op, tok)}
+	}
+	return nil
+}
+
+// acceptKeyword consumes the given word if it comes next
+func (p *parser) acceptKeyword(word string) bool {
+	if tok := p.peek(); tok.kind == tokenIdent && tok.text == word {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseOr: and (("||" | "or") and)*
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") || p.acceptKeyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd: unary (("&&" | "and") unary)*
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") || p.acceptKeyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseUnary: "!" unary | comparison
+func (p *parser) parseUnary() (node, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+// comparisons are the binary operators binding tighter than && and ||
+var comparisons = map[string]bool{"==": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "=~": true, "!~": true}
+
+// parseComparison: value (op value)?
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	tok := p.peek()
+	isIn := tok.kind == tokenIdent && tok.text == "in"
+	if !isIn && (tok.kind != tokenOperator || !comparisons[tok.text]) {
+		return left, nil
+	}
+	p.next()
+
+	if tok.text == "=~" || tok.text == "!~" {
+		pattern := p.next()
+		if pattern.kind != tokenString {
+			return nil, &SyntaxError{Pos: pattern.pos, Msg: fmt.Sprintf("%s needs a string literal on the right, found %s", tok.text, pattern)}
+		}
+		regex, err := regexp.Compile(pattern.value)
+		if err != nil {
+			return nil, &SyntaxError{Pos: pattern.pos, Msg: fmt.Sprintf("invalid regular expression: %v", err)}
+		}
+		return &matchNode{negate: tok.text == "!~", operand: left, regex: regex}, nil
+	}
+
+	right, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{op: tok.text, left: left, right: right}, nil
+}
+
+// parsePostfix: primary ("." name | "[" expr "]" | "|" "length")*
+func (p *parser) parsePostfix() (node, error) {
+	value, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.accept("."):
+			name, err := p.fieldName()
+			if err != nil {
+				return nil, err
+			}
+			value = &indexNode{operand: value, index: name}
+		case p.accept("|"):
+			filter := p.next()
+			if filter.kind != tokenIdent || filter.text != "length" {
+				return nil, &SyntaxError{Pos: filter.pos, Msg: fmt.Sprintf("unsupported filter %s after \"|\" (want length)", filter)}
+			}
+			value = &callNode{name: "length", fn: length, arg: value}
+		case p.accept("["):
+			index, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			value = &indexNode{operand: value, index: index}
+		default:
+			return value, nil
+		}
+	}
+}
+
+// fieldName reads the name after a ".", the "." already consumed
+func (p *parser) fieldName() (node, error) {
+	name := p.next()
+	if name.kind != tokenIdent {
+		return nil, &SyntaxError{Pos: name.pos, Msg: fmt.Sprintf("expected a field name after \".\", found %s", name)}
+	}
+	return &literalNode{value: name.text}, nil
+}
+
+// parsePrimary: literal | name | call | path | "(" expr ")" | "[" list "]"
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenNumber:
+		return &literalNode{value: tok.number}, nil
+	case tokenDuration:
+		return &literalNode{value: tok.duration}, nil
+	case tokenString:
+		return &literalNode{value: tok.value}, nil
+	case tokenIdent:
+		return p.parseName(tok)
+	case tokenOperator:
+		switch tok.text {
+		case "(":
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			return p.parseList()
+		case ".":
+			return p.parsePath()
+		case "-":
+			switch operand := p.next(); operand.kind {
+			case tokenNumber:
+				return &literalNode{value: -operand.number}, nil
+			case tokenDuration:
+				return &literalNode{value: -operand.duration}, nil
+			}
+			return nil, &SyntaxError{Pos: tok.pos, Msg: "\"-\" must precede a number or duration"}
+		}
+	}
+	return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unexpected %s", tok)}
+}
+
+// parsePath starts a jq-style path at the JSON document: "." alone, ".name"
+// or ".[index]", the "." already consumed
+func (p *parser) parsePath() (node, error) {
+	if tok := p.peek(); tok.kind != tokenIdent {
+		return &documentNode{}, nil // "." or ".[index]", the index read by parsePostfix
+	}
+	name, err := p.fieldName()
+	if err != nil {
+		return nil, err
+	}
+	return &indexNode{operand: &documentNode{}, index: name}, nil
+}
+
+// parseName resolves a keyword, variable or function call
+func (p *parser) parseName(tok token) (node, error) {
+	switch tok.text {
+	case "true":
+		return &literalNode{value: true}, nil
+	case "false":
+		return &literalNode{value: false}, nil
+	case "null":
+		return &literalNode{value: nil}, nil
+	}
+
+	if p.accept("(") {
+		fn, ok := functions[tok.text]
+		if !ok {
+			return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unknown function %q (want json or len)", tok.text)}
+		}
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &callNode{name: tok.text, fn: fn, arg: arg}, nil
+	}
+
+	if _, ok := variables[tok.text]; !ok {
+		return nil, &SyntaxError{Pos: tok.pos, Msg: fmt.Sprintf("unknown name %q (want exit, duration, stdout, stderr or output)", tok.text)}
+	}
+	return &variableNode{name: tok.text}, nil
+}
+
+// parseList: "[" (expr ("," expr)*)? "]", the "[" already consumed
+func (p *parser) parseList() (node, error) {
+	list := &listNode{}
+	if p.accept("]") {
+		return list, nil
+	}
+	for {
+		item, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		list.items = append(list.items, item)
+		if p.accept("]") {
+			return list, nil
+		}
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package httpaware
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// awsThrottlingCodes are the error codes the AWS SDKs retry as throttling
+var awsThrottlingCodes = []string{
+	"Throttling", "ThrottlingException", "ThrottledException", "RequestThrottledException",
+	"TooManyRequestsException", "ProvisionedThroughputExceededException",
+	"TransactionInProgressException", "RequestLimitExceeded", "BandwidthLimitExceeded",
+	"LimitExceededException", "RequestThrottled", "SlowDown", "PriorRequestNotComplete",
+	"EC2ThrottledException",
+}
+
+var (
+	// aws CLI: "An error occurred (ThrottlingException) when calling ...";
+	// Go SDK v2: "api error ThrottlingException: Rate exceeded"
+	awsErrorRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`An error occurred \(([A-Za-z0-9.]+)\)`),
+		regexp.MustCompile(`api error ([A-Za-z0-9.]+):`),
+	}
+
+	// gcloud: "ERROR: (gcloud.compute.instances.list) ..."
+	gcloudErrorRegex = regexp.MustCompile(`(?m)^ERROR: \(gcloud\.[^)]*\)`)
+
+	// Google API quota errors as gcloud and the REST APIs report them
+	gcloudThrottleRegex = regexp.MustCompile(`RESOURCE_EXHAUSTED|rateLimitExceeded|userRateLimitExceeded|Rate Limit Exceeded|Quota exceeded|\b429\b`)
+)
+
+// awsParser reads AWS CLI and SDK errors. An error is a failure, and a
+// throttling error backs off exponentially, since AWS names no retry time.
+type awsParser struct {
+	backoff throttleBackoff
+}
+
+// Name implements ResponseParser
+func (p *awsParser) Name() string {
+	return ParserAWS
+}
+
+// Parse implements ResponseParser
+func (p *awsParser) Parse(output string) (*ParseResult, error) {
+	code := ""
+	for _, regex := range awsErrorRegexes {
+		if match := regex.FindStringSubmatch(output); match != nil {
+			code = match[1]
+			break
+		}
+	}
+
+	throttled := slices.Contains(awsThrottlingCodes, code) || strings.Contains(output, "Rate exceeded")
+	if code == "" && !throttled {
+		return nil, nil
+	}
+	if code == "" {
+		code = "Rate exceeded"
+	}
+
+	result := &ParseResult{Parser: ParserAWS, Outcome: OutcomeFailure, Detail: code}
+	if throttled {
+		result.Timing = p.backoff.next()
+	} else {
+		p.backoff.reset()
+	}
+	return result, nil
+}
+
+// gcloudParser reads gcloud errors. An error is a failure; a quota error waits
+// for its RetryInfo, or backs off exponentially without one.
+type gcloudParser struct {
+	backoff throttleBackoff
+}
+
+// Name implements ResponseParser
+func (p *gcloudParser) Name() string {
+	return ParserGCloud
+}
+
+// Parse implements ResponseParser
+func (p *gcloudParser) Parse(output string) (*ParseResult, error) {
+	if !gcloudErrorRegex.MatchString(output) {
+		return nil, nil
+	}
+
+	result := &ParseResult{Parser: ParserGCloud, Outcome: OutcomeFailure, Detail: "gcloud error"}
+	if code, ok := grpcStatusCode(output); ok {
+		result.Detail = code
+	}
+
+	switch delay, ok := grpcRetryDelay(output); {
+	case ok:
+		p.backoff.reset()
+		result.Timing = &TimingInfo{Delay: delay, Source: TimingSourceGRPCRetryInfo, Confidence: 1.0}
+	case gcloudThrottleRegex.MatchString(output):
+		result.Timing = p.backoff.next()
+	default:
+		p.backoff.reset()
+	}
+	return result, nil
+}
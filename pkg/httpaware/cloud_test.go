@@ -0,0 +1,169 @@
+package httpaware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAWSParser(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		expectNil      bool
+		expectedDetail string
+		expectTiming   bool
+	}{
+		{
+			name:           "cli_throttling_exception",
+			output:         "An error occurred (ThrottlingException) when calling the DescribeInstances operation (reached max retries: 4): Rate exceeded",
+			expectedDetail: "ThrottlingException",
+			expectTiming:   true,
+		},
+		{
+			name:           "s3_slow_down",
+			output:         "An error occurred (SlowDown) when calling the PutObject operation: Please reduce your request rate.",
+			expectedDetail: "SlowDown",
+			expectTiming:   true,
+		},
+		{
+			name:           "go_sdk_error",
+			output:         "operation error DynamoDB: Query, api error ProvisionedThroughputExceededException: The level of configured provisioned throughput for the table was exceeded",
+			expectedDetail: "ProvisionedThroughputExceededException",
+			expectTiming:   true,
+		},
+		{
+			name:           "bare_rate_exceeded",
+			output:         "Rate exceeded",
+			expectedDetail: "Rate exceeded",
+			expectTiming:   true,
+		},
+		{
+			name:           "access_denied",
+			output:         "An error occurred (AccessDenied) when calling the ListBuckets operation: Access Denied",
+			expectedDetail: "AccessDenied",
+		},
+		{
+			name:      "successful_output",
+			output:    `{"Buckets": [{"Name": "logs"}]}`,
+			expectNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &awsParser{}
+			result, err := parser.Parse(tt.output)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if tt.expectNil {
+				if result != nil {
+					t.Errorf("Expected no result, got: %+v", result)
+				}
+				return
+			}
+			if result == nil {
+				t.Fatalf("Expected result, got nil")
+			}
+			if result.Outcome != OutcomeFailure {
+				t.Errorf("Expected failure outcome, got %v", result.Outcome)
+			}
+			if result.Detail != tt.expectedDetail {
+				t.Errorf("Expected detail %q, got %q", tt.expectedDetail, result.Detail)
+			}
+			if tt.expectTiming {
+				if result.Timing == nil || result.Timing.Source != TimingSourceThrottleError {
+					t.Errorf("Expected throttle-error timing, got: %+v", result.Timing)
+				}
+			} else if result.Timing != nil {
+				t.Errorf("Expected no timing, got: %+v", result.Timing)
+			}
+		})
+	}
+}
+
+func TestGCloudParser(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		expectNil      bool
+		expectedDetail string
+		expectedDelay  time.Duration
+		expectedSource TimingSource
+	}{
+		{
+			name: "quota_with_retry_info",
+			output: "ERROR: (gcloud.compute.instances.list) RESOURCE_EXHAUSTED: Quota exceeded for quota metric 'Queries'\n" +
+				"- '@type': type.googleapis.com/google.rpc.RetryInfo\n" +
+				"  \"retryDelay\": \"20s\"\n",
+			expectedDetail: "gcloud error",
+			expectedDelay:  20 * time.Second,
+			expectedSource: TimingSourceGRPCRetryInfo,
+		},
+		{
+			name:           "rate_limit_exceeded",
+			output:         "ERROR: (gcloud.storage.ls) HTTPError 429: rateLimitExceeded",
+			expectedDetail: "gcloud error",
+			expectedDelay:  throttleBaseDelay,
+			expectedSource: TimingSourceThrottleError,
+		},
+		{
+			name:           "status_in_json_error",
+			output:         "ERROR: (gcloud.run.services.list) {\n  \"status\": \"UNAVAILABLE\"\n}",
+			expectedDetail: "UNAVAILABLE",
+		},
+		{
+			name:           "permission_error",
+			output:         "ERROR: (gcloud.projects.describe) User does not have permission to access project",
+			expectedDetail: "gcloud error",
+		},
+		{
+			name:      "successful_output",
+			output:    "NAME  ZONE  STATUS\nweb-1 us-central1-a RUNNING",
+			expectNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &gcloudParser{}
+			result, err := parser.Parse(tt.output)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if tt.expectNil {
+				if result != nil {
+					t.Errorf("Expected no result, got: %+v", result)
+				}
+				return
+			}
+			if result == nil {
+				t.Fatalf("Expected result, got nil")
+			}
+			if result.Outcome != OutcomeFailure {
+				t.Errorf("Expected failure outcome, got %v", result.Outcome)
+			}
+			if result.Detail != tt.expectedDetail {
+				t.Errorf("Expected detail %q, got %q", tt.expectedDetail, result.Detail)
+			}
+
+			if tt.expectedDelay == 0 {
+				if result.Timing != nil {
+					t.Errorf("Expected no timing, got: %+v", result.Timing)
+				}
+				return
+			}
+			if result.Timing == nil {
+				t.Fatalf("Expected timing, got nil")
+			}
+			if result.Timing.Delay != tt.expectedDelay {
+				t.Errorf("Expected delay %v, got %v", tt.expectedDelay, result.Timing.Delay)
+			}
+			if result.Timing.Source != tt.expectedSource {
+				t.Errorf("Expected source %v, got %v", tt.expectedSource, result.Timing.Source)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package httpaware
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// grpcCodes maps gRPC status codes, without underscores and in upper case, to
+// their canonical names, so ResourceExhausted and RESOURCE_EXHAUSTED match
+var grpcCodes = func() map[string]string {
+	codes := make(map[string]string)
+	for _, code := range []string{
+		"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED",
+		"NOT_FOUND", "ALREADY_EXISTS", "PERMISSION_DENIED", "RESOURCE_EXHAUSTED",
+		"FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED",
+		"INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+	} {
+		codes[strings.ReplaceAll(code, "_", "")] = code
+	}
+	codes["CANCELED"] = "CANCELLED"
+	return codes
+}()
+
+var (
+	// grpcurl prints "Code: ResourceExhausted"; Go clients "code = ResourceExhausted";
+	// JSON transcoding and gcloud "status": "RESOURCE_EXHAUSTED"
+	grpcCodeRegexes = []*regexp.Regexp{
+		regexp.MustCompile(`(?m)^\s*Code:\s*([A-Za-z_]+)\s*$`),
+		regexp.MustCompile(`code = ([A-Za-z_]+)`),
+		regexp.MustCompile(`"status"\s*:\s*"([A-Z_]+)"`),
+	}
+
+	// google.rpc.RetryInfo as JSON ("retryDelay": "1.5s") or text format
+	// (retry_delay { seconds: 1 nanos: 500000000 })
+	retryDelayJSONRegex = regexp.MustCompile(`"retryDelay"\s*:\s*"(\d+(?:\.\d+)?)s"`)
+	retryDelayTextRegex = regexp.MustCompile(`retry_delay\s*\{\s*seconds:\s*(\d+)(?:\s+nanos:\s*(\d+))?`)
+)
+
+// grpcParser reads gRPC status codes and RetryInfo from grpcurl and gRPC client
+// output. Any code other than OK is a failure.
+type grpcParser struct {
+	backoff throttleBackoff
+}
+
+// Name implements ResponseParser
+func (p *grpcParser) Name() string {
+	return ParserGRPC
+}
+
+// Parse implements ResponseParser
+func (p *grpcParser) Parse(output string) (*ParseResult, error) {
+	code, ok := grpcStatusCode(output)
+	if !ok {
+		return nil, nil
+	}
+
+	result := &ParseResult{Parser: ParserGRPC, Outcome: OutcomeFailure, Detail: code}
+	if code == "OK" {
+		result.Outcome = OutcomeSuccess
+	}
+
+	if delay, ok := grpcRetryDelay(output); ok {
+		p.backoff.reset()
+		result.Timing = &TimingInfo{Delay: delay, Source: TimingSourceGRPCRetryInfo, Confidence: 1.0}
+	} else if code == "RESOURCE_EXHAUSTED" {
+		result.Timing = p.backoff.next()
+	} else {
+		p.backoff.reset()
+	}
+	return result, nil
+}
+
+// grpcStatusCode returns the canonical gRPC status code in the output
+func grpcStatusCode(output string) (string, bool) {
+	for _, regex := range grpcCodeRegexes {
+		for _, match := range regex.FindAllStringSubmatch(output, -1) {
+			key := strings.ToUpper(strings.ReplaceAll(match[1], "_", ""))
+			if code, ok := grpcCodes[key]; ok {
+				return code, true
+			}
+		}
+	}
+	return "", false
+}
+
+// grpcRetryDelay returns the delay of a google.rpc.RetryInfo detail
+func grpcRetryDelay(output string) (time.Duration, bool) {
+	if match := retryDelayJSONRegex.FindStringSubmatch(output); match != nil {
+		seconds, err := strconv.ParseFloat(match[1], 64)
+		if err == nil && seconds > 0 {
+			return time.Duration(seconds * float64(time.Second)), true
+		}
+	}
+
+	if match := retryDelayTextRegex.FindStringSubmatch(output); match != nil {
+		seconds, _ := strconv.ParseInt(match[1], 10, 64)
+		nanos, _ := strconv.ParseInt(match[2], 10, 64)
+		if delay := time.Duration(seconds)*time.Second + time.Duration(nanos); delay > 0 {
+			return delay, true
+		}
+	}
+
+	return 0, false
+}
+
+// throttleBaseDelay is the first delay after a throttling error that names no
+// retry time
+const throttleBaseDelay = 1 * time.Second
+
+// throttleBackoff doubles the delay after each consecutive throttling error
+// without retry timing, as the AWS and Google SDKs do
+type throttleBackoff struct {
+	mu          sync.Mutex
+	consecutive int
+}
+
+// next returns the timing for one more throttling error
+func (b *throttleBackoff) next() *TimingInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delay := throttleBaseDelay << min(b.consecutive, 20)
+	b.consecutive++
+	return &TimingInfo{Delay: delay, Source: TimingSourceThrottleError, Confidence: 0.5}
+}
+
+// reset starts the backoff over after a response that was not throttled
+func (b *throttleBackoff) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutive = 0
+}
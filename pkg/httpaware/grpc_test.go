@@ -0,0 +1,141 @@
+package httpaware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGRPCParser(t *testing.T) {
+	tests := []struct {
+		name            string
+		output          string
+		expectNil       bool
+		expectedOutcome Outcome
+		expectedDetail  string
+		expectedDelay   time.Duration
+		expectedSource  TimingSource
+	}{
+		{
+			name: "grpcurl_resource_exhausted_with_retry_info",
+			output: "ERROR:\n" +
+				"  Code: ResourceExhausted\n" +
+				"  Message: Quota exceeded for quota metric 'Read requests'\n" +
+				"  Details:\n" +
+				"  1)\t{\n" +
+				"    \t  \"@type\": \"type.googleapis.com/google.rpc.RetryInfo\",\n" +
+				"    \t  \"retryDelay\": \"30s\"\n" +
+				"    \t}\n",
+			expectedOutcome: OutcomeFailure,
+			expectedDetail:  "RESOURCE_EXHAUSTED",
+			expectedDelay:   30 * time.Second,
+			expectedSource:  TimingSourceGRPCRetryInfo,
+		},
+		{
+			name:            "go_client_error_with_text_retry_info",
+			output:          "rpc error: code = Unavailable desc = backend overloaded retry_delay { seconds: 2 nanos: 500000000 }",
+			expectedOutcome: OutcomeFailure,
+			expectedDetail:  "UNAVAILABLE",
+			expectedDelay:   2500 * time.Millisecond,
+			expectedSource:  TimingSourceGRPCRetryInfo,
+		},
+		{
+			name:            "fractional_retry_delay",
+			output:          `{"error": {"code": 429, "status": "RESOURCE_EXHAUSTED", "details": [{"retryDelay": "1.5s"}]}}`,
+			expectedOutcome: OutcomeFailure,
+			expectedDetail:  "RESOURCE_EXHAUSTED",
+			expectedDelay:   1500 * time.Millisecond,
+			expectedSource:  TimingSourceGRPCRetryInfo,
+		},
+		{
+			name:            "resource_exhausted_without_retry_info",
+			output:          "ERROR:\n  Code: ResourceExhausted\n  Message: too many requests\n",
+			expectedOutcome: OutcomeFailure,
+			expectedDetail:  "RESOURCE_EXHAUSTED",
+			expectedDelay:   throttleBaseDelay,
+			expectedSource:  TimingSourceThrottleError,
+		},
+		{
+			name:            "other_error_has_no_timing",
+			output:          "ERROR:\n  Code: NotFound\n  Message: no such user\n",
+			expectedOutcome: OutcomeFailure,
+			expectedDetail:  "NOT_FOUND",
+		},
+		{
+			name:            "ok_status",
+			output:          "Code: OK\n",
+			expectedOutcome: OutcomeSuccess,
+			expectedDetail:  "OK",
+		},
+		{
+			name:      "plain_response",
+			output:    `{"name": "users/1", "email": "a@example.com"}`,
+			expectNil: true,
+		},
+		{
+			name:      "unknown_code_word",
+			output:    "Code: Banana\n",
+			expectNil: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parser := &grpcParser{}
+			result, err := parser.Parse(tt.output)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if tt.expectNil {
+				if result != nil {
+					t.Errorf("Expected no result, got: %+v", result)
+				}
+				return
+			}
+			if result == nil {
+				t.Fatalf("Expected result, got nil")
+			}
+			if result.Outcome != tt.expectedOutcome {
+				t.Errorf("Expected outcome %v, got %v", tt.expectedOutcome, result.Outcome)
+			}
+			if result.Detail != tt.expectedDetail {
+				t.Errorf("Expected detail %q, got %q", tt.expectedDetail, result.Detail)
+			}
+
+			if tt.expectedDelay == 0 {
+				if result.Timing != nil {
+					t.Errorf("Expected no timing, got: %+v", result.Timing)
+				}
+				return
+			}
+			if result.Timing == nil {
+				t.Fatalf("Expected timing, got nil")
+			}
+			if result.Timing.Delay != tt.expectedDelay {
+				t.Errorf("Expected delay %v, got %v", tt.expectedDelay, result.Timing.Delay)
+			}
+			if result.Timing.Source != tt.expectedSource {
+				t.Errorf("Expected source %v, got %v", tt.expectedSource, result.Timing.Source)
+			}
+		})
+	}
+}
+
+func TestThrottleBackoff(t *testing.T) {
+	parser := &grpcParser{}
+	throttled := "ERROR:\n  Code: ResourceExhausted\n"
+
+	// Consecutive throttling without RetryInfo doubles the delay
+	for _, expected := range []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		result, _ := parser.Parse(throttled)
+		if result.Timing.Delay != expected {
+			t.Errorf("Expected delay %v, got %v", expected, result.Timing.Delay)
+		}
+	}
+
+	// Any other response starts it over
+	_, _ = parser.Parse("Code: OK\n")
+	if result, _ := parser.Parse(throttled); result.Timing.Delay != throttleBaseDelay {
+		t.Errorf("Expected delay to reset to %v, got %v", throttleBaseDelay, result.Timing.Delay)
+	}
+}
@@ -2,121 +2,191 @@ package httpaware
 
 import (
 	"encoding/json"
+	"fmt"
 	"math"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/swi/repeater/pkg/scheduler"
 )
 
 // httpResponseParser implements HTTPResponseParser interface
 type httpResponseParser struct {
-	config HTTPAwareConfig
+	config    HTTPAwareConfig
+	clock     scheduler.Clock
+	selectors []FieldSelector // parsed JSONFields
+}
+
+// defaultParserConfig is the configuration of NewHTTPResponseParser
+func defaultParserConfig() HTTPAwareConfig {
+	return HTTPAwareConfig{
+		ParseJSON:         true,
+		ParseHeaders:      true,
+		TrustClientErrors: false,
+		JSONFields:        []string{"retry_after", "retryAfter"},
+		HeaderNames:       []string{"Retry-After"},
+	}
 }
 
 // NewHTTPResponseParser creates a new HTTP response parser with default configuration
 func NewHTTPResponseParser() HTTPResponseParser {
-	return &httpResponseParser{
-		config: HTTPAwareConfig{
-			ParseJSON:         true,
-			ParseHeaders:      true,
-			TrustClientErrors: false,
-			JSONFields:        []string{"retry_after", "retryAfter"},
-			HeaderNames:       []string{"Retry-After"},
-		},
-	}
+	return NewHTTPResponseParserWithConfig(defaultParserConfig())
 }
 
 // NewHTTPResponseParserWithConfig creates a new HTTP response parser with custom configuration
 func NewHTTPResponseParserWithConfig(config HTTPAwareConfig) HTTPResponseParser {
+	return NewHTTPResponseParserWithClock(config, scheduler.NewRealClock())
+}
+
+// NewHTTPResponseParserWithClock creates an HTTP response parser that measures
+// absolute reset times against the given clock. JSONFields that are not valid
+// field selectors are ignored; see ParseFieldSelector.
+func NewHTTPResponseParserWithClock(config HTTPAwareConfig, clock scheduler.Clock) HTTPResponseParser {
+	return newHTTPResponseParser(config, clock)
+}
+
+func newHTTPResponseParser(config HTTPAwareConfig, clock scheduler.Clock) *httpResponseParser {
+	var selectors []FieldSelector
+	for _, field := range config.JSONFields {
+		if selector, err := ParseFieldSelector(field); err == nil {
+			selectors = append(selectors, selector)
+		}
+	}
+
 	return &httpResponseParser{
-		config: config,
+		config:    config,
+		clock:     clock,
+		selectors: selectors,
 	}
 }
 
 // ParseResponse extracts timing information from an HTTP response
 func (p *httpResponseParser) ParseResponse(response string) (*TimingInfo, error) {
-	if !p.SupportsResponse(response) {
+	parsed, ok := ParseHTTPResponse(response)
+	if !ok {
 		return nil, nil
 	}
+	return p.timing(parsed), nil
+}
 
+// timing extracts timing information from a parsed response
+func (p *httpResponseParser) timing(parsed *Response) *TimingInfo {
 	// Try to extract timing information in priority order
 	if p.config.ParseHeaders {
-		if timingInfo := p.parseRetryAfterHeader(response); timingInfo != nil {
-			return timingInfo, nil
+		if timingInfo := p.parseRetryAfterHeader(parsed); timingInfo != nil {
+			return timingInfo
 		}
 	}
 
 	if p.config.ParseJSON {
-		if timingInfo := p.parseJSONTiming(response); timingInfo != nil {
-			return timingInfo, nil
+		if timingInfo := p.parseJSONTiming(parsed); timingInfo != nil {
+			return timingInfo
 		}
 	}
 
-	return nil, nil
+	// Quota headers pace every response, successful ones included
+	if p.config.ParseHeaders {
+		if timingInfo := p.parseQuotaHeaders(parsed); timingInfo != nil {
+			return timingInfo
+		}
+	}
+
+	return nil
 }
 
-// SupportsResponse returns true if the response appears to be HTTP
+// SupportsResponse returns true if the output contains an HTTP response
 func (p *httpResponseParser) SupportsResponse(response string) bool {
-	return strings.HasPrefix(response, "HTTP/")
+	_, ok := ParseHTTPResponse(response)
+	return ok
 }
 
-// parseRetryAfterHeader extracts timing from Retry-After header
-func (p *httpResponseParser) parseRetryAfterHeader(response string) *TimingInfo {
-	// Extract status code first
-	statusCode := p.extractStatusCode(response)
+// Name implements ResponseParser
+func (p *httpResponseParser) Name() string {
+	return ParserHTTP
+}
+
+// Parse implements ResponseParser. The final response's status classifies the
+// execution: 4xx and 5xx are failures.
+func (p *httpResponseParser) Parse(output string) (*ParseResult, error) {
+	parsed, ok := ParseHTTPResponse(output)
+	if !ok {
+		return nil, nil
+	}
+	return p.ParseHTTP(parsed)
+}
+
+// ParseHTTP implements HTTPResponseAnalyzer
+func (p *httpResponseParser) ParseHTTP(parsed *Response) (*ParseResult, error) {
+	outcome := OutcomeSuccess
+	if parsed.StatusCode >= 400 {
+		outcome = OutcomeFailure
+	}
+
+	return &ParseResult{
+		Parser:  ParserHTTP,
+		Timing:  p.timing(parsed),
+		Outcome: outcome,
+		Detail:  fmt.Sprintf("HTTP %d", parsed.StatusCode),
+	}, nil
+}
 
-	// Only process server errors (5xx) and rate limiting (429, 403 for GitHub)
-	// Skip success (2xx) and client errors (4xx) unless configured
+// trustsStatus reports whether timing in a response with this status should be
+// followed: server errors and rate limiting (429, and 403 for GitHub), plus
+// other client errors when configured. Successful responses never carry retry
+// timing.
+func (p *httpResponseParser) trustsStatus(statusCode int) bool {
 	if statusCode >= 200 && statusCode < 300 {
-		return nil // Success responses
+		return false
 	}
 	if statusCode >= 400 && statusCode < 500 && statusCode != 429 && statusCode != 403 && !p.config.TrustClientErrors {
-		return nil // Client errors (except 429 and 403 for rate limiting)
+		return false
 	}
+	return true
+}
 
-	// Find Retry-After header
-	headerRegex := regexp.MustCompile(`(?i)Retry-After:\s*(\d+)`)
-	matches := headerRegex.FindStringSubmatch(response)
-	if len(matches) < 2 {
+// parseRetryAfterHeader extracts timing from the Retry-After header, or the
+// configured header names, in delta-seconds or HTTP-date form
+func (p *httpResponseParser) parseRetryAfterHeader(response *Response) *TimingInfo {
+	if !p.trustsStatus(response.StatusCode) {
 		return nil
 	}
 
-	seconds, err := strconv.Atoi(matches[1])
-	if err != nil || seconds < 0 {
-		return nil
+	names := p.config.HeaderNames
+	if len(names) == 0 {
+		names = []string{"Retry-After"}
 	}
 
-	delay := time.Duration(seconds) * time.Second
+	for _, name := range names {
+		delay, ok := response.RetryAfter(name, p.clock.Now())
+		if !ok {
+			continue
+		}
 
-	// Apply min delay constraint
-	if delay == 0 {
-		delay = 1 * time.Second // Minimum delay
-	}
+		// Apply min delay constraint
+		if delay == 0 {
+			delay = 1 * time.Second // Minimum delay
+		}
 
-	return &TimingInfo{
-		Delay:      delay,
-		Source:     TimingSourceRetryAfterHeader,
-		Confidence: 1.0,
+		return &TimingInfo{
+			Delay:      delay,
+			Source:     TimingSourceRetryAfterHeader,
+			Confidence: 1.0,
+		}
 	}
+
+	return nil
 }
 
 // parseJSONTiming extracts timing from JSON response body
-func (p *httpResponseParser) parseJSONTiming(response string) *TimingInfo {
-	// Extract status code first
-	statusCode := p.extractStatusCode(response)
-
-	// Only process server errors (5xx) and rate limiting (429, 403 for GitHub)
-	if statusCode >= 200 && statusCode < 300 {
-		return nil // Success responses
-	}
-	if statusCode >= 400 && statusCode < 500 && statusCode != 429 && statusCode != 403 && !p.config.TrustClientErrors {
-		return nil // Client errors (except 429 and 403 for rate limiting)
+func (p *httpResponseParser) parseJSONTiming(response *Response) *TimingInfo {
+	if !p.trustsStatus(response.StatusCode) {
+		return nil
 	}
 
-	// Extract JSON body
-	jsonBody := p.extractJSONBody(response)
-	if jsonBody == "" {
+	// Basic JSON validation
+	jsonBody := strings.TrimSpace(response.Body)
+	if !strings.HasPrefix(jsonBody, "{") || !strings.HasSuffix(jsonBody, "}") {
 		return nil
 	}
 
@@ -126,8 +196,8 @@ func (p *httpResponseParser) parseJSONTiming(response string) *TimingInfo {
 	}
 
 	// Check custom fields first (highest priority)
-	for _, field := range p.config.JSONFields {
-		if delay := p.extractDelayFromJSON(data, field); delay > 0 {
+	for _, selector := range p.selectors {
+		if delay, ok := selector.Delay(data, p.clock.Now()); ok {
 			return &TimingInfo{
 				Delay:      delay,
 				Source:     TimingSourceJSONRetryAfter,
@@ -189,39 +259,96 @@ func (p *httpResponseParser) parseJSONTiming(response string) *TimingInfo {
 	return nil
 }
 
-// extractStatusCode extracts HTTP status code from response
-func (p *httpResponseParser) extractStatusCode(response string) int {
-	statusRegex := regexp.MustCompile(`HTTP/\d\.\d\s+(\d+)`)
-	matches := statusRegex.FindStringSubmatch(response)
-	if len(matches) < 2 {
-		return 0
+// epochThreshold separates absolute X-RateLimit-Reset values (Unix seconds)
+// from relative ones (seconds until reset)
+const epochThreshold = 1e9
+
+// parseQuotaHeaders paces requests from rate limit quota headers, whatever the
+// status: the remaining quota is spread evenly until the window resets, and an
+// exhausted quota waits for the reset. It understands the IETF RateLimit
+// header (limit=100, remaining=12, reset=30 or r=12;t=30), the separate
+// RateLimit-Remaining and RateLimit-Reset headers, and the X-RateLimit-*
+// headers used by GitHub, Twitter and Discord.
+func (p *httpResponseParser) parseQuotaHeaders(response *Response) *TimingInfo {
+	headers := response.Header
+
+	var remaining, reset float64
+	var haveRemaining, haveReset bool
+
+	if value := headers.Get("RateLimit"); value != "" {
+		params := parseRateLimitParams(value)
+		remaining, haveRemaining = firstParam(params, "remaining", "r")
+		reset, haveReset = firstParam(params, "reset", "t")
 	}
 
-	statusCode, err := strconv.Atoi(matches[1])
-	if err != nil {
-		return 0
+	for _, name := range []string{"RateLimit-Remaining", "X-RateLimit-Remaining"} {
+		if haveRemaining {
+			break
+		}
+		remaining, haveRemaining = parseSeconds(headers.Get(name))
 	}
 
-	return statusCode
-}
+	if !haveReset {
+		reset, haveReset = parseSeconds(headers.Get("RateLimit-Reset"))
+	}
+	if !haveReset {
+		reset, haveReset = parseSeconds(headers.Get("X-RateLimit-Reset-After"))
+	}
+	if !haveReset {
+		if reset, haveReset = parseSeconds(headers.Get("X-RateLimit-Reset")); haveReset && reset >= epochThreshold {
+			now := p.clock.Now()
+			reset = float64(time.Unix(0, int64(reset*float64(time.Second))).Sub(now)) / float64(time.Second)
+		}
+	}
 
-// extractJSONBody extracts JSON body from HTTP response
-func (p *httpResponseParser) extractJSONBody(response string) string {
-	// Find the end of headers (double CRLF)
-	headerEndIndex := strings.Index(response, "\r\n\r\n")
-	if headerEndIndex == -1 {
-		return ""
+	if !haveRemaining || !haveReset || remaining < 0 || reset <= 0 {
+		return nil
 	}
 
-	body := response[headerEndIndex+4:]
-	body = strings.TrimSpace(body)
+	untilReset := time.Duration(reset * float64(time.Second))
+	delay := untilReset
+	if remaining >= 1 {
+		delay = untilReset / time.Duration(remaining)
+	}
 
-	// Basic JSON validation
-	if !strings.HasPrefix(body, "{") || !strings.HasSuffix(body, "}") {
-		return ""
+	return &TimingInfo{
+		Delay:      delay,
+		Source:     TimingSourceQuotaHeaders,
+		Confidence: 0.9,
+	}
+}
+
+// parseRateLimitParams parses the parameters of an IETF RateLimit header,
+// separated by commas or semicolons, into lowercase keys
+func parseRateLimitParams(value string) map[string]string {
+	params := make(map[string]string)
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok {
+			continue // a policy name such as "default"
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(val), `"`)
+	}
+	return params
+}
+
+// firstParam returns the first of the given parameters that holds a number
+func firstParam(params map[string]string, keys ...string) (float64, bool) {
+	for _, key := range keys {
+		if value, ok := parseSeconds(params[key]); ok {
+			return value, true
+		}
 	}
+	return 0, false
+}
 
-	return body
+// parseSeconds parses a header number, which may be fractional
+func parseSeconds(value string) (float64, bool) {
+	parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+		return 0, false
+	}
+	return parsed, true
 }
 
 // extractDelayFromJSON extracts delay value from JSON data
@@ -231,26 +358,9 @@ func (p *httpResponseParser) extractDelayFromJSON(data map[string]interface{}, f
 		return 0
 	}
 
-	var seconds float64
-	switch v := value.(type) {
-	case float64:
-		seconds = v
-	case int:
-		seconds = float64(v)
-	case string:
-		parsed, err := strconv.ParseFloat(v, 64)
-		if err != nil {
-			return 0
-		}
-		seconds = parsed
-	default:
+	seconds, ok := jsonNumber(value)
+	if !ok {
 		return 0
 	}
-
-	if seconds <= 0 {
-		return 0
-	}
-
-	// Round up fractional seconds (like Discord API)
-	return time.Duration(math.Ceil(seconds)) * time.Second
+	return ceilSeconds(seconds)
 }
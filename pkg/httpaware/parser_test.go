@@ -1,8 +1,12 @@
 package httpaware
 
 import (
+	"fmt"
+	"net/http"
 	"testing"
 	"time"
+
+	"github.com/swi/repeater/pkg/scheduler"
 )
 
 func TestHTTPResponseParser_RetryAfterHeader(t *testing.T) {
@@ -398,3 +402,189 @@ func TestHTTPResponseParser_NonHTTPResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestHTTPResponseParser_QuotaHeaders(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	parser := NewHTTPResponseParserWithClock(defaultParserConfig(), scheduler.NewVirtualClock(now))
+
+	tests := []struct {
+		name          string
+		httpResponse  string
+		expectedDelay time.Duration
+		expectNil     bool
+	}{
+		{
+			name:          "ietf_combined_header",
+			httpResponse:  "HTTP/1.1 200 OK\r\nRateLimit: limit=100, remaining=12, reset=30\r\n\r\n{}",
+			expectedDelay: 2500 * time.Millisecond,
+		},
+		{
+			name:          "ietf_structured_header",
+			httpResponse:  "HTTP/1.1 200 OK\r\nRateLimit-Policy: \"default\";q=100;w=60\r\nRateLimit: \"default\";r=10;t=60\r\n\r\n",
+			expectedDelay: 6 * time.Second,
+		},
+		{
+			name:          "ietf_separate_headers",
+			httpResponse:  "HTTP/1.1 200 OK\r\nRateLimit-Limit: 10\r\nRateLimit-Remaining: 4\r\nRateLimit-Reset: 20\r\n\r\n",
+			expectedDelay: 5 * time.Second,
+		},
+		{
+			name: "github_epoch_reset",
+			httpResponse: "HTTP/1.1 200 OK\r\n" +
+				"X-RateLimit-Limit: 5000\r\n" +
+				"X-RateLimit-Remaining: 60\r\n" +
+				fmt.Sprintf("X-RateLimit-Reset: %d\r\n", now.Add(10*time.Minute).Unix()) +
+				"\r\n",
+			expectedDelay: 10 * time.Second,
+		},
+		{
+			name:          "discord_reset_after",
+			httpResponse:  "HTTP/1.1 200 OK\nx-ratelimit-remaining: 3\nx-ratelimit-reset-after: 1.5\n\n",
+			expectedDelay: 500 * time.Millisecond,
+		},
+		{
+			name:          "exhausted_quota_waits_for_reset",
+			httpResponse:  "HTTP/1.1 429 Too Many Requests\r\nX-RateLimit-Remaining: 0\r\nX-RateLimit-Reset: 30\r\n\r\n",
+			expectedDelay: 30 * time.Second,
+		},
+		{
+			name:          "retry_after_takes_priority",
+			httpResponse:  "HTTP/1.1 429 Too Many Requests\r\nRetry-After: 90\r\nRateLimit: remaining=0, reset=30\r\n\r\n",
+			expectedDelay: 90 * time.Second,
+		},
+		{
+			name: "reset_in_the_past",
+			httpResponse: "HTTP/1.1 200 OK\r\n" +
+				"X-RateLimit-Remaining: 10\r\n" +
+				fmt.Sprintf("X-RateLimit-Reset: %d\r\n", now.Add(-time.Minute).Unix()) +
+				"\r\n",
+			expectNil: true,
+		},
+		{
+			name:         "remaining_without_reset",
+			httpResponse: "HTTP/1.1 200 OK\r\nX-RateLimit-Remaining: 10\r\n\r\n",
+			expectNil:    true,
+		},
+		{
+			name:         "headers_in_body_ignored",
+			httpResponse: "HTTP/1.1 200 OK\r\n\r\nRateLimit: remaining=1, reset=30\r\n",
+			expectNil:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timingInfo, err := parser.ParseResponse(tt.httpResponse)
+			if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+
+			if tt.expectNil {
+				if timingInfo != nil {
+					t.Errorf("Expected no timing info, got: %+v", timingInfo)
+				}
+				return
+			}
+			if timingInfo == nil {
+				t.Fatalf("Expected timing info, got nil")
+			}
+			if timingInfo.Delay != tt.expectedDelay {
+				t.Errorf("Expected delay %v, got %v", tt.expectedDelay, timingInfo.Delay)
+			}
+		})
+	}
+
+	// Quota headers are header parsing
+	config := defaultParserConfig()
+	config.ParseHeaders = false
+	noHeaders := NewHTTPResponseParserWithClock(config, scheduler.NewVirtualClock(now))
+	if timingInfo, _ := noHeaders.ParseResponse("HTTP/1.1 200 OK\r\nRateLimit: remaining=1, reset=30\r\n\r\n"); timingInfo != nil {
+		t.Errorf("Expected no timing info with header parsing disabled, got: %+v", timingInfo)
+	}
+}
+
+func TestHTTPResponseParser_ResponseFraming(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	parser := NewHTTPResponseParserWithClock(defaultParserConfig(), scheduler.NewVirtualClock(now))
+
+	tests := []struct {
+		name           string
+		httpResponse   string
+		expectedDelay  time.Duration
+		expectedSource TimingSource
+		expectNil      bool
+	}{
+		{
+			name: "http_date_retry_after",
+			httpResponse: "HTTP/1.1 503 Service Unavailable\r\n" +
+				"Retry-After: " + now.Add(90*time.Second).Format(http.TimeFormat) + "\r\n" +
+				"\r\n",
+			expectedDelay:  90 * time.Second,
+			expectedSource: TimingSourceRetryAfterHeader,
+		},
+		{
+			name: "redirect_to_rate_limit",
+			httpResponse: "HTTP/1.1 301 Moved Permanently\r\n" +
+				"Location: /v2\r\n" +
+				"\r\n" +
+				"HTTP/2 429\r\n" +
+				"retry-after: 45\r\n" +
+				"\r\n",
+			expectedDelay:  45 * time.Second,
+			expectedSource: TimingSourceRetryAfterHeader,
+		},
+		{
+			name: "redirect_to_success",
+			httpResponse: "HTTP/1.1 503 Service Unavailable\r\n" +
+				"Retry-After: 30\r\n" +
+				"\r\n" +
+				"HTTP/1.1 200 OK\r\n" +
+				"\r\n",
+			expectNil: true,
+		},
+		{
+			name: "json_body_with_status_trailer",
+			httpResponse: "HTTP/1.1 429 Too Many Requests\n" +
+				"Content-Type: application/json\n" +
+				"\n" +
+				`{"retry_after": 20}` + "\n" +
+				"429",
+			expectedDelay:  20 * time.Second,
+			expectedSource: TimingSourceJSONRetryAfter,
+		},
+		{
+			name: "header_dump_after_noise",
+			httpResponse: "* Connected to api.example.com\n" +
+				"HTTP/2 503\n" +
+				"retry-after: 15\n" +
+				"\n",
+			expectedDelay:  15 * time.Second,
+			expectedSource: TimingSourceRetryAfterHeader,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timingInfo, err := parser.ParseResponse(tt.httpResponse)
+			if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+
+			if tt.expectNil {
+				if timingInfo != nil {
+					t.Errorf("Expected no timing info, got: %+v", timingInfo)
+				}
+				return
+			}
+			if timingInfo == nil {
+				t.Fatalf("Expected timing info, got nil")
+			}
+			if timingInfo.Delay != tt.expectedDelay {
+				t.Errorf("Expected delay %v, got %v", tt.expectedDelay, timingInfo.Delay)
+			}
+			if timingInfo.Source != tt.expectedSource {
+				t.Errorf("Expected source %v, got %v", tt.expectedSource, timingInfo.Source)
+			}
+		})
+	}
+}
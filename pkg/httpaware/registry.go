@@ -0,0 +1,183 @@
+package httpaware
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+// Built-in response parser names
+const (
+	ParserHTTP   = "http"
+	ParserGRPC   = "grpc"
+	ParserAWS    = "aws"
+	ParserGCloud = "gcloud"
+)
+
+// ParserFactory creates a response parser for one run. Parsers may keep state
+// between responses, such as a throttling backoff.
+type ParserFactory func(config HTTPAwareConfig, clock scheduler.Clock) ResponseParser
+
+// ParserRegistry holds the response parsers selectable by name
+type ParserRegistry struct {
+	mu        sync.RWMutex
+	factories map[string]ParserFactory
+}
+
+// NewParserRegistry creates an empty parser registry
+func NewParserRegistry() *ParserRegistry {
+	return &ParserRegistry{
+		factories: make(map[string]ParserFactory),
+	}
+}
+
+// DefaultParsers holds the built-in parsers and any registered by plugins
+var DefaultParsers = newBuiltinParserRegistry()
+
+// newBuiltinParserRegistry creates a registry of the built-in parsers
+func newBuiltinParserRegistry() *ParserRegistry {
+	registry := NewParserRegistry()
+	_ = registry.Register(ParserHTTP, func(config HTTPAwareConfig, clock scheduler.Clock) ResponseParser {
+		return newHTTPResponseParser(config, clock)
+	})
+	_ = registry.Register(ParserGRPC, func(config HTTPAwareConfig, clock scheduler.Clock) ResponseParser {
+		return &grpcParser{}
+	})
+	_ = registry.Register(ParserAWS, func(config HTTPAwareConfig, clock scheduler.Clock) ResponseParser {
+		return &awsParser{}
+	})
+	_ = registry.Register(ParserGCloud, func(config HTTPAwareConfig, clock scheduler.Clock) ResponseParser {
+		return &gcloudParser{}
+	})
+	return registry
+}
+
+// RegisterParser registers a parser with DefaultParsers
+func RegisterParser(name string, factory ParserFactory) error {
+	return DefaultParsers.Register(name, factory)
+}
+
+// Register registers a parser factory under a name
+func (r *ParserRegistry) Register(name string, factory ParserFactory) error {
+	if name == "" || strings.ContainsAny(name, ", ") {
+		return fmt.Errorf("invalid parser name %q", name)
+	}
+	if factory == nil {
+		return fmt.Errorf("parser %q has no factory", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.factories[name]; exists {
+		return fmt.Errorf("parser %q already registered", name)
+	}
+	r.factories[name] = factory
+	return nil
+}
+
+// Has reports whether a parser is registered under name
+func (r *ParserRegistry) Has(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, exists := r.factories[name]
+	return exists
+}
+
+// Names returns the registered parser names in sorted order
+func (r *ParserRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.namesLocked()
+}
+
+func (r *ParserRegistry) namesLocked() []string {
+	names := make([]string, 0, len(r.factories))
+	for name := range r.factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// New creates a parser that tries the named parsers in order; the first to
+// recognize the output wins. No names means http.
+func (r *ParserRegistry) New(names []string, config HTTPAwareConfig, clock scheduler.Clock) (ResponseParser, error) {
+	if len(names) == 0 {
+		names = []string{ParserHTTP}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	parsers := make(parserChain, 0, len(names))
+	for _, name := range names {
+		factory, exists := r.factories[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown response parser %q (available: %s)", name, strings.Join(r.namesLocked(), ", "))
+		}
+		parsers = append(parsers, factory(config, clock))
+	}
+
+	if len(parsers) == 1 {
+		return parsers[0], nil
+	}
+	return parsers, nil
+}
+
+// parserChain tries each parser in turn
+type parserChain []ResponseParser
+
+// Name returns the chained parser names, comma-separated
+func (c parserChain) Name() string {
+	names := make([]string, len(c))
+	for i, parser := range c {
+		names[i] = parser.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Parse returns the result of the first parser that recognizes the output
+func (c parserChain) Parse(output string) (*ParseResult, error) {
+	for _, parser := range c {
+		result, err := parser.Parse(output)
+		if err != nil {
+			return nil, fmt.Errorf("%s parser: %w", parser.Name(), err)
+		}
+		if result != nil {
+			if result.Parser == "" {
+				result.Parser = parser.Name()
+			}
+			return result, nil
+		}
+	}
+	return nil, nil
+}
+
+// ParseHTTP implements HTTPResponseAnalyzer. Parsers that cannot read a framed
+// response are given it as curl -i would print it.
+func (c parserChain) ParseHTTP(response *Response) (*ParseResult, error) {
+	for _, parser := range c {
+		var result *ParseResult
+		var err error
+		if analyzer, ok := parser.(HTTPResponseAnalyzer); ok {
+			result, err = analyzer.ParseHTTP(response)
+		} else {
+			result, err = parser.Parse(response.String())
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s parser: %w", parser.Name(), err)
+		}
+		if result != nil {
+			if result.Parser == "" {
+				result.Parser = parser.Name()
+			}
+			return result, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,149 @@
+package httpaware
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+func TestDefaultParsers_BuiltinNames(t *testing.T) {
+	names := DefaultParsers.Names()
+	for _, name := range []string{ParserAWS, ParserGCloud, ParserGRPC, ParserHTTP} {
+		if !slices.Contains(names, name) {
+			t.Errorf("Expected built-in parser %q in %v", name, names)
+		}
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("Expected sorted names, got %v", names)
+	}
+}
+
+func TestParserRegistry_Register(t *testing.T) {
+	registry := NewParserRegistry()
+	factory := func(HTTPAwareConfig, scheduler.Clock) ResponseParser { return &grpcParser{} }
+
+	tests := []struct {
+		name        string
+		parserName  string
+		factory     ParserFactory
+		expectError string
+	}{
+		{name: "valid", parserName: "vendor", factory: factory},
+		{name: "duplicate", parserName: "vendor", factory: factory, expectError: "already registered"},
+		{name: "empty_name", parserName: "", factory: factory, expectError: "invalid parser name"},
+		{name: "name_with_comma", parserName: "a,b", factory: factory, expectError: "invalid parser name"},
+		{name: "nil_factory", parserName: "other", factory: nil, expectError: "no factory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Register(tt.parserName, tt.factory)
+			if tt.expectError == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+				t.Errorf("Expected error containing %q, got: %v", tt.expectError, err)
+			}
+		})
+	}
+}
+
+func TestParserRegistry_New(t *testing.T) {
+	clock := scheduler.NewVirtualClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	config := defaultParserConfig()
+
+	// No names means http
+	parser, err := DefaultParsers.New(nil, config, clock)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if parser.Name() != ParserHTTP {
+		t.Errorf("Expected http parser, got %q", parser.Name())
+	}
+
+	// Unknown names are rejected with the available ones
+	if _, err := DefaultParsers.New([]string{"grpc", "soap"}, config, clock); err == nil || !strings.Contains(err.Error(), "available: aws") {
+		t.Errorf("Expected unknown parser error listing available parsers, got: %v", err)
+	}
+
+	// A chain tries each parser in order
+	chain, err := DefaultParsers.New([]string{ParserGRPC, ParserAWS, ParserHTTP}, config, clock)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if chain.Name() != "grpc,aws,http" {
+		t.Errorf("Expected chain name grpc,aws,http, got %q", chain.Name())
+	}
+
+	tests := []struct {
+		name            string
+		output          string
+		expectedParser  string
+		expectedOutcome Outcome
+		expectedDelay   time.Duration
+	}{
+		{
+			name:            "grpc_output",
+			output:          "ERROR:\n  Code: ResourceExhausted\n  Details: {\"retryDelay\": \"12s\"}\n",
+			expectedParser:  ParserGRPC,
+			expectedOutcome: OutcomeFailure,
+			expectedDelay:   12 * time.Second,
+		},
+		{
+			name:            "aws_output",
+			output:          "An error occurred (ThrottlingException) when calling the GetItem operation: Rate exceeded",
+			expectedParser:  ParserAWS,
+			expectedOutcome: OutcomeFailure,
+			expectedDelay:   throttleBaseDelay,
+		},
+		{
+			name:            "http_output",
+			output:          "HTTP/2 429\r\nretry-after: 7\r\n\r\n",
+			expectedParser:  ParserHTTP,
+			expectedOutcome: OutcomeFailure,
+			expectedDelay:   7 * time.Second,
+		},
+		{
+			name:            "http_success",
+			output:          "HTTP/1.1 200 OK\r\n\r\n{}",
+			expectedParser:  ParserHTTP,
+			expectedOutcome: OutcomeSuccess,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := chain.Parse(tt.output)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if result == nil {
+				t.Fatalf("Expected result, got nil")
+			}
+			if result.Parser != tt.expectedParser {
+				t.Errorf("Expected parser %q, got %q", tt.expectedParser, result.Parser)
+			}
+			if result.Outcome != tt.expectedOutcome {
+				t.Errorf("Expected outcome %v, got %v", tt.expectedOutcome, result.Outcome)
+			}
+			var delay time.Duration
+			if result.Timing != nil {
+				delay = result.Timing.Delay
+			}
+			if delay != tt.expectedDelay {
+				t.Errorf("Expected delay %v, got %v", tt.expectedDelay, delay)
+			}
+		})
+	}
+
+	// Output no parser recognizes
+	if result, _ := chain.Parse("all good"); result != nil {
+		t.Errorf("Expected no result, got: %+v", result)
+	}
+}
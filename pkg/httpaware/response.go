@@ -0,0 +1,179 @@
+package httpaware
+
+import (
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Response is the final HTTP response found in a command's output
+type Response struct {
+	Proto      string // e.g. "HTTP/1.1" or "HTTP/2"
+	StatusCode int
+	Reason     string      // reason phrase, empty for HTTP/2
+	Header     http.Header // keys in canonical form
+	Body       string
+}
+
+// statusLineRegex matches an HTTP status line: HTTP/1.1 200 OK, HTTP/2 429
+var statusLineRegex = regexp.MustCompile(`^(HTTP/\d(?:\.\d)?)\s+(\d{3})(?:\s+(.*))?$`)
+
+// ParseHTTPResponse finds the final HTTP response in command output, as printed
+// by curl -i, curl -iL, curl -D - or similar. Output before the first status
+// line is skipped, and when one header block is directly followed by another
+// (redirects, 100 Continue) the last one wins. Lines may end in CRLF or LF. A
+// trailing status code appended by curl -w '%{http_code}' is dropped from the
+// body. It reports false if the output holds no status line.
+func ParseHTTPResponse(output string) (*Response, bool) {
+	lines := strings.Split(output, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+
+	start := -1
+	for i, line := range lines {
+		if statusLineRegex.MatchString(line) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, false
+	}
+
+	var response *Response
+	i := start
+	for {
+		response, i = parseResponseBlock(lines, i)
+
+		// Another response straight after the headers, as in a redirect chain
+		next := i
+		for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+			next++
+		}
+		if next < len(lines) && statusLineRegex.MatchString(lines[next]) {
+			i = next
+			continue
+		}
+		break
+	}
+
+	if i < len(lines) {
+		response.Body = strings.Join(lines[i:], "\n")
+	}
+	response.Body = stripStatusTrailer(response.Body, response.StatusCode)
+	return response, true
+}
+
+// parseResponseBlock parses the status line at lines[i] and the headers after
+// it, returning the response and the index just past the blank line that ends
+// the headers
+func parseResponseBlock(lines []string, i int) (*Response, int) {
+	matches := statusLineRegex.FindStringSubmatch(lines[i])
+	statusCode, _ := strconv.Atoi(matches[2])
+	response := &Response{
+		Proto:      matches[1],
+		StatusCode: statusCode,
+		Reason:     strings.TrimSpace(matches[3]),
+		Header:     make(http.Header),
+	}
+
+	var lastName string
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			return response, i + 1
+		}
+
+		// Obsolete line folding continues the previous header
+		if (line[0] == ' ' || line[0] == '\t') && lastName != "" {
+			values := response.Header[lastName]
+			values[len(values)-1] += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			// Not a header: the headers ended without a blank line
+			return response, i
+		}
+		lastName = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		response.Header.Add(lastName, strings.TrimSpace(value))
+	}
+
+	return response, i
+}
+
+// stripStatusTrailer removes a status code appended to the body by
+// curl -w '\n%{http_code}'. Only a code on its own final line is a trailer;
+// a body that merely ends in the code is left alone.
+func stripStatusTrailer(body string, statusCode int) string {
+	trimmed := strings.TrimRight(body, " \t\r\n")
+	rest, last := "", trimmed
+	if i := strings.LastIndexByte(trimmed, '\n'); i >= 0 {
+		rest, last = trimmed[:i], trimmed[i+1:]
+	}
+
+	if strings.TrimSpace(last) != strconv.Itoa(statusCode) {
+		return body
+	}
+	return strings.TrimRight(rest, " \t\r\n")
+}
+
+// String renders the response as curl -i prints it: status line, headers in
+// sorted order, a blank line and the body, with CRLF line endings
+func (r *Response) String() string {
+	var b strings.Builder
+	b.WriteString(r.Proto)
+	b.WriteString(" ")
+	b.WriteString(strconv.Itoa(r.StatusCode))
+	if r.Reason != "" {
+		b.WriteString(" ")
+		b.WriteString(r.Reason)
+	}
+	b.WriteString("\r\n")
+
+	names := make([]string, 0, len(r.Header))
+	for name := range r.Header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, value := range r.Header[name] {
+			b.WriteString(name)
+			b.WriteString(": ")
+			b.WriteString(value)
+			b.WriteString("\r\n")
+		}
+	}
+
+	b.WriteString("\r\n")
+	b.WriteString(r.Body)
+	return b.String()
+}
+
+// RetryAfter returns the delay requested by a Retry-After style header, which
+// may hold delta-seconds or an HTTP-date; dates are measured from now. It
+// reports false if the header is missing or malformed.
+func (r *Response) RetryAfter(name string, now time.Time) (time.Duration, bool) {
+	value := strings.TrimSpace(r.Header.Get(name))
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,267 @@
+package httpaware
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseHTTPResponse(t *testing.T) {
+	tests := []struct {
+		name           string
+		output         string
+		expectedFound  bool
+		expectedProto  string
+		expectedStatus int
+		expectedReason string
+		expectedHeader map[string]string
+		expectedBody   string
+	}{
+		{
+			name: "single_response",
+			output: "HTTP/1.1 503 Service Unavailable\r\n" +
+				"Retry-After: 30\r\n" +
+				"Content-Type: text/plain\r\n" +
+				"\r\n" +
+				"down for maintenance",
+			expectedFound:  true,
+			expectedProto:  "HTTP/1.1",
+			expectedStatus: 503,
+			expectedReason: "Service Unavailable",
+			expectedHeader: map[string]string{"Retry-After": "30", "Content-Type": "text/plain"},
+			expectedBody:   "down for maintenance",
+		},
+		{
+			name: "redirect_chain",
+			output: "HTTP/1.1 301 Moved Permanently\r\n" +
+				"Location: https://example.com/api\r\n" +
+				"Retry-After: 5\r\n" +
+				"\r\n" +
+				"HTTP/1.1 302 Found\r\n" +
+				"Location: https://example.com/api/v2\r\n" +
+				"\r\n" +
+				"HTTP/2 429\r\n" +
+				"retry-after: 60\r\n" +
+				"\r\n" +
+				`{"error": "slow down"}`,
+			expectedFound:  true,
+			expectedProto:  "HTTP/2",
+			expectedStatus: 429,
+			expectedHeader: map[string]string{"Retry-After": "60", "Location": ""},
+			expectedBody:   `{"error": "slow down"}`,
+		},
+		{
+			name: "continue_then_response",
+			output: "HTTP/1.1 100 Continue\r\n" +
+				"\r\n" +
+				"HTTP/1.1 502 Bad Gateway\r\n" +
+				"\r\n",
+			expectedFound:  true,
+			expectedProto:  "HTTP/1.1",
+			expectedStatus: 502,
+			expectedReason: "Bad Gateway",
+		},
+		{
+			name: "http2_without_reason",
+			output: "HTTP/2 429\n" +
+				"ratelimit-remaining: 0\n" +
+				"\n",
+			expectedFound:  true,
+			expectedProto:  "HTTP/2",
+			expectedStatus: 429,
+			expectedHeader: map[string]string{"RateLimit-Remaining": "0"},
+		},
+		{
+			name: "mixed_line_endings",
+			output: "HTTP/1.1 503 Service Unavailable\r\n" +
+				"Retry-After: 10\n" +
+				"X-Request-Id: abc\r\n" +
+				"\n" +
+				"line one\r\nline two",
+			expectedFound:  true,
+			expectedProto:  "HTTP/1.1",
+			expectedStatus: 503,
+			expectedReason: "Service Unavailable",
+			expectedHeader: map[string]string{"Retry-After": "10", "X-Request-Id": "abc"},
+			expectedBody:   "line one\nline two",
+		},
+		{
+			name: "header_dump_after_noise",
+			output: "  % Total    % Received % Xferd\n" +
+				"fetching https://example.com/api\n" +
+				"HTTP/1.1 429 Too Many Requests\r\n" +
+				"Retry-After: 120\r\n" +
+				"\r\n",
+			expectedFound:  true,
+			expectedProto:  "HTTP/1.1",
+			expectedStatus: 429,
+			expectedReason: "Too Many Requests",
+			expectedHeader: map[string]string{"Retry-After": "120"},
+		},
+		{
+			name: "status_code_trailer_on_own_line",
+			output: "HTTP/1.1 503 Service Unavailable\r\n" +
+				"\r\n" +
+				`{"retry_after": 30}` + "\n" +
+				"503",
+			expectedFound:  true,
+			expectedProto:  "HTTP/1.1",
+			expectedStatus: 503,
+			expectedReason: "Service Unavailable",
+			expectedBody:   `{"retry_after": 30}`,
+		},
+		{
+			name: "body_ending_in_status_code",
+			output: "HTTP/1.1 503 Service Unavailable\r\n" +
+				"\r\n" +
+				"upstream returned 503",
+			expectedFound:  true,
+			expectedProto:  "HTTP/1.1",
+			expectedStatus: 503,
+			expectedReason: "Service Unavailable",
+			expectedBody:   "upstream returned 503",
+		},
+		{
+			name: "body_that_is_only_the_status_code",
+			output: "HTTP/1.1 429 Too Many Requests\r\n" +
+				"\r\n" +
+				"429\n",
+			expectedFound:  true,
+			expectedProto:  "HTTP/1.1",
+			expectedStatus: 429,
+			expectedReason: "Too Many Requests",
+			expectedBody:   "",
+		},
+		{
+			name: "body_ending_in_longer_number",
+			output: "HTTP/1.1 200 OK\r\n" +
+				"\r\n" +
+				"count: 1200",
+			expectedFound:  true,
+			expectedProto:  "HTTP/1.1",
+			expectedStatus: 200,
+			expectedReason: "OK",
+			expectedBody:   "count: 1200",
+		},
+		{
+			name: "folded_header",
+			output: "HTTP/1.1 503 Service Unavailable\r\n" +
+				"Warning: 199 proxy\r\n" +
+				"\tretry later\r\n" +
+				"\r\n",
+			expectedFound:  true,
+			expectedProto:  "HTTP/1.1",
+			expectedStatus: 503,
+			expectedReason: "Service Unavailable",
+			expectedHeader: map[string]string{"Warning": "199 proxy retry later"},
+		},
+		{
+			name:   "plain_text_output",
+			output: "Service is healthy\nUptime: 24 hours",
+		},
+		{
+			name:   "empty_output",
+			output: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, found := ParseHTTPResponse(tt.output)
+			if found != tt.expectedFound {
+				t.Fatalf("Expected found %v, got %v", tt.expectedFound, found)
+			}
+			if !found {
+				return
+			}
+
+			if response.Proto != tt.expectedProto {
+				t.Errorf("Expected proto %q, got %q", tt.expectedProto, response.Proto)
+			}
+			if response.StatusCode != tt.expectedStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectedStatus, response.StatusCode)
+			}
+			if response.Reason != tt.expectedReason {
+				t.Errorf("Expected reason %q, got %q", tt.expectedReason, response.Reason)
+			}
+			for name, value := range tt.expectedHeader {
+				if got := response.Header.Get(name); got != value {
+					t.Errorf("Expected header %s %q, got %q", name, value, got)
+				}
+			}
+			if response.Body != tt.expectedBody {
+				t.Errorf("Expected body %q, got %q", tt.expectedBody, response.Body)
+			}
+		})
+	}
+}
+
+func TestResponse_RetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		value         string
+		expectedDelay time.Duration
+		expectedFound bool
+	}{
+		{"delta_seconds", "30", 30 * time.Second, true},
+		{"zero", "0", 0, true},
+		{"http_date", "Mon, 01 Jan 2024 12:02:00 GMT", 2 * time.Minute, true},
+		{"rfc850_date", "Monday, 01-Jan-24 12:00:45 GMT", 45 * time.Second, true},
+		{"asctime_date", "Mon Jan  1 12:00:10 2024", 10 * time.Second, true},
+		{"date_in_past", "Mon, 01 Jan 2024 11:00:00 GMT", 0, true},
+		{"negative", "-30", 0, false},
+		{"malformed", "soon", 0, false},
+		{"missing", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, _ := ParseHTTPResponse("HTTP/1.1 503 Service Unavailable\r\nRetry-After: " + tt.value + "\r\n\r\n")
+
+			delay, found := response.RetryAfter("Retry-After", now)
+			if found != tt.expectedFound {
+				t.Fatalf("Expected found %v, got %v", tt.expectedFound, found)
+			}
+			if delay != tt.expectedDelay {
+				t.Errorf("Expected delay %v, got %v", tt.expectedDelay, delay)
+			}
+		})
+	}
+}
+
+func TestResponse_StringRoundTrip(t *testing.T) {
+	response := &Response{
+		Proto:      "HTTP/1.1",
+		StatusCode: 429,
+		Reason:     "Too Many Requests",
+		Header: http.Header{
+			"Retry-After":  {"30"},
+			"Content-Type": {"application/json"},
+			"Set-Cookie":   {"a=1", "b=2"},
+		},
+		Body: `{"error": "slow down"}`,
+	}
+
+	rendered := response.String()
+	if !strings.HasPrefix(rendered, "HTTP/1.1 429 Too Many Requests\r\nContent-Type: application/json\r\n") {
+		t.Errorf("Expected status line and sorted headers, got %q", rendered)
+	}
+
+	parsed, ok := ParseHTTPResponse(rendered)
+	if !ok {
+		t.Fatalf("Expected rendered response to parse")
+	}
+	if parsed.Proto != response.Proto || parsed.StatusCode != response.StatusCode || parsed.Reason != response.Reason {
+		t.Errorf("Expected status line %s %d %s, got %s %d %s", response.Proto, response.StatusCode, response.Reason, parsed.Proto, parsed.StatusCode, parsed.Reason)
+	}
+	if !reflect.DeepEqual(parsed.Header, response.Header) {
+		t.Errorf("Expected headers %v, got %v", response.Header, parsed.Header)
+	}
+	if parsed.Body != response.Body {
+		t.Errorf("Expected body %q, got %q", response.Body, parsed.Body)
+	}
+}
@@ -1,88 +1,219 @@
 package httpaware
 
 import (
+	"sync"
 	"time"
 
 	"github.com/swi/repeater/pkg/scheduler"
 )
 
-// httpAwareScheduler implements HTTPAwareScheduler interface
+// defaultInterval paces the scheduler when it has no fallback scheduler
+const defaultInterval = 1 * time.Second
+
+// httpAwareScheduler implements HTTPAwareScheduler as a decorator: it passes
+// the fallback scheduler's ticks through, postponing the next one whenever the
+// last response asked for a delay.
+//
+// It implements scheduler.CompletionAware. After each tick it waits for the
+// execution to be reported complete, so the response of that execution is
+// known before the next tick is released.
 type httpAwareScheduler struct {
 	config            HTTPAwareConfig
-	parser            HTTPResponseParser
+	parser            ResponseParser
+	clock             scheduler.Clock
 	fallbackScheduler scheduler.Scheduler
-	lastResponse      string
-	lastTimingInfo    *TimingInfo
-	nextCh            chan time.Time
-	stopped           bool
+
+	mu             sync.Mutex
+	lastResponse   string
+	lastTimingInfo *TimingInfo
+	lastResult     *ParseResult
+	holdUntil      time.Time // no tick before this time
+
+	nextCh    chan time.Time
+	completed chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
 }
 
 // NewHTTPAwareScheduler creates a new HTTP-aware scheduler with default configuration
 func NewHTTPAwareScheduler(config HTTPAwareConfig) HTTPAwareScheduler {
-	return &httpAwareScheduler{
-		config: config,
-		parser: NewHTTPResponseParser(),
-		nextCh: make(chan time.Time, 1),
-	}
+	return NewHTTPAwareSchedulerWithClock(config, scheduler.NewRealClock())
+}
+
+// NewHTTPAwareSchedulerWithClock creates an HTTP-aware scheduler with the
+// default parser that times its delays on the given clock
+func NewHTTPAwareSchedulerWithClock(config HTTPAwareConfig, clock scheduler.Clock) HTTPAwareScheduler {
+	return newHTTPAwareScheduler(config, newHTTPResponseParser(defaultParserConfig(), clock), clock)
+}
+
+// NewHTTPAwareSchedulerWithParser creates an HTTP-aware scheduler that reads
+// timing with the given parser, such as one built by ParserRegistry.New
+func NewHTTPAwareSchedulerWithParser(config HTTPAwareConfig, parser ResponseParser, clock scheduler.Clock) HTTPAwareScheduler {
+	return newHTTPAwareScheduler(config, parser, clock)
 }
 
 // NewHTTPAwareSchedulerWithConfig creates a new HTTP-aware scheduler with custom configuration
 func NewHTTPAwareSchedulerWithConfig(config HTTPAwareConfig) HTTPAwareScheduler {
+	clock := scheduler.NewRealClock()
+	return newHTTPAwareScheduler(config, newHTTPResponseParser(config, clock), clock)
+}
+
+func newHTTPAwareScheduler(config HTTPAwareConfig, parser ResponseParser, clock scheduler.Clock) *httpAwareScheduler {
 	return &httpAwareScheduler{
-		config: config,
-		parser: NewHTTPResponseParserWithConfig(config),
-		nextCh: make(chan time.Time, 1),
+		config:    config,
+		parser:    parser,
+		clock:     clock,
+		nextCh:    make(chan time.Time, 1),
+		completed: make(chan struct{}, 1),
+		done:      make(chan struct{}),
 	}
 }
 
 // Next returns a channel that will send the next execution time
 func (s *httpAwareScheduler) Next() <-chan time.Time {
-	if s.stopped {
-		return s.nextCh
-	}
+	s.startOnce.Do(func() {
+		go s.run()
+	})
+	return s.nextCh
+}
 
-	// Calculate the delay based on HTTP timing or fallback
-	delay := s.NextDelay()
-
-	// Send the next execution time after the calculated delay
-	go func() {
-		<-time.After(delay)
-		if !s.stopped {
-			select {
-			case s.nextCh <- time.Now():
-			default:
-				// Channel is full, skip this tick
-			}
+// run forwards the fallback scheduler's ticks, holding each back until the
+// delay requested by the last response has passed
+func (s *httpAwareScheduler) run() {
+	for {
+		if !s.awaitFallback() {
+			return
 		}
-	}()
 
-	return s.nextCh
+		// Postpone by the timing the last response asked for
+		s.mu.Lock()
+		hold := s.holdUntil.Sub(s.clock.Now())
+		s.mu.Unlock()
+		if !s.wait(hold) {
+			return
+		}
+
+		select {
+		case s.nextCh <- s.clock.Now():
+		case <-s.done:
+			return
+		}
+
+		// The next tick depends on the response of this execution
+		select {
+		case <-s.completed:
+		case <-s.done:
+			return
+		}
+	}
 }
 
-// Stop stops the scheduler
-func (s *httpAwareScheduler) Stop() {
-	s.stopped = true
-	if s.fallbackScheduler != nil {
-		s.fallbackScheduler.Stop()
+// awaitFallback waits for the fallback scheduler's next tick, or for the
+// default interval without one, and reports false once stopped
+func (s *httpAwareScheduler) awaitFallback() bool {
+	if s.fallbackScheduler == nil {
+		return s.wait(defaultInterval)
 	}
 
-	// Close the channel if it exists and is not already closed
-	if s.nextCh != nil {
-		select {
-		case <-s.nextCh:
-			// Channel already closed or has a value
-		default:
-			close(s.nextCh)
+	select {
+	case _, ok := <-s.fallbackScheduler.Next():
+		return ok
+	case <-s.done:
+		return false
+	}
+}
+
+// wait blocks for d on the clock and reports false once stopped
+func (s *httpAwareScheduler) wait(d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := s.clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// ExecutionCompleted implements scheduler.CompletionAware, releasing the next
+// tick. Call it after SetLastResponse for the same execution.
+func (s *httpAwareScheduler) ExecutionCompleted(at time.Time) {
+	select {
+	case s.completed <- struct{}{}:
+	default:
+	}
+}
+
+// Stop stops the scheduler and its fallback scheduler
+func (s *httpAwareScheduler) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+		if s.fallbackScheduler != nil {
+			s.fallbackScheduler.Stop()
 		}
+	})
+}
+
+// Unwrap returns the fallback scheduler, if any
+func (s *httpAwareScheduler) Unwrap() []scheduler.Scheduler {
+	if s.fallbackScheduler == nil {
+		return nil
 	}
+	return []scheduler.Scheduler{s.fallbackScheduler}
 }
 
-// SetLastResponse sets the last HTTP response for timing extraction
+// SetLastResponse sets the last HTTP response for timing extraction. A
+// response with timing holds the next tick back by the constrained delay; one
+// without lets the fallback schedule resume.
 func (s *httpAwareScheduler) SetLastResponse(response string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.lastResponse = response
 
 	// Parse the response to extract timing information
-	if timingInfo, err := s.parser.ParseResponse(response); err == nil && timingInfo != nil {
+	result, err := s.parser.Parse(response)
+	if err != nil {
+		result = nil
+	}
+	s.record(result)
+}
+
+// SetLastHTTPResponse is SetLastResponse for a response that is already
+// framed, as returned by the native HTTP probe. Parsers implementing
+// HTTPResponseAnalyzer read it directly.
+func (s *httpAwareScheduler) SetLastHTTPResponse(response *Response) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastResponse = response.String()
+
+	var result *ParseResult
+	var err error
+	if analyzer, ok := s.parser.(HTTPResponseAnalyzer); ok {
+		result, err = analyzer.ParseHTTP(response)
+	} else {
+		result, err = s.parser.Parse(s.lastResponse)
+	}
+	if err != nil {
+		result = nil
+	}
+	s.record(result)
+}
+
+// record stores a parse result and holds the next tick back by its
+// constrained delay. The caller holds s.mu.
+func (s *httpAwareScheduler) record(result *ParseResult) {
+	s.lastResult = result
+
+	if result != nil && result.Timing != nil {
+		timingInfo := result.Timing
+
 		// Apply constraints
 		delay := timingInfo.Delay
 
@@ -100,26 +231,40 @@ func (s *httpAwareScheduler) SetLastResponse(response string) {
 			Source:     timingInfo.Source,
 			Confidence: timingInfo.Confidence,
 		}
+		result.Timing = s.lastTimingInfo
+		s.holdUntil = s.clock.Now().Add(delay)
 	} else {
 		s.lastTimingInfo = nil
+		s.holdUntil = time.Time{}
 	}
 }
 
-// SetFallbackScheduler sets the fallback scheduler to use when no HTTP timing is available
+// SetFallbackScheduler sets the scheduler whose cadence is followed when no
+// HTTP timing is available. Set it before the first call to Next.
 func (s *httpAwareScheduler) SetFallbackScheduler(fallback scheduler.Scheduler) {
 	s.fallbackScheduler = fallback
 }
 
 // GetTimingInfo returns the last extracted timing information
 func (s *httpAwareScheduler) GetTimingInfo() *TimingInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.lastTimingInfo
 }
 
+// GetParseResult returns what the parser read from the last response, or nil
+// if it did not recognize it
+func (s *httpAwareScheduler) GetParseResult() *ParseResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastResult
+}
+
 // NextDelay returns the next delay duration (for testing purposes)
 func (s *httpAwareScheduler) NextDelay() time.Duration {
 	// If we have HTTP timing information, use it
-	if s.lastTimingInfo != nil {
-		return s.lastTimingInfo.Delay
+	if timingInfo := s.GetTimingInfo(); timingInfo != nil {
+		return timingInfo.Delay
 	}
 
 	// Otherwise, use fallback scheduler
@@ -129,7 +274,7 @@ func (s *httpAwareScheduler) NextDelay() time.Duration {
 		return s.simulateFallbackDelay()
 	}
 
-	return 1 * time.Second
+	return defaultInterval
 }
 
 // TestableScheduler interface for testing purposes
@@ -1,6 +1,7 @@
 package httpaware
 
 import (
+	"net/http"
 	"testing"
 	"time"
 
@@ -324,3 +325,140 @@ func (m *mockExponentialScheduler) GetNextDelay() time.Duration {
 	}
 	return delay
 }
+
+// manualScheduler fires whenever a tick is sent on its channel
+type manualScheduler struct {
+	ticks chan time.Time
+}
+
+func (m *manualScheduler) Next() <-chan time.Time { return m.ticks }
+func (m *manualScheduler) Stop()                  {}
+
+// receiveTick waits briefly for the scheduler to fire
+func receiveTick(t *testing.T, s scheduler.Scheduler) time.Time {
+	t.Helper()
+	select {
+	case tick := <-s.Next():
+		return tick
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not fire")
+		return time.Time{}
+	}
+}
+
+// assertNoTick checks that the scheduler does not fire right away
+func assertNoTick(t *testing.T, s scheduler.Scheduler) {
+	t.Helper()
+	select {
+	case tick := <-s.Next():
+		t.Errorf("Expected no tick yet, got one at %v", tick)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestHTTPAwareScheduler_PostponesFallbackTicks(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	fallback := &manualScheduler{ticks: make(chan time.Time, 1)}
+	httpScheduler := NewHTTPAwareSchedulerWithClock(HTTPAwareConfig{MinDelay: time.Second, MaxDelay: 20 * time.Minute}, clock)
+	httpScheduler.SetFallbackScheduler(fallback)
+	defer httpScheduler.Stop()
+
+	// completed reports an execution's response, then lets the fallback fire
+	completed := func(response string) {
+		httpScheduler.SetLastResponse(response)
+		httpScheduler.(scheduler.CompletionAware).ExecutionCompleted(clock.Now())
+		fallback.ticks <- clock.Now()
+	}
+
+	fallback.ticks <- start
+	if first := receiveTick(t, httpScheduler); !first.Equal(start) {
+		t.Errorf("Expected first tick at start, got %v", first.Sub(start))
+	}
+
+	// Retry-After holds the fallback's tick back
+	completed("HTTP/1.1 429 Too Many Requests\r\nRetry-After: 600\r\n\r\n")
+	clock.BlockUntil(1)
+	assertNoTick(t, httpScheduler)
+	clock.Advance(10 * time.Minute)
+	if second := receiveTick(t, httpScheduler); !second.Equal(start.Add(10 * time.Minute)) {
+		t.Errorf("Expected second tick after 10m, got %v", second.Sub(start))
+	}
+
+	// The delay is capped at MaxDelay
+	completed("HTTP/1.1 503 Service Unavailable\r\nRetry-After: 3600\r\n\r\n")
+	clock.BlockUntil(1)
+	clock.Advance(20*time.Minute - time.Second)
+	assertNoTick(t, httpScheduler)
+	clock.Advance(time.Second)
+	if third := receiveTick(t, httpScheduler); !third.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("Expected third tick 20m later, got %v", third.Sub(start))
+	}
+
+	// Without timing the fallback's tick passes straight through
+	completed("HTTP/1.1 200 OK\r\n\r\n")
+	if fourth := receiveTick(t, httpScheduler); !fourth.Equal(start.Add(30 * time.Minute)) {
+		t.Errorf("Expected fourth tick without delay, got %v", fourth.Sub(start))
+	}
+}
+
+func TestHTTPAwareScheduler_WaitsForCompletion(t *testing.T) {
+	clock := scheduler.NewVirtualClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	fallback := &manualScheduler{ticks: make(chan time.Time, 1)}
+	httpScheduler := NewHTTPAwareSchedulerWithClock(HTTPAwareConfig{}, clock)
+	httpScheduler.SetFallbackScheduler(fallback)
+	defer httpScheduler.Stop()
+
+	fallback.ticks <- clock.Now()
+	receiveTick(t, httpScheduler)
+
+	// The fallback fires, but nothing is released until the execution completes
+	fallback.ticks <- clock.Now()
+	assertNoTick(t, httpScheduler)
+
+	httpScheduler.(scheduler.CompletionAware).ExecutionCompleted(clock.Now())
+	receiveTick(t, httpScheduler)
+}
+
+func TestHTTPAwareScheduler_SetLastHTTPResponse(t *testing.T) {
+	config := HTTPAwareConfig{
+		MaxDelay:     time.Minute,
+		ParseHeaders: true,
+		ParseJSON:    true,
+	}
+
+	tests := []struct {
+		name   string
+		parser ResponseParser
+	}{
+		{name: "http_parser", parser: newHTTPResponseParser(config, scheduler.NewRealClock())},
+		{name: "chain_with_grpc_first", parser: parserChain{&grpcParser{}, newHTTPResponseParser(config, scheduler.NewRealClock())}},
+	}
+
+	response := &Response{
+		Proto:      "HTTP/2",
+		StatusCode: 503,
+		Header:     http.Header{"Retry-After": {"90"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpScheduler := NewHTTPAwareSchedulerWithParser(config, tt.parser, scheduler.NewRealClock())
+			httpScheduler.SetLastHTTPResponse(response)
+
+			result := httpScheduler.GetParseResult()
+			if result == nil {
+				t.Fatalf("Expected parse result, got nil")
+			}
+			if result.Parser != ParserHTTP || result.Outcome != OutcomeFailure {
+				t.Errorf("Expected http failure, got %s %v", result.Parser, result.Outcome)
+			}
+			// Capped by MaxDelay
+			if timing := httpScheduler.GetTimingInfo(); timing == nil || timing.Delay != time.Minute {
+				t.Errorf("Expected 1m delay, got: %+v", timing)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package httpaware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldUnit says how a selected JSON value is turned into a delay
+type FieldUnit string
+
+// Field units accepted after a selector, as in error.details[0].retryDelay:duration
+const (
+	FieldUnitAuto     FieldUnit = ""         // numbers are seconds; strings may also be durations or RFC 3339
+	FieldUnitSeconds  FieldUnit = "s"        // seconds, rounded up to whole seconds
+	FieldUnitMillis   FieldUnit = "ms"       // milliseconds
+	FieldUnitDuration FieldUnit = "duration" // Go or protobuf duration string: "30s", "1.5s", "2m"
+	FieldUnitEpoch    FieldUnit = "epoch"    // absolute Unix time in seconds
+	FieldUnitEpochMs  FieldUnit = "epoch-ms" // absolute Unix time in milliseconds
+	FieldUnitRFC3339  FieldUnit = "rfc3339"  // absolute RFC 3339 timestamp
+)
+
+// fieldUnitAliases maps the accepted unit names to units
+var fieldUnitAliases = map[string]FieldUnit{
+	"s":            FieldUnitSeconds,
+	"sec":          FieldUnitSeconds,
+	"seconds":      FieldUnitSeconds,
+	"ms":           FieldUnitMillis,
+	"millis":       FieldUnitMillis,
+	"milliseconds": FieldUnitMillis,
+	"duration":     FieldUnitDuration,
+	"epoch":        FieldUnitEpoch,
+	"unix":         FieldUnitEpoch,
+	"epoch-ms":     FieldUnitEpochMs,
+	"unix-ms":      FieldUnitEpochMs,
+	"rfc3339":      FieldUnitRFC3339,
+	"timestamp":    FieldUnitRFC3339,
+}
+
+// pathStep is one step of a selector path: an object key, an array index, or
+// any array element
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// FieldSelector picks a timing value out of a JSON body with a JSONPath-like
+// expression and converts it to a delay
+type FieldSelector struct {
+	Expr string
+	Unit FieldUnit
+	path []pathStep
+}
+
+// ParseFieldSelector parses a selector such as retry_after,
+// error.details[*].retryDelay:duration or $.meta["rate-limit"].resetAt:rfc3339.
+// Paths use dotted keys, [n] indexes, [*] for any element and quoted keys in
+// brackets; an optional :unit suffix names the value's unit.
+func ParseFieldSelector(expr string) (FieldSelector, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return FieldSelector{}, fmt.Errorf("empty field selector")
+	}
+
+	pathExpr, unit := expr, FieldUnitAuto
+	if i := unitSeparator(expr); i >= 0 {
+		name := strings.ToLower(strings.TrimSpace(expr[i+1:]))
+		u, ok := fieldUnitAliases[name]
+		if !ok {
+			return FieldSelector{}, fmt.Errorf("unknown unit %q in field selector %q (valid units: s, ms, duration, epoch, epoch-ms, rfc3339)", name, expr)
+		}
+		pathExpr, unit = expr[:i], u
+	}
+
+	path, err := parseSelectorPath(pathExpr)
+	if err != nil {
+		return FieldSelector{}, fmt.Errorf("invalid field selector %q: %w", expr, err)
+	}
+
+	return FieldSelector{Expr: expr, Unit: unit, path: path}, nil
+}
+
+// unitSeparator returns the index of the colon introducing the unit, ignoring
+// colons inside quoted keys, or -1
+func unitSeparator(expr string) int {
+	separator := -1
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ':':
+			separator = i
+		}
+	}
+	return separator
+}
+
+// parseSelectorPath parses the path part of a selector
+func parseSelectorPath(expr string) ([]pathStep, error) {
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimPrefix(expr, "$")
+	expr = strings.TrimPrefix(expr, ".")
+
+	var path []pathStep
+	for i := 0; i < len(expr); {
+		switch expr[i] {
+		case '.':
+			if i+1 >= len(expr) || expr[i+1] == '.' || expr[i+1] == '[' {
+				return nil, fmt.Errorf("empty key at offset %d", i)
+			}
+			i++
+		case '[':
+			end := strings.IndexByte(expr[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ at offset %d", i)
+			}
+			step, err := parseBracketStep(expr[i+1 : i+end])
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, step)
+			i += end + 1
+		default:
+			end := strings.IndexAny(expr[i:], ".[")
+			if end < 0 {
+				end = len(expr) - i
+			}
+			path = append(path, pathStep{key: expr[i : i+end]})
+			i += end
+		}
+	}
+
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return path, nil
+}
+
+// parseBracketStep parses the inside of [...]: an index, * or a quoted key
+func parseBracketStep(inner string) (pathStep, error) {
+	inner = strings.TrimSpace(inner)
+	if inner == "*" {
+		return pathStep{wildcard: true}, nil
+	}
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+		return pathStep{key: inner[1 : len(inner)-1]}, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil || index < 0 {
+		return pathStep{}, fmt.Errorf("invalid index [%s]", inner)
+	}
+	return pathStep{index: index, isIndex: true}, nil
+}
+
+// Lookup returns the first value the selector's path reaches in data
+func (s FieldSelector) Lookup(data interface{}) (interface{}, bool) {
+	return lookupPath(data, s.path)
+}
+
+// lookupPath walks path through decoded JSON, trying every element at a
+// wildcard
+func lookupPath(data interface{}, path []pathStep) (interface{}, bool) {
+	if len(path) == 0 {
+		return data, data != nil
+	}
+
+	step := path[0]
+	switch {
+	case step.wildcard:
+		items, ok := data.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		for _, item := range items {
+			if value, ok := lookupPath(item, path[1:]); ok {
+				return value, true
+			}
+		}
+		return nil, false
+	case step.isIndex:
+		items, ok := data.([]interface{})
+		if !ok || step.index >= len(items) {
+			return nil, false
+		}
+		return lookupPath(items[step.index], path[1:])
+	default:
+		object, ok := data.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		value, ok := object[step.key]
+		if !ok {
+			return nil, false
+		}
+		return lookupPath(value, path[1:])
+	}
+}
+
+// Delay selects the value from data and converts it to a delay measured from
+// now. It reports false if the value is missing, malformed or not in the future.
+func (s FieldSelector) Delay(data interface{}, now time.Time) (time.Duration, bool) {
+	value, ok := s.Lookup(data)
+	if !ok {
+		return 0, false
+	}
+
+	delay, ok := valueToDelay(value, s.Unit, now)
+	if !ok || delay <= 0 {
+		return 0, false
+	}
+	return delay, true
+}
+
+// valueToDelay converts a JSON number or string in the given unit to a delay
+func valueToDelay(value interface{}, unit FieldUnit, now time.Time) (time.Duration, bool) {
+	number, isNumber := jsonNumber(value)
+	text, isString := value.(string)
+	if !isNumber && !isString {
+		return 0, false
+	}
+
+	switch unit {
+	case FieldUnitAuto, FieldUnitSeconds:
+		if isNumber {
+			return ceilSeconds(number), true
+		}
+		if unit == FieldUnitAuto {
+			if d, err := time.ParseDuration(strings.TrimSpace(text)); err == nil {
+				return d, true
+			}
+			if at, err := time.Parse(time.RFC3339, strings.TrimSpace(text)); err == nil {
+				return at.Sub(now), true
+			}
+		}
+	case FieldUnitMillis:
+		if isNumber {
+			return time.Duration(number * float64(time.Millisecond)), true
+		}
+	case FieldUnitDuration:
+		if isNumber {
+			return time.Duration(number * float64(time.Second)), true
+		}
+		if d, err := time.ParseDuration(strings.TrimSpace(text)); err == nil {
+			return d, true
+		}
+	case FieldUnitEpoch:
+		if isNumber {
+			return time.Unix(0, int64(number*float64(time.Second))).Sub(now), true
+		}
+	case FieldUnitEpochMs:
+		if isNumber {
+			return time.UnixMilli(int64(number)).Sub(now), true
+		}
+	case FieldUnitRFC3339:
+		if isString {
+			if at, err := time.Parse(time.RFC3339, strings.TrimSpace(text)); err == nil {
+				return at.Sub(now), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// jsonNumber returns a JSON number, or a string holding one, as a float
+func jsonNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case string:
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return 0, false
+		}
+		return parsed, true
+	}
+	return 0, false
+}
+
+// ceilSeconds rounds fractional seconds up (like Discord API)
+func ceilSeconds(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(seconds)) * time.Second
+}
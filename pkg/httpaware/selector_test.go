@@ -0,0 +1,166 @@
+package httpaware
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+func TestParseFieldSelector(t *testing.T) {
+	tests := []struct {
+		name         string
+		expr         string
+		expectedUnit FieldUnit
+		expectError  string
+	}{
+		{name: "flat_field", expr: "retry_after", expectedUnit: FieldUnitAuto},
+		{name: "nested_path", expr: "meta.rateLimit.resetAt", expectedUnit: FieldUnitAuto},
+		{name: "root_prefix", expr: "$.error.retry", expectedUnit: FieldUnitAuto},
+		{name: "index_and_unit", expr: "error.details[0].retryDelay:duration", expectedUnit: FieldUnitDuration},
+		{name: "wildcard", expr: "error.details[*].retryDelay", expectedUnit: FieldUnitAuto},
+		{name: "quoted_key_with_colon", expr: `headers["x:reset"]:epoch`, expectedUnit: FieldUnitEpoch},
+		{name: "unit_alias", expr: "wait:milliseconds", expectedUnit: FieldUnitMillis},
+		{name: "unit_case", expr: "resetAt:RFC3339", expectedUnit: FieldUnitRFC3339},
+		{name: "empty", expr: " ", expectError: "empty field selector"},
+		{name: "unknown_unit", expr: "retry:weeks", expectError: "unknown unit"},
+		{name: "bad_index", expr: "items[-1]", expectError: "invalid index"},
+		{name: "unclosed_bracket", expr: "items[0", expectError: "unclosed"},
+		{name: "empty_key", expr: "error..retry", expectError: "empty key"},
+		{name: "only_root", expr: "$", expectError: "empty path"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := ParseFieldSelector(tt.expr)
+			if tt.expectError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectError) {
+					t.Errorf("Expected error containing %q, got: %v", tt.expectError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if selector.Unit != tt.expectedUnit {
+				t.Errorf("Expected unit %q, got %q", tt.expectedUnit, selector.Unit)
+			}
+		})
+	}
+}
+
+func TestFieldSelector_Delay(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	body := `{
+		"retry_after": 1.5,
+		"wait_ms": 2500,
+		"error": {
+			"details": [
+				{"@type": "type.googleapis.com/google.rpc.ErrorInfo", "reason": "RATE_LIMIT_EXCEEDED"},
+				{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "30s"}
+			]
+		},
+		"meta": {"rateLimit": {"resetAt": "2025-01-01T00:02:00Z", "resetEpoch": 1735689720, "resetEpochMs": 1735689615000}},
+		"extensions": {"cost": {"throttleStatus": {"restoreSeconds": "12"}}},
+		"headers": {"x-reset": "45"},
+		"past": "2024-12-31T23:00:00Z"
+	}`
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		t.Fatalf("Invalid test body: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		expr          string
+		expectedDelay time.Duration
+		expectedFound bool
+	}{
+		{"flat_seconds_round_up", "retry_after", 2 * time.Second, true},
+		{"milliseconds", "wait_ms:ms", 2500 * time.Millisecond, true},
+		{"index_duration", "error.details[1].retryDelay:duration", 30 * time.Second, true},
+		{"wildcard_finds_first_match", "error.details[*].retryDelay", 30 * time.Second, true},
+		{"index_without_field", "error.details[0].retryDelay", 0, false},
+		{"index_out_of_range", "error.details[5].retryDelay", 0, false},
+		{"rfc3339", "meta.rateLimit.resetAt:rfc3339", 2 * time.Minute, true},
+		{"rfc3339_auto_detected", "meta.rateLimit.resetAt", 2 * time.Minute, true},
+		{"epoch_seconds", "meta.rateLimit.resetEpoch:epoch", 2 * time.Minute, true},
+		{"epoch_milliseconds", "meta.rateLimit.resetEpochMs:epoch-ms", 15 * time.Second, true},
+		{"numeric_string", "extensions.cost.throttleStatus.restoreSeconds", 12 * time.Second, true},
+		{"quoted_key", `headers["x-reset"]:s`, 45 * time.Second, true},
+		{"time_in_past", "past:rfc3339", 0, false},
+		{"wrong_unit", "retry_after:rfc3339", 0, false},
+		{"object_value", "meta.rateLimit", 0, false},
+		{"missing_path", "missing.field", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := ParseFieldSelector(tt.expr)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			delay, found := selector.Delay(data, now)
+			if found != tt.expectedFound {
+				t.Fatalf("Expected found %v, got %v (delay %v)", tt.expectedFound, found, delay)
+			}
+			if delay != tt.expectedDelay {
+				t.Errorf("Expected delay %v, got %v", tt.expectedDelay, delay)
+			}
+		})
+	}
+}
+
+func TestHTTPResponseParser_FieldSelectors(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	config := defaultParserConfig()
+	config.JSONFields = []string{"error.details[*].retryDelay:duration", "meta.rateLimit.resetAt:rfc3339", "bad[index"}
+	parser := NewHTTPResponseParserWithClock(config, scheduler.NewVirtualClock(now))
+
+	tests := []struct {
+		name          string
+		httpResponse  string
+		expectedDelay time.Duration
+	}{
+		{
+			name: "google_retry_info",
+			httpResponse: "HTTP/2 429\r\n\r\n" +
+				`{"error": {"code": 429, "details": [{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "17s"}]}}`,
+			expectedDelay: 17 * time.Second,
+		},
+		{
+			name: "reset_timestamp",
+			httpResponse: "HTTP/1.1 503 Service Unavailable\r\n\r\n" +
+				`{"meta": {"rateLimit": {"resetAt": "2025-01-01T00:05:00Z"}}}`,
+			expectedDelay: 5 * time.Minute,
+		},
+		{
+			name: "selectors_before_standard_fields",
+			httpResponse: "HTTP/1.1 429 Too Many Requests\r\n\r\n" +
+				`{"retry_after": 60, "error": {"details": [{"retryDelay": "2s"}]}}`,
+			expectedDelay: 2 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timingInfo, err := parser.ParseResponse(tt.httpResponse)
+			if err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if timingInfo == nil {
+				t.Fatalf("Expected timing info, got nil")
+			}
+			if timingInfo.Delay != tt.expectedDelay {
+				t.Errorf("Expected delay %v, got %v", tt.expectedDelay, timingInfo.Delay)
+			}
+			if timingInfo.Confidence != 1.0 {
+				t.Errorf("Expected confidence 1.0 for a configured field, got %v", timingInfo.Confidence)
+			}
+		})
+	}
+}
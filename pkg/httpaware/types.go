@@ -14,6 +14,9 @@ const (
 	TimingSourceJSONRetryAfter
 	TimingSourceJSONRateLimit
 	TimingSourceJSONBackoff
+	TimingSourceQuotaHeaders
+	TimingSourceGRPCRetryInfo
+	TimingSourceThrottleError
 )
 
 // String returns a string representation of the timing source
@@ -27,6 +30,12 @@ func (ts TimingSource) String() string {
 		return "json-rate-limit"
 	case TimingSourceJSONBackoff:
 		return "json-backoff"
+	case TimingSourceQuotaHeaders:
+		return "quota-headers"
+	case TimingSourceGRPCRetryInfo:
+		return "grpc-retry-info"
+	case TimingSourceThrottleError:
+		return "throttle-error"
 	default:
 		return "unknown"
 	}
@@ -48,12 +57,60 @@ type HTTPResponseParser interface {
 	SupportsResponse(response string) bool
 }
 
+// Outcome classifies an execution from its output
+type Outcome int
+
+const (
+	OutcomeUnknown Outcome = iota // the parser has no opinion
+	OutcomeSuccess
+	OutcomeFailure
+)
+
+// String returns a string representation of the outcome
+func (o Outcome) String() string {
+	switch o {
+	case OutcomeSuccess:
+		return "success"
+	case OutcomeFailure:
+		return "failure"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseResult is what a ResponseParser reads from command output
+type ParseResult struct {
+	Parser  string      // name of the parser that recognized the output
+	Timing  *TimingInfo // nil if the output carries no timing
+	Outcome Outcome
+	Detail  string // e.g. "HTTP 429", "RESOURCE_EXHAUSTED", "ThrottlingException"
+}
+
+// ResponseParser reads throttling timing and an outcome from the output of a
+// command such as curl, grpcurl or a cloud CLI
+type ResponseParser interface {
+	// Name returns the name the parser is selected by with --aware
+	Name() string
+
+	// Parse returns nil if the parser does not recognize the output
+	Parse(output string) (*ParseResult, error)
+}
+
+// HTTPResponseAnalyzer is implemented by parsers that can read an already
+// framed HTTP response, such as one returned by the native HTTP probe, without
+// going through command output
+type HTTPResponseAnalyzer interface {
+	ParseHTTP(response *Response) (*ParseResult, error)
+}
+
 // HTTPAwareScheduler combines HTTP intelligence with fallback scheduling
 type HTTPAwareScheduler interface {
 	scheduler.Scheduler
 	SetLastResponse(response string)
+	SetLastHTTPResponse(response *Response)
 	SetFallbackScheduler(fallback scheduler.Scheduler)
 	GetTimingInfo() *TimingInfo
+	GetParseResult() *ParseResult
 	NextDelay() time.Duration // For testing purposes
 }
 
@@ -72,4 +129,8 @@ type HTTPAwareConfig struct {
 	// Timing extraction patterns
 	JSONFields  []string // Custom JSON fields to check
 	HeaderNames []string // Custom header names to check
+
+	// Parsers names the registered response parsers to try in order; empty
+	// means http
+	Parsers []string
 }
@@ -0,0 +1,42 @@
+package interfaces
+
+import "time"
+
+// Clock abstracts the passage of time for schedulers, rate limiters and the
+// runner. Production code uses the wall clock; tests and schedule previews
+// substitute a manually advanced clock so timing can be stepped
+// deterministically.
+type Clock interface {
+	// Now returns the current time according to this clock
+	Now() time.Time
+
+	// After returns a channel that receives the clock time once d has elapsed
+	After(d time.Duration) <-chan time.Time
+
+	// NewTimer creates a timer that fires once after d
+	NewTimer(d time.Duration) Timer
+
+	// NewTicker creates a ticker that fires every d
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the Clock equivalent of time.Timer
+type Timer interface {
+	// C returns the channel on which the timer delivers its firing time
+	C() <-chan time.Time
+
+	// Stop prevents the timer from firing and reports whether it was active
+	Stop() bool
+
+	// Reset changes the timer to fire after d and reports whether it was active
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the Clock equivalent of time.Ticker
+type Ticker interface {
+	// C returns the channel on which ticks are delivered
+	C() <-chan time.Time
+
+	// Stop turns off the ticker
+	Stop()
+}
@@ -5,6 +5,7 @@
 //   - pkg/scheduler/* - Basic scheduling implementations
 //   - pkg/adaptive/* - Adaptive scheduling implementations
 //   - pkg/strategies/* - Mathematical retry strategies
+//   - pkg/scheduler/clock.go - Real and virtual Clock implementations
 package interfaces
 
 import (
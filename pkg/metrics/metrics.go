@@ -22,6 +22,8 @@ type MetricsServer struct {
 
 	// Scheduler metrics
 	currentInterval time.Duration
+	phaseIndex      int    // 0-based index of the active phase
+	phaseName       string // active phase of a phased schedule, if any
 
 	// Rate limit metrics
 	rateLimitHits    int64
@@ -97,6 +99,14 @@ func (m *MetricsServer) RecordSchedulerInterval(interval time.Duration) {
 	m.currentInterval = interval
 }
 
+// RecordPhase records the active phase of a phased schedule
+func (m *MetricsServer) RecordPhase(index int, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.phaseIndex = index
+	m.phaseName = name
+}
+
 // RecordRateLimitHit records a rate limit hit
 func (m *MetricsServer) RecordRateLimitHit() {
 	m.mu.Lock()
@@ -120,6 +130,8 @@ func (m *MetricsServer) Reset() {
 	m.failureCount = 0
 	m.durations = make([]time.Duration, 0)
 	m.currentInterval = 0
+	m.phaseIndex = 0
+	m.phaseName = ""
 	m.rateLimitHits = 0
 	m.rateLimitAllowed = 0
 }
@@ -179,6 +191,13 @@ func (m *MetricsServer) metricsHandler(w http.ResponseWriter, r *http.Request) {
 	_, _ = fmt.Fprintf(w, "# TYPE rpr_scheduler_interval_seconds gauge\n")
 	_, _ = fmt.Fprintf(w, "rpr_scheduler_interval_seconds %g\n", m.currentInterval.Seconds())
 
+	// Active phase, labelled so dashboards can split results by phase
+	if m.phaseName != "" {
+		_, _ = fmt.Fprintf(w, "# HELP rpr_phase_info Active phase of a phased schedule\n")
+		_, _ = fmt.Fprintf(w, "# TYPE rpr_phase_info gauge\n")
+		_, _ = fmt.Fprintf(w, "rpr_phase_info{phase=%q,index=\"%d\"} 1\n", m.phaseName, m.phaseIndex)
+	}
+
 	// Rate limit counters
 	_, _ = fmt.Fprintf(w, "# HELP rpr_rate_limit_total Rate limit events\n")
 	_, _ = fmt.Fprintf(w, "# TYPE rpr_rate_limit_total counter\n")
@@ -147,6 +147,34 @@ func TestMetricsServer_RecordSchedulerMetrics(t *testing.T) {
 	}
 }
 
+func TestMetricsServer_RecordPhase(t *testing.T) {
+	server := NewMetricsServer(0)
+
+	// No phase gauge outside phased schedules
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	w := httptest.NewRecorder()
+	server.metricsHandler(w, req)
+	if strings.Contains(w.Body.String(), "rpr_phase_info") {
+		t.Error("Expected no phase metric before a phase is recorded")
+	}
+
+	server.RecordPhase(0, "warmup")
+	server.RecordPhase(1, "steady")
+
+	req = httptest.NewRequest("GET", "/metrics", nil)
+	w = httptest.NewRecorder()
+	server.metricsHandler(w, req)
+
+	body := w.Body.String()
+	expected := "rpr_phase_info{phase=\"steady\",index=\"1\"} 1"
+	if !strings.Contains(body, expected) {
+		t.Errorf("Expected phase metric not found: %s", expected)
+	}
+	if strings.Contains(body, "warmup") {
+		t.Error("Expected only the active phase to be reported")
+	}
+}
+
 func TestMetricsServer_RecordRateLimitMetrics(t *testing.T) {
 	server := NewMetricsServer(0)
 
@@ -0,0 +1,95 @@
+package patterns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternMatcher_Expressions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		config    PatternConfig
+		execution Execution
+		success   bool
+		reason    string
+	}{
+		{
+			name:      "success expression holds for a non-zero exit code",
+			config:    PatternConfig{SuccessExpr: "exit in [0,3] && duration < 2s"},
+			execution: Execution{ExitCode: 3, Duration: time.Second},
+			success:   true,
+			reason:    "success expression matched",
+		},
+		{
+			name:      "success expression fails a zero exit code",
+			config:    PatternConfig{SuccessExpr: "exit in [0,3] && duration < 2s"},
+			execution: Execution{Duration: 5 * time.Second},
+			reason:    "success expression not satisfied",
+		},
+		{
+			name:      "success expression sees stdout and stderr apart",
+			config:    PatternConfig{SuccessExpr: `stdout =~ "ok" && !(stderr =~ "WARN")`},
+			execution: Execution{Stdout: "ok\n", Stderr: "WARN low disk\n"},
+			reason:    "success expression not satisfied",
+		},
+		{
+			name:      "success expression and pattern must both hold",
+			config:    PatternConfig{SuccessExpr: "exit == 0", SuccessPattern: "ready"},
+			execution: Execution{Stdout: "starting"},
+			reason:    "success pattern not matched",
+		},
+		{
+			name:      "failure expression takes precedence",
+			config:    PatternConfig{SuccessExpr: "exit == 0", FailureExpr: `json(stdout).status != "green"`},
+			execution: Execution{Stdout: `{"status": "yellow"}`},
+			reason:    "failure expression matched",
+		},
+		{
+			name:      "failure expression that does not hold",
+			config:    PatternConfig{FailureExpr: `json(stdout).status != "green"`},
+			execution: Execution{Stdout: `{"status": "green"}`},
+			success:   true,
+			reason:    "exit code used",
+		},
+		{
+			name:      "evaluation errors fail the execution",
+			config:    PatternConfig{SuccessExpr: `json(stdout).status == "green"`},
+			execution: Execution{Stdout: "<html>"},
+			reason:    "success expression: json: invalid JSON",
+		},
+		{
+			name:      "failure expression applies to HTTP success codes",
+			config:    PatternConfig{HTTPSuccessCodes: "2xx", FailureExpr: "duration > 1s"},
+			execution: Execution{Stdout: "HTTP/1.1 200 OK\r\n\r\n", Duration: 3 * time.Second},
+			reason:    "failure expression matched",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := NewPatternMatcher(tt.config)
+			require.NoError(t, err)
+
+			result := matcher.EvaluateExecution(tt.execution)
+			assert.Equal(t, tt.success, result.Success)
+			assert.Contains(t, result.Reason, tt.reason)
+		})
+	}
+}
+
+func TestPatternMatcher_InvalidExpression(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewPatternMatcher(PatternConfig{SuccessExpr: "exit == 0 &&"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid success expression: unexpected end of expression at column 13")
+
+	_, err = NewPatternMatcher(PatternConfig{FailureExpr: "status == 1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid failure expression: unknown name "status"`)
+}
@@ -0,0 +1,96 @@
+package patterns
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/swi/repeater/pkg/expr"
+)
+
+// maxValueText bounds how much of a value a failure reason quotes
+const maxValueText = 60
+
+// JSONExpectation asserts that a path in an execution's JSON output holds a
+// value, written PATH=VALUE as in .replicas.ready=3 or .status="ok"
+type JSONExpectation struct {
+	Path  *expr.Query
+	Value any // as decoded by encoding/json
+}
+
+// ParseJSONExpectation parses PATH=VALUE. The path is a jq-style path such as
+// .items[0].name or .items | length; the value is JSON, and anything that is
+// not valid JSON is taken as a string, so .status=ok means .status="ok".
+func ParseJSONExpectation(spec string) (JSONExpectation, error) {
+	path, value, ok := cutAssignment(spec)
+	if !ok {
+		return JSONExpectation{}, fmt.Errorf("%q is not PATH=VALUE", spec)
+	}
+
+	query, err := expr.CompileQuery(path)
+	if err != nil {
+		return JSONExpectation{}, fmt.Errorf("invalid path %q: %w", strings.TrimSpace(path), err)
+	}
+
+	var want any
+	if err := json.Unmarshal([]byte(value), &want); err != nil {
+		want = value
+	}
+	return JSONExpectation{Path: query, Value: want}, nil
+}
+
+// cutAssignment splits spec at the first "=" outside quotes and brackets, so
+// paths such as .["a=b"] keep their "="
+func cutAssignment(spec string) (path, value string, found bool) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(spec); i++ {
+		c := spec[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+		case c == '=' && depth == 0:
+			return spec[:i], spec[i+1:], true
+		}
+	}
+	return spec, "", false
+}
+
+// Check reports whether the expectation holds for env and, when it does not,
+// why, e.g. ".replicas.ready expected 3 got 2"
+func (e JSONExpectation) Check(env expr.Env) (string, bool) {
+	got, err := e.Path.Eval(env)
+	if err != nil {
+		return fmt.Sprintf("%s: %v", e.Path, err), false
+	}
+	if !reflect.DeepEqual(got, e.Value) {
+		return fmt.Sprintf("%s expected %s got %s", e.Path, formatJSON(e.Value), formatJSON(got)), false
+	}
+	return "", true
+}
+
+// formatJSON renders a value for a failure reason, shortening long ones
+func formatJSON(value any) string {
+	var buf strings.Builder
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Sprint(value)
+	}
+	text := strings.TrimSuffix(buf.String(), "\n")
+	if len(text) > maxValueText {
+		return text[:maxValueText] + "..."
+	}
+	return text
+}
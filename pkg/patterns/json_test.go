@@ -0,0 +1,138 @@
+package patterns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSONExpectation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		spec     string
+		path     string
+		value    any
+		errorMsg string
+	}{
+		{name: "number", spec: ".replicas.ready=3", path: ".replicas.ready", value: float64(3)},
+		{name: "quoted string", spec: `.status="ok"`, path: ".status", value: "ok"},
+		{name: "bare string", spec: ".status=ok", path: ".status", value: "ok"},
+		{name: "boolean", spec: ".healthy=true", path: ".healthy", value: true},
+		{name: "null", spec: ".error=null", path: ".error", value: nil},
+		{name: "list", spec: `.zones=["a","b"]`, path: ".zones", value: []any{"a", "b"}},
+		{name: "length", spec: ".items | length=2", path: ".items | length", value: float64(2)},
+		{name: "equals sign in key", spec: `.["a=b"]=1`, path: `.["a=b"]`, value: float64(1)},
+		{name: "equals sign in value", spec: ".query=a=b", path: ".query", value: "a=b"},
+		{name: "empty string value", spec: ".name=", path: ".name", value: ""},
+		{name: "missing value", spec: ".status", errorMsg: `".status" is not PATH=VALUE`},
+		{name: "not a path", spec: "status=ok", errorMsg: `invalid path "status": path must start with "."`},
+		{name: "bad path", spec: ".items..name=1", errorMsg: `invalid path ".items..name": expected a field name`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectation, err := ParseJSONExpectation(tt.spec)
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.path, expectation.Path.String())
+			assert.Equal(t, tt.value, expectation.Value)
+		})
+	}
+}
+
+func TestPatternMatcher_ExpectJSON(t *testing.T) {
+	t.Parallel()
+
+	deployment := `{"status": "ok", "replicas": {"ready": 2, "desired": 3}, "items": [{"name": "web"}]}`
+
+	tests := []struct {
+		name      string
+		config    PatternConfig
+		execution Execution
+		success   bool
+		reason    string
+	}{
+		{
+			name:      "all expectations hold",
+			config:    PatternConfig{ExpectJSON: []string{".status=ok", ".items[0].name=web", ".items | length=1"}},
+			execution: Execution{Stdout: deployment},
+			success:   true,
+			reason:    "JSON expectations met",
+		},
+		{
+			name:      "first unmet expectation is the reason",
+			config:    PatternConfig{ExpectJSON: []string{".status=ok", ".replicas.ready=3", ".replicas.desired=5"}},
+			execution: Execution{Stdout: deployment},
+			reason:    ".replicas.ready expected 3 got 2",
+		},
+		{
+			name:      "missing field reads as null",
+			config:    PatternConfig{ExpectJSON: []string{`.error.code="E1"`}},
+			execution: Execution{Stdout: deployment},
+			reason:    `.error.code expected "E1" got null`,
+		},
+		{
+			name:      "output that is not JSON",
+			config:    PatternConfig{ExpectJSON: []string{".status=ok"}},
+			execution: Execution{Stdout: "Service Unavailable"},
+			reason:    ".status: json: invalid JSON",
+		},
+		{
+			name:      "exit code does not decide",
+			config:    PatternConfig{ExpectJSON: []string{".status=ok"}},
+			execution: Execution{Stdout: deployment, ExitCode: 22},
+			success:   true,
+			reason:    "JSON expectations met",
+		},
+		{
+			name:      "expression holds",
+			config:    PatternConfig{ExpectJSONExpr: `.status == "ok" and (.items | length) > 0`},
+			execution: Execution{Stdout: deployment},
+			success:   true,
+			reason:    "JSON expectations met",
+		},
+		{
+			name:      "expression does not hold",
+			config:    PatternConfig{ExpectJSONExpr: ".replicas.ready >= .replicas.desired"},
+			execution: Execution{Stdout: deployment},
+			reason:    ".replicas.ready >= .replicas.desired not satisfied",
+		},
+		{
+			name:      "paths read the body of an HTTP response",
+			config:    PatternConfig{ExpectJSON: []string{".status=ok"}},
+			execution: Execution{Stdout: "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n" + deployment},
+			success:   true,
+			reason:    "JSON expectations met",
+		},
+		{
+			name:      "failure pattern takes precedence",
+			config:    PatternConfig{ExpectJSON: []string{".status=ok"}, FailurePattern: "web"},
+			execution: Execution{Stdout: deployment},
+			reason:    "failure pattern matched",
+		},
+		{
+			name:      "success expression is checked first",
+			config:    PatternConfig{SuccessExpr: "duration < 1s", ExpectJSON: []string{".replicas.ready=3"}},
+			execution: Execution{Stdout: deployment},
+			reason:    ".replicas.ready expected 3 got 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := NewPatternMatcher(tt.config)
+			require.NoError(t, err)
+
+			result := matcher.EvaluateExecution(tt.execution)
+			assert.Equal(t, tt.success, result.Success)
+			assert.Contains(t, result.Reason, tt.reason)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package patterns
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Target is the output a pattern is matched against
+type Target string
+
+const (
+	TargetOutput Target = ""       // stdout followed by stderr
+	TargetStdout Target = "stdout" // written stdout:REGEX
+	TargetStderr Target = "stderr" // written stderr:REGEX
+)
+
+// MatchMode decides whether any or all of a list of patterns must match
+type MatchMode string
+
+const (
+	MatchAny MatchMode = "any"
+	MatchAll MatchMode = "all"
+)
+
+// ParseMatchMode parses "any" or "all"; empty means any
+func ParseMatchMode(mode string) (MatchMode, error) {
+	switch MatchMode(strings.ToLower(mode)) {
+	case "", MatchAny:
+		return MatchAny, nil
+	case MatchAll:
+		return MatchAll, nil
+	}
+	return "", fmt.Errorf("invalid match mode %q (want any or all)", mode)
+}
+
+// Pattern is a compiled regular expression and the output it targets
+type Pattern struct {
+	Source string // as written, e.g. "stderr:ERROR"
+	Target Target
+	Regex  *regexp.Regexp
+}
+
+// ParsePattern compiles REGEX, stdout:REGEX or stderr:REGEX. Without a
+// prefix the pattern sees stdout followed by stderr.
+func ParsePattern(spec string, caseInsensitive bool) (*Pattern, error) {
+	pattern := &Pattern{Source: spec, Target: TargetOutput}
+	expression := spec
+	for _, target := range []Target{TargetStdout, TargetStderr} {
+		if rest, ok := strings.CutPrefix(spec, string(target)+":"); ok {
+			pattern.Target = target
+			expression = rest
+			break
+		}
+	}
+
+	if caseInsensitive {
+		expression = "(?i)" + expression
+	}
+	regex, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	pattern.Regex = regex
+	return pattern, nil
+}
+
+// text returns the part of an execution's output the pattern targets
+func (p *Pattern) text(execution Execution) string {
+	switch p.Target {
+	case TargetStdout:
+		return execution.Stdout
+	case TargetStderr:
+		return execution.Stderr
+	}
+	return execution.Stdout + execution.Stderr
+}
+
+// Matches reports whether the pattern matches the execution's output at
+// least threshold times
+func (p *Pattern) Matches(execution Execution, threshold int) bool {
+	if threshold <= 1 {
+		return p.Regex.MatchString(p.text(execution))
+	}
+	return len(p.Regex.FindAllStringIndex(p.text(execution), threshold)) >= threshold
+}
+
+// countLine counts the pattern's matches in one line of output, up to limit;
+// a line on a stream the pattern does not target has none
+func (p *Pattern) countLine(stream, line string, limit int) int {
+	if p.Target != TargetOutput && string(p.Target) != stream {
+		return 0
+	}
+	return len(p.Regex.FindAllStringIndex(line, limit))
+}
+
+// compilePatterns compiles a list of pattern specs, naming the kind of
+// pattern in errors
+func compilePatterns(kind string, specs []string, caseInsensitive bool) ([]*Pattern, error) {
+	var compiled []*Pattern
+	for _, spec := range specs {
+		if spec == "" {
+			continue
+		}
+		pattern, err := ParsePattern(spec, caseInsensitive)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pattern: %w", kind, err)
+		}
+		compiled = append(compiled, pattern)
+	}
+	return compiled, nil
+}
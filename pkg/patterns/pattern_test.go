@@ -0,0 +1,191 @@
+package patterns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePattern(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		spec            string
+		caseInsensitive bool
+		target          Target
+		matches         Execution
+		misses          Execution
+	}{
+		{name: "whole output", spec: "ready", target: TargetOutput, matches: Execution{Stderr: "ready"}, misses: Execution{Stdout: "starting"}},
+		{name: "stdout only", spec: "stdout:ready", target: TargetStdout, matches: Execution{Stdout: "ready"}, misses: Execution{Stderr: "ready"}},
+		{name: "stderr only", spec: "stderr:^ERROR", target: TargetStderr, matches: Execution{Stderr: "ERROR x"}, misses: Execution{Stdout: "ERROR x"}},
+		{name: "case insensitive target", spec: "stderr:error", caseInsensitive: true, target: TargetStderr, matches: Execution{Stderr: "ERROR"}, misses: Execution{Stdout: "ERROR"}},
+		{name: "other prefixes are regex", spec: "status: ok", target: TargetOutput, matches: Execution{Stdout: "status: ok"}, misses: Execution{Stdout: "ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pattern, err := ParsePattern(tt.spec, tt.caseInsensitive)
+			require.NoError(t, err)
+			assert.Equal(t, tt.spec, pattern.Source)
+			assert.Equal(t, tt.target, pattern.Target)
+			assert.True(t, pattern.Matches(tt.matches, 1))
+			assert.False(t, pattern.Matches(tt.misses, 1))
+		})
+	}
+
+	_, err := ParsePattern("stderr:(", false)
+	assert.Error(t, err)
+}
+
+func TestPattern_MatchesThreshold(t *testing.T) {
+	t.Parallel()
+
+	pattern, err := ParsePattern("ERROR", false)
+	require.NoError(t, err)
+
+	execution := Execution{Stdout: "ERROR a\nok\n", Stderr: "ERROR b\n"}
+	assert.True(t, pattern.Matches(execution, 2))
+	assert.False(t, pattern.Matches(execution, 3))
+}
+
+func TestParseMatchMode(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]MatchMode{"": MatchAny, "any": MatchAny, "ALL": MatchAll} {
+		mode, err := ParseMatchMode(input)
+		require.NoError(t, err)
+		assert.Equal(t, want, mode)
+	}
+
+	_, err := ParseMatchMode("some")
+	assert.EqualError(t, err, `invalid match mode "some" (want any or all)`)
+}
+
+func TestPatternMatcher_MultiplePatterns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		config    PatternConfig
+		execution Execution
+		success   bool
+		fatal     bool
+		reason    string
+		matched   []string
+	}{
+		{
+			name:      "any success pattern",
+			config:    PatternConfig{SuccessPattern: "deployed", SuccessPatterns: []string{"up to date"}},
+			execution: Execution{Stdout: "already up to date", ExitCode: 1},
+			success:   true,
+			reason:    "success pattern matched",
+			matched:   []string{"up to date"},
+		},
+		{
+			name:      "all success patterns",
+			config:    PatternConfig{SuccessPattern: "stdout:migrated", SuccessPatterns: []string{"stdout:seeded"}, MatchMode: MatchAll},
+			execution: Execution{Stdout: "migrated", ExitCode: 1},
+			reason:    "exit code used",
+			matched:   []string{"stdout:migrated"},
+		},
+		{
+			name:      "failure pattern targets stderr",
+			config:    PatternConfig{FailurePattern: "stderr:ERROR"},
+			execution: Execution{Stdout: "ERROR count: 0", Stderr: "ERROR disk full"},
+			reason:    "failure pattern matched",
+			matched:   []string{"stderr:ERROR"},
+		},
+		{
+			name:      "failure pattern on the other stream",
+			config:    PatternConfig{FailurePattern: "stderr:ERROR"},
+			execution: Execution{Stdout: "ERROR count: 0"},
+			success:   true,
+			reason:    "exit code used",
+		},
+		{
+			name:      "failure below the threshold",
+			config:    PatternConfig{FailurePattern: "ERROR", MinMatches: 3},
+			execution: Execution{Stdout: "ERROR a\nERROR b\n"},
+			success:   true,
+			reason:    "exit code used",
+		},
+		{
+			name:      "failure at the threshold",
+			config:    PatternConfig{FailurePattern: "ERROR", MinMatches: 3},
+			execution: Execution{Stdout: "ERROR a\nERROR b\n", Stderr: "ERROR c\n"},
+			reason:    "failure pattern matched",
+			matched:   []string{"ERROR"},
+		},
+		{
+			name:      "all failure patterns",
+			config:    PatternConfig{FailurePattern: "timeout", FailurePatterns: []string{"retrying"}, MatchMode: MatchAll},
+			execution: Execution{Stdout: "timeout, giving up"},
+			success:   true,
+			reason:    "exit code used",
+			matched:   []string{"timeout"},
+		},
+		{
+			name:      "required-absent pattern ignores the threshold",
+			config:    PatternConfig{SuccessPattern: "done", RequireAbsent: []string{"stderr:deprecated"}, MinMatches: 5},
+			execution: Execution{Stdout: "done done done done done", Stderr: "flag is deprecated"},
+			reason:    `required-absent pattern "stderr:deprecated" found`,
+			matched:   []string{"stderr:deprecated", "done"},
+		},
+		{
+			name:      "fatal pattern stops the run",
+			config:    PatternConfig{SuccessPattern: "ok", FatalPatterns: []string{"stderr:permission denied"}},
+			execution: Execution{Stdout: "ok", Stderr: "permission denied"},
+			fatal:     true,
+			reason:    `fatal pattern "stderr:permission denied" matched`,
+			matched:   []string{"stderr:permission denied", "ok"},
+		},
+		{
+			name:      "fatal pattern precedes HTTP success codes",
+			config:    PatternConfig{HTTPSuccessCodes: "2xx", FatalPatterns: []string{"maintenance"}},
+			execution: Execution{Stdout: "HTTP/1.1 200 OK\r\n\r\nmaintenance mode"},
+			fatal:     true,
+			reason:    `fatal pattern "maintenance" matched`,
+			matched:   []string{"maintenance"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := NewPatternMatcher(tt.config)
+			require.NoError(t, err)
+
+			result := matcher.EvaluateExecution(tt.execution)
+			assert.Equal(t, tt.success, result.Success)
+			assert.Equal(t, tt.fatal, result.Fatal)
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, tt.matched, result.Matched)
+		})
+	}
+}
+
+func TestNewPatternMatcher_InvalidPatterns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		config   PatternConfig
+		errorMsg string
+	}{
+		{name: "extra success pattern", config: PatternConfig{SuccessPattern: "ok", SuccessPatterns: []string{"("}}, errorMsg: "invalid success pattern"},
+		{name: "required-absent pattern", config: PatternConfig{RequireAbsent: []string{"stderr:["}}, errorMsg: "invalid required-absent pattern"},
+		{name: "fatal pattern", config: PatternConfig{FatalPatterns: []string{"*"}}, errorMsg: "invalid fatal pattern"},
+		{name: "match mode", config: PatternConfig{MatchMode: "most"}, errorMsg: "invalid match mode"},
+		{name: "negative threshold", config: PatternConfig{MinMatches: -1}, errorMsg: "min matches must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPatternMatcher(tt.config)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package patterns
 
 import (
+	"errors"
 	"fmt"
-	"regexp"
+	"strings"
+	"time"
+
+	"github.com/swi/repeater/pkg/expr"
+	"github.com/swi/repeater/pkg/httpaware"
 )
 
 // PatternConfig holds configuration for pattern matching
@@ -10,77 +15,211 @@ type PatternConfig struct {
 	SuccessPattern  string
 	FailurePattern  string
 	CaseInsensitive bool
+
+	// More patterns, each REGEX, stdout:REGEX or stderr:REGEX; see ParsePattern
+	SuccessPatterns []string  // with SuccessPattern, any or all must match per MatchMode
+	FailurePatterns []string  // with FailurePattern, any or all must match per MatchMode
+	RequireAbsent   []string  // any match fails the execution
+	FatalPatterns   []string  // any match fails the execution and stops the run
+	MatchMode       MatchMode // any (default) or all
+	MinMatches      int       // matches a success or failure pattern needs, default 1
+
+	// Deciding while the command runs, line by line; see Watch
+	FailFastOnPattern bool // stop the command on a fatal, failure or required-absent pattern
+	SucceedOnPattern  bool // stop the command as a success once the success patterns match
+
+	// Conditions over the exit code, duration and output; see pkg/expr
+	SuccessExpr string // e.g. `exit in [0,3] && duration < 2s`
+	FailureExpr string // e.g. `stderr =~ "WARN"`
+
+	// Assertions on the JSON in stdout, or in the body of an HTTP response
+	ExpectJSON     []string // PATH=VALUE, e.g. ".replicas.ready=3"; see ParseJSONExpectation
+	ExpectJSONExpr string   // e.g. `.status == "ok" && (.items | length) > 0`
+
+	// HTTP status classification of curl -i style output; see ParseStatusCodes
+	HTTPSuccessCodes string // e.g. "2xx,304"
+	HTTPRetryCodes   string // e.g. "429,5xx"
+	HTTPFatalCodes   string // e.g. "401,403"
 }
 
 // EvaluationResult represents the result of pattern evaluation
 type EvaluationResult struct {
 	Success  bool
 	ExitCode int
-	Reason   string // For debugging/logging
+	Reason   string   // For debugging/logging
+	Fatal    bool     // The run should stop, e.g. on an HTTP fatal code
+	Matched  []string // Patterns found in the output, as written
+}
+
+// Execution is the outcome of a command or probe for EvaluateExecution
+type Execution struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
 }
 
 // PatternMatcher handles success/failure pattern matching
 type PatternMatcher struct {
-	config       PatternConfig
-	successRegex *regexp.Regexp
-	failureRegex *regexp.Regexp
+	config          PatternConfig
+	successPatterns []*Pattern
+	failurePatterns []*Pattern
+	absentPatterns  []*Pattern
+	fatalPatterns   []*Pattern
+	matchMode       MatchMode
+	minMatches      int
+	successExpr     *expr.Expr
+	failureExpr     *expr.Expr
+	expectJSON      []JSONExpectation
+	jsonExpr        *expr.Expr
+	successCodes    StatusCodes
+	retryCodes      StatusCodes
+	fatalCodes      StatusCodes
 }
 
 // NewPatternMatcher creates a new pattern matcher with the given configuration
 func NewPatternMatcher(config PatternConfig) (*PatternMatcher, error) {
 	matcher := &PatternMatcher{
-		config: config,
+		config:     config,
+		minMatches: max(config.MinMatches, 1),
 	}
 
-	// Compile success pattern if provided
-	if config.SuccessPattern != "" {
-		pattern := config.SuccessPattern
-		if config.CaseInsensitive {
-			pattern = "(?i)" + pattern
-		}
+	// Compile patterns if provided
+	var err error
+	ci := config.CaseInsensitive
+	if matcher.successPatterns, err = compilePatterns("success", append([]string{config.SuccessPattern}, config.SuccessPatterns...), ci); err != nil {
+		return nil, err
+	}
+	if matcher.failurePatterns, err = compilePatterns("failure", append([]string{config.FailurePattern}, config.FailurePatterns...), ci); err != nil {
+		return nil, err
+	}
+	if matcher.absentPatterns, err = compilePatterns("required-absent", config.RequireAbsent, ci); err != nil {
+		return nil, err
+	}
+	if matcher.fatalPatterns, err = compilePatterns("fatal", config.FatalPatterns, ci); err != nil {
+		return nil, err
+	}
+	if matcher.matchMode, err = ParseMatchMode(string(config.MatchMode)); err != nil {
+		return nil, err
+	}
+	if config.MinMatches < 0 {
+		return nil, fmt.Errorf("min matches must not be negative, got %d", config.MinMatches)
+	}
 
-		regex, err := regexp.Compile(pattern)
-		if err != nil {
-			return nil, fmt.Errorf("invalid success pattern: %w", err)
+	// Check that early decisions have patterns to decide on
+	if config.FailFastOnPattern && matcher.failurePatterns == nil && matcher.absentPatterns == nil && matcher.fatalPatterns == nil {
+		return nil, errors.New("failing fast on pattern needs a failure, required-absent or fatal pattern")
+	}
+	if config.SucceedOnPattern {
+		if matcher.successPatterns == nil {
+			return nil, errors.New("succeeding on pattern needs a success pattern")
+		}
+		if config.SuccessExpr != "" || config.FailureExpr != "" || len(config.ExpectJSON) > 0 || config.ExpectJSONExpr != "" ||
+			config.HTTPSuccessCodes != "" || config.HTTPRetryCodes != "" || config.HTTPFatalCodes != "" {
+			return nil, errors.New("succeeding on pattern cannot be combined with expressions, JSON assertions or HTTP status codes, which need the finished execution")
 		}
-		matcher.successRegex = regex
 	}
 
-	// Compile failure pattern if provided
-	if config.FailurePattern != "" {
-		pattern := config.FailurePattern
-		if config.CaseInsensitive {
-			pattern = "(?i)" + pattern
+	// Compile expressions if provided
+	if config.SuccessExpr != "" {
+		if matcher.successExpr, err = expr.Compile(config.SuccessExpr); err != nil {
+			return nil, fmt.Errorf("invalid success expression: %w", err)
+		}
+	}
+	if config.FailureExpr != "" {
+		if matcher.failureExpr, err = expr.Compile(config.FailureExpr); err != nil {
+			return nil, fmt.Errorf("invalid failure expression: %w", err)
 		}
+	}
 
-		regex, err := regexp.Compile(pattern)
+	// Parse JSON assertions if provided
+	for _, spec := range config.ExpectJSON {
+		expectation, err := ParseJSONExpectation(spec)
 		if err != nil {
-			return nil, fmt.Errorf("invalid failure pattern: %w", err)
+			return nil, fmt.Errorf("invalid JSON expectation: %w", err)
+		}
+		matcher.expectJSON = append(matcher.expectJSON, expectation)
+	}
+	if config.ExpectJSONExpr != "" {
+		if matcher.jsonExpr, err = expr.Compile(config.ExpectJSONExpr); err != nil {
+			return nil, fmt.Errorf("invalid JSON expression: %w", err)
 		}
-		matcher.failureRegex = regex
+	}
+
+	// Parse HTTP status code sets if provided
+	if matcher.successCodes, err = ParseStatusCodes(config.HTTPSuccessCodes); err != nil {
+		return nil, fmt.Errorf("invalid HTTP success codes: %w", err)
+	}
+	if matcher.retryCodes, err = ParseStatusCodes(config.HTTPRetryCodes); err != nil {
+		return nil, fmt.Errorf("invalid HTTP retry codes: %w", err)
+	}
+	if matcher.fatalCodes, err = ParseStatusCodes(config.HTTPFatalCodes); err != nil {
+		return nil, fmt.Errorf("invalid HTTP fatal codes: %w", err)
 	}
 
 	return matcher, nil
 }
 
-// EvaluateResult evaluates command output and exit code using configured patterns
+// EvaluateResult evaluates command output and exit code using configured
+// patterns; expressions see the output as stdout
 func (pm *PatternMatcher) EvaluateResult(output string, exitCode int) EvaluationResult {
-	// Pattern precedence:
-	// 1. Failure pattern match → Command fails (exit code 1)
-	// 2. Success pattern match → Command succeeds (exit code 0)
-	// 3. Exit code → Standard behavior (0 = success, non-zero = failure)
+	return pm.EvaluateExecution(Execution{Stdout: output, ExitCode: exitCode})
+}
 
-	// Check failure pattern first (highest precedence)
-	if pm.failureRegex != nil && pm.failureRegex.MatchString(output) {
-		return EvaluationResult{
-			Success:  false,
-			ExitCode: 1,
-			Reason:   "failure pattern matched",
+// EvaluateExecution judges an execution by the configured criteria
+func (pm *PatternMatcher) EvaluateExecution(execution Execution) EvaluationResult {
+	found := pm.match(execution)
+	result := pm.evaluate(execution, found)
+	result.Matched = found.names
+	return result
+}
+
+// evaluate applies the criteria in order of precedence:
+//  1. Fatal pattern match → Command fails and the run stops
+//  2. HTTP status code of the final response, when code sets are configured
+//  3. Failure expression, failure patterns or a required-absent pattern
+//     match → Command fails (exit code 1)
+//  4. Success expression and JSON assertions → Command succeeds only if
+//     they all hold, and the success patterns match when set too
+//  5. Success patterns match → Command succeeds (exit code 0)
+//  6. Exit code → Standard behavior (0 = success, non-zero = failure)
+func (pm *PatternMatcher) evaluate(execution Execution, found matches) EvaluationResult {
+	for _, pattern := range pm.fatalPatterns {
+		if found.has(pattern) {
+			return EvaluationResult{
+				Success:  false,
+				ExitCode: 1,
+				Reason:   fmt.Sprintf("fatal pattern %q matched", pattern.Source),
+				Fatal:    true,
+			}
+		}
+	}
+
+	if result, decided := pm.evaluateStatus(execution, found); decided {
+		return result
+	}
+
+	// Check failure criteria first (highest precedence)
+	if result, failed := pm.evaluateFailure(execution, found); failed {
+		return result
+	}
+
+	// Success conditions decide on their own, beside any success patterns
+	if pm.successExpr != nil || pm.expectJSON != nil || pm.jsonExpr != nil {
+		if result, failed := pm.evaluateConditions(execution); failed {
+			return result
+		}
+		if pm.successPatterns != nil && !pm.satisfied(pm.successPatterns, found) {
+			return EvaluationResult{Success: false, ExitCode: 1, Reason: "success pattern not matched"}
+		}
+		if pm.successExpr == nil {
+			return EvaluationResult{Success: true, ExitCode: 0, Reason: "JSON expectations met"}
 		}
+		return EvaluationResult{Success: true, ExitCode: 0, Reason: "success expression matched"}
 	}
 
-	// Check success pattern second
-	if pm.successRegex != nil && pm.successRegex.MatchString(output) {
+	// Check success patterns second
+	if pm.satisfied(pm.successPatterns, found) {
 		return EvaluationResult{
 			Success:  true,
 			ExitCode: 0,
@@ -90,8 +229,219 @@ func (pm *PatternMatcher) EvaluateResult(output string, exitCode int) Evaluation
 
 	// Fall back to exit code
 	return EvaluationResult{
-		Success:  exitCode == 0,
-		ExitCode: exitCode,
+		Success:  execution.ExitCode == 0,
+		ExitCode: execution.ExitCode,
 		Reason:   "exit code used",
 	}
 }
+
+// evaluateFailure checks the failure expression, the failure patterns, then
+// the required-absent patterns. An expression that cannot be evaluated fails
+// the execution too.
+func (pm *PatternMatcher) evaluateFailure(execution Execution, found matches) (EvaluationResult, bool) {
+	if pm.failureExpr != nil {
+		holds, err := pm.failureExpr.Eval(exprEnv(execution))
+		if err != nil {
+			return EvaluationResult{Success: false, ExitCode: 1, Reason: fmt.Sprintf("failure expression: %v", err)}, true
+		}
+		if holds {
+			return EvaluationResult{Success: false, ExitCode: 1, Reason: "failure expression matched"}, true
+		}
+	}
+
+	if pm.satisfied(pm.failurePatterns, found) {
+		return EvaluationResult{
+			Success:  false,
+			ExitCode: 1,
+			Reason:   "failure pattern matched",
+		}, true
+	}
+
+	for _, pattern := range pm.absentPatterns {
+		if found.has(pattern) {
+			return EvaluationResult{
+				Success:  false,
+				ExitCode: 1,
+				Reason:   fmt.Sprintf("required-absent pattern %q found", pattern.Source),
+			}, true
+		}
+	}
+
+	return EvaluationResult{}, false
+}
+
+// matches records which patterns an execution's output matched
+type matches struct {
+	found map[*Pattern]bool
+	names []string // sources of the matched patterns, fatal patterns first
+}
+
+func (m matches) has(pattern *Pattern) bool {
+	return m.found[pattern]
+}
+
+func (m *matches) add(pattern *Pattern) {
+	if m.found == nil {
+		m.found = make(map[*Pattern]bool)
+	}
+	m.found[pattern] = true
+	m.names = append(m.names, pattern.Source)
+}
+
+// patternGroup is a list of patterns and the matches each needs to count
+type patternGroup struct {
+	patterns  []*Pattern
+	threshold int
+}
+
+// groups lists the patterns in order of precedence. Success and failure
+// patterns need the configured number of matches; fatal and required-absent
+// patterns need one.
+func (pm *PatternMatcher) groups() []patternGroup {
+	return []patternGroup{
+		{pm.fatalPatterns, 1},
+		{pm.failurePatterns, pm.minMatches},
+		{pm.absentPatterns, 1},
+		{pm.successPatterns, pm.minMatches},
+	}
+}
+
+// match runs every pattern once against the execution
+func (pm *PatternMatcher) match(execution Execution) matches {
+	var m matches
+	for _, group := range pm.groups() {
+		for _, pattern := range group.patterns {
+			if pattern.Matches(execution, group.threshold) {
+				m.add(pattern)
+			}
+		}
+	}
+	return m
+}
+
+// satisfied reports whether any or all of the patterns matched, per the
+// match mode; it is false for no patterns
+func (pm *PatternMatcher) satisfied(patterns []*Pattern, found matches) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+	if pm.matchMode == MatchAll {
+		for _, pattern := range patterns {
+			if !found.has(pattern) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, pattern := range patterns {
+		if found.has(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluateConditions checks the success expression, then each JSON
+// expectation, then the JSON expression, failing on the first that does not
+// hold or cannot be evaluated
+func (pm *PatternMatcher) evaluateConditions(execution Execution) (EvaluationResult, bool) {
+	env := exprEnv(execution)
+	failed := func(reason string) (EvaluationResult, bool) {
+		return EvaluationResult{Success: false, ExitCode: 1, Reason: reason}, true
+	}
+
+	if pm.successExpr != nil {
+		holds, err := pm.successExpr.Eval(env)
+		if err != nil {
+			return failed(fmt.Sprintf("success expression: %v", err))
+		}
+		if !holds {
+			return failed("success expression not satisfied")
+		}
+	}
+
+	for _, expectation := range pm.expectJSON {
+		if reason, ok := expectation.Check(env); !ok {
+			return failed(reason)
+		}
+	}
+
+	if pm.jsonExpr != nil {
+		holds, err := pm.jsonExpr.Eval(env)
+		if err != nil {
+			return failed(fmt.Sprintf("%s: %v", pm.jsonExpr, err))
+		}
+		if !holds {
+			return failed(fmt.Sprintf("%s not satisfied", pm.jsonExpr))
+		}
+	}
+
+	return EvaluationResult{}, false
+}
+
+// exprEnv exposes an execution to expressions. Paths such as .status read
+// the body when stdout holds an HTTP response, as from curl -i or --http.
+func exprEnv(execution Execution) expr.Env {
+	env := expr.Env{
+		ExitCode: execution.ExitCode,
+		Duration: execution.Duration,
+		Stdout:   execution.Stdout,
+		Stderr:   execution.Stderr,
+	}
+	if strings.HasPrefix(execution.Stdout, "HTTP/") {
+		if response, ok := httpaware.ParseHTTPResponse(execution.Stdout); ok {
+			env.Body = response.Body
+		}
+	}
+	return env
+}
+
+// evaluateStatus classifies output holding an HTTP response by its status
+// code: fatal codes fail and stop the run, retry codes fail, and success codes
+// succeed unless a failure expression or pattern matches. With success codes
+// configured, any other status fails; otherwise it is left to the patterns
+// and exit code.
+func (pm *PatternMatcher) evaluateStatus(execution Execution, found matches) (EvaluationResult, bool) {
+	if pm.successCodes.IsEmpty() && pm.retryCodes.IsEmpty() && pm.fatalCodes.IsEmpty() {
+		return EvaluationResult{}, false
+	}
+
+	response, ok := httpaware.ParseHTTPResponse(execution.Stdout + execution.Stderr)
+	if !ok {
+		return EvaluationResult{}, false
+	}
+	status := response.StatusCode
+
+	switch {
+	case pm.fatalCodes.Contains(status):
+		return EvaluationResult{
+			Success:  false,
+			ExitCode: 1,
+			Reason:   fmt.Sprintf("HTTP %d matched fatal codes", status),
+			Fatal:    true,
+		}, true
+	case pm.retryCodes.Contains(status):
+		return EvaluationResult{
+			Success:  false,
+			ExitCode: 1,
+			Reason:   fmt.Sprintf("HTTP %d matched retry codes", status),
+		}, true
+	case pm.successCodes.Contains(status):
+		if result, failed := pm.evaluateFailure(execution, found); failed {
+			return result, true
+		}
+		return EvaluationResult{
+			Success:  true,
+			ExitCode: 0,
+			Reason:   fmt.Sprintf("HTTP %d matched success codes", status),
+		}, true
+	case !pm.successCodes.IsEmpty():
+		return EvaluationResult{
+			Success:  false,
+			ExitCode: 1,
+			Reason:   fmt.Sprintf("HTTP %d not in success codes", status),
+		}, true
+	}
+
+	return EvaluationResult{}, false
+}
@@ -0,0 +1,94 @@
+package patterns
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StatusCodes is a set of HTTP status codes, written as a comma-separated list
+// of codes (304), classes (5xx) and ranges (500-504)
+type StatusCodes struct {
+	codes   map[int]bool
+	classes [6]bool // 1xx through 5xx
+}
+
+// ParseStatusCodes parses a status code list such as "2xx,304" or "429,500-504"
+func ParseStatusCodes(spec string) (StatusCodes, error) {
+	set := StatusCodes{codes: make(map[int]bool)}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.ToLower(strings.TrimSpace(item))
+		if item == "" {
+			continue
+		}
+
+		// Class: 2xx
+		if len(item) == 3 && item[1:] == "xx" {
+			class := int(item[0] - '0')
+			if class < 1 || class > 5 {
+				return StatusCodes{}, fmt.Errorf("invalid status class %q", item)
+			}
+			set.classes[class] = true
+			continue
+		}
+
+		// Range: 500-504
+		if low, high, ok := strings.Cut(item, "-"); ok {
+			from, err := parseStatusCode(low)
+			if err != nil {
+				return StatusCodes{}, err
+			}
+			to, err := parseStatusCode(high)
+			if err != nil {
+				return StatusCodes{}, err
+			}
+			if to < from {
+				return StatusCodes{}, fmt.Errorf("invalid status range %q", item)
+			}
+			for code := from; code <= to; code++ {
+				set.codes[code] = true
+			}
+			continue
+		}
+
+		code, err := parseStatusCode(item)
+		if err != nil {
+			return StatusCodes{}, err
+		}
+		set.codes[code] = true
+	}
+
+	return set, nil
+}
+
+// parseStatusCode parses a single status code between 100 and 599
+func parseStatusCode(value string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", value)
+	}
+	return code, nil
+}
+
+// Contains reports whether code is in the set
+func (s StatusCodes) Contains(code int) bool {
+	if s.codes[code] {
+		return true
+	}
+	class := code / 100
+	return class >= 1 && class <= 5 && s.classes[class]
+}
+
+// IsEmpty reports whether the set holds no codes
+func (s StatusCodes) IsEmpty() bool {
+	if len(s.codes) > 0 {
+		return false
+	}
+	for _, class := range s.classes {
+		if class {
+			return false
+		}
+	}
+	return true
+}
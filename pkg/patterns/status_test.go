@@ -0,0 +1,179 @@
+package patterns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		spec     string
+		included []int
+		excluded []int
+		empty    bool
+		errorMsg string
+	}{
+		{
+			name:  "empty spec",
+			spec:  "",
+			empty: true,
+		},
+		{
+			name:     "class and single code",
+			spec:     "2xx,304",
+			included: []int{200, 204, 299, 304},
+			excluded: []int{301, 404, 500},
+		},
+		{
+			name:     "range with spaces",
+			spec:     "429, 500-504",
+			included: []int{429, 500, 502, 504},
+			excluded: []int{428, 505},
+		},
+		{
+			name:     "upper case class",
+			spec:     "5XX",
+			included: []int{500, 599},
+			excluded: []int{499},
+		},
+		{
+			name:     "class out of range",
+			spec:     "6xx",
+			errorMsg: "invalid status class",
+		},
+		{
+			name:     "code out of range",
+			spec:     "99",
+			errorMsg: "invalid status code",
+		},
+		{
+			name:     "not a number",
+			spec:     "ok",
+			errorMsg: "invalid status code",
+		},
+		{
+			name:     "reversed range",
+			spec:     "504-500",
+			errorMsg: "invalid status range",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			codes, err := ParseStatusCodes(tt.spec)
+			if tt.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.empty, codes.IsEmpty())
+			for _, code := range tt.included {
+				assert.True(t, codes.Contains(code), "expected %d in %q", code, tt.spec)
+			}
+			for _, code := range tt.excluded {
+				assert.False(t, codes.Contains(code), "expected %d not in %q", code, tt.spec)
+			}
+		})
+	}
+}
+
+func TestPatternMatcher_HTTPStatusCodes(t *testing.T) {
+	t.Parallel()
+
+	config := PatternConfig{
+		FailurePattern:   `"error"`,
+		HTTPSuccessCodes: "2xx,304",
+		HTTPRetryCodes:   "429,5xx",
+		HTTPFatalCodes:   "401,403",
+	}
+
+	tests := []struct {
+		name           string
+		config         PatternConfig
+		output         string
+		exitCode       int
+		expectedResult bool
+		expectedFatal  bool
+		expectedReason string
+	}{
+		{
+			name:           "success code",
+			config:         config,
+			output:         "HTTP/1.1 200 OK\r\n\r\n{}",
+			expectedResult: true,
+			expectedReason: "HTTP 200 matched success codes",
+		},
+		{
+			name:           "success code with failure pattern in body",
+			config:         config,
+			output:         "HTTP/1.1 200 OK\r\n\r\n{\"error\": \"partial\"}",
+			expectedResult: false,
+			expectedReason: "failure pattern matched",
+		},
+		{
+			name:           "retry code",
+			config:         config,
+			output:         "HTTP/2 503\r\nretry-after: 5\r\n\r\n",
+			expectedResult: false,
+			expectedReason: "HTTP 503 matched retry codes",
+		},
+		{
+			name:           "fatal code",
+			config:         config,
+			output:         "HTTP/1.1 401 Unauthorized\r\n\r\n",
+			expectedResult: false,
+			expectedFatal:  true,
+			expectedReason: "HTTP 401 matched fatal codes",
+		},
+		{
+			name:           "unlisted code with success codes configured",
+			config:         config,
+			output:         "HTTP/1.1 404 Not Found\r\n\r\n",
+			expectedResult: false,
+			expectedReason: "HTTP 404 not in success codes",
+		},
+		{
+			name:           "final response after redirect",
+			config:         config,
+			output:         "HTTP/1.1 302 Found\r\nLocation: /next\r\n\r\nHTTP/1.1 200 OK\r\n\r\ndone",
+			expectedResult: true,
+			expectedReason: "HTTP 200 matched success codes",
+		},
+		{
+			name:           "unlisted code without success codes falls back to exit code",
+			config:         PatternConfig{HTTPFatalCodes: "401"},
+			output:         "HTTP/1.1 404 Not Found\r\n\r\n",
+			exitCode:       0,
+			expectedResult: true,
+			expectedReason: "exit code used",
+		},
+		{
+			name:           "output without HTTP response falls back to exit code",
+			config:         config,
+			output:         "curl: (7) Failed to connect",
+			exitCode:       7,
+			expectedResult: false,
+			expectedReason: "exit code used",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := NewPatternMatcher(tt.config)
+			require.NoError(t, err)
+
+			result := matcher.EvaluateResult(tt.output, tt.exitCode)
+
+			assert.Equal(t, tt.expectedResult, result.Success)
+			assert.Equal(t, tt.expectedFatal, result.Fatal)
+			assert.Equal(t, tt.expectedReason, result.Reason)
+		})
+	}
+}
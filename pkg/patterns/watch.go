@@ -0,0 +1,113 @@
+package patterns
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Watch follows an execution's output line by line so the command can be
+// stopped as soon as its outcome is known: on a fatal, failure or
+// required-absent pattern with FailFastOnPattern, once the success patterns
+// match with SucceedOnPattern. Matches are counted across lines, so a pattern
+// spanning lines never matches here. It is safe for concurrent use.
+type Watch struct {
+	matcher *PatternMatcher
+
+	mu       sync.Mutex
+	counts   map[*Pattern]int
+	found    matches
+	lines    map[string]int // lines seen per stream
+	decision *EvaluationResult
+}
+
+// Watch returns a Watch for one execution, or nil when the matcher decides
+// only on finished executions
+func (pm *PatternMatcher) Watch() *Watch {
+	if !pm.config.FailFastOnPattern && !pm.config.SucceedOnPattern {
+		return nil
+	}
+	return &Watch{
+		matcher: pm,
+		counts:  make(map[*Pattern]int),
+		lines:   make(map[string]int),
+	}
+}
+
+// Line records a line of output, stream being "stdout" or "stderr". It
+// reports true for the line that decides the execution; the command should
+// then be stopped.
+func (w *Watch) Line(stream, line string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.decision != nil {
+		return false
+	}
+	w.lines[stream]++
+
+	for _, group := range w.matcher.groups() {
+		for _, pattern := range group.patterns {
+			count := w.counts[pattern]
+			if count >= group.threshold {
+				continue
+			}
+			count += pattern.countLine(stream, line, group.threshold-count)
+			w.counts[pattern] = count
+			if count >= group.threshold {
+				w.found.add(pattern)
+			}
+		}
+	}
+
+	result, decided := w.decide()
+	if !decided {
+		return false
+	}
+	result.Reason = fmt.Sprintf("%s; command stopped at %s line %d", result.Reason, stream, w.lines[stream])
+	result.Matched = w.found.names
+	w.decision = &result
+	return true
+}
+
+// decide judges the output seen so far, as evaluate would
+func (w *Watch) decide() (EvaluationResult, bool) {
+	pm := w.matcher
+	var failure *EvaluationResult
+	for _, pattern := range pm.fatalPatterns {
+		if w.found.has(pattern) {
+			failure = &EvaluationResult{ExitCode: 1, Reason: fmt.Sprintf("fatal pattern %q matched", pattern.Source), Fatal: true}
+			break
+		}
+	}
+	if failure == nil && pm.satisfied(pm.failurePatterns, w.found) {
+		failure = &EvaluationResult{ExitCode: 1, Reason: "failure pattern matched"}
+	}
+	if failure == nil {
+		for _, pattern := range pm.absentPatterns {
+			if w.found.has(pattern) {
+				failure = &EvaluationResult{ExitCode: 1, Reason: fmt.Sprintf("required-absent pattern %q found", pattern.Source)}
+				break
+			}
+		}
+	}
+
+	switch {
+	case failure != nil:
+		// A failure seen so far also rules out succeeding early
+		return *failure, pm.config.FailFastOnPattern
+	case pm.config.SucceedOnPattern && pm.satisfied(pm.successPatterns, w.found):
+		return EvaluationResult{Success: true, Reason: "success pattern matched"}, true
+	}
+	return EvaluationResult{}, false
+}
+
+// Decision returns the verdict of the line that decided the execution, if any
+func (w *Watch) Decision() (EvaluationResult, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.decision == nil {
+		return EvaluationResult{}, false
+	}
+	return *w.decision, true
+}
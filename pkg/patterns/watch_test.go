@@ -0,0 +1,144 @@
+package patterns
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type outputLine struct{ stream, text string }
+
+func TestWatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		config    PatternConfig
+		lines     []outputLine
+		decidedAt int // index of the deciding line, -1 for none
+		success   bool
+		reason    string
+		matched   []string
+	}{
+		{
+			name:      "failure pattern",
+			config:    PatternConfig{FailurePattern: "ERROR", FailFastOnPattern: true},
+			lines:     []outputLine{{"stdout", "ok"}, {"stderr", "ERROR x"}, {"stdout", "ERROR y"}},
+			decidedAt: 1,
+			reason:    "failure pattern matched; command stopped at stderr line 1",
+			matched:   []string{"ERROR"},
+		},
+		{
+			name:      "stream target",
+			config:    PatternConfig{FailurePattern: "stderr:ERROR", FailFastOnPattern: true},
+			lines:     []outputLine{{"stdout", "ERROR in test name"}, {"stdout", "ok"}},
+			decidedAt: -1,
+		},
+		{
+			name:      "threshold counts across lines",
+			config:    PatternConfig{FailurePattern: "ERROR", MinMatches: 3, FailFastOnPattern: true},
+			lines:     []outputLine{{"stdout", "ERROR ERROR"}, {"stdout", "ok"}, {"stderr", "ERROR"}},
+			decidedAt: 2,
+			reason:    "failure pattern matched; command stopped at stderr line 1",
+			matched:   []string{"ERROR"},
+		},
+		{
+			name:      "all success patterns across lines",
+			config:    PatternConfig{SuccessPattern: "migrated", SuccessPatterns: []string{"seeded"}, MatchMode: MatchAll, SucceedOnPattern: true},
+			lines:     []outputLine{{"stdout", "migrated"}, {"stdout", "seeding"}, {"stdout", "seeded"}},
+			decidedAt: 2,
+			success:   true,
+			reason:    "success pattern matched; command stopped at stdout line 3",
+			matched:   []string{"migrated", "seeded"},
+		},
+		{
+			name:      "failure without fail fast blocks early success",
+			config:    PatternConfig{SuccessPattern: "ready", FailurePattern: "degraded", SucceedOnPattern: true},
+			lines:     []outputLine{{"stdout", "degraded"}, {"stdout", "ready"}},
+			decidedAt: -1,
+		},
+		{
+			name:      "required-absent pattern",
+			config:    PatternConfig{RequireAbsent: []string{"deprecated"}, FailFastOnPattern: true},
+			lines:     []outputLine{{"stderr", "flag is deprecated"}},
+			decidedAt: 0,
+			reason:    `required-absent pattern "deprecated" found; command stopped at stderr line 1`,
+			matched:   []string{"deprecated"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher, err := NewPatternMatcher(tt.config)
+			require.NoError(t, err)
+			watch := matcher.Watch()
+			require.NotNil(t, watch)
+
+			decidedAt := -1
+			for i, line := range tt.lines {
+				if watch.Line(line.stream, line.text) {
+					require.Equal(t, -1, decidedAt, "only one line decides")
+					decidedAt = i
+				}
+			}
+			assert.Equal(t, tt.decidedAt, decidedAt)
+
+			result, decided := watch.Decision()
+			assert.Equal(t, tt.decidedAt >= 0, decided)
+			assert.Equal(t, tt.success, result.Success)
+			assert.Equal(t, tt.reason, result.Reason)
+			assert.Equal(t, tt.matched, result.Matched)
+		})
+	}
+}
+
+func TestWatch_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	matcher, err := NewPatternMatcher(PatternConfig{FailurePattern: "ERROR", MinMatches: 100, FailFastOnPattern: true})
+	require.NoError(t, err)
+	watch := matcher.Watch()
+
+	var wg sync.WaitGroup
+	var decisions sync.Map
+	for _, stream := range []string{"stdout", "stderr"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if watch.Line(stream, "ERROR") {
+					decisions.Store(stream, i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	count := 0
+	decisions.Range(func(_, _ any) bool { count++; return true })
+	assert.Equal(t, 1, count, "exactly one line decides")
+}
+
+func TestPatternMatcher_WatchConfig(t *testing.T) {
+	t.Parallel()
+
+	matcher, err := NewPatternMatcher(PatternConfig{FailurePattern: "ERROR"})
+	require.NoError(t, err)
+	assert.Nil(t, matcher.Watch(), "no watch without an early decision")
+
+	for _, tt := range []struct {
+		config   PatternConfig
+		errorMsg string
+	}{
+		{PatternConfig{SuccessPattern: "ok", FailFastOnPattern: true}, "failing fast on pattern needs a failure, required-absent or fatal pattern"},
+		{PatternConfig{FailurePattern: "ERROR", SucceedOnPattern: true}, "succeeding on pattern needs a success pattern"},
+		{PatternConfig{SuccessPattern: "ok", SuccessExpr: "exit == 0", SucceedOnPattern: true}, "cannot be combined"},
+		{PatternConfig{SuccessPattern: "ok", HTTPSuccessCodes: "2xx", SucceedOnPattern: true}, "cannot be combined"},
+	} {
+		_, err := NewPatternMatcher(tt.config)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), tt.errorMsg)
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"maps"
 
+	"github.com/swi/repeater/pkg/httpaware"
 	"github.com/swi/repeater/pkg/interfaces"
+	"github.com/swi/repeater/pkg/scheduler"
 )
 
 // PluginCoordinator manages plugin lifecycle and coordination
@@ -13,14 +15,25 @@ type PluginCoordinator struct {
 	schedulerPlugins map[string]SchedulerPlugin
 	executorPlugins  map[string]ExecutorPlugin
 	outputPlugins    map[string]OutputPlugin
+	parserPlugins    map[string]ParserPlugin
+	parsers          *httpaware.ParserRegistry
 }
 
-// NewPluginCoordinator creates a new plugin coordinator
+// NewPluginCoordinator creates a new plugin coordinator that makes parser
+// plugins selectable through httpaware.DefaultParsers
 func NewPluginCoordinator() *PluginCoordinator {
+	return NewPluginCoordinatorWithParsers(httpaware.DefaultParsers)
+}
+
+// NewPluginCoordinatorWithParsers creates a plugin coordinator that registers
+// parser plugins with the given parser registry
+func NewPluginCoordinatorWithParsers(parsers *httpaware.ParserRegistry) *PluginCoordinator {
 	return &PluginCoordinator{
 		schedulerPlugins: make(map[string]SchedulerPlugin),
 		executorPlugins:  make(map[string]ExecutorPlugin),
 		outputPlugins:    make(map[string]OutputPlugin),
+		parserPlugins:    make(map[string]ParserPlugin),
+		parsers:          parsers,
 	}
 }
 
@@ -54,6 +67,26 @@ func (pc *PluginCoordinator) RegisterOutputPlugin(plugin OutputPlugin) error {
 	return nil
 }
 
+// RegisterParserPlugin registers a response parser plugin and makes it
+// selectable with --aware. The plugin instance is shared by every run, so it
+// should not keep state between responses.
+func (pc *PluginCoordinator) RegisterParserPlugin(plugin ParserPlugin) error {
+	name := plugin.Name()
+	if _, exists := pc.parserPlugins[name]; exists {
+		return fmt.Errorf("parser plugin %q already registered", name)
+	}
+
+	factory := func(httpaware.HTTPAwareConfig, scheduler.Clock) httpaware.ResponseParser {
+		return plugin
+	}
+	if err := pc.parsers.Register(name, factory); err != nil {
+		return fmt.Errorf("parser plugin %q: %w", name, err)
+	}
+
+	pc.parserPlugins[name] = plugin
+	return nil
+}
+
 // CreateScheduler creates a scheduler from a plugin
 func (pc *PluginCoordinator) CreateScheduler(ctx context.Context, pluginName string, config map[string]any) (interfaces.Scheduler, error) {
 	plugin, exists := pc.schedulerPlugins[pluginName]
@@ -89,6 +122,13 @@ func (pc *PluginCoordinator) GetOutputPlugins() map[string]OutputPlugin {
 	return result
 }
 
+// GetParserPlugins returns all registered response parser plugins
+func (pc *PluginCoordinator) GetParserPlugins() map[string]ParserPlugin {
+	result := make(map[string]ParserPlugin, len(pc.parserPlugins))
+	maps.Copy(result, pc.parserPlugins)
+	return result
+}
+
 // ValidatePluginConfig validates configuration for a specific plugin
 func (pc *PluginCoordinator) ValidatePluginConfig(pluginType, pluginName string, config map[string]any) error {
 	switch pluginType {
@@ -2,9 +2,11 @@ package plugin
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/swi/repeater/pkg/httpaware"
 	"github.com/swi/repeater/pkg/interfaces"
 )
 
@@ -134,3 +136,53 @@ func TestPluginCoordinator_GetPlugins(t *testing.T) {
 		t.Error("Expected executor1 to be registered")
 	}
 }
+
+type mockParserPlugin struct {
+	name string
+}
+
+func (m *mockParserPlugin) Name() string        { return m.name }
+func (m *mockParserPlugin) Version() string     { return "1.0.0" }
+func (m *mockParserPlugin) Description() string { return "Mock parser" }
+func (m *mockParserPlugin) Parse(output string) (*httpaware.ParseResult, error) {
+	if !strings.Contains(output, "SLOW DOWN") {
+		return nil, nil
+	}
+	return &httpaware.ParseResult{
+		Timing:  &httpaware.TimingInfo{Delay: 42 * time.Second},
+		Outcome: httpaware.OutcomeFailure,
+	}, nil
+}
+
+func TestPluginCoordinator_RegisterParserPlugin(t *testing.T) {
+	parsers := httpaware.NewParserRegistry()
+	coordinator := NewPluginCoordinatorWithParsers(parsers)
+
+	plugin := &mockParserPlugin{name: "vendor"}
+
+	// Test successful registration
+	if err := coordinator.RegisterParserPlugin(plugin); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !parsers.Has("vendor") {
+		t.Error("Expected parser plugin to be selectable from the parser registry")
+	}
+	if len(coordinator.GetParserPlugins()) != 1 {
+		t.Errorf("Expected 1 parser plugin, got %d", len(coordinator.GetParserPlugins()))
+	}
+
+	// Test duplicate registration
+	if err := coordinator.RegisterParserPlugin(plugin); err == nil {
+		t.Error("Expected error for duplicate registration, got nil")
+	}
+
+	// The registered parser is built by name
+	parser, err := parsers.New([]string{"vendor"}, httpaware.HTTPAwareConfig{}, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	result, err := parser.Parse("error: SLOW DOWN")
+	if err != nil || result == nil || result.Timing.Delay != 42*time.Second {
+		t.Errorf("Expected plugin timing of 42s, got %+v (err %v)", result, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/swi/repeater/pkg/httpaware"
 	"github.com/swi/repeater/pkg/interfaces"
 )
 
@@ -43,6 +44,15 @@ type OutputPlugin interface {
 	RequiredConfig() []string
 }
 
+// ParserPlugin interface defines the contract for response parser plugins,
+// which read throttling timing and an outcome from command output. Once
+// registered they are selectable with --aware by name.
+type ParserPlugin interface {
+	httpaware.ResponseParser
+	Version() string
+	Description() string
+}
+
 // ExecutorOptions represents options for command execution
 type ExecutorOptions struct {
 	Timeout time.Duration
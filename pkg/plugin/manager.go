@@ -259,7 +259,7 @@ func (pv *PluginValidator) ValidateManifest(manifest *PluginManifest) error {
 	}
 
 	// Validate plugin type
-	validTypes := []string{"scheduler", "executor", "output"}
+	validTypes := []string{"scheduler", "executor", "output", "parser"}
 	validType := false
 	for _, t := range validTypes {
 		if manifest.Plugin.Type == t {
@@ -10,6 +10,7 @@ type PluginRegistry struct {
 	schedulerPlugins map[string]SchedulerPlugin
 	executorPlugins  map[string]ExecutorPlugin
 	outputPlugins    map[string]OutputPlugin
+	parserPlugins    map[string]ParserPlugin
 	mu               sync.RWMutex
 }
 
@@ -19,6 +20,7 @@ func NewPluginRegistry() *PluginRegistry {
 		schedulerPlugins: make(map[string]SchedulerPlugin),
 		executorPlugins:  make(map[string]ExecutorPlugin),
 		outputPlugins:    make(map[string]OutputPlugin),
+		parserPlugins:    make(map[string]ParserPlugin),
 	}
 }
 
@@ -90,3 +92,26 @@ func (r *PluginRegistry) GetOutputPlugin(name string) (OutputPlugin, bool) {
 	plugin, exists := r.outputPlugins[name]
 	return plugin, exists
 }
+
+// RegisterParserPlugin registers a response parser plugin
+func (r *PluginRegistry) RegisterParserPlugin(name string, plugin interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	parserPlugin, ok := plugin.(ParserPlugin)
+	if !ok {
+		return fmt.Errorf("plugin does not implement ParserPlugin interface")
+	}
+
+	r.parserPlugins[name] = parserPlugin
+	return nil
+}
+
+// GetParserPlugin retrieves a response parser plugin by name
+func (r *PluginRegistry) GetParserPlugin(name string) (ParserPlugin, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	plugin, exists := r.parserPlugins[name]
+	return plugin, exists
+}
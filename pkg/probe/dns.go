@@ -0,0 +1,154 @@
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/swi/repeater/pkg/executor"
+)
+
+// DNSTypes are the record types a DNS probe can look up
+var DNSTypes = []string{"A", "AAAA", "SRV"}
+
+// DNSConfig configures a DNS probe
+type DNSConfig struct {
+	Name    string        // name to resolve
+	Server  string        // host[:port] of the DNS server; empty uses the system resolver
+	Type    string        // A (default), AAAA or SRV
+	Expect  string        // an answer that must be present; see DNSProbe
+	Timeout time.Duration // per lookup; zero leaves it to the executor timeout
+}
+
+// DNSProbe resolves a name and prints one answer per line: addresses for A
+// and AAAA, "priority weight port target" for SRV. Expect matches an address,
+// or for SRV a target with or without its port (db.internal or db.internal:5432).
+// The lookup latency is the execution duration.
+type DNSProbe struct {
+	config   DNSConfig
+	resolver *net.Resolver
+}
+
+// NewDNSProbe creates a DNS probe, validating the record type and server
+func NewDNSProbe(config DNSConfig) (*DNSProbe, error) {
+	if strings.TrimSpace(config.Name) == "" {
+		return nil, fmt.Errorf("dns probe requires a name")
+	}
+
+	config.Type = strings.ToUpper(config.Type)
+	if config.Type == "" {
+		config.Type = "A"
+	}
+	if !slices.Contains(DNSTypes, config.Type) {
+		return nil, fmt.Errorf("unsupported record type %q (supported: %s)", config.Type, strings.Join(DNSTypes, ", "))
+	}
+
+	resolver := net.DefaultResolver
+	if config.Server != "" {
+		server := config.Server
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(strings.Trim(server, "[]"), "53")
+		}
+		if _, port, _ := net.SplitHostPort(server); port == "" {
+			return nil, fmt.Errorf("invalid DNS server %q", config.Server)
+		}
+		config.Server = server
+
+		resolver = &net.Resolver{
+			PreferGo: true,
+			Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, server)
+			},
+		}
+	}
+
+	return &DNSProbe{config: config, resolver: resolver}, nil
+}
+
+// Name implements executor.Probe
+func (p *DNSProbe) Name() string {
+	return "dns"
+}
+
+// SupportsStreaming implements executor.Probe
+func (p *DNSProbe) SupportsStreaming() bool {
+	return false
+}
+
+// SupportedPlatforms implements executor.Probe
+func (p *DNSProbe) SupportedPlatforms() []string {
+	return allPlatforms
+}
+
+// Execute implements executor.Probe. A failed lookup, an empty answer or a
+// missing expected answer exits 1.
+func (p *DNSProbe) Execute(ctx context.Context, _ []string) (*executor.ExecutionResult, error) {
+	if p.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	answers, matches, err := p.lookup(ctx)
+	duration := time.Since(start)
+	phases := &executor.PhaseTiming{DNS: duration}
+	if err != nil {
+		return failed(err, duration, phases), nil
+	}
+
+	result := &executor.ExecutionResult{
+		Duration: duration,
+		Phases:   phases,
+	}
+	if len(answers) > 0 {
+		result.Stdout = strings.Join(answers, "\n") + "\n"
+	}
+
+	switch {
+	case len(answers) == 0:
+		result.ExitCode = 1
+		result.Stderr = fmt.Sprintf("no %s records for %s\n", p.config.Type, p.config.Name)
+	case p.config.Expect != "" && !slices.Contains(matches, p.config.Expect):
+		result.ExitCode = 1
+		result.Stderr = fmt.Sprintf("expected answer %q not found\n", p.config.Expect)
+	}
+	return result, nil
+}
+
+// lookup returns the printable answers and the values Expect may match
+func (p *DNSProbe) lookup(ctx context.Context) (answers, matches []string, err error) {
+	switch p.config.Type {
+	case "SRV":
+		_, records, err := p.resolver.LookupSRV(ctx, "", "", p.config.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, record := range records {
+			target := strings.TrimSuffix(record.Target, ".")
+			port := strconv.Itoa(int(record.Port))
+			answers = append(answers, fmt.Sprintf("%d %d %s %s", record.Priority, record.Weight, port, record.Target))
+			matches = append(matches, target, target+":"+port, record.Target)
+		}
+		return answers, matches, nil
+
+	default:
+		network := "ip4"
+		if p.config.Type == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := p.resolver.LookupIP(ctx, network, p.config.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+		return answers, answers, nil
+	}
+}
@@ -0,0 +1,199 @@
+package probe
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveDNS answers A queries for names under .test with the given addresses
+// and SRV queries with one record for db.internal:5432. missing.test and names
+// outside .test get NXDOMAIN. It returns the server address.
+func serveDNS(t *testing.T, addresses ...net.IP) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply := dnsReply(buf[:n], addresses); reply != nil {
+				_, _ = conn.WriteTo(reply, addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+// dnsReply builds the answer to a single-question query
+func dnsReply(query []byte, addresses []net.IP) []byte {
+	if len(query) < 12 {
+		return nil
+	}
+
+	// The question runs from byte 12 to the end of QNAME plus QTYPE and QCLASS
+	end := 12
+	var name string
+	for end < len(query) && query[end] != 0 {
+		length := int(query[end])
+		name += string(query[end+1:end+1+length]) + "."
+		end += length + 1
+	}
+	end += 5
+	if end > len(query) {
+		return nil
+	}
+	question := query[12:end]
+	qtype := binary.BigEndian.Uint16(query[end-4:])
+
+	known := strings.HasSuffix(name, ".test.") && name != "missing.test."
+
+	var answers [][]byte
+	switch {
+	case !known:
+	case qtype == 1: // A
+		for _, ip := range addresses {
+			answers = append(answers, dnsRecord(1, ip.To4()))
+		}
+	case qtype == 33: // SRV: priority 10, weight 5, port 5432, target db.internal.
+		rdata := []byte{0, 10, 0, 5, 0x15, 0x38}
+		rdata = append(rdata, 2, 'd', 'b', 8, 'i', 'n', 't', 'e', 'r', 'n', 'a', 'l', 0)
+		answers = append(answers, dnsRecord(33, rdata))
+	}
+
+	reply := make([]byte, 12, 512)
+	copy(reply, query[:2])                        // ID
+	binary.BigEndian.PutUint16(reply[2:], 0x8180) // response, recursion desired and available
+	binary.BigEndian.PutUint16(reply[4:], 1)      // QDCOUNT
+	binary.BigEndian.PutUint16(reply[6:], uint16(len(answers)))
+	if !known {
+		reply[3] |= 3 // NXDOMAIN
+	}
+	reply = append(reply, question...)
+	for _, answer := range answers {
+		reply = append(reply, answer...)
+	}
+	return reply
+}
+
+// dnsRecord encodes an answer for the name in the question
+func dnsRecord(rtype uint16, rdata []byte) []byte {
+	record := []byte{0xc0, 12} // pointer to the question name
+	record = binary.BigEndian.AppendUint16(record, rtype)
+	record = binary.BigEndian.AppendUint16(record, 1)  // IN
+	record = binary.BigEndian.AppendUint32(record, 60) // TTL
+	record = binary.BigEndian.AppendUint16(record, uint16(len(rdata)))
+	return append(record, rdata...)
+}
+
+func TestNewDNSProbe_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   DNSConfig
+		errorMsg string
+	}{
+		{name: "defaults", config: DNSConfig{Name: "example.com"}},
+		{name: "server without port", config: DNSConfig{Name: "example.com", Server: "10.0.0.2", Type: "aaaa"}},
+		{name: "ipv6 server", config: DNSConfig{Name: "example.com", Server: "::1"}},
+		{name: "missing name", config: DNSConfig{}, errorMsg: "requires a name"},
+		{name: "unsupported type", config: DNSConfig{Name: "example.com", Type: "MX"}, errorMsg: "unsupported record type"},
+		{name: "empty port", config: DNSConfig{Name: "example.com", Server: "10.0.0.2:"}, errorMsg: "invalid DNS server"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewDNSProbe(tt.config)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestDNSProbe_Execute(t *testing.T) {
+	server := serveDNS(t, net.ParseIP("10.0.0.7"), net.ParseIP("10.0.0.8"))
+
+	tests := []struct {
+		name           string
+		config         DNSConfig
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "A lookup",
+			config:         DNSConfig{Name: "api.test.", Server: server},
+			expectedStdout: "10.0.0.7\n10.0.0.8\n",
+		},
+		{
+			name:           "expected answer present",
+			config:         DNSConfig{Name: "api.test.", Server: server, Expect: "10.0.0.8"},
+			expectedStdout: "10.0.0.7\n10.0.0.8\n",
+		},
+		{
+			name:           "expected answer missing",
+			config:         DNSConfig{Name: "api.test.", Server: server, Expect: "10.0.0.9"},
+			expectedCode:   1,
+			expectedStdout: "10.0.0.7\n10.0.0.8\n",
+			expectedStderr: `expected answer "10.0.0.9" not found`,
+		},
+		{
+			name:           "SRV lookup",
+			config:         DNSConfig{Name: "_postgres._tcp.test.", Server: server, Type: "SRV", Expect: "db.internal:5432"},
+			expectedStdout: "10 5 5432 db.internal.\n",
+		},
+		{
+			name:           "no such name",
+			config:         DNSConfig{Name: "missing.test.", Server: server},
+			expectedCode:   1,
+			expectedStderr: "no such host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe, err := NewDNSProbe(tt.config)
+			require.NoError(t, err)
+
+			result, err := probe.Execute(context.Background(), nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, result.ExitCode, result.Stderr)
+			assert.Equal(t, tt.expectedStdout, result.Stdout)
+			assert.Contains(t, result.Stderr, tt.expectedStderr)
+			require.NotNil(t, result.Phases)
+			assert.Equal(t, result.Phases.DNS, result.Duration)
+		})
+	}
+}
+
+func TestDNSProbe_Timeout(t *testing.T) {
+	// A server that never answers
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	probe, err := NewDNSProbe(DNSConfig{Name: "api.test.", Server: conn.LocalAddr().String(), Timeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+
+	result, err := probe.Execute(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Less(t, result.Duration, 5*time.Second)
+}
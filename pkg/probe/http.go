@@ -0,0 +1,261 @@
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swi/repeater/pkg/executor"
+	"github.com/swi/repeater/pkg/httpaware"
+	"github.com/swi/repeater/pkg/patterns"
+)
+
+// maxBodySize caps how much of a response body an HTTP probe keeps
+const maxBodySize = 10 << 20
+
+// maxRedirects is how many redirects a following HTTP probe accepts, as curl
+const maxRedirects = 50
+
+// defaultExpectStatus is the status list an HTTP probe passes on by default:
+// anything but a client or server error, like curl --fail
+const defaultExpectStatus = "2xx,3xx"
+
+// HTTPConfig configures an HTTP probe
+type HTTPConfig struct {
+	Method       string        // e.g. GET, HEAD or POST
+	URL          string        // http or https URL
+	Headers      []string      // request headers as "Name: value"
+	Data         string        // request body
+	Timeout      time.Duration // per request; zero leaves it to the executor timeout
+	Insecure     bool          // skip TLS certificate verification
+	Follow       bool          // follow redirects
+	ExpectStatus string        // statuses that pass, e.g. "2xx,304"; see patterns.ParseStatusCodes
+}
+
+// HTTPProbe sends an HTTP request in-process. Its result carries the response
+// as curl -i would print it on stdout, the parsed response in HTTP, and the
+// DNS, connect, TLS and time-to-first-byte phases.
+type HTTPProbe struct {
+	config HTTPConfig
+	header http.Header
+	expect patterns.StatusCodes
+	client *http.Client
+}
+
+// NewHTTPProbe creates an HTTP probe, validating the request it will send
+func NewHTTPProbe(config HTTPConfig) (*HTTPProbe, error) {
+	config.Method = strings.ToUpper(strings.TrimSpace(config.Method))
+	if config.Method == "" {
+		return nil, errors.New("http probe requires a method")
+	}
+
+	target, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL %q: %w", config.URL, err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return nil, fmt.Errorf("invalid URL %q: scheme must be http or https", config.URL)
+	}
+	if target.Host == "" {
+		return nil, fmt.Errorf("invalid URL %q: missing host", config.URL)
+	}
+
+	header := make(http.Header)
+	for _, line := range config.Headers {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid header %q: expected 'Name: value'", line)
+		}
+		header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	if config.Data != "" && header.Get("Content-Type") == "" {
+		header.Set("Content-Type", "application/x-www-form-urlencoded") // as curl --data
+	}
+
+	if config.ExpectStatus == "" {
+		config.ExpectStatus = defaultExpectStatus
+	}
+	expect, err := patterns.ParseStatusCodes(config.ExpectStatus)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected status: %w", err)
+	}
+
+	transport := &http.Transport{
+		Proxy:             http.ProxyFromEnvironment,
+		TLSClientConfig:   &tls.Config{InsecureSkipVerify: config.Insecure}, // opt-in via --insecure
+		DisableKeepAlives: true,                                             // every probe measures a fresh connection
+		ForceAttemptHTTP2: true,
+	}
+	client := &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !config.Follow {
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+
+	return &HTTPProbe{
+		config: config,
+		header: header,
+		expect: expect,
+		client: client,
+	}, nil
+}
+
+// Name implements executor.Probe
+func (p *HTTPProbe) Name() string {
+	return "http"
+}
+
+// SupportsStreaming implements executor.Probe
+func (p *HTTPProbe) SupportsStreaming() bool {
+	return false
+}
+
+// SupportedPlatforms implements executor.Probe
+func (p *HTTPProbe) SupportedPlatforms() []string {
+	return allPlatforms
+}
+
+// Execute implements executor.Probe. A response with an unexpected status
+// exits 1, as does a request that fails before a response arrives.
+func (p *HTTPProbe) Execute(ctx context.Context, _ []string) (*executor.ExecutionResult, error) {
+	if p.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		defer cancel()
+	}
+
+	var body io.Reader
+	if p.config.Data != "" {
+		body = strings.NewReader(p.config.Data)
+	}
+
+	tracer := &phaseTracer{}
+	req, err := http.NewRequestWithContext(httptrace.WithClientTrace(ctx, tracer.trace()), p.config.Method, p.config.URL, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = p.header.Clone()
+	if host := p.header.Get("Host"); host != "" {
+		req.Host = host
+	}
+
+	start := time.Now()
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return failed(err, time.Since(start), tracer.phases()), nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	duration := time.Since(start)
+	if err != nil {
+		return failed(fmt.Errorf("reading response body: %w", err), duration, tracer.phases()), nil
+	}
+
+	response := &httpaware.Response{
+		Proto:      responseProto(resp),
+		StatusCode: resp.StatusCode,
+		Reason:     strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode))),
+		Header:     resp.Header,
+		Body:       string(data),
+	}
+
+	exitCode := 0
+	if !p.expect.Contains(resp.StatusCode) {
+		exitCode = 1
+	}
+
+	return &executor.ExecutionResult{
+		ExitCode: exitCode,
+		Stdout:   response.String(),
+		Duration: duration,
+		HTTP:     response,
+		Phases:   tracer.phases(),
+	}, nil
+}
+
+// responseProto returns the protocol as a status line shows it: HTTP/1.1, HTTP/2
+func responseProto(resp *http.Response) string {
+	if resp.ProtoMajor >= 2 {
+		return fmt.Sprintf("HTTP/%d", resp.ProtoMajor)
+	}
+	return fmt.Sprintf("HTTP/%d.%d", resp.ProtoMajor, resp.ProtoMinor)
+}
+
+// phaseTracer times the phases of a request through httptrace. Phases of
+// redirected requests add up; time to first byte is that of the last request.
+type phaseTracer struct {
+	mu           sync.Mutex
+	timing       executor.PhaseTiming
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	wroteRequest time.Time
+}
+
+func (t *phaseTracer) trace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.start(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.done(&t.dnsStart, &t.timing.DNS) },
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if t.connectStart.IsZero() { // dialing several addresses counts from the first
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				t.done(&t.connectStart, &t.timing.Connect)
+			}
+		},
+		TLSHandshakeStart: func() { t.start(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.done(&t.tlsStart, &t.timing.TLS) },
+		WroteRequest:      func(httptrace.WroteRequestInfo) { t.start(&t.wroteRequest) },
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if !t.wroteRequest.IsZero() {
+				t.timing.TTFB = time.Since(t.wroteRequest)
+			}
+		},
+	}
+}
+
+func (t *phaseTracer) start(at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	*at = time.Now()
+}
+
+func (t *phaseTracer) done(started *time.Time, total *time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !started.IsZero() {
+		*total += time.Since(*started)
+		*started = time.Time{}
+	}
+}
+
+func (t *phaseTracer) phases() *executor.PhaseTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timing := t.timing
+	return &timing
+}
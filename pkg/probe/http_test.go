@@ -0,0 +1,219 @@
+package probe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swi/repeater/pkg/httpaware"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"status": "ok"}`)
+	})
+	mux.HandleFunc("/throttled", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("X-Token", r.Header.Get("X-Token"))
+		w.Header().Set("X-Content-Type", r.Header.Get("Content-Type"))
+		_, _ = w.Write(body)
+	})
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/health", http.StatusFound)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestNewHTTPProbe_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   HTTPConfig
+		errorMsg string
+	}{
+		{name: "valid", config: HTTPConfig{Method: "get", URL: "https://example.com/health"}},
+		{name: "missing method", config: HTTPConfig{URL: "https://example.com"}, errorMsg: "requires a method"},
+		{name: "unsupported scheme", config: HTTPConfig{Method: "GET", URL: "ftp://example.com"}, errorMsg: "scheme must be http or https"},
+		{name: "missing host", config: HTTPConfig{Method: "GET", URL: "http://"}, errorMsg: "missing host"},
+		{name: "malformed header", config: HTTPConfig{Method: "GET", URL: "http://example.com", Headers: []string{"X-Token"}}, errorMsg: "invalid header"},
+		{name: "bad expected status", config: HTTPConfig{Method: "GET", URL: "http://example.com", ExpectStatus: "2xx,abc"}, errorMsg: "invalid expected status"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHTTPProbe(tt.config)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestHTTPProbe_Execute(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		name           string
+		config         HTTPConfig
+		expectedCode   int
+		expectedStatus int
+		check          func(t *testing.T, response *httpaware.Response)
+	}{
+		{
+			name:           "successful GET",
+			config:         HTTPConfig{Method: "GET", URL: server.URL + "/health"},
+			expectedStatus: 200,
+			check: func(t *testing.T, response *httpaware.Response) {
+				assert.Equal(t, "HTTP/1.1", response.Proto)
+				assert.Equal(t, "OK", response.Reason)
+				assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+				assert.Equal(t, `{"status": "ok"}`, response.Body)
+			},
+		},
+		{
+			name:           "error status fails by default",
+			config:         HTTPConfig{Method: "GET", URL: server.URL + "/throttled"},
+			expectedCode:   1,
+			expectedStatus: 429,
+			check: func(t *testing.T, response *httpaware.Response) {
+				assert.Equal(t, "30", response.Header.Get("Retry-After"))
+			},
+		},
+		{
+			name:           "expected status",
+			config:         HTTPConfig{Method: "GET", URL: server.URL + "/throttled", ExpectStatus: "429"},
+			expectedStatus: 429,
+		},
+		{
+			name: "method, headers and data",
+			config: HTTPConfig{
+				Method:  "post",
+				URL:     server.URL + "/echo",
+				Headers: []string{"X-Token: secret"},
+				Data:    "a=1",
+			},
+			expectedStatus: 200,
+			check: func(t *testing.T, response *httpaware.Response) {
+				assert.Equal(t, "POST", response.Header.Get("X-Method"))
+				assert.Equal(t, "secret", response.Header.Get("X-Token"))
+				assert.Equal(t, "application/x-www-form-urlencoded", response.Header.Get("X-Content-Type"))
+				assert.Equal(t, "a=1", response.Body)
+			},
+		},
+		{
+			name:           "redirect not followed",
+			config:         HTTPConfig{Method: "GET", URL: server.URL + "/moved"},
+			expectedStatus: 302,
+			check: func(t *testing.T, response *httpaware.Response) {
+				assert.Equal(t, "/health", response.Header.Get("Location"))
+			},
+		},
+		{
+			name:           "redirect followed",
+			config:         HTTPConfig{Method: "GET", URL: server.URL + "/moved", Follow: true},
+			expectedStatus: 200,
+			check: func(t *testing.T, response *httpaware.Response) {
+				assert.Equal(t, `{"status": "ok"}`, response.Body)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe, err := NewHTTPProbe(tt.config)
+			require.NoError(t, err)
+
+			result, err := probe.Execute(context.Background(), nil)
+			require.NoError(t, err)
+			require.NotNil(t, result.HTTP)
+
+			assert.Equal(t, tt.expectedCode, result.ExitCode)
+			assert.Equal(t, tt.expectedStatus, result.HTTP.StatusCode)
+			assert.Greater(t, result.Duration, time.Duration(0))
+			require.NotNil(t, result.Phases)
+			assert.Greater(t, result.Phases.Connect, time.Duration(0))
+			assert.Greater(t, result.Phases.TTFB, time.Duration(0))
+
+			// Stdout reads back as the same response
+			parsed, ok := httpaware.ParseHTTPResponse(result.Stdout)
+			require.True(t, ok)
+			assert.Equal(t, result.HTTP.StatusCode, parsed.StatusCode)
+			assert.Equal(t, result.HTTP.Body, parsed.Body)
+
+			if tt.check != nil {
+				tt.check(t, result.HTTP)
+			}
+		})
+	}
+}
+
+func TestHTTPProbe_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "secure")
+	}))
+	defer server.Close()
+
+	// The test server's certificate is self-signed
+	probe, err := NewHTTPProbe(HTTPConfig{Method: "GET", URL: server.URL})
+	require.NoError(t, err)
+	result, err := probe.Execute(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Contains(t, result.Stderr, "certificate")
+	assert.Nil(t, result.HTTP)
+
+	probe, err = NewHTTPProbe(HTTPConfig{Method: "GET", URL: server.URL, Insecure: true})
+	require.NoError(t, err)
+	result, err = probe.Execute(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, "secure", result.HTTP.Body)
+	assert.Greater(t, result.Phases.TLS, time.Duration(0))
+}
+
+func TestHTTPProbe_Failures(t *testing.T) {
+	server := newTestServer(t)
+
+	// Request timeout
+	probe, err := NewHTTPProbe(HTTPConfig{Method: "GET", URL: server.URL + "/slow", Timeout: 20 * time.Millisecond})
+	require.NoError(t, err)
+	result, err := probe.Execute(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Contains(t, result.Stderr, "deadline exceeded")
+
+	// Nothing listening
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	probe, err = NewHTTPProbe(HTTPConfig{Method: "GET", URL: closed.URL})
+	require.NoError(t, err)
+	result, err = probe.Execute(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Contains(t, result.Stderr, "connection refused")
+}
@@ -0,0 +1,23 @@
+// Package probe implements executor.Probe checks that run in-process instead
+// of shelling out to curl, nc or dig.
+package probe
+
+import (
+	"time"
+
+	"github.com/swi/repeater/pkg/executor"
+)
+
+// allPlatforms is what every probe in this package supports
+var allPlatforms = []string{"linux", "darwin", "windows"}
+
+// failed reports a check that could not complete, such as a refused connection,
+// the way a command would: exit code 1 with the error on stderr
+func failed(err error, duration time.Duration, phases *executor.PhaseTiming) *executor.ExecutionResult {
+	return &executor.ExecutionResult{
+		ExitCode: 1,
+		Stderr:   err.Error() + "\n",
+		Duration: duration,
+		Phases:   phases,
+	}
+}
@@ -0,0 +1,194 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/swi/repeater/pkg/executor"
+)
+
+// maxReadSize caps how much a socket probe reads while waiting for --expect
+const maxReadSize = 64 << 10
+
+// SocketConfig configures a TCP, UDP or Unix socket probe
+type SocketConfig struct {
+	Network string        // tcp, udp or unix
+	Address string        // host:port, or the socket path for unix
+	Send    string        // data written after connecting; Go escapes such as \r\n are interpreted
+	Expect  string        // text that must arrive before the probe passes
+	Timeout time.Duration // per probe; zero leaves it to the executor timeout
+}
+
+// SocketProbe connects to a socket, optionally sends a payload and waits for
+// an expected reply or banner. The connect latency is the execution duration;
+// whatever was read is the output.
+type SocketProbe struct {
+	config SocketConfig
+	send   []byte
+}
+
+// NewSocketProbe creates a socket probe, validating its address and payload
+func NewSocketProbe(config SocketConfig) (*SocketProbe, error) {
+	switch config.Network {
+	case "tcp", "udp":
+		host, port, err := net.SplitHostPort(config.Address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s address %q: %w", config.Network, config.Address, err)
+		}
+		if host == "" {
+			return nil, fmt.Errorf("invalid %s address %q: missing host", config.Network, config.Address)
+		}
+		if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+			return nil, fmt.Errorf("invalid %s address %q: port must be 1-65535", config.Network, config.Address)
+		}
+	case "unix":
+		if config.Address == "" {
+			return nil, errors.New("unix probe requires a socket path")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported network %q", config.Network)
+	}
+
+	send, err := unescape(config.Send)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payload %q: %w", config.Send, err)
+	}
+	expect, err := unescape(config.Expect)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expected reply %q: %w", config.Expect, err)
+	}
+	config.Expect = string(expect)
+
+	if config.Network == "udp" && len(send) == 0 && config.Expect != "" {
+		return nil, errors.New("udp probe needs a payload to get a reply")
+	}
+
+	return &SocketProbe{config: config, send: send}, nil
+}
+
+// unescape interprets Go escape sequences, so "PING\r\n" sends CR LF
+func unescape(value string) ([]byte, error) {
+	if !strings.Contains(value, `\`) {
+		return []byte(value), nil
+	}
+
+	var out []byte
+	for value != "" {
+		if value[0] == '"' { // a bare quote is just a quote
+			out = append(out, '"')
+			value = value[1:]
+			continue
+		}
+		r, multibyte, tail, err := strconv.UnquoteChar(value, '"')
+		if err != nil {
+			return nil, err
+		}
+		if r < 256 && !multibyte {
+			out = append(out, byte(r)) // \xff is a raw byte
+		} else {
+			out = utf8.AppendRune(out, r)
+		}
+		value = tail
+	}
+	return out, nil
+}
+
+// Name implements executor.Probe
+func (p *SocketProbe) Name() string {
+	return p.config.Network
+}
+
+// SupportsStreaming implements executor.Probe
+func (p *SocketProbe) SupportsStreaming() bool {
+	return false
+}
+
+// SupportedPlatforms implements executor.Probe. Unix sockets exist on Windows
+// 10 and later too.
+func (p *SocketProbe) SupportedPlatforms() []string {
+	return allPlatforms
+}
+
+// Execute implements executor.Probe. Without an expected reply the probe passes
+// once connected and the payload, if any, is written.
+func (p *SocketProbe) Execute(ctx context.Context, _ []string) (*executor.ExecutionResult, error) {
+	if p.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.config.Timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, p.config.Network, p.config.Address)
+	connect := time.Since(start)
+	phases := &executor.PhaseTiming{Connect: connect}
+	if err != nil {
+		return failed(err, connect, phases), nil
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Reads and writes end with the context
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	sent := time.Now()
+	if len(p.send) > 0 {
+		if _, err := conn.Write(p.send); err != nil {
+			return failed(fmt.Errorf("send: %w", err), connect, phases), nil
+		}
+	}
+
+	if p.config.Expect == "" {
+		return &executor.ExecutionResult{Duration: connect, Phases: phases}, nil
+	}
+
+	received, err := p.readUntilExpected(conn, sent, phases)
+	result := &executor.ExecutionResult{
+		Stdout:   string(received),
+		Duration: connect,
+		Phases:   phases,
+	}
+	if !strings.Contains(string(received), p.config.Expect) {
+		result.ExitCode = 1
+		result.Stderr = fmt.Sprintf("expected %q not received", p.config.Expect)
+		if err != nil {
+			result.Stderr += ": " + err.Error()
+		}
+		result.Stderr += "\n"
+	}
+	return result, nil
+}
+
+// readUntilExpected reads until the expected text arrives, the peer closes the
+// connection, the deadline passes or maxReadSize is reached. It records the
+// time to the first byte in phases.
+func (p *SocketProbe) readUntilExpected(conn net.Conn, sent time.Time, phases *executor.PhaseTiming) ([]byte, error) {
+	var received []byte
+	buf := make([]byte, 4096)
+	for len(received) < maxReadSize {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			if phases.TTFB == 0 {
+				phases.TTFB = time.Since(sent)
+			}
+			received = append(received, buf[:n]...)
+			if strings.Contains(string(received), p.config.Expect) {
+				return received, nil
+			}
+		}
+		if err != nil {
+			return received, err
+		}
+	}
+	return received, nil
+}
@@ -0,0 +1,206 @@
+package probe
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveStream accepts connections on listener, greets each with banner and
+// answers every line it reads with "echo: <line>"
+func serveStream(t *testing.T, listener net.Listener, banner string) {
+	t.Helper()
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = conn.Write([]byte(banner))
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					_, _ = conn.Write([]byte("echo: " + scanner.Text() + "\n"))
+				}
+			}()
+		}
+	}()
+}
+
+func TestNewSocketProbe_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   SocketConfig
+		errorMsg string
+	}{
+		{name: "tcp", config: SocketConfig{Network: "tcp", Address: "localhost:5432"}},
+		{name: "unix", config: SocketConfig{Network: "unix", Address: "/run/app.sock"}},
+		{name: "udp with payload", config: SocketConfig{Network: "udp", Address: "127.0.0.1:53", Send: `\x00\x01`, Expect: "pong"}},
+		{name: "missing port", config: SocketConfig{Network: "tcp", Address: "localhost"}, errorMsg: "invalid tcp address"},
+		{name: "missing host", config: SocketConfig{Network: "tcp", Address: ":80"}, errorMsg: "missing host"},
+		{name: "bad port", config: SocketConfig{Network: "udp", Address: "localhost:99999"}, errorMsg: "port must be 1-65535"},
+		{name: "empty unix path", config: SocketConfig{Network: "unix"}, errorMsg: "requires a socket path"},
+		{name: "bad escape", config: SocketConfig{Network: "tcp", Address: "localhost:25", Send: `\q`}, errorMsg: "invalid payload"},
+		{name: "udp expect without payload", config: SocketConfig{Network: "udp", Address: "localhost:53", Expect: "x"}, errorMsg: "needs a payload"},
+		{name: "unknown network", config: SocketConfig{Network: "sctp", Address: "localhost:1"}, errorMsg: "unsupported network"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewSocketProbe(tt.config)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestSocketProbe_Stream(t *testing.T) {
+	tcpListener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	serveStream(t, tcpListener, "220 ready\r\n")
+
+	socketPath := filepath.Join(t.TempDir(), "app.sock")
+	unixListener, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+	serveStream(t, unixListener, "hello\n")
+
+	tests := []struct {
+		name           string
+		config         SocketConfig
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:   "tcp connect only",
+			config: SocketConfig{Network: "tcp", Address: tcpListener.Addr().String()},
+		},
+		{
+			name:           "tcp banner",
+			config:         SocketConfig{Network: "tcp", Address: tcpListener.Addr().String(), Expect: "220"},
+			expectedStdout: "220 ready\r\n",
+		},
+		{
+			name:           "tcp send and expect",
+			config:         SocketConfig{Network: "tcp", Address: tcpListener.Addr().String(), Send: `PING\n`, Expect: "echo: PING"},
+			expectedStdout: "echo: PING",
+		},
+		{
+			name:           "tcp unexpected reply",
+			config:         SocketConfig{Network: "tcp", Address: tcpListener.Addr().String(), Expect: "OK", Timeout: 100 * time.Millisecond},
+			expectedCode:   1,
+			expectedStdout: "220 ready",
+			expectedStderr: `expected "OK" not received`,
+		},
+		{
+			name:           "unix send and expect",
+			config:         SocketConfig{Network: "unix", Address: socketPath, Send: "status\n", Expect: "echo: status"},
+			expectedStdout: "echo: status",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe, err := NewSocketProbe(tt.config)
+			require.NoError(t, err)
+
+			result, err := probe.Execute(context.Background(), nil)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedCode, result.ExitCode, result.Stderr)
+			assert.Contains(t, result.Stdout, tt.expectedStdout)
+			assert.Contains(t, result.Stderr, tt.expectedStderr)
+			require.NotNil(t, result.Phases)
+			assert.Equal(t, result.Phases.Connect, result.Duration)
+			assert.Greater(t, result.Duration, time.Duration(0))
+		})
+	}
+}
+
+func TestSocketProbe_ConnectFailure(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	probe, err := NewSocketProbe(SocketConfig{Network: "tcp", Address: address})
+	require.NoError(t, err)
+
+	result, err := probe.Execute(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Contains(t, result.Stderr, "connection refused")
+
+	probe, err = NewSocketProbe(SocketConfig{Network: "unix", Address: filepath.Join(t.TempDir(), "missing.sock")})
+	require.NoError(t, err)
+	result, err = probe.Execute(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ExitCode)
+}
+
+func TestSocketProbe_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo([]byte(strings.ToUpper(string(buf[:n]))), addr)
+		}
+	}()
+
+	probe, err := NewSocketProbe(SocketConfig{Network: "udp", Address: conn.LocalAddr().String(), Send: "ping", Expect: "PING"})
+	require.NoError(t, err)
+	result, err := probe.Execute(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ExitCode, result.Stderr)
+	assert.Equal(t, "PING", result.Stdout)
+
+	// No reply in time
+	probe, err = NewSocketProbe(SocketConfig{Network: "udp", Address: conn.LocalAddr().String(), Send: "ping", Expect: "pong", Timeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+	result, err = probe.Execute(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ExitCode)
+	assert.Contains(t, result.Stderr, `expected "pong" not received`)
+}
+
+func TestUnescape(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`plain`, "plain"},
+		{`PING\r\n`, "PING\r\n"},
+		{`\x00\xff`, "\x00\xff"},
+		{`say "hi"\n`, "say \"hi\"\n"},
+		{`café`, "café"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := unescape(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, []byte(tt.expected), got)
+		})
+	}
+}
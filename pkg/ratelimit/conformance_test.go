@@ -0,0 +1,181 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+var (
+	_ RateLimiter = (*DiophantineRateLimiter)(nil)
+	_ RateLimiter = (*MultiWindowRateLimiter)(nil)
+	_ RateLimiter = (*GCRARateLimiter)(nil)
+	_ RateLimiter = (*TokenBucketRateLimiter)(nil)
+	_ RateLimiter = (*SlidingLogRateLimiter)(nil)
+	_ RateLimiter = (*SlidingCounterRateLimiter)(nil)
+	_ RateLimiter = (*PreciseTokenBucket)(nil)
+
+	_ RetryReserver = (*DiophantineRateLimiter)(nil)
+	_ RetryReserver = (*MultiWindowRateLimiter)(nil)
+)
+
+var conformanceEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// exactAlgorithms never admit more than a window's limit in any period
+var exactAlgorithms = map[string]bool{
+	AlgorithmDiophantine: true,
+	AlgorithmSlidingLog:  true,
+}
+
+// saturate requests as fast as the limiter allows until the clock reaches
+// until, checking that every predicted time is honoured, and returns the
+// admission times
+func saturate(t *testing.T, limiter RateLimiter, clock *scheduler.VirtualClock, until time.Time) []time.Time {
+	t.Helper()
+
+	var admitted []time.Time
+	for clock.Now().Before(until) {
+		if limiter.Allow() {
+			admitted = append(admitted, clock.Now())
+			continue
+		}
+
+		next := limiter.NextAllowedTime()
+		require.False(t, next.Before(clock.Now()), "next allowed time is in the past")
+		if !next.Before(until) {
+			break
+		}
+		clock.Set(next)
+		require.True(t, limiter.Allow(), "request denied at its predicted time %v", next)
+		admitted = append(admitted, clock.Now())
+	}
+	return admitted
+}
+
+// maxInPeriod returns the most admissions in any half-open period-long stretch
+func maxInPeriod(times []time.Time, period time.Duration) int {
+	most, start := 0, 0
+	for end := range times {
+		for !times[end].Before(times[start].Add(period)) {
+			start++
+		}
+		most = max(most, end-start+1)
+	}
+	return most
+}
+
+func newConformanceLimiter(t *testing.T, algorithm string, windows []Window, burst int64) (RateLimiter, *scheduler.VirtualClock) {
+	t.Helper()
+	clock := scheduler.NewVirtualClock(conformanceEpoch)
+	limiter, err := NewRateLimiterWithClock(Spec{Algorithm: algorithm, Windows: windows, Burst: burst}, clock)
+	require.NoError(t, err)
+	return limiter, clock
+}
+
+func TestConformance_AdmitsInitialBurst(t *testing.T) {
+	for _, algorithm := range Algorithms {
+		t.Run(algorithm, func(t *testing.T) {
+			limiter, _ := newConformanceLimiter(t, algorithm, []Window{{5, time.Minute}}, 0)
+
+			for i := 0; i < 5; i++ {
+				assert.True(t, limiter.Allow(), "request %d within the limit", i+1)
+			}
+			assert.False(t, limiter.Allow(), "request beyond the limit")
+
+			stats := limiter.Statistics()
+			assert.Equal(t, int64(6), stats.TotalRequests)
+			assert.Equal(t, int64(5), stats.AllowedRequests)
+			assert.Equal(t, int64(1), stats.DeniedRequests)
+			assert.Equal(t, int64(5), stats.Rate)
+		})
+	}
+}
+
+func TestConformance_SustainedRate(t *testing.T) {
+	for _, algorithm := range Algorithms {
+		t.Run(algorithm, func(t *testing.T) {
+			limiter, clock := newConformanceLimiter(t, algorithm, []Window{{5, time.Minute}}, 0)
+
+			admitted := saturate(t, limiter, clock, conformanceEpoch.Add(time.Hour))
+
+			// 5/min for an hour is 300, plus at most one initial burst; the
+			// sliding counter's estimate may hold the rate somewhat lower
+			assert.LessOrEqual(t, len(admitted), 305)
+			assert.GreaterOrEqual(t, len(admitted), 240)
+			if exactAlgorithms[algorithm] {
+				assert.LessOrEqual(t, maxInPeriod(admitted, time.Minute), 5)
+			}
+
+			stats := limiter.Statistics()
+			assert.Equal(t, int64(len(admitted)), stats.AllowedRequests)
+			assert.Equal(t, stats.TotalRequests, stats.AllowedRequests+stats.DeniedRequests)
+		})
+	}
+}
+
+func TestConformance_BurstOfOneSpacesRequests(t *testing.T) {
+	for _, algorithm := range Algorithms {
+		t.Run(algorithm, func(t *testing.T) {
+			limiter, clock := newConformanceLimiter(t, algorithm, []Window{{5, time.Minute}}, 1)
+
+			admitted := saturate(t, limiter, clock, conformanceEpoch.Add(10*time.Minute))
+
+			require.Greater(t, len(admitted), 1)
+			for i := 1; i < len(admitted); i++ {
+				assert.GreaterOrEqual(t, admitted[i].Sub(admitted[i-1]), 12*time.Second,
+					"requests %d and %d too close", i, i+1)
+			}
+		})
+	}
+}
+
+func TestConformance_MultipleWindows(t *testing.T) {
+	for _, algorithm := range Algorithms {
+		t.Run(algorithm, func(t *testing.T) {
+			limiter, clock := newConformanceLimiter(t, algorithm, []Window{{2, time.Second}, {10, time.Minute}}, 0)
+
+			admitted := saturate(t, limiter, clock, conformanceEpoch.Add(10*time.Minute))
+
+			// The per-minute window governs the long run
+			assert.LessOrEqual(t, len(admitted), 110)
+			assert.GreaterOrEqual(t, len(admitted), 80)
+			if exactAlgorithms[algorithm] {
+				assert.LessOrEqual(t, maxInPeriod(admitted, time.Second), 2)
+				assert.LessOrEqual(t, maxInPeriod(admitted, time.Minute), 10)
+			}
+		})
+	}
+}
+
+func TestNewRateLimiter_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    Spec
+		wantErr string
+	}{
+		{"no windows", Spec{}, "at least one rate window"},
+		{"zero limit", Spec{Windows: []Window{{0, time.Second}}}, "must be positive"},
+		{"unknown algorithm", Spec{Algorithm: "leaky", Windows: []Window{{1, time.Second}}}, "unknown rate algorithm"},
+		{"retries outside diophantine", Spec{
+			Algorithm:    AlgorithmGCRA,
+			Windows:      []Window{{1, time.Second}},
+			RetryPattern: []time.Duration{0, time.Minute},
+		}, "retry patterns require"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRateLimiter(tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	limiter, err := NewRateLimiter(Spec{Windows: []Window{{1, time.Second}}, RetryPattern: []time.Duration{0}})
+	require.NoError(t, err)
+	assert.IsType(t, &DiophantineRateLimiter{}, limiter, "diophantine is the default")
+}
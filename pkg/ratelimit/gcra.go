@@ -0,0 +1,73 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/swi/repeater/pkg/interfaces"
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+// GCRARateLimiter implements the generic cell rate algorithm: each window
+// spaces requests one emission interval (period/limit) apart on average,
+// tolerating bursts of up to burst requests. Its whole state is one
+// theoretical arrival time per window.
+type GCRARateLimiter struct {
+	*windowedLimiter
+}
+
+// NewGCRARateLimiter creates a GCRA rate limiter. burst 0 means each window's limit.
+func NewGCRARateLimiter(windows []Window, burst int64) *GCRARateLimiter {
+	return NewGCRARateLimiterWithClock(windows, burst, scheduler.NewRealClock())
+}
+
+// NewGCRARateLimiterWithClock creates a GCRA rate limiter that reads time from
+// the given clock
+func NewGCRARateLimiterWithClock(windows []Window, burst int64, clock interfaces.Clock) *GCRARateLimiter {
+	return &GCRARateLimiter{newWindowedLimiter(windows, clock, func(w Window) windowLimiter {
+		return newGCRAWindow(w, burst)
+	})}
+}
+
+// gcraWindow is the GCRA state of one window
+type gcraWindow struct {
+	emission  time.Duration // period/limit, the sustained spacing
+	tolerance time.Duration // (burst-1) emission intervals of slack
+	tat       time.Time     // theoretical arrival time of the next request
+}
+
+func newGCRAWindow(w Window, burst int64) *gcraWindow {
+	if burst <= 0 {
+		burst = w.Limit
+	}
+	emission := w.Period / time.Duration(w.Limit)
+	return &gcraWindow{
+		emission:  emission,
+		tolerance: emission * time.Duration(burst-1),
+	}
+}
+
+func (g *gcraWindow) fits(t time.Time) bool {
+	return !g.tat.Add(-g.tolerance).After(t)
+}
+
+func (g *gcraWindow) record(t time.Time) {
+	if g.tat.Before(t) {
+		g.tat = t
+	}
+	g.tat = g.tat.Add(g.emission)
+}
+
+func (g *gcraWindow) earliestFrom(t time.Time) time.Time {
+	if earliest := g.tat.Add(-g.tolerance); earliest.After(t) {
+		return earliest
+	}
+	return t
+}
+
+func (g *gcraWindow) remaining(t time.Time) int64 {
+	tat := g.tat
+	if tat.Before(t) {
+		tat = t
+	}
+	return int64((g.tolerance + g.emission - tat.Sub(t)) / g.emission)
+}
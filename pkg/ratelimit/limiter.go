@@ -0,0 +1,317 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swi/repeater/pkg/interfaces"
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+// RateLimiter admits requests at a bounded rate
+type RateLimiter interface {
+	// Allow reports whether a request may run now, recording it if so
+	Allow() bool
+	// NextAllowedTime returns the earliest time a request would be allowed
+	NextAllowedTime() time.Time
+	// Statistics returns counts of the requests seen so far
+	Statistics() Statistics
+}
+
+// RetryReserver is a RateLimiter that reserves capacity for every attempt of
+// an admitted request's retry pattern up front
+type RetryReserver interface {
+	RateLimiter
+	// Admit is Allow, also returning the request time the attempts were reserved from
+	Admit() (time.Time, bool)
+	// RetryPattern returns the reserved attempt offsets from the request time
+	RetryPattern() []time.Duration
+	// Release frees the reserved attempts of the request admitted at
+	// requestTime, from the given attempt index on
+	Release(requestTime time.Time, fromAttempt int)
+}
+
+// Rate limiting algorithms selectable with --rate-algorithm
+const (
+	AlgorithmDiophantine    = "diophantine"
+	AlgorithmTokenBucket    = "token-bucket"
+	AlgorithmGCRA           = "gcra"
+	AlgorithmSlidingLog     = "sliding-log"
+	AlgorithmSlidingCounter = "sliding-counter"
+)
+
+// Algorithms lists the available rate limiting algorithms
+var Algorithms = []string{
+	AlgorithmDiophantine,
+	AlgorithmTokenBucket,
+	AlgorithmGCRA,
+	AlgorithmSlidingLog,
+	AlgorithmSlidingCounter,
+}
+
+// Spec describes a rate limiter to build
+type Spec struct {
+	Algorithm    string          // one of Algorithms; empty means diophantine
+	Windows      []Window        // enforced together
+	Burst        int64           // most requests admitted back to back (0 = algorithm default)
+	RetryPattern []time.Duration // retry offsets; diophantine only
+}
+
+// NewRateLimiter creates the rate limiter described by spec
+func NewRateLimiter(spec Spec) (RateLimiter, error) {
+	return NewRateLimiterWithClock(spec, scheduler.NewRealClock())
+}
+
+// NewRateLimiterWithClock creates the rate limiter described by spec, reading
+// time from the given clock.
+//
+// Burst semantics differ by algorithm. Token bucket and GCRA admit up to Burst
+// requests at once (default: each window's limit) and then one per
+// period/limit, so a window may briefly see more than its limit. The window
+// algorithms never exceed a window's limit; Burst adds a stricter window that
+// spreads the slowest window's quota evenly (see WithBurst).
+func NewRateLimiterWithClock(spec Spec, clock interfaces.Clock) (RateLimiter, error) {
+	if len(spec.Windows) == 0 {
+		return nil, fmt.Errorf("at least one rate window required")
+	}
+	for _, w := range spec.Windows {
+		if w.Limit <= 0 || w.Period <= 0 {
+			return nil, fmt.Errorf("invalid rate window %s: limit and period must be positive", w)
+		}
+	}
+	if spec.Burst < 0 {
+		return nil, fmt.Errorf("burst must not be negative, got %d", spec.Burst)
+	}
+
+	algorithm := spec.Algorithm
+	if algorithm == "" {
+		algorithm = AlgorithmDiophantine
+	}
+
+	if algorithm != AlgorithmDiophantine && hasRetries(spec.RetryPattern) {
+		return nil, fmt.Errorf("retry patterns require the %s algorithm", AlgorithmDiophantine)
+	}
+
+	switch algorithm {
+	case AlgorithmDiophantine:
+		windows := WithBurst(spec.Windows, spec.Burst)
+		if len(windows) == 1 {
+			return NewDiophantineRateLimiterWithClock(windows[0].Limit, windows[0].Period, spec.RetryPattern, clock), nil
+		}
+		return NewMultiWindowRateLimiterWithClock(windows, spec.RetryPattern, clock), nil
+	case AlgorithmTokenBucket:
+		return NewTokenBucketRateLimiterWithClock(spec.Windows, spec.Burst, clock), nil
+	case AlgorithmGCRA:
+		return NewGCRARateLimiterWithClock(spec.Windows, spec.Burst, clock), nil
+	case AlgorithmSlidingLog:
+		return NewSlidingLogRateLimiterWithClock(WithBurst(spec.Windows, spec.Burst), clock), nil
+	case AlgorithmSlidingCounter:
+		return NewSlidingCounterRateLimiterWithClock(WithBurst(spec.Windows, spec.Burst), clock), nil
+	default:
+		return nil, fmt.Errorf("unknown rate algorithm: %s (valid algorithms: %s)", spec.Algorithm, strings.Join(Algorithms, ", "))
+	}
+}
+
+// hasRetries reports whether a retry pattern schedules anything beyond the
+// initial attempt
+func hasRetries(pattern []time.Duration) bool {
+	for _, offset := range pattern {
+		if offset != 0 {
+			return true
+		}
+	}
+	return len(pattern) > 1
+}
+
+// windowLimiter is the per-window state of a rate limiter. It can tell whether
+// a request fits without recording it, so that several windows can all be
+// checked before any is charged. Callers serialize access.
+type windowLimiter interface {
+	fits(t time.Time) bool              // a request at t stays within the window
+	record(t time.Time)                 // charge a request admitted at t
+	earliestFrom(t time.Time) time.Time // earliest time at or after t that fits
+	remaining(t time.Time) int64        // requests that would fit at t
+}
+
+// windowedLimiter implements RateLimiter over one windowLimiter per window.
+// A request is admitted only if every window can take it.
+type windowedLimiter struct {
+	mu      sync.Mutex
+	windows []Window
+	cores   []windowLimiter
+	clock   interfaces.Clock
+
+	// Statistics
+	totalRequests   int64
+	allowedRequests int64
+	deniedRequests  int64
+}
+
+func newWindowedLimiter(windows []Window, clock interfaces.Clock, build func(Window) windowLimiter) *windowedLimiter {
+	cores := make([]windowLimiter, len(windows))
+	for i, w := range windows {
+		cores[i] = build(w)
+	}
+	return &windowedLimiter{windows: windows, cores: cores, clock: clock}
+}
+
+// Allow admits a request if no window would be exceeded, recording it in all of them
+func (l *windowedLimiter) Allow() bool {
+	_, ok := l.admit()
+	return ok
+}
+
+// admit is Allow, also returning the time the request was checked at
+func (l *windowedLimiter) admit() (time.Time, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.totalRequests++
+	now := l.clock.Now()
+
+	for _, core := range l.cores {
+		if !core.fits(now) {
+			l.deniedRequests++
+			return now, false
+		}
+	}
+
+	for _, core := range l.cores {
+		core.record(now)
+	}
+	l.allowedRequests++
+	return now, true
+}
+
+// NextAllowedTime returns the earliest time every window can admit a request
+func (l *windowedLimiter) NextAllowedTime() time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	candidate := l.clock.Now()
+
+	// Each window may push the candidate past a slot another window had free,
+	// so repeat until all agree
+	for round := 0; round < 100; round++ {
+		settled := true
+		for _, core := range l.cores {
+			if next := core.earliestFrom(candidate); next.After(candidate) {
+				candidate, settled = next, false
+			}
+		}
+		if settled {
+			break
+		}
+	}
+
+	return candidate
+}
+
+// Windows returns the enforced windows
+func (l *windowedLimiter) Windows() []Window {
+	return l.windows
+}
+
+// Statistics returns rate limiting statistics. Rate and capacity describe the
+// window with the lowest sustained rate.
+func (l *windowedLimiter) Statistics() Statistics {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	slowest := slowestWindow(l.windows)
+	stats := Statistics{
+		TotalRequests:   l.totalRequests,
+		AllowedRequests: l.allowedRequests,
+		DeniedRequests:  l.deniedRequests,
+		Rate:            slowest.Limit,
+		Capacity:        slowest.Limit,
+		CurrentTokens:   slowest.Limit,
+	}
+
+	now := l.clock.Now()
+	for _, core := range l.cores {
+		stats.CurrentTokens = min(stats.CurrentTokens, core.remaining(now))
+	}
+
+	return stats
+}
+
+// MultiWindowRateLimiter enforces several Diophantine windows together, e.g.
+// 10/s and 1000/h
+type MultiWindowRateLimiter struct {
+	*windowedLimiter
+	retryPattern []time.Duration
+}
+
+// NewMultiWindowRateLimiter creates a rate limiter that enforces every window
+func NewMultiWindowRateLimiter(windows []Window, retryPattern []time.Duration) *MultiWindowRateLimiter {
+	return NewMultiWindowRateLimiterWithClock(windows, retryPattern, scheduler.NewRealClock())
+}
+
+// NewMultiWindowRateLimiterWithClock creates a multi-window rate limiter that
+// reads time from the given clock
+func NewMultiWindowRateLimiterWithClock(windows []Window, retryPattern []time.Duration, clock interfaces.Clock) *MultiWindowRateLimiter {
+	if retryPattern == nil {
+		retryPattern = []time.Duration{0}
+	}
+
+	limiter := newWindowedLimiter(windows, clock, func(w Window) windowLimiter {
+		return diophantineWindow{NewDiophantineRateLimiterWithClock(w.Limit, w.Period, retryPattern, clock)}
+	})
+	return &MultiWindowRateLimiter{windowedLimiter: limiter, retryPattern: retryPattern}
+}
+
+// Admit is Allow, also returning the request time the attempts were reserved from
+func (m *MultiWindowRateLimiter) Admit() (time.Time, bool) {
+	return m.admit()
+}
+
+// RetryPattern returns the attempt offsets reserved for every admitted request
+func (m *MultiWindowRateLimiter) RetryPattern() []time.Duration {
+	return m.retryPattern
+}
+
+// Release frees the reserved attempts of the request admitted at requestTime,
+// from the given attempt index on, in every window
+func (m *MultiWindowRateLimiter) Release(requestTime time.Time, fromAttempt int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, core := range m.cores {
+		core.(diophantineWindow).d.Release(requestTime, fromAttempt)
+	}
+}
+
+// diophantineWindow adapts a DiophantineRateLimiter to windowLimiter
+type diophantineWindow struct {
+	d *DiophantineRateLimiter
+}
+
+func (w diophantineWindow) fits(t time.Time) bool {
+	w.d.mu.Lock()
+	defer w.d.mu.Unlock()
+	w.d.cleanupOldTimes(t)
+	return w.d.canScheduleAt(t)
+}
+
+func (w diophantineWindow) record(t time.Time) {
+	w.d.mu.Lock()
+	defer w.d.mu.Unlock()
+	w.d.schedule(t)
+	w.d.totalRequests++
+	w.d.allowedRequests++
+}
+
+func (w diophantineWindow) earliestFrom(t time.Time) time.Time {
+	w.d.mu.Lock()
+	defer w.d.mu.Unlock()
+	return w.d.earliestFrom(t)
+}
+
+func (w diophantineWindow) remaining(t time.Time) int64 {
+	w.d.mu.Lock()
+	defer w.d.mu.Unlock()
+	return w.d.remaining(t)
+}
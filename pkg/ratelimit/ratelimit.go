@@ -1,21 +1,22 @@
 package ratelimit
 
 import (
-	"fmt"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
+
+	"github.com/swi/repeater/pkg/interfaces"
+	"github.com/swi/repeater/pkg/scheduler"
 )
 
 // DiophantineRateLimiter implements mathematically precise rate limiting using constraint satisfaction
 // It ensures no time window exceeds the rate limit, preventing server overwhelm
 type DiophantineRateLimiter struct {
-	mu             sync.Mutex
-	rateLimit      int64           // maximum requests per window
-	windowSize     time.Duration   // time window for rate limiting
-	retryPattern   []time.Duration // retry offsets (e.g., [0, 10m, 30m])
-	scheduledTimes []time.Time     // all scheduled request times
+	mu           sync.Mutex
+	rateLimit    int64           // maximum requests per window
+	windowSize   time.Duration   // time window for rate limiting
+	retryPattern []time.Duration // retry offsets (e.g., [0, 10m, 30m])
+	timeline     attemptTimeline // every scheduled attempt, retries included
+	clock        interfaces.Clock
 
 	// Statistics
 	totalRequests   int64
@@ -32,6 +33,7 @@ type PreciseTokenBucket struct {
 	ratePerNanosec int64 // tokens per nanosecond * 1e9 for precision
 	lastUpdateNs   int64 // last update timestamp in nanoseconds
 	tokenDebtNs    int64 // accumulated fractional tokens in nanoseconds
+	clock          interfaces.Clock
 
 	// Statistics
 	totalRequests   int64
@@ -52,22 +54,35 @@ type Statistics struct {
 
 // NewDiophantineRateLimiter creates a new Diophantine constraint-based rate limiter
 func NewDiophantineRateLimiter(rateLimit int64, windowSize time.Duration, retryPattern []time.Duration) *DiophantineRateLimiter {
+	return NewDiophantineRateLimiterWithClock(rateLimit, windowSize, retryPattern, scheduler.NewRealClock())
+}
+
+// NewDiophantineRateLimiterWithClock creates a Diophantine rate limiter that reads time
+// from the given clock, so admission can be simulated on a virtual clock
+func NewDiophantineRateLimiterWithClock(rateLimit int64, windowSize time.Duration, retryPattern []time.Duration, clock interfaces.Clock) *DiophantineRateLimiter {
 	if retryPattern == nil {
 		retryPattern = []time.Duration{0} // Default: single attempt, no retries
 	}
 
 	return &DiophantineRateLimiter{
-		rateLimit:      rateLimit,
-		windowSize:     windowSize,
-		retryPattern:   retryPattern,
-		scheduledTimes: make([]time.Time, 0),
+		rateLimit:    rateLimit,
+		windowSize:   windowSize,
+		retryPattern: retryPattern,
+		clock:        clock,
 	}
 }
 
 // NewPreciseTokenBucket creates a new precise token bucket rate limiter
 // DEPRECATED: Use NewDiophantineRateLimiter for server-friendly rate limiting
 func NewPreciseTokenBucket(rate, capacity int64) *PreciseTokenBucket {
-	now := time.Now().UnixNano()
+	return NewPreciseTokenBucketWithClock(rate, capacity, scheduler.NewRealClock())
+}
+
+// NewPreciseTokenBucketWithClock creates a precise token bucket that refills
+// according to the given clock
+// DEPRECATED: Use NewDiophantineRateLimiterWithClock for server-friendly rate limiting
+func NewPreciseTokenBucketWithClock(rate, capacity int64, clock interfaces.Clock) *PreciseTokenBucket {
+	now := clock.Now().UnixNano()
 
 	return &PreciseTokenBucket{
 		capacityTokens: capacity,
@@ -76,127 +91,119 @@ func NewPreciseTokenBucket(rate, capacity int64) *PreciseTokenBucket {
 		lastUpdateNs:   now,
 		tokenDebtNs:    0,
 		rate:           rate,
+		clock:          clock,
 	}
 }
 
 // Allow checks if a request can be scheduled without violating any time window constraints
 func (d *DiophantineRateLimiter) Allow() bool {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-
-	d.totalRequests++
-
-	now := time.Now()
-
-	// Clean up old scheduled times that are outside any relevant window
-	d.cleanupOldTimes(now)
+	_, ok := d.Admit()
+	return ok
+}
 
-	// Check if we can safely schedule this request
-	if d.canScheduleAt(now) {
-		d.scheduledTimes = append(d.scheduledTimes, now)
-		d.allowedRequests++
-		return true
+// attemptTimes returns the attempt times, in Unix nanoseconds, of a request at t
+func (d *DiophantineRateLimiter) attemptTimes(t time.Time) []int64 {
+	attempts := make([]int64, len(d.retryPattern))
+	for i, offset := range d.retryPattern {
+		attempts[i] = t.Add(offset).UnixNano()
 	}
-
-	d.deniedRequests++
-	return false
+	return attempts
 }
 
-// canScheduleAt checks if a request can be scheduled at the given time without violating rate limits
+// canScheduleAt checks if a request can be scheduled at the given time without
+// violating rate limits. The timeline already satisfies every window, so only
+// windows holding one of the new attempts need checking: those starting at an
+// existing attempt up to a window before each new one, and those starting at
+// the new attempts themselves. Each check is O(log n).
 func (d *DiophantineRateLimiter) canScheduleAt(requestTime time.Time) bool {
-	// Count how many requests would be in the window starting at requestTime
-	// This is the critical window that determines if we can schedule this request
+	window := int64(d.windowSize)
+	attempts := d.attemptTimes(requestTime)
 
-	windowStart := requestTime
-	windowEnd := requestTime.Add(d.windowSize)
+	// Tentatively count the new attempts in the windows of existing ones
+	for _, at := range attempts {
+		d.timeline.addRange(at-window, at, 1)
+	}
+	defer func() {
+		for _, at := range attempts {
+			d.timeline.addRange(at-window, at, -1)
+		}
+	}()
 
-	count := int64(0)
+	for _, at := range attempts {
+		if d.timeline.maxRange(at-window, at) > d.rateLimit {
+			return false
+		}
 
-	// Count existing scheduled requests that would have attempts in this window
-	for _, scheduledTime := range d.scheduledTimes {
-		for _, offset := range d.retryPattern {
-			attemptTime := scheduledTime.Add(offset)
-			if !attemptTime.Before(windowStart) && attemptTime.Before(windowEnd) {
+		// The window starting at this new attempt
+		count := d.timeline.countBetween(at, at+window)
+		for _, other := range attempts {
+			if other >= at && other < at+window {
 				count++
 			}
 		}
-	}
-
-	// Count attempts from this new request that would be in this window
-	for _, offset := range d.retryPattern {
-		attemptTime := requestTime.Add(offset)
-		if !attemptTime.Before(windowStart) && attemptTime.Before(windowEnd) {
-			count++
+		if count > d.rateLimit {
+			return false
 		}
 	}
 
-	// If this window would exceed the limit, reject the request
-	if count > d.rateLimit {
-		return false
+	return true
+}
+
+// schedule records every attempt of a request admitted at t
+func (d *DiophantineRateLimiter) schedule(t time.Time) {
+	window := int64(d.windowSize)
+	for _, at := range d.attemptTimes(t) {
+		d.timeline.addRange(at-window, at, 1)
+		d.timeline.insert(at, d.timeline.countBetween(at, at+window)+1)
 	}
+}
 
-	// Also need to check if this new request would cause any existing windows to be violated
-	// Check windows that start before requestTime but could include our new attempts
-	for _, scheduledTime := range d.scheduledTimes {
-		for _, existingOffset := range d.retryPattern {
-			existingAttemptTime := scheduledTime.Add(existingOffset)
-			// Check window starting at this existing attempt
-			existingWindowStart := existingAttemptTime
-			existingWindowEnd := existingAttemptTime.Add(d.windowSize)
-
-			// Count all attempts in this existing window (including our new ones)
-			existingWindowCount := int64(0)
-
-			// Count existing attempts in this window
-			for _, otherScheduledTime := range d.scheduledTimes {
-				for _, otherOffset := range d.retryPattern {
-					otherAttemptTime := otherScheduledTime.Add(otherOffset)
-					if !otherAttemptTime.Before(existingWindowStart) && otherAttemptTime.Before(existingWindowEnd) {
-						existingWindowCount++
-					}
-				}
-			}
+// Admit is Allow, also returning the request time the attempts were reserved from
+func (d *DiophantineRateLimiter) Admit() (time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 
-			// Count new attempts that would fall in this existing window
-			for _, newOffset := range d.retryPattern {
-				newAttemptTime := requestTime.Add(newOffset)
-				if !newAttemptTime.Before(existingWindowStart) && newAttemptTime.Before(existingWindowEnd) {
-					existingWindowCount++
-				}
-			}
+	d.totalRequests++
 
-			if existingWindowCount > d.rateLimit {
-				return false
-			}
-		}
+	now := d.clock.Now()
+	d.cleanupOldTimes(now)
+
+	if d.canScheduleAt(now) {
+		d.schedule(now)
+		d.allowedRequests++
+		return now, true
 	}
 
-	return true
+	d.deniedRequests++
+	return now, false
 }
 
-// cleanupOldTimes removes scheduled times that are outside any relevant window
-func (d *DiophantineRateLimiter) cleanupOldTimes(now time.Time) {
-	cutoff := now.Add(-d.windowSize - d.maxRetryOffset())
+// RetryPattern returns the attempt offsets reserved for every admitted request
+func (d *DiophantineRateLimiter) RetryPattern() []time.Duration {
+	return d.retryPattern
+}
+
+// Release frees the reserved attempts of the request admitted at requestTime,
+// from the given attempt index on, so other requests can use their capacity
+func (d *DiophantineRateLimiter) Release(requestTime time.Time, fromAttempt int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 
-	// Keep only times that could still affect future windows
-	filtered := d.scheduledTimes[:0]
-	for _, t := range d.scheduledTimes {
-		if t.After(cutoff) {
-			filtered = append(filtered, t)
+	// Attempts already cleaned up are gone from the timeline and skipped
+	window := int64(d.windowSize)
+	attempts := d.attemptTimes(requestTime)
+	for i := max(fromAttempt, 0); i < len(attempts); i++ {
+		at := attempts[i]
+		if d.timeline.remove(at) {
+			d.timeline.addRange(at-window, at, -1)
 		}
 	}
-	d.scheduledTimes = filtered
 }
 
-// maxRetryOffset returns the maximum retry offset
-func (d *DiophantineRateLimiter) maxRetryOffset() time.Duration {
-	max := time.Duration(0)
-	for _, offset := range d.retryPattern {
-		if offset > max {
-			max = offset
-		}
-	}
-	return max
+// cleanupOldTimes removes attempts whose windows end by now: no window they
+// share can hold a request scheduled from now on
+func (d *DiophantineRateLimiter) cleanupOldTimes(now time.Time) {
+	d.timeline.removeThrough(now.Add(-d.windowSize).UnixNano())
 }
 
 // NextAllowedTime calculates the earliest time a request can be safely scheduled
@@ -204,22 +211,60 @@ func (d *DiophantineRateLimiter) NextAllowedTime() time.Time {
 	d.mu.Lock()
 	defer d.mu.Unlock()
 
-	now := time.Now()
+	now := d.clock.Now()
 	d.cleanupOldTimes(now)
+	return d.earliestFrom(now)
+}
+
+// earliestFrom returns the earliest time at or after from at which a request
+// can be safely scheduled. The caller must hold d.mu.
+//
+// Whether a request fits only changes when one of its attempts crosses an
+// existing attempt or the edge of an existing attempt's window, so the
+// candidates are visited in order instead of stepping through time.
+func (d *DiophantineRateLimiter) earliestFrom(from time.Time) time.Time {
+	window := int64(d.windowSize)
+	base := from.UnixNano()
+	candidate := base
+
+	for {
+		at := from.Add(time.Duration(candidate - base))
+		if d.canScheduleAt(at) {
+			return at
+		}
 
-	// Try scheduling at increasingly later times until we find a safe slot
-	candidate := now
-	increment := time.Second // Start with 1-second increments
+		next, ok := d.nextChange(candidate, window)
+		if !ok {
+			// The retry pattern alone overflows a window; it can never fit
+			return from.Add(d.windowSize)
+		}
+		candidate = next
+	}
+}
 
-	for attempts := 0; attempts < 3600; attempts++ { // Max 1 hour ahead
-		if d.canScheduleAt(candidate) {
-			return candidate
+// nextChange returns the first request time after candidate at which an
+// attempt enters or leaves a window it shares with an existing attempt
+func (d *DiophantineRateLimiter) nextChange(candidate, window int64) (int64, bool) {
+	var next int64
+	found := false
+
+	for _, offset := range d.retryPattern {
+		at := candidate + int64(offset)
+		// An attempt at x shares the window of an existing attempt a while
+		// a <= x < a+window, and its own window holds a while a-window < x <= a
+		for _, shift := range []int64{0, window, 1 - window, 1} {
+			existing, ok := d.timeline.after(at - shift)
+			if !ok {
+				continue
+			}
+			change := existing + shift - int64(offset)
+			if !found || change < next {
+				next, found = change, true
+			}
 		}
-		candidate = candidate.Add(increment)
 	}
 
-	// If we can't find a slot within an hour, return far future
-	return now.Add(time.Hour)
+	return next, found
 }
 
 // Statistics returns current rate limiting statistics for Diophantine limiter
@@ -232,11 +277,21 @@ func (d *DiophantineRateLimiter) Statistics() Statistics {
 		AllowedRequests: d.allowedRequests,
 		DeniedRequests:  d.deniedRequests,
 		Rate:            d.rateLimit,
-		Capacity:        d.rateLimit,                                // For compatibility
-		CurrentTokens:   d.rateLimit - int64(len(d.scheduledTimes)), // Approximate
+		Capacity:        d.rateLimit, // For compatibility
+		CurrentTokens:   d.remaining(d.clock.Now()),
 	}
 }
 
+// remaining returns how many more attempts the window ending at now could take
+func (d *DiophantineRateLimiter) remaining(now time.Time) int64 {
+	end := now.UnixNano() + 1
+	used := d.timeline.countBetween(end-int64(d.windowSize), end)
+	if used >= d.rateLimit {
+		return 0
+	}
+	return d.rateLimit - used
+}
+
 // Allow checks if a request should be allowed and consumes a token if so
 // DEPRECATED: Use DiophantineRateLimiter.Allow() for server-friendly rate limiting
 func (tb *PreciseTokenBucket) Allow() bool {
@@ -245,7 +300,7 @@ func (tb *PreciseTokenBucket) Allow() bool {
 
 	tb.totalRequests++
 
-	now := time.Now().UnixNano()
+	now := tb.clock.Now().UnixNano()
 	tb.addTokens(now)
 
 	if tb.currentTokens >= 1 {
@@ -258,6 +313,27 @@ func (tb *PreciseTokenBucket) Allow() bool {
 	return false
 }
 
+// NextAllowedTime returns when the bucket next holds a whole token
+// DEPRECATED: Use NewTokenBucketRateLimiter for rates other than per second
+func (tb *PreciseTokenBucket) NextAllowedTime() time.Time {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	now := tb.clock.Now()
+	tb.addTokens(now.UnixNano())
+
+	if tb.currentTokens >= 1 {
+		return now
+	}
+	if tb.ratePerNanosec <= 0 {
+		return now.Add(time.Hour) // never refills; check back later
+	}
+
+	// Time for the fractional remainder to reach a whole token, rounded up
+	missing := int64(1e9) - tb.tokenDebtNs
+	return now.Add(time.Duration((missing + tb.ratePerNanosec - 1) / tb.ratePerNanosec))
+}
+
 // addTokens adds tokens based on elapsed time using precise integer arithmetic
 func (tb *PreciseTokenBucket) addTokens(now int64) {
 	if now <= tb.lastUpdateNs {
@@ -289,7 +365,7 @@ func (tb *PreciseTokenBucket) Statistics() Statistics {
 	defer tb.mu.Unlock()
 
 	// Update tokens before reporting
-	now := time.Now().UnixNano()
+	now := tb.clock.Now().UnixNano()
 	tb.addTokens(now)
 
 	return Statistics{
@@ -332,39 +408,6 @@ func (drl *DistributedRateLimiter) Allow() bool {
 	return drl.localLimiter.Allow()
 }
 
-// ParseRateSpec parses rate specifications like "10/1s", "100/1m", "1000/1h"
-func ParseRateSpec(spec string) (rate int64, period time.Duration, err error) {
-	parts := strings.Split(spec, "/")
-	if len(parts) != 2 {
-		return 0, 0, fmt.Errorf("invalid rate spec format: %s (expected format: rate/period)", spec)
-	}
-
-	rateStr := strings.TrimSpace(parts[0])
-	periodStr := strings.TrimSpace(parts[1])
-
-	if rateStr == "" {
-		return 0, 0, fmt.Errorf("missing rate in spec: %s", spec)
-	}
-
-	if periodStr == "" {
-		return 0, 0, fmt.Errorf("missing period in spec: %s", spec)
-	}
-
-	// Parse rate
-	rate, err = strconv.ParseInt(rateStr, 10, 64)
-	if err != nil {
-		return 0, 0, fmt.Errorf("invalid rate '%s': %w", rateStr, err)
-	}
-
-	// Parse period
-	period, err = time.ParseDuration(periodStr)
-	if err != nil {
-		return 0, 0, fmt.Errorf("invalid period '%s': %w", periodStr, err)
-	}
-
-	return rate, period, nil
-}
-
 // min returns the minimum of two int64 values
 func min(a, b int64) int64 {
 	if a < b {
@@ -1,11 +1,15 @@
 package ratelimit
 
 import (
+	"fmt"
+	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/swi/repeater/pkg/scheduler"
 )
 
 // TestDiophantineRateLimiter_BasicRateLimit tests basic Diophantine rate limiting
@@ -79,6 +83,34 @@ func TestDiophantineRateLimiter_RetryPatterns(t *testing.T) {
 	assert.GreaterOrEqual(t, allowed, 1, "should allow at least 1 task")
 }
 
+// TestDiophantineRateLimiter_Release tests that released retries free capacity
+func TestDiophantineRateLimiter_Release(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+	retryPattern := []time.Duration{0, 10 * time.Minute, 30 * time.Minute}
+	limiter := NewDiophantineRateLimiterWithClock(6, time.Hour, retryPattern, clock)
+
+	first, ok := limiter.Admit()
+	require.True(t, ok)
+	assert.Equal(t, start, first)
+	clock.Advance(time.Minute)
+	_, ok = limiter.Admit()
+	require.True(t, ok)
+	_, ok = limiter.Admit()
+	assert.False(t, ok, "two requests reserve all six attempts")
+
+	// The first request succeeded on its first attempt
+	limiter.Release(first, 1)
+	assert.False(t, limiter.Allow(), "two free attempts are not enough for three")
+
+	limiter.Release(first, 0)
+	assert.True(t, limiter.Allow(), "three free attempts fit another request")
+
+	// Releasing again finds nothing left to free
+	limiter.Release(first, 0)
+	assert.False(t, limiter.Allow())
+}
+
 // TestDiophantineRateLimiter_WindowConstraints tests window-based constraints
 func TestDiophantineRateLimiter_WindowConstraints(t *testing.T) {
 	// 5 requests per 10 seconds
@@ -371,3 +403,234 @@ func TestRateLimitParser_ParseRateSpec(t *testing.T) {
 		})
 	}
 }
+
+// TestDiophantineRateLimiter_VirtualClock tests the limiter against an injected clock
+func TestDiophantineRateLimiter_VirtualClock(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+	limiter := NewDiophantineRateLimiterWithClock(2, time.Minute, nil, clock)
+
+	assert.True(t, limiter.Allow())
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+
+	next := limiter.NextAllowedTime()
+	assert.True(t, next.After(start))
+	assert.False(t, next.After(start.Add(time.Minute)))
+
+	clock.Set(next)
+	assert.True(t, limiter.Allow(), "request should be admitted at the predicted time")
+}
+
+// TestPreciseTokenBucket_VirtualClock tests token refill against an injected clock
+func TestPreciseTokenBucket_VirtualClock(t *testing.T) {
+	clock := scheduler.NewVirtualClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	bucket := NewPreciseTokenBucketWithClock(1, 2, clock)
+
+	assert.True(t, bucket.Allow())
+	assert.True(t, bucket.Allow())
+	assert.False(t, bucket.Allow(), "bucket should be empty")
+
+	clock.Advance(999 * time.Millisecond)
+	assert.False(t, bucket.Allow(), "no token before a full second")
+
+	clock.Advance(time.Millisecond)
+	assert.True(t, bucket.Allow(), "one token after a second")
+
+	// An hour of idle time only refills up to capacity
+	clock.Advance(time.Hour)
+	assert.Equal(t, int64(2), bucket.Statistics().CurrentTokens)
+}
+
+// TestParseRateWindows tests multi-window and human-friendly rate specs
+func TestParseRateWindows(t *testing.T) {
+	tests := []struct {
+		spec        string
+		expect      []Window
+		expectError bool
+	}{
+		{"10/1s", []Window{{10, time.Second}}, false},
+		{"100/hour", []Window{{100, time.Hour}}, false},
+		{"5 per minute", []Window{{5, time.Minute}}, false},
+		{"5 PER 2 hours", []Window{{5, 2 * time.Hour}}, false},
+		{"10/s, 1000/h, 10000/day", []Window{{10, time.Second}, {1000, time.Hour}, {10000, 24 * time.Hour}}, false},
+		{"3/1d", []Window{{3, 24 * time.Hour}}, false},
+		{"10/s,", nil, true},
+		{"10/fortnight", nil, true},
+		{"0/1s", nil, true},
+		{"10/0s", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			windows, err := ParseRateWindows(tt.spec)
+			if tt.expectError {
+				assert.Error(t, err, "expected error for spec: %s", tt.spec)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expect, windows)
+		})
+	}
+
+	_, _, err := ParseRateSpec("10/s,20/m")
+	assert.Error(t, err, "ParseRateSpec takes a single window")
+}
+
+// TestWithBurst tests that a burst limit spreads the slowest window
+func TestWithBurst(t *testing.T) {
+	windows := []Window{{10, time.Second}, {1000, time.Hour}}
+
+	burst := WithBurst(windows, 5)
+	require.Len(t, burst, 3)
+	assert.Equal(t, Window{5, 18 * time.Second}, burst[2], "1000/h in bursts of 5 is 5 per 18s")
+	assert.Len(t, windows, 2, "input windows are not modified")
+
+	assert.Equal(t, windows, WithBurst(windows, 0))
+	assert.Equal(t, windows, WithBurst(windows, 1000), "a burst of the whole window adds nothing")
+}
+
+// TestMultiWindowRateLimiter tests that every window is enforced together
+func TestMultiWindowRateLimiter(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+	limiter := NewMultiWindowRateLimiterWithClock([]Window{{2, time.Second}, {3, time.Minute}}, nil, clock)
+
+	// The per-second window stops the third request
+	assert.True(t, limiter.Allow())
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+
+	// The per-minute window stops the fourth, though the second has passed
+	clock.Advance(time.Second)
+	assert.True(t, limiter.Allow())
+	assert.False(t, limiter.Allow())
+
+	next := limiter.NextAllowedTime()
+	assert.False(t, next.Before(start.Add(time.Minute)), "next slot waits for the minute window")
+
+	clock.Set(next)
+	assert.True(t, limiter.Allow(), "request should be admitted at the predicted time")
+
+	stats := limiter.Statistics()
+	assert.Equal(t, int64(6), stats.TotalRequests)
+	assert.Equal(t, int64(4), stats.AllowedRequests)
+	assert.Equal(t, int64(3), stats.Rate, "rate reports the slowest window")
+}
+
+// newFullDiophantine returns a limiter for limit attempts a day with two
+// retries per request, saturated at the current time
+func newFullDiophantine(limit int64) (*DiophantineRateLimiter, *scheduler.VirtualClock) {
+	clock := scheduler.NewVirtualClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	pattern := []time.Duration{0, 10 * time.Minute, 30 * time.Minute}
+	limiter := NewDiophantineRateLimiterWithClock(limit, 24*time.Hour, pattern, clock)
+
+	// Spread requests across the day so the timeline holds a full window
+	spacing := 24 * time.Hour / time.Duration(limit)
+	for limiter.Allow() {
+		clock.Advance(3 * spacing)
+	}
+	return limiter, clock
+}
+
+// BenchmarkDiophantineRateLimiter_AllowDenied measures admission checks
+// against a full window
+func BenchmarkDiophantineRateLimiter_AllowDenied(b *testing.B) {
+	for _, limit := range []int64{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			limiter, _ := newFullDiophantine(limit)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				limiter.Allow()
+			}
+		})
+	}
+}
+
+// BenchmarkDiophantineRateLimiter_Steady measures a saturated request stream:
+// finding the next allowed time and admitting a request there
+func BenchmarkDiophantineRateLimiter_Steady(b *testing.B) {
+	for _, limit := range []int64{100, 1000, 10000} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			limiter, clock := newFullDiophantine(limit)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				clock.Set(limiter.NextAllowedTime())
+				if !limiter.Allow() {
+					b.Fatal("request denied at its next allowed time")
+				}
+			}
+		})
+	}
+}
+
+// bruteForceFits reports whether a request at t keeps every window of the
+// given attempts within limit, checking the window at every attempt
+func bruteForceFits(attempts []time.Time, t time.Time, pattern []time.Duration, limit int64, window time.Duration) bool {
+	all := append([]time.Time(nil), attempts...)
+	for _, offset := range pattern {
+		all = append(all, t.Add(offset))
+	}
+
+	for _, start := range all {
+		var count int64
+		for _, at := range all {
+			if !at.Before(start) && at.Before(start.Add(window)) {
+				count++
+			}
+		}
+		if count > limit {
+			return false
+		}
+	}
+	return true
+}
+
+// TestDiophantineRateLimiter_MatchesBruteForce checks admissions, releases and
+// next allowed times against an exhaustive check of every window
+func TestDiophantineRateLimiter_MatchesBruteForce(t *testing.T) {
+	patterns := [][]time.Duration{
+		{0},
+		{0, 10 * time.Second, 30 * time.Second},
+		{0, 45 * time.Second, 2 * time.Minute},
+	}
+
+	for _, pattern := range patterns {
+		t.Run(fmt.Sprint(pattern), func(t *testing.T) {
+			start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			clock := scheduler.NewVirtualClock(start)
+			limiter := NewDiophantineRateLimiterWithClock(4, time.Minute, pattern, clock)
+
+			var attempts []time.Time
+			rng := rand.New(rand.NewSource(1))
+			for i := 0; i < 200; i++ {
+				clock.Advance(time.Duration(rng.Intn(20)) * time.Second)
+				now := clock.Now()
+
+				want := bruteForceFits(attempts, now, pattern, 4, time.Minute)
+				require.Equal(t, want, limiter.Allow(), "admission at %v", now.Sub(start))
+				if want {
+					// Some requests succeed at once and release their retries
+					reserved := pattern
+					if rng.Intn(3) == 0 {
+						limiter.Release(now, 1)
+						reserved = pattern[:1]
+					}
+					for _, offset := range reserved {
+						attempts = append(attempts, now.Add(offset))
+					}
+					continue
+				}
+
+				// Every whole second before the next allowed time is refused
+				next := limiter.NextAllowedTime()
+				require.True(t, bruteForceFits(attempts, next, pattern, 4, time.Minute), "next allowed time %v does not fit", next.Sub(start))
+				for at := now; at.Before(next); at = at.Add(time.Second) {
+					require.False(t, bruteForceFits(attempts, at, pattern, 4, time.Minute), "%v fits before next allowed time %v", at.Sub(start), next.Sub(start))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+
+	"github.com/swi/repeater/pkg/interfaces"
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+// SlidingCounterRateLimiter implements the sliding window counter algorithm:
+// each window keeps only the counts of the current and previous fixed periods
+// and estimates the sliding count by weighting the previous period by how much
+// of it still overlaps. Memory is constant; the estimate assumes requests were
+// spread evenly, so the admitted rate can fall slightly below the limit.
+type SlidingCounterRateLimiter struct {
+	*windowedLimiter
+}
+
+// NewSlidingCounterRateLimiter creates a sliding window counter rate limiter
+func NewSlidingCounterRateLimiter(windows []Window) *SlidingCounterRateLimiter {
+	return NewSlidingCounterRateLimiterWithClock(windows, scheduler.NewRealClock())
+}
+
+// NewSlidingCounterRateLimiterWithClock creates a sliding window counter rate
+// limiter that reads time from the given clock
+func NewSlidingCounterRateLimiterWithClock(windows []Window, clock interfaces.Clock) *SlidingCounterRateLimiter {
+	return &SlidingCounterRateLimiter{newWindowedLimiter(windows, clock, func(w Window) windowLimiter {
+		return newSlidingCounterWindow(w)
+	})}
+}
+
+// slidingCounterWindow counts requests in fixed periods aligned to the Unix epoch
+type slidingCounterWindow struct {
+	window   Window
+	index    int64 // fixed period the current count belongs to
+	current  int64
+	previous int64
+}
+
+func newSlidingCounterWindow(w Window) *slidingCounterWindow {
+	return &slidingCounterWindow{window: w}
+}
+
+// countsAt returns the fixed period containing t with the previous and current
+// counts as they would be at t
+func (s *slidingCounterWindow) countsAt(t time.Time) (index, previous, current int64) {
+	index = t.UnixNano() / int64(s.window.Period)
+	switch index {
+	case s.index:
+		return index, s.previous, s.current
+	case s.index + 1:
+		return index, s.current, 0
+	default:
+		return index, 0, 0
+	}
+}
+
+// estimate returns the weighted sliding count at t
+func (s *slidingCounterWindow) estimate(t time.Time) float64 {
+	index, previous, current := s.countsAt(t)
+	elapsed := t.UnixNano() - index*int64(s.window.Period)
+	overlap := 1 - float64(elapsed)/float64(s.window.Period)
+	return float64(previous)*overlap + float64(current)
+}
+
+func (s *slidingCounterWindow) fits(t time.Time) bool {
+	return s.estimate(t)+1 <= float64(s.window.Limit)
+}
+
+func (s *slidingCounterWindow) record(t time.Time) {
+	s.index, s.previous, s.current = s.countsAt(t)
+	s.current++
+}
+
+func (s *slidingCounterWindow) earliestFrom(t time.Time) time.Time {
+	period := int64(s.window.Period)
+	candidate := t
+
+	// The answer lies in the candidate's fixed period or, once the current
+	// count is full, in one of the next two
+	for range 3 {
+		if s.fits(candidate) {
+			return candidate
+		}
+
+		index, previous, current := s.countsAt(candidate)
+		start := index * period
+		free := s.window.Limit - current - 1
+		if free >= 0 && previous > 0 {
+			// previous*(1-elapsed/period) + current + 1 <= limit
+			elapsed := int64(math.Ceil(float64(period) * (1 - float64(free)/float64(previous))))
+			if at := time.Unix(0, start+elapsed); at.After(candidate) && s.fits(at) {
+				return at
+			}
+		}
+		candidate = time.Unix(0, start+period)
+	}
+
+	return candidate
+}
+
+func (s *slidingCounterWindow) remaining(t time.Time) int64 {
+	return max(0, int64(float64(s.window.Limit)-s.estimate(t)))
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/swi/repeater/pkg/interfaces"
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+// SlidingLogRateLimiter implements the sliding window log algorithm: it keeps
+// the time of every request admitted within the last period of each window, so
+// no period-long stretch of time ever holds more than limit requests. Memory
+// grows with the limit.
+type SlidingLogRateLimiter struct {
+	*windowedLimiter
+}
+
+// NewSlidingLogRateLimiter creates a sliding window log rate limiter
+func NewSlidingLogRateLimiter(windows []Window) *SlidingLogRateLimiter {
+	return NewSlidingLogRateLimiterWithClock(windows, scheduler.NewRealClock())
+}
+
+// NewSlidingLogRateLimiterWithClock creates a sliding window log rate limiter
+// that reads time from the given clock
+func NewSlidingLogRateLimiterWithClock(windows []Window, clock interfaces.Clock) *SlidingLogRateLimiter {
+	return &SlidingLogRateLimiter{newWindowedLimiter(windows, clock, func(w Window) windowLimiter {
+		return newSlidingLogWindow(w)
+	})}
+}
+
+// slidingLogWindow is the request log of one window, oldest first
+type slidingLogWindow struct {
+	window Window
+	log    []time.Time
+}
+
+func newSlidingLogWindow(w Window) *slidingLogWindow {
+	return &slidingLogWindow{window: w}
+}
+
+// inWindow returns the logged requests within the period ending at t
+func (s *slidingLogWindow) inWindow(t time.Time) []time.Time {
+	cutoff := t.Add(-s.window.Period)
+	for i, at := range s.log {
+		if at.After(cutoff) {
+			return s.log[i:]
+		}
+	}
+	return nil
+}
+
+func (s *slidingLogWindow) fits(t time.Time) bool {
+	// Entries that can no longer count against any request are dropped
+	s.log = s.inWindow(t)
+	return int64(len(s.log)) < s.window.Limit
+}
+
+func (s *slidingLogWindow) record(t time.Time) {
+	s.log = append(s.log, t)
+}
+
+func (s *slidingLogWindow) earliestFrom(t time.Time) time.Time {
+	recent := s.inWindow(t)
+	excess := int64(len(recent)) - s.window.Limit
+	if excess < 0 {
+		return t
+	}
+	// Wait until enough of the oldest entries have left the window
+	return recent[excess].Add(s.window.Period)
+}
+
+func (s *slidingLogWindow) remaining(t time.Time) int64 {
+	return max(0, s.window.Limit-int64(len(s.inWindow(t))))
+}
@@ -0,0 +1,168 @@
+package ratelimit
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is one rate limit: at most Limit requests in any Period
+type Window struct {
+	Limit  int64
+	Period time.Duration
+}
+
+// String formats the window as a rate spec, e.g. "10/1s"
+func (w Window) String() string {
+	return fmt.Sprintf("%d/%v", w.Limit, w.Period)
+}
+
+// periodUnits maps the unit words accepted in rate specs to durations
+var periodUnits = map[string]time.Duration{
+	"ms": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+	"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+	"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+	"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+	"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+	"w": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+}
+
+// periodPattern matches a period written as an optional count and a unit word,
+// e.g. "hour", "2 hours" or "1d"
+var periodPattern = regexp.MustCompile(`^(\d+)?\s*([a-z]+)$`)
+
+// ParseRateSpec parses a single rate window like "10/1s", "100/minute" or
+// "5 per hour"
+func ParseRateSpec(spec string) (rate int64, period time.Duration, err error) {
+	if strings.Contains(spec, ",") {
+		return 0, 0, fmt.Errorf("invalid rate spec format: %s (expected a single rate/period)", spec)
+	}
+
+	w, err := parseWindow(spec)
+	if err != nil {
+		return 0, 0, err
+	}
+	return w.Limit, w.Period, nil
+}
+
+// ParseRateWindows parses a comma-separated list of rate windows that are
+// enforced together, e.g. "10/s, 1000/hour, 10000/day"
+func ParseRateWindows(spec string) ([]Window, error) {
+	var windows []Window
+
+	for _, part := range strings.Split(spec, ",") {
+		w, err := parseWindow(part)
+		if err != nil {
+			return nil, err
+		}
+		if w.Limit <= 0 {
+			return nil, fmt.Errorf("rate must be positive in %s", strings.TrimSpace(part))
+		}
+		windows = append(windows, w)
+	}
+
+	return windows, nil
+}
+
+// WithBurst caps how many requests may be admitted back to back. The window
+// with the lowest sustained rate is spread out so that at most burst requests
+// fall in any burst/rate stretch of time; windows already that strict are
+// returned unchanged.
+func WithBurst(windows []Window, burst int64) []Window {
+	if burst <= 0 || len(windows) == 0 {
+		return windows
+	}
+
+	slowest := slowestWindow(windows)
+	if burst >= slowest.Limit {
+		return windows
+	}
+
+	spread := Window{
+		Limit:  burst,
+		Period: slowest.Period / time.Duration(slowest.Limit) * time.Duration(burst),
+	}
+	return append(append([]Window(nil), windows...), spread)
+}
+
+// slowestWindow returns the window with the lowest sustained rate
+func slowestWindow(windows []Window) Window {
+	slowest := windows[0]
+	for _, w := range windows[1:] {
+		if float64(w.Limit)/float64(w.Period) < float64(slowest.Limit)/float64(slowest.Period) {
+			slowest = w
+		}
+	}
+	return slowest
+}
+
+// parseWindow parses one "RATE/PERIOD" or "RATE per PERIOD" window
+func parseWindow(spec string) (Window, error) {
+	spec = strings.TrimSpace(spec)
+
+	rateStr, periodStr, found := strings.Cut(spec, "/")
+	if !found {
+		rateStr, periodStr, found = strings.Cut(strings.ToLower(spec), " per ")
+	}
+	if !found {
+		return Window{}, fmt.Errorf("invalid rate spec format: %s (expected format: rate/period or 'rate per period')", spec)
+	}
+
+	rateStr = strings.TrimSpace(rateStr)
+	periodStr = strings.TrimSpace(periodStr)
+
+	if rateStr == "" {
+		return Window{}, fmt.Errorf("missing rate in spec: %s", spec)
+	}
+
+	if periodStr == "" {
+		return Window{}, fmt.Errorf("missing period in spec: %s", spec)
+	}
+
+	// Parse rate
+	rate, err := strconv.ParseInt(rateStr, 10, 64)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid rate '%s': %w", rateStr, err)
+	}
+
+	// Parse period
+	period, err := parsePeriod(periodStr)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid period '%s': %w", periodStr, err)
+	}
+
+	return Window{Limit: rate, Period: period}, nil
+}
+
+// parsePeriod parses a Go duration ("1h30m") or a unit word with an optional
+// count ("hour", "2 hours", "1d")
+func parsePeriod(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return 0, fmt.Errorf("period must be positive")
+		}
+		return d, nil
+	}
+
+	m := periodPattern.FindStringSubmatch(strings.ToLower(s))
+	if m == nil {
+		return 0, fmt.Errorf("unrecognized period")
+	}
+
+	unit, ok := periodUnits[m[2]]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit %q", m[2])
+	}
+
+	count := int64(1)
+	if m[1] != "" {
+		count, _ = strconv.ParseInt(m[1], 10, 64)
+		if count <= 0 {
+			return 0, fmt.Errorf("period must be positive")
+		}
+	}
+
+	return unit * time.Duration(count), nil
+}
@@ -0,0 +1,198 @@
+package ratelimit
+
+// attemptTimeline is a treap of attempt times, in Unix nanoseconds. Every node
+// also holds how many attempts fall in the window starting at its own time,
+// with lazy range additions and subtree maxima, so the fullest window starting
+// in any time range is found in O(log n).
+type attemptTimeline struct {
+	root *timelineNode
+	seed uint64 // xorshift state for node priorities
+}
+
+type timelineNode struct {
+	at          int64  // attempt time
+	count       int64  // attempts in the window starting at this one
+	maxCount    int64  // largest count in this subtree
+	pending     int64  // addition not yet pushed to the children
+	priority    uint64 // heap order of the treap
+	size        int
+	left, right *timelineNode
+}
+
+func nodeSize(n *timelineNode) int {
+	if n == nil {
+		return 0
+	}
+	return n.size
+}
+
+// add adds delta to every count in the subtree
+func (n *timelineNode) add(delta int64) {
+	if n == nil {
+		return
+	}
+	n.count += delta
+	n.maxCount += delta
+	n.pending += delta
+}
+
+// push hands a pending addition down to the children
+func (n *timelineNode) push() {
+	if n.pending != 0 {
+		n.left.add(n.pending)
+		n.right.add(n.pending)
+		n.pending = 0
+	}
+}
+
+// update recomputes the subtree size and maximum from the children
+func (n *timelineNode) update() {
+	n.size = 1 + nodeSize(n.left) + nodeSize(n.right)
+	n.maxCount = n.count
+	if n.left != nil && n.left.maxCount > n.maxCount {
+		n.maxCount = n.left.maxCount
+	}
+	if n.right != nil && n.right.maxCount > n.maxCount {
+		n.maxCount = n.right.maxCount
+	}
+}
+
+// split divides n into the nodes before key (through key if inclusive) and the rest
+func split(n *timelineNode, key int64, inclusive bool) (*timelineNode, *timelineNode) {
+	if n == nil {
+		return nil, nil
+	}
+	n.push()
+
+	if n.at < key || (inclusive && n.at == key) {
+		left, right := split(n.right, key, inclusive)
+		n.right = left
+		n.update()
+		return n, right
+	}
+
+	left, right := split(n.left, key, inclusive)
+	n.left = right
+	n.update()
+	return left, n
+}
+
+// merge joins two treaps where every time in a precedes every time in b
+func merge(a, b *timelineNode) *timelineNode {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+
+	if a.priority > b.priority {
+		a.push()
+		a.right = merge(a.right, b)
+		a.update()
+		return a
+	}
+
+	b.push()
+	b.left = merge(a, b.left)
+	b.update()
+	return b
+}
+
+// Len returns the number of attempts on the timeline
+func (t *attemptTimeline) Len() int {
+	return nodeSize(t.root)
+}
+
+// insert adds an attempt at the given time whose window holds count attempts
+func (t *attemptTimeline) insert(at, count int64) {
+	// xorshift64; any fixed odd seed gives a valid sequence
+	if t.seed == 0 {
+		t.seed = 0x9e3779b97f4a7c15
+	}
+	t.seed ^= t.seed << 13
+	t.seed ^= t.seed >> 7
+	t.seed ^= t.seed << 17
+
+	node := &timelineNode{at: at, count: count, maxCount: count, priority: t.seed, size: 1}
+	left, right := split(t.root, at, true)
+	t.root = merge(merge(left, node), right)
+}
+
+// withRange calls fn with the subtree of attempts in (lo, hi]
+func (t *attemptTimeline) withRange(lo, hi int64, fn func(*timelineNode)) {
+	before, rest := split(t.root, lo, true)
+	inside, after := split(rest, hi, true)
+	fn(inside)
+	t.root = merge(merge(before, inside), after)
+}
+
+// addRange adds delta to the window counts of attempts in (lo, hi]
+func (t *attemptTimeline) addRange(lo, hi, delta int64) {
+	t.withRange(lo, hi, func(n *timelineNode) { n.add(delta) })
+}
+
+// maxRange returns the fullest window count among attempts in (lo, hi], or 0
+func (t *attemptTimeline) maxRange(lo, hi int64) int64 {
+	var most int64
+	t.withRange(lo, hi, func(n *timelineNode) {
+		if n != nil {
+			most = n.maxCount
+		}
+	})
+	return most
+}
+
+// countBefore returns the number of attempts strictly before at
+func (t *attemptTimeline) countBefore(at int64) int64 {
+	var count int
+	for n := t.root; n != nil; {
+		if n.at < at {
+			count += nodeSize(n.left) + 1
+			n = n.right
+		} else {
+			n = n.left
+		}
+	}
+	return int64(count)
+}
+
+// countBetween returns the number of attempts in [lo, hi)
+func (t *attemptTimeline) countBetween(lo, hi int64) int64 {
+	return t.countBefore(hi) - t.countBefore(lo)
+}
+
+// after returns the earliest attempt strictly after at
+func (t *attemptTimeline) after(at int64) (int64, bool) {
+	var best int64
+	found := false
+	for n := t.root; n != nil; {
+		if n.at > at {
+			best, found = n.at, true
+			n = n.left
+		} else {
+			n = n.right
+		}
+	}
+	return best, found
+}
+
+// removeThrough drops every attempt at or before cutoff
+func (t *attemptTimeline) removeThrough(cutoff int64) {
+	_, t.root = split(t.root, cutoff, true)
+}
+
+// remove drops one attempt at exactly at, reporting whether there was one
+func (t *attemptTimeline) remove(at int64) bool {
+	before, rest := split(t.root, at, false)
+	matching, after := split(rest, at, true)
+	if matching == nil {
+		t.root = merge(before, after)
+		return false
+	}
+
+	matching.push()
+	matching = merge(matching.left, matching.right)
+	t.root = merge(merge(before, matching), after)
+	return true
+}
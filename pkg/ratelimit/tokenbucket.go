@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"time"
+
+	"github.com/swi/repeater/pkg/interfaces"
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+// TokenBucketRateLimiter implements the token bucket algorithm: each window's
+// bucket holds up to burst tokens, starts full and refills at limit tokens per
+// period. A request takes one token from every bucket.
+type TokenBucketRateLimiter struct {
+	*windowedLimiter
+}
+
+// NewTokenBucketRateLimiter creates a token bucket rate limiter. burst 0 means
+// each window's limit.
+func NewTokenBucketRateLimiter(windows []Window, burst int64) *TokenBucketRateLimiter {
+	return NewTokenBucketRateLimiterWithClock(windows, burst, scheduler.NewRealClock())
+}
+
+// NewTokenBucketRateLimiterWithClock creates a token bucket rate limiter that
+// refills according to the given clock
+func NewTokenBucketRateLimiterWithClock(windows []Window, burst int64, clock interfaces.Clock) *TokenBucketRateLimiter {
+	return &TokenBucketRateLimiter{newWindowedLimiter(windows, clock, func(w Window) windowLimiter {
+		return newTokenBucketWindow(w, burst)
+	})}
+}
+
+// tokenBucketWindow is the bucket of one window. Tokens are counted in
+// nanosecond units of refill time, so a token is worth period/limit.
+type tokenBucketWindow struct {
+	perToken time.Duration // refill time of one token
+	capacity time.Duration // capacity in refill time
+	level    time.Duration // tokens held at updated, in refill time
+	updated  time.Time
+	started  bool
+}
+
+func newTokenBucketWindow(w Window, burst int64) *tokenBucketWindow {
+	if burst <= 0 {
+		burst = w.Limit
+	}
+	perToken := w.Period / time.Duration(w.Limit)
+	return &tokenBucketWindow{
+		perToken: perToken,
+		capacity: perToken * time.Duration(burst),
+	}
+}
+
+// levelAt returns the tokens held at t, in refill time
+func (b *tokenBucketWindow) levelAt(t time.Time) time.Duration {
+	if !b.started {
+		return b.capacity // starts full
+	}
+	if t.Before(b.updated) {
+		return b.level
+	}
+	if level := b.level + t.Sub(b.updated); level < b.capacity {
+		return level
+	}
+	return b.capacity
+}
+
+func (b *tokenBucketWindow) fits(t time.Time) bool {
+	return b.levelAt(t) >= b.perToken
+}
+
+func (b *tokenBucketWindow) record(t time.Time) {
+	b.level = b.levelAt(t) - b.perToken
+	b.updated, b.started = t, true
+}
+
+func (b *tokenBucketWindow) earliestFrom(t time.Time) time.Time {
+	if level := b.levelAt(t); level < b.perToken {
+		return t.Add(b.perToken - level)
+	}
+	return t
+}
+
+func (b *tokenBucketWindow) remaining(t time.Time) int64 {
+	return int64(b.levelAt(t) / b.perToken)
+}
@@ -72,7 +72,9 @@ func (rm *RecoveryManager) GetRecoveryState() *RecoveryState {
 	return &stateCopy
 }
 
-// ExecuteWithRetry executes a function with retry policy
+// ExecuteWithRetry executes a function with retry policy.
+//
+// Deprecated: use retry.Do.
 func (rm *RecoveryManager) ExecuteWithRetry(ctx context.Context, fn ExecuteFunc) error {
 	rm.mu.RLock()
 	policy := rm.retryPolicy
@@ -142,7 +144,9 @@ func (rm *RecoveryManager) ExecuteWithFallback(ctx context.Context, fn ExecuteFu
 	return fallback(ctx, err)
 }
 
-// ExecuteWithRetryAndFallback executes a function with both retry and fallback
+// ExecuteWithRetryAndFallback executes a function with both retry and fallback.
+//
+// Deprecated: use retry.Do with retry.WithFallback.
 func (rm *RecoveryManager) ExecuteWithRetryAndFallback(ctx context.Context, fn ExecuteFunc) error {
 	err := rm.ExecuteWithRetry(ctx, fn)
 	if err == nil {
@@ -4,7 +4,10 @@ import (
 	"time"
 )
 
-// RetryPolicy defines the interface for retry policies
+// RetryPolicy defines the interface for retry policies.
+//
+// Deprecated: use retry.Policy, which shares the backoff strategies of the
+// rpr retry subcommands and adds circuit breakers and fallbacks.
 type RetryPolicy interface {
 	ShouldRetry(attempt int) bool
 	NextDelay(attempt int) time.Duration
@@ -20,6 +23,8 @@ type ExponentialBackoffPolicy struct {
 }
 
 // NewExponentialBackoffPolicy creates a new exponential backoff policy
+//
+// Deprecated: use retry.New with retry.Exponential.
 func NewExponentialBackoffPolicy(maxRetries int, initialDelay time.Duration, multiplier float64, maxDelay time.Duration) *ExponentialBackoffPolicy {
 	return &ExponentialBackoffPolicy{
 		maxRetries:   maxRetries,
@@ -65,6 +70,8 @@ type LinearBackoffPolicy struct {
 }
 
 // NewLinearBackoffPolicy creates a new linear backoff policy
+//
+// Deprecated: use retry.New with retry.Linear.
 func NewLinearBackoffPolicy(maxRetries int, initialDelay time.Duration, increment time.Duration) *LinearBackoffPolicy {
 	return &LinearBackoffPolicy{
 		maxRetries:   maxRetries,
@@ -99,6 +106,8 @@ type FixedDelayPolicy struct {
 }
 
 // NewFixedDelayPolicy creates a new fixed delay policy
+//
+// Deprecated: use retry.New with retry.Fixed.
 func NewFixedDelayPolicy(maxRetries int, delay time.Duration) *FixedDelayPolicy {
 	return &FixedDelayPolicy{
 		maxRetries: maxRetries,
@@ -129,6 +138,8 @@ type ConditionalRetryPolicy struct {
 }
 
 // NewConditionalRetryPolicy creates a new conditional retry policy
+//
+// Deprecated: use retry.New with retry.RetryIf.
 func NewConditionalRetryPolicy(maxRetries int, delay time.Duration) *ConditionalRetryPolicy {
 	return &ConditionalRetryPolicy{
 		maxRetries: maxRetries,
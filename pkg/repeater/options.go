@@ -0,0 +1,536 @@
+package repeater
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/swi/repeater/pkg/executor"
+	"github.com/swi/repeater/pkg/expr"
+	"github.com/swi/repeater/pkg/patterns"
+	"github.com/swi/repeater/pkg/ratelimit"
+	"github.com/swi/repeater/pkg/runner"
+)
+
+// Option configures a run
+type Option func(*runner.Config) error
+
+// Command runs the given command and arguments on each execution
+func Command(name string, args ...string) Option {
+	return func(c *runner.Config) error {
+		if name == "" {
+			return errors.New("command cannot be empty")
+		}
+		if c.Probe != nil {
+			return errors.New("command and function are mutually exclusive")
+		}
+		c.Command = append([]string{name}, args...)
+		return nil
+	}
+}
+
+// Function runs fn in-process on each execution, in place of a command
+func Function(fn Func) Option {
+	return func(c *runner.Config) error {
+		if fn == nil {
+			return errors.New("function cannot be nil")
+		}
+		if len(c.Command) > 0 {
+			return errors.New("command and function are mutually exclusive")
+		}
+		c.Probe = &funcProbe{fn: fn}
+		return nil
+	}
+}
+
+// Timeout bounds each execution; the default is 30 seconds
+func Timeout(timeout time.Duration) Option {
+	return func(c *runner.Config) error {
+		if timeout <= 0 {
+			return errors.New("timeout must be positive")
+		}
+		c.Timeout = timeout
+		return nil
+	}
+}
+
+// schedule sets the run's schedule, refusing a second one
+func schedule(name string, apply func(c *runner.Config) error) Option {
+	return func(c *runner.Config) error {
+		if c.Subcommand != "" {
+			return fmt.Errorf("schedule already set to %s", c.Subcommand)
+		}
+		if err := apply(c); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		c.Subcommand = name
+		return nil
+	}
+}
+
+// Interval executes every interval
+func Interval(every time.Duration) Option {
+	return schedule("interval", func(c *runner.Config) error {
+		if every <= 0 {
+			return errors.New("interval must be positive")
+		}
+		c.Every = every
+		return nil
+	})
+}
+
+// Exponential retries with delays of baseDelay doubling up to maxDelay (zero
+// for the 60s default); see Multiplier and Attempts
+func Exponential(baseDelay, maxDelay time.Duration) Option {
+	return strategy("exponential", baseDelay, maxDelay)
+}
+
+// Fibonacci retries with delays of baseDelay times successive Fibonacci
+// numbers, up to maxDelay
+func Fibonacci(baseDelay, maxDelay time.Duration) Option {
+	return strategy("fibonacci", baseDelay, maxDelay)
+}
+
+// Linear retries with delays growing by increment, up to maxDelay
+func Linear(increment, maxDelay time.Duration) Option {
+	return schedule("linear", func(c *runner.Config) error {
+		if increment <= 0 || maxDelay < 0 {
+			return errors.New("delays must be positive")
+		}
+		c.Increment, c.MaxDelay = increment, maxDelay
+		return nil
+	})
+}
+
+// Polynomial retries with delays of baseDelay times attempt^exponent, up to
+// maxDelay
+func Polynomial(baseDelay time.Duration, exponent float64, maxDelay time.Duration) Option {
+	return schedule("polynomial", func(c *runner.Config) error {
+		if baseDelay <= 0 || maxDelay < 0 {
+			return errors.New("delays must be positive")
+		}
+		if exponent <= 0 {
+			return errors.New("exponent must be positive")
+		}
+		c.BaseDelay, c.Exponent, c.MaxDelay = baseDelay, exponent, maxDelay
+		return nil
+	})
+}
+
+// DecorrelatedJitter retries with randomized delays between baseDelay and the
+// previous delay times the multiplier (2 by default), up to maxDelay; see Seed
+func DecorrelatedJitter(baseDelay, maxDelay time.Duration) Option {
+	return strategy("decorrelated-jitter", baseDelay, maxDelay)
+}
+
+// strategy sets a retry strategy driven by a base and a maximum delay
+func strategy(name string, baseDelay, maxDelay time.Duration) Option {
+	return schedule(name, func(c *runner.Config) error {
+		if baseDelay <= 0 || maxDelay < 0 {
+			return errors.New("delays must be positive")
+		}
+		c.BaseDelay, c.MaxDelay = baseDelay, maxDelay
+		return nil
+	})
+}
+
+// Multiplier sets the growth factor of Exponential and DecorrelatedJitter
+func Multiplier(multiplier float64) Option {
+	return func(c *runner.Config) error {
+		if multiplier <= 1 {
+			return errors.New("multiplier must be greater than 1")
+		}
+		c.Multiplier = multiplier
+		return nil
+	}
+}
+
+// Attempts caps how many times a retry strategy executes; the default is 3
+func Attempts(attempts int) Option {
+	return func(c *runner.Config) error {
+		if attempts <= 0 {
+			return errors.New("attempts must be positive")
+		}
+		c.MaxRetries = attempts
+		return nil
+	}
+}
+
+// Seed makes DecorrelatedJitter delays reproducible
+func Seed(seed int64) Option {
+	return func(c *runner.Config) error {
+		c.Seed = seed
+		return nil
+	}
+}
+
+// Adaptive adjusts the interval between minInterval and maxInterval from the
+// response times and outcomes of executions
+func Adaptive(baseInterval, minInterval, maxInterval time.Duration) Option {
+	return schedule("adaptive", func(c *runner.Config) error {
+		if minInterval <= 0 || minInterval >= maxInterval {
+			return errors.New("min interval must be positive and less than max interval")
+		}
+		if baseInterval < minInterval || baseInterval > maxInterval {
+			return errors.New("base interval must be between min and max interval")
+		}
+		c.BaseInterval, c.MinInterval, c.MaxInterval = baseInterval, minInterval, maxInterval
+		c.SlowThreshold, c.FastThreshold, c.FailureThreshold = 2.0, 0.5, 0.3
+		return nil
+	})
+}
+
+// LoadAdaptive adjusts the interval around baseInterval to keep CPU and memory
+// usage (percentages) and the load average near their targets. Zero targets
+// default to 70%, 80% and 1.0.
+func LoadAdaptive(baseInterval time.Duration, targetCPU, targetMemory, targetLoad float64) Option {
+	return schedule("load-adaptive", func(c *runner.Config) error {
+		if baseInterval <= 0 {
+			return errors.New("base interval must be positive")
+		}
+		if targetCPU < 0 || targetCPU > 100 || targetMemory < 0 || targetMemory > 100 {
+			return errors.New("cpu and memory targets must be between 0 and 100")
+		}
+		if targetLoad < 0 {
+			return errors.New("load target must not be negative")
+		}
+		c.BaseInterval = baseInterval
+		c.TargetCPU, c.TargetMemory, c.TargetLoad = cmp.Or(targetCPU, 70), cmp.Or(targetMemory, 80), cmp.Or(targetLoad, 1.0)
+		c.MinInterval, c.MaxInterval = baseInterval/10, baseInterval*10
+		return nil
+	})
+}
+
+// Cron executes on a cron expression, evaluated in timezone (empty for UTC)
+func Cron(expression, timezone string) Option {
+	return schedule("cron", func(c *runner.Config) error {
+		if expression == "" {
+			return errors.New("cron expression cannot be empty")
+		}
+		c.CronExpression, c.Timezone = expression, timezone
+		return nil
+	})
+}
+
+// Phases runs each phase's schedule in turn, as the phases subcommand does.
+// Every phase but the last needs Times or For, unless it is an exponential
+// phase, which ends after its attempts.
+func Phases(phases ...runner.PhaseConfig) Option {
+	return schedule("phases", func(c *runner.Config) error {
+		if len(phases) == 0 {
+			return errors.New("at least one phase is required")
+		}
+		for i, phase := range phases[:len(phases)-1] {
+			if phase.Times == 0 && phase.For == 0 && phase.Mode != "exponential" {
+				return fmt.Errorf("phase %d never ends: set Times or For", i+1)
+			}
+		}
+		c.Phases = phases
+		return nil
+	})
+}
+
+// RateLimit throttles executions to a rate such as "10/1m" or
+// "10/s,1000/hour". On its own it is the schedule; with one it caps it.
+func RateLimit(spec string) Option {
+	return func(c *runner.Config) error {
+		if _, err := ratelimit.ParseRateWindows(spec); err != nil {
+			return fmt.Errorf("invalid rate limit: %w", err)
+		}
+		c.RateSpec = spec
+		return nil
+	}
+}
+
+// Times stops the run after the given number of executions
+func Times(times int64) Option {
+	return func(c *runner.Config) error {
+		if times <= 0 {
+			return errors.New("times must be positive")
+		}
+		c.Times = times
+		return nil
+	}
+}
+
+// For stops the run after the given duration
+func For(duration time.Duration) Option {
+	return func(c *runner.Config) error {
+		if duration <= 0 {
+			return errors.New("duration must be positive")
+		}
+		c.For = duration
+		return nil
+	}
+}
+
+// Deadline stops scheduling executions at the given time
+func Deadline(deadline time.Time) Option {
+	return func(c *runner.Config) error {
+		c.Deadline = deadline
+		return nil
+	}
+}
+
+// StartAt holds back the first execution until the given time
+func StartAt(start time.Time) Option {
+	return func(c *runner.Config) error {
+		c.StartAt = start
+		return nil
+	}
+}
+
+// Delay offsets every scheduled execution by the given delay
+func Delay(delay time.Duration) Option {
+	return func(c *runner.Config) error {
+		if delay < 0 {
+			return errors.New("delay must not be negative")
+		}
+		c.Delay = delay
+		return nil
+	}
+}
+
+// SuccessPattern counts an execution whose output matches pattern as a
+// success, whatever its exit code. A stdout: or stderr: prefix matches one
+// stream only; given more than once, any pattern may match unless MatchAll.
+func SuccessPattern(pattern string) Option {
+	return func(c *runner.Config) error {
+		if _, err := patterns.ParsePattern(pattern, false); err != nil {
+			return fmt.Errorf("invalid success pattern: %w", err)
+		}
+		addPattern(&c.SuccessPattern, &c.SuccessPatterns, pattern)
+		return nil
+	}
+}
+
+// FailurePattern counts an execution whose output matches pattern as a
+// failure, whatever its exit code. A stdout: or stderr: prefix matches one
+// stream only; given more than once, any pattern may match unless MatchAll.
+func FailurePattern(pattern string) Option {
+	return func(c *runner.Config) error {
+		if _, err := patterns.ParsePattern(pattern, false); err != nil {
+			return fmt.Errorf("invalid failure pattern: %w", err)
+		}
+		addPattern(&c.FailurePattern, &c.FailurePatterns, pattern)
+		return nil
+	}
+}
+
+// addPattern fills the first pattern, then appends the rest
+func addPattern(first *string, more *[]string, pattern string) {
+	if *first == "" {
+		*first = pattern
+		return
+	}
+	*more = append(*more, pattern)
+}
+
+// RequireAbsent counts an execution whose output matches pattern as a
+// failure, however many matches a failure pattern needs; may be given more
+// than once
+func RequireAbsent(pattern string) Option {
+	return func(c *runner.Config) error {
+		if _, err := patterns.ParsePattern(pattern, false); err != nil {
+			return fmt.Errorf("invalid required-absent pattern: %w", err)
+		}
+		c.RequireAbsent = append(c.RequireAbsent, pattern)
+		return nil
+	}
+}
+
+// FatalPattern stops the run with runner.ErrAborted when the output matches
+// pattern; may be given more than once
+func FatalPattern(pattern string) Option {
+	return func(c *runner.Config) error {
+		if _, err := patterns.ParsePattern(pattern, false); err != nil {
+			return fmt.Errorf("invalid fatal pattern: %w", err)
+		}
+		c.FatalPatterns = append(c.FatalPatterns, pattern)
+		return nil
+	}
+}
+
+// MatchAll requires every success pattern to match for a success, and every
+// failure pattern for a failure, rather than any one
+func MatchAll() Option {
+	return func(c *runner.Config) error {
+		c.MatchMode = string(patterns.MatchAll)
+		return nil
+	}
+}
+
+// MinMatches makes success and failure patterns count only when they match
+// at least n times, e.g. FailurePattern("ERROR") with MinMatches(3)
+func MinMatches(n int) Option {
+	return func(c *runner.Config) error {
+		if n < 1 {
+			return fmt.Errorf("min matches must be positive, got %d", n)
+		}
+		c.MinMatches = n
+		return nil
+	}
+}
+
+// FailFastOnPattern stops the command as soon as a line of its output matches
+// a failure, required-absent or fatal pattern, rather than when it exits
+func FailFastOnPattern() Option {
+	return func(c *runner.Config) error {
+		c.FailFastOnPattern = true
+		return nil
+	}
+}
+
+// SucceedOnPattern stops the command as a success as soon as its output
+// matches the success patterns, e.g. a server's "listening on" line
+func SucceedOnPattern() Option {
+	return func(c *runner.Config) error {
+		c.SucceedOnPattern = true
+		return nil
+	}
+}
+
+// SuccessExpr counts an execution as a success only if the condition holds,
+// e.g. `exit in [0,3] && duration < 2s`; see pkg/expr
+func SuccessExpr(condition string) Option {
+	return func(c *runner.Config) error {
+		if _, err := expr.Compile(condition); err != nil {
+			return fmt.Errorf("invalid success expression: %w", err)
+		}
+		c.SuccessExpr = condition
+		return nil
+	}
+}
+
+// FailureExpr counts an execution as a failure if the condition holds, e.g.
+// `json(stdout).status != "green"`; see pkg/expr
+func FailureExpr(condition string) Option {
+	return func(c *runner.Config) error {
+		if _, err := expr.Compile(condition); err != nil {
+			return fmt.Errorf("invalid failure expression: %w", err)
+		}
+		c.FailureExpr = condition
+		return nil
+	}
+}
+
+// ExpectJSON counts an execution as a success only if its JSON output holds
+// value at the jq-style path, e.g. ExpectJSON(".replicas.ready", 3); may be
+// given more than once
+func ExpectJSON(path string, value any) Option {
+	return func(c *runner.Config) error {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("invalid JSON expectation: %w", err)
+		}
+		spec := path + "=" + string(encoded)
+		if _, err := patterns.ParseJSONExpectation(spec); err != nil {
+			return fmt.Errorf("invalid JSON expectation: %w", err)
+		}
+		c.ExpectJSON = append(c.ExpectJSON, spec)
+		return nil
+	}
+}
+
+// ExpectJSONExpr counts an execution as a success only if the jq-style
+// condition holds for its JSON output, e.g. `.items | length > 0`
+func ExpectJSONExpr(condition string) Option {
+	return func(c *runner.Config) error {
+		if _, err := expr.Compile(condition); err != nil {
+			return fmt.Errorf("invalid JSON expression: %w", err)
+		}
+		c.ExpectJSONExpr = condition
+		return nil
+	}
+}
+
+// SuccessCodes counts the listed exit codes as success, and any other as
+// failure, e.g. SuccessCodes("0,1") for grep; codes are mapped before the
+// patterns see them
+func SuccessCodes(codes string) Option {
+	return exitCodes(codes, func(c *runner.Config) *string { return &c.SuccessCodes })
+}
+
+// RetryCodes marks the listed exit codes as failures worth retrying, e.g.
+// RetryCodes("75,111"), whatever the patterns make of the output
+func RetryCodes(codes string) Option {
+	return exitCodes(codes, func(c *runner.Config) *string { return &c.RetryCodes })
+}
+
+// AbortCodes stops the run with runner.ErrAborted on the listed exit codes,
+// e.g. AbortCodes("2,64-78")
+func AbortCodes(codes string) Option {
+	return exitCodes(codes, func(c *runner.Config) *string { return &c.AbortCodes })
+}
+
+// exitCodes sets one of the exit code lists, checking it against the others
+func exitCodes(codes string, field func(c *runner.Config) *string) Option {
+	return func(c *runner.Config) error {
+		*field(c) = codes
+		if _, err := executor.NewExitCodeMap(*c.GetExitCodeConfig()); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
+// CaseInsensitive makes the success and failure patterns ignore case
+func CaseInsensitive() Option {
+	return func(c *runner.Config) error {
+		c.CaseInsensitive = true
+		return nil
+	}
+}
+
+// Stream prints each execution's output to stdout; runs are quiet by default
+func Stream() Option {
+	return func(c *runner.Config) error {
+		c.Quiet = false
+		c.Stream = true
+		return nil
+	}
+}
+
+// Verbose prints execution details and probe timings
+func Verbose() Option {
+	return func(c *runner.Config) error {
+		c.Verbose = true
+		return nil
+	}
+}
+
+// Metrics serves Prometheus metrics for the run on the given port
+func Metrics(port int) Option {
+	return func(c *runner.Config) error {
+		if port <= 0 || port > 65535 {
+			return errors.New("metrics port must be 1-65535")
+		}
+		c.MetricsEnabled, c.MetricsPort = true, port
+		return nil
+	}
+}
+
+// Health serves health check endpoints for the run on the given port
+func Health(port int) Option {
+	return func(c *runner.Config) error {
+		if port <= 0 || port > 65535 {
+			return errors.New("health port must be 1-65535")
+		}
+		c.HealthEnabled, c.HealthPort = true, port
+		return nil
+	}
+}
+
+// Observe notifies observer of the run's lifecycle: ticks, executions, output
+// lines and schedule changes; see runner.Observer
+func Observe(observer runner.Observer) Option {
+	return func(c *runner.Config) error {
+		if observer == nil {
+			return errors.New("observer cannot be nil")
+		}
+		c.Observers = append(c.Observers, observer)
+		return nil
+	}
+}
@@ -0,0 +1,142 @@
+// Package repeater runs a command or a Go function on any of rpr's schedules
+// from Go code. A run is built from typed options instead of command-line
+// flags, and goes through the same schedulers, stop conditions, pattern
+// matching, metrics and health endpoints as the rpr command:
+//
+//	r, err := repeater.New(
+//		repeater.Function(checkDatabase),
+//		repeater.Interval(10*time.Second),
+//		repeater.For(5*time.Minute),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	stats, err := r.Run(ctx)
+package repeater
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/swi/repeater/pkg/executor"
+	"github.com/swi/repeater/pkg/runner"
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+// Result is the outcome of one call of a Func. A non-zero ExitCode fails the
+// execution; Stdout and Stderr are matched by the success and failure patterns
+// as a command's output would be.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+}
+
+// Func is a Go function run in place of a command. Returning an error fails
+// the execution, with the error as its stderr; the run itself carries on, as
+// it does when a command exits non-zero. ctx ends when the execution timeout
+// passes or the run stops.
+type Func func(ctx context.Context) (Result, error)
+
+// Repeater runs a command or function on a schedule
+type Repeater struct {
+	config *runner.Config
+	runner *runner.Runner
+}
+
+// New creates a repeater from the given options. One of Command or Function
+// is required, and either a schedule option or a Times or For limit.
+func New(options ...Option) (*Repeater, error) {
+	return NewWithClock(scheduler.NewRealClock(), options...)
+}
+
+// NewWithClock creates a repeater whose schedules and stop conditions read
+// time from the given clock
+func NewWithClock(clock scheduler.Clock, options ...Option) (*Repeater, error) {
+	config := &runner.Config{Quiet: true}
+	for _, option := range options {
+		if err := option(config); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(config.Command) == 0 && config.Probe == nil {
+		return nil, errors.New("a command or function is required")
+	}
+
+	// A function is timed on the same clock as the schedule
+	if probe, ok := config.Probe.(*funcProbe); ok {
+		probe.clock = clock
+	}
+
+	// Without a schedule, the stop conditions pick a plain one as the CLI's
+	// count and duration subcommands do
+	if config.Subcommand == "" {
+		switch {
+		case config.RateSpec != "":
+			config.Subcommand = "rate-limit"
+		case config.Times > 0:
+			config.Subcommand = "count"
+		case config.For > 0:
+			config.Subcommand = "duration"
+		default:
+			return nil, errors.New("a schedule, Times or For is required")
+		}
+	}
+
+	r, err := runner.NewRunnerWithClock(config, clock)
+	if err != nil {
+		return nil, err
+	}
+	return &Repeater{config: config, runner: r}, nil
+}
+
+// Run executes until a stop condition is met or ctx is canceled, returning the
+// statistics of every execution. As with runner.Runner, canceling ctx returns
+// the stats so far with an error wrapping context.Canceled.
+func (r *Repeater) Run(ctx context.Context) (*runner.ExecutionStats, error) {
+	return r.runner.Run(ctx)
+}
+
+// funcProbe runs a Func through the executor, like the built-in probes
+type funcProbe struct {
+	fn    Func
+	clock scheduler.Clock
+}
+
+// Name implements executor.Probe
+func (p *funcProbe) Name() string {
+	return "func"
+}
+
+// SupportsStreaming implements executor.Probe
+func (p *funcProbe) SupportsStreaming() bool {
+	return false
+}
+
+// SupportedPlatforms implements executor.Probe
+func (p *funcProbe) SupportedPlatforms() []string {
+	return []string{"linux", "darwin", "windows"}
+}
+
+// Execute implements executor.Probe
+func (p *funcProbe) Execute(ctx context.Context, _ []string) (*executor.ExecutionResult, error) {
+	start := p.clock.Now()
+	result, err := p.fn(ctx)
+	duration := p.clock.Now().Sub(start)
+
+	if err != nil {
+		if result.ExitCode == 0 {
+			result.ExitCode = 1
+		}
+		result.Stderr += fmt.Sprintf("%v\n", err)
+	}
+
+	return &executor.ExecutionResult{
+		ExitCode: result.ExitCode,
+		Stdout:   result.Stdout,
+		Stderr:   result.Stderr,
+		Duration: duration,
+	}, nil
+}
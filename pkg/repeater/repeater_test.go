@@ -0,0 +1,231 @@
+package repeater
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/swi/repeater/pkg/runner"
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+func noop(ctx context.Context) (Result, error) {
+	return Result{}, nil
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []Option
+		errorMsg string
+	}{
+		{name: "count from times", options: []Option{Function(noop), Times(3)}},
+		{name: "duration from for", options: []Option{Command("true"), For(time.Second)}},
+		{name: "rate limit alone", options: []Option{Function(noop), RateLimit("10/1m")}},
+		{name: "schedule with limits", options: []Option{Function(noop), Exponential(time.Second, time.Minute), Attempts(5), Multiplier(1.5)}},
+		{name: "adaptive", options: []Option{Function(noop), Adaptive(time.Second, 100*time.Millisecond, 10*time.Second)}},
+		{name: "nothing to run", options: []Option{Times(3)}, errorMsg: "a command or function is required"},
+		{name: "no schedule", options: []Option{Function(noop)}, errorMsg: "a schedule, Times or For is required"},
+		{name: "command and function", options: []Option{Command("true"), Function(noop)}, errorMsg: "mutually exclusive"},
+		{name: "two schedules", options: []Option{Function(noop), Interval(time.Second), Cron("* * * * *", "")}, errorMsg: "schedule already set to interval"},
+		{name: "bad interval", options: []Option{Function(noop), Interval(0)}, errorMsg: "interval: interval must be positive"},
+		{name: "bad adaptive bounds", options: []Option{Function(noop), Adaptive(time.Minute, time.Second, 10*time.Second)}, errorMsg: "base interval must be between"},
+		{name: "bad rate", options: []Option{Function(noop), RateLimit("often")}, errorMsg: "invalid rate limit"},
+		{name: "bad pattern", options: []Option{Function(noop), Times(1), SuccessPattern("(")}, errorMsg: "invalid success pattern"},
+		{name: "unending phase", options: []Option{Function(noop), Phases(phase("interval", 0), phase("interval", 0))}, errorMsg: "phase 1 never ends"},
+		{name: "nil function", options: []Option{Function(nil)}, errorMsg: "function cannot be nil"},
+		{name: "bad expression", options: []Option{Function(noop), Times(1), SuccessExpr("exit = 0")}, errorMsg: "invalid success expression"},
+		{name: "bad json path", options: []Option{Function(noop), Times(1), ExpectJSON("replicas", 3)}, errorMsg: "invalid JSON expectation"},
+		{name: "bad fatal pattern", options: []Option{Function(noop), Times(1), FatalPattern("stderr:(")}, errorMsg: "invalid fatal pattern"},
+		{name: "bad min matches", options: []Option{Function(noop), Times(1), FailurePattern("ERROR"), MinMatches(0)}, errorMsg: "min matches must be positive"},
+		{name: "bad exit codes", options: []Option{Function(noop), Times(1), AbortCodes("64-")}, errorMsg: "invalid abort codes"},
+		{name: "overlapping exit codes", options: []Option{Function(noop), Times(1), SuccessCodes("0,1"), RetryCodes("1")}, errorMsg: "exit code 1 is in both success and retry codes"},
+		{name: "nil observer", options: []Option{Function(noop), Times(1), Observe(nil)}, errorMsg: "observer cannot be nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.options...)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errorMsg)
+		})
+	}
+}
+
+func TestRepeater_Function(t *testing.T) {
+	var calls, deadlines atomic.Int32
+	r, err := New(
+		Function(func(ctx context.Context) (Result, error) {
+			if _, ok := ctx.Deadline(); ok {
+				deadlines.Add(1)
+			}
+			switch calls.Add(1) {
+			case 2:
+				return Result{}, errors.New("database unavailable")
+			case 3:
+				return Result{Stdout: "partial", ExitCode: 2}, nil
+			}
+			return Result{Stdout: "ok"}, nil
+		}),
+		Interval(5*time.Millisecond),
+		Times(4),
+		Timeout(time.Second),
+	)
+	require.NoError(t, err)
+
+	stats, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(4), calls.Load())
+	assert.Equal(t, int32(4), deadlines.Load(), "the timeout reaches the function")
+	assert.Equal(t, 4, stats.TotalExecutions)
+	assert.Equal(t, 2, stats.SuccessfulExecutions)
+	assert.Equal(t, 2, stats.FailedExecutions)
+
+	assert.Equal(t, "ok", stats.Executions[0].Stdout)
+	assert.Equal(t, 1, stats.Executions[1].ExitCode)
+	assert.Equal(t, "database unavailable\n", stats.Executions[1].Stderr)
+	assert.Equal(t, 2, stats.Executions[2].ExitCode)
+}
+
+func TestRepeater_FunctionTimedOnClock(t *testing.T) {
+	clock := scheduler.NewVirtualClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	r, err := NewWithClock(clock,
+		Function(func(context.Context) (Result, error) {
+			clock.Advance(5 * time.Second)
+			return Result{}, nil
+		}),
+		Times(1),
+		SuccessExpr("duration >= 5s"),
+	)
+	require.NoError(t, err)
+
+	stats, err := r.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.SuccessfulExecutions)
+	assert.Equal(t, 5*time.Second, stats.Executions[0].Duration)
+}
+
+func TestRepeater_Patterns(t *testing.T) {
+	outputs := []string{"status: ok", "status: DEGRADED", "status: ok"}
+	var calls atomic.Int32
+	r, err := New(
+		Function(func(ctx context.Context) (Result, error) {
+			return Result{Stdout: outputs[calls.Add(1)-1]}, nil
+		}),
+		Times(3),
+		FailurePattern("degraded"),
+		CaseInsensitive(),
+	)
+	require.NoError(t, err)
+
+	stats, err := r.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.SuccessfulExecutions)
+	assert.Equal(t, 1, stats.FailedExecutions)
+}
+
+func TestRepeater_ExpectJSON(t *testing.T) {
+	outputs := []string{`{"ready": 3, "status": "ok"}`, `{"ready": 2, "status": "ok"}`, "not json"}
+	var calls atomic.Int32
+	r, err := New(
+		Function(func(ctx context.Context) (Result, error) {
+			return Result{Stdout: outputs[calls.Add(1)-1]}, nil
+		}),
+		Times(3),
+		ExpectJSON(".ready", 3),
+		ExpectJSONExpr(`.status == "ok"`),
+	)
+	require.NoError(t, err)
+
+	stats, err := r.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.SuccessfulExecutions)
+	assert.Equal(t, ".ready expected 3 got 2", stats.Executions[1].Reason)
+	assert.Contains(t, stats.Executions[2].Reason, "json: invalid JSON")
+}
+
+func TestRepeater_FatalPattern(t *testing.T) {
+	var calls atomic.Int32
+	r, err := New(
+		Function(func(ctx context.Context) (Result, error) {
+			if calls.Add(1) == 2 {
+				return Result{Stderr: "panic: nil map"}, nil
+			}
+			return Result{Stdout: "ok"}, nil
+		}),
+		Times(5),
+		SuccessPattern("stdout:ok"),
+		FatalPattern("stderr:^panic:"),
+	)
+	require.NoError(t, err)
+
+	stats, err := r.Run(context.Background())
+	require.ErrorIs(t, err, runner.ErrAborted)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Equal(t, []string{"stdout:ok"}, stats.Executions[0].MatchedPatterns)
+	assert.Equal(t, []string{"stderr:^panic:"}, stats.Executions[1].MatchedPatterns)
+}
+
+func TestRepeater_ExitCodes(t *testing.T) {
+	var calls atomic.Int32
+	r, err := New(
+		Function(func(ctx context.Context) (Result, error) {
+			if calls.Add(1) == 3 {
+				return Result{ExitCode: 2}, nil
+			}
+			return Result{ExitCode: 1}, nil
+		}),
+		Times(5),
+		SuccessCodes("0,1"),
+		AbortCodes("2"),
+	)
+	require.NoError(t, err)
+
+	stats, err := r.Run(context.Background())
+	require.ErrorIs(t, err, runner.ErrAborted)
+	assert.Equal(t, 2, stats.SuccessfulExecutions)
+	assert.Equal(t, map[int]int{1: 2, 2: 1}, stats.ExitCodes)
+}
+
+func TestRepeater_Command(t *testing.T) {
+	r, err := New(Command("echo", "hello"), Times(2))
+	require.NoError(t, err)
+
+	stats, err := r.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.SuccessfulExecutions)
+	assert.Equal(t, "hello\n", stats.Executions[0].Stdout)
+}
+
+func TestRepeater_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	r, err := New(
+		Function(func(context.Context) (Result, error) {
+			if calls.Add(1) == 2 {
+				cancel()
+			}
+			return Result{}, nil
+		}),
+		Interval(5*time.Millisecond),
+	)
+	require.NoError(t, err)
+
+	stats, err := r.Run(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.GreaterOrEqual(t, stats.TotalExecutions, 1)
+}
+
+func phase(mode string, times int64) runner.PhaseConfig {
+	return runner.PhaseConfig{Mode: mode, Every: time.Second, Times: times}
+}
@@ -0,0 +1,167 @@
+package retry
+
+import (
+	"errors"
+	"time"
+
+	"github.com/swi/repeater/pkg/interfaces"
+	"github.com/swi/repeater/pkg/recovery"
+	"github.com/swi/repeater/pkg/strategies"
+)
+
+// Option configures a retry policy
+type Option func(*Policy) error
+
+// Exponential waits baseDelay * multiplier^(retry-1) between attempts, capped
+// at maxDelay (zero for no cap): 1s, 2s, 4s, 8s...
+func Exponential(baseDelay time.Duration, multiplier float64, maxDelay time.Duration) Option {
+	return func(p *Policy) error {
+		p.config.BaseDelay, p.config.Multiplier, p.config.MaxDelay = baseDelay, multiplier, maxDelay
+		p.newStrategy = func(config strategies.StrategyConfig) strategies.Strategy {
+			return strategies.NewExponentialStrategy(config.BaseDelay, config.Multiplier, config.MaxDelay)
+		}
+		return nil
+	}
+}
+
+// Fibonacci waits baseDelay times successive Fibonacci numbers, capped at
+// maxDelay: 1s, 1s, 2s, 3s, 5s...
+func Fibonacci(baseDelay, maxDelay time.Duration) Option {
+	return func(p *Policy) error {
+		p.config.BaseDelay, p.config.MaxDelay = baseDelay, maxDelay
+		p.newStrategy = func(config strategies.StrategyConfig) strategies.Strategy {
+			return strategies.NewFibonacciStrategy(config.BaseDelay, config.MaxDelay)
+		}
+		return nil
+	}
+}
+
+// Linear waits increment * retry, capped at maxDelay: 1s, 2s, 3s...
+func Linear(increment, maxDelay time.Duration) Option {
+	return func(p *Policy) error {
+		p.config.Increment, p.config.MaxDelay = increment, maxDelay
+		p.newStrategy = func(config strategies.StrategyConfig) strategies.Strategy {
+			return strategies.NewLinearStrategy(config.Increment, config.MaxDelay)
+		}
+		return nil
+	}
+}
+
+// Polynomial waits baseDelay * retry^exponent, capped at maxDelay
+func Polynomial(baseDelay time.Duration, exponent float64, maxDelay time.Duration) Option {
+	return func(p *Policy) error {
+		p.config.BaseDelay, p.config.Exponent, p.config.MaxDelay = baseDelay, exponent, maxDelay
+		p.newStrategy = func(config strategies.StrategyConfig) strategies.Strategy {
+			return strategies.NewPolynomialStrategy(config.BaseDelay, config.Exponent, config.MaxDelay)
+		}
+		return nil
+	}
+}
+
+// DecorrelatedJitter waits a random delay between baseDelay and the previous
+// delay times multiplier, capped at maxDelay. seed makes the delays
+// reproducible; zero seeds from the time.
+func DecorrelatedJitter(baseDelay time.Duration, multiplier float64, maxDelay time.Duration, seed int64) Option {
+	return func(p *Policy) error {
+		p.config.BaseDelay, p.config.Multiplier, p.config.MaxDelay = baseDelay, multiplier, maxDelay
+		p.newStrategy = func(config strategies.StrategyConfig) strategies.Strategy {
+			if seed != 0 {
+				return strategies.NewDecorrelatedJitterStrategyWithSeed(config.BaseDelay, config.Multiplier, config.MaxDelay, seed)
+			}
+			return strategies.NewDecorrelatedJitterStrategy(config.BaseDelay, config.Multiplier, config.MaxDelay)
+		}
+		return nil
+	}
+}
+
+// Fixed waits the same delay before every retry
+func Fixed(delay time.Duration) Option {
+	return func(p *Policy) error {
+		p.config.BaseDelay = delay
+		p.newStrategy = func(config strategies.StrategyConfig) strategies.Strategy {
+			return strategies.NewFixedStrategy(config.BaseDelay)
+		}
+		return nil
+	}
+}
+
+// WithStrategy backs off with a custom strategy, validated against config.
+// The same strategy instance serves every Do call, so it should be stateless.
+// Attempts still overrides config.MaxAttempts.
+func WithStrategy(strategy strategies.Strategy, config strategies.StrategyConfig) Option {
+	return func(p *Policy) error {
+		if strategy == nil {
+			return errors.New("strategy cannot be nil")
+		}
+		p.config = config
+		p.newStrategy = func(strategies.StrategyConfig) strategies.Strategy { return strategy }
+		return nil
+	}
+}
+
+// Attempts caps the attempts, counting the first; the default is 3
+func Attempts(attempts int) Option {
+	return func(p *Policy) error {
+		if attempts <= 0 {
+			return errors.New("attempts must be positive")
+		}
+		p.attempts = attempts
+		return nil
+	}
+}
+
+// RetryIf retries only errors for which retryable returns true; any other
+// error ends the retries at once
+func RetryIf(retryable func(error) bool) Option {
+	return func(p *Policy) error {
+		if retryable == nil {
+			return errors.New("retry condition cannot be nil")
+		}
+		p.retryIf = retryable
+		return nil
+	}
+}
+
+// WithCircuitBreaker runs every attempt through breaker. Once it opens,
+// attempts fail with recovery.ErrCircuitBreakerOpen and the retries stop.
+func WithCircuitBreaker(breaker *recovery.CircuitBreaker) Option {
+	return func(p *Policy) error {
+		if breaker == nil {
+			return errors.New("circuit breaker cannot be nil")
+		}
+		p.breaker = breaker
+		return nil
+	}
+}
+
+// WithFallback calls fallback with the last error once the retries give up;
+// its result becomes Do's
+func WithFallback(fallback recovery.FallbackFunc) Option {
+	return func(p *Policy) error {
+		if fallback == nil {
+			return errors.New("fallback cannot be nil")
+		}
+		p.fallback = fallback
+		return nil
+	}
+}
+
+// OnRetry calls notify before each wait, with the failed attempt's number and
+// error and the delay before the next attempt
+func OnRetry(notify func(attempt int, err error, delay time.Duration)) Option {
+	return func(p *Policy) error {
+		p.onRetry = notify
+		return nil
+	}
+}
+
+// WithClock waits out delays on the given clock instead of the wall clock
+func WithClock(clock interfaces.Clock) Option {
+	return func(p *Policy) error {
+		if clock == nil {
+			return errors.New("clock cannot be nil")
+		}
+		p.clock = clock
+		return nil
+	}
+}
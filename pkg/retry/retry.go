@@ -0,0 +1,209 @@
+// Package retry runs an operation until it succeeds, waiting between attempts
+// as a backoff strategy from pkg/strategies dictates. A circuit breaker and a
+// fallback from pkg/recovery can guard the operation:
+//
+//	err := retry.Do(ctx, fetch,
+//		retry.Exponential(100*time.Millisecond, 2, 5*time.Second),
+//		retry.Attempts(5),
+//		retry.WithCircuitBreaker(breaker),
+//		retry.WithFallback(readFromCache),
+//	)
+//
+// The rpr retry subcommands (exponential, fibonacci, linear, polynomial and
+// decorrelated-jitter) schedule their attempts with the same policies.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/swi/repeater/pkg/interfaces"
+	"github.com/swi/repeater/pkg/recovery"
+	"github.com/swi/repeater/pkg/strategies"
+)
+
+// Func is an operation to retry; a nil error ends the retries
+type Func = recovery.ExecuteFunc
+
+// Policy describes how an operation is retried: the backoff strategy, the
+// attempt limit, which errors are retried and what guards the operation. A
+// policy is safe to reuse; every Do call starts a fresh backoff.
+type Policy struct {
+	config      strategies.StrategyConfig
+	newStrategy func(config strategies.StrategyConfig) strategies.Strategy
+	attempts    int // from Attempts; zero keeps the strategy config's MaxAttempts
+	retryIf     func(error) bool
+	breaker     *recovery.CircuitBreaker
+	fallback    recovery.FallbackFunc
+	onRetry     func(attempt int, err error, delay time.Duration)
+	clock       interfaces.Clock
+}
+
+// New creates a policy from the given options. Without a strategy option it
+// backs off exponentially from 1s, doubling up to 60s, over 3 attempts.
+func New(options ...Option) (*Policy, error) {
+	policy := &Policy{
+		config: *strategies.DefaultConfig(),
+		newStrategy: func(config strategies.StrategyConfig) strategies.Strategy {
+			return strategies.NewExponentialStrategy(config.BaseDelay, config.Multiplier, config.MaxDelay)
+		},
+	}
+
+	for _, option := range options {
+		if err := option(policy); err != nil {
+			return nil, err
+		}
+	}
+
+	if policy.attempts > 0 {
+		policy.config.MaxAttempts = policy.attempts
+	}
+	strategy := policy.newStrategy(policy.config)
+	if err := strategy.ValidateConfig(&policy.config); err != nil {
+		return nil, fmt.Errorf("invalid %s retry policy: %w", strategy.Name(), err)
+	}
+
+	return policy, nil
+}
+
+// Do retries fn with a policy built from the given options; see Policy.Do
+func Do(ctx context.Context, fn Func, options ...Option) error {
+	policy, err := New(options...)
+	if err != nil {
+		return err
+	}
+	return policy.Do(ctx, fn)
+}
+
+// Name returns the name of the policy's backoff strategy, e.g. "exponential"
+func (p *Policy) Name() string {
+	return p.newStrategy(p.config).Name()
+}
+
+// Attempts returns the most attempts an operation gets, counting the first
+func (p *Policy) Attempts() int {
+	return p.config.MaxAttempts
+}
+
+// Do calls fn until it succeeds, its error is not retryable, the attempts run
+// out, the circuit breaker opens or ctx ends. When the retries give up, the
+// fallback, if any, gets the last error and its result is returned; otherwise
+// the last error is, wrapped with the attempt count once they ran out. A
+// canceled ctx returns its error without the fallback.
+func (p *Policy) Do(ctx context.Context, fn Func) error {
+	backoff := p.Backoff()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		start := p.now()
+		err = p.call(ctx, fn)
+		if err == nil {
+			return nil
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+
+		if errors.Is(err, recovery.ErrCircuitBreakerOpen) || (p.retryIf != nil && !p.retryIf(err)) {
+			break
+		}
+		if !backoff.Allows(attempt + 1) {
+			err = fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+			break
+		}
+
+		delay := backoff.Delay(attempt, p.now().Sub(start))
+		if p.onRetry != nil {
+			p.onRetry(attempt, err, delay)
+		}
+		if err := p.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+
+	if p.fallback != nil {
+		return p.fallback(ctx, err)
+	}
+	return err
+}
+
+// call runs one attempt, through the circuit breaker if there is one
+func (p *Policy) call(ctx context.Context, fn Func) error {
+	if p.breaker != nil {
+		return p.breaker.Execute(ctx, fn)
+	}
+	return fn(ctx)
+}
+
+// now reads the policy's clock, or the wall clock without one
+func (p *Policy) now() time.Time {
+	if p.clock != nil {
+		return p.clock.Now()
+	}
+	return time.Now()
+}
+
+// sleep waits for delay on the policy's clock, returning early with ctx's
+// error if it ends first
+func (p *Policy) sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	var fired <-chan time.Time
+	if p.clock != nil {
+		timer := p.clock.NewTimer(delay)
+		defer timer.Stop()
+		fired = timer.C()
+	} else {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		fired = timer.C
+	}
+
+	select {
+	case <-fired:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Backoff returns a fresh sequence of the policy's delays for one operation
+// or schedule. Strategies such as decorrelated jitter derive each delay from
+// the one before, so sequences are not shared.
+func (p *Policy) Backoff() *Backoff {
+	return &Backoff{
+		strategy: p.newStrategy(p.config),
+		attempts: p.config.MaxAttempts,
+	}
+}
+
+// Backoff is one sequence of retry delays
+type Backoff struct {
+	strategy strategies.Strategy
+	attempts int
+}
+
+// Allows reports whether the given attempt, counting the first as 1, is
+// within the attempt limit
+func (b *Backoff) Allows(attempt int) bool {
+	return b.attempts <= 0 || attempt <= b.attempts
+}
+
+// Delay returns the wait before the given retry, 1 being the wait between the
+// first and second attempts. lastDuration is how long the last attempt took.
+func (b *Backoff) Delay(retry int, lastDuration time.Duration) time.Duration {
+	return b.strategy.NextDelay(retry, lastDuration)
+}
+
+// Strategy returns the strategy computing the delays
+func (b *Backoff) Strategy() strategies.Strategy {
+	return b.strategy
+}
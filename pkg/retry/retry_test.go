@@ -0,0 +1,199 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/swi/repeater/pkg/recovery"
+)
+
+var errTransient = errors.New("transient")
+
+// failing returns a Func that fails the given number of times, then succeeds,
+// counting its calls
+func failing(failures int, calls *int) Func {
+	return func(ctx context.Context) error {
+		*calls++
+		if *calls <= failures {
+			return errTransient
+		}
+		return nil
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  []Option
+		errorMsg string
+	}{
+		{name: "defaults"},
+		{name: "exponential", options: []Option{Exponential(time.Second, 2, time.Minute), Attempts(5)}},
+		{name: "fixed without delay", options: []Option{Fixed(0)}},
+		{name: "multiplier too small", options: []Option{Exponential(time.Second, 1, time.Minute)}, errorMsg: "invalid exponential retry policy: multiplier must be greater than 1.0"},
+		{name: "cap below base", options: []Option{Fibonacci(time.Minute, time.Second)}, errorMsg: "max-delay must be greater than base-delay"},
+		{name: "no increment", options: []Option{Linear(0, time.Second)}, errorMsg: "increment must be positive"},
+		{name: "no attempts", options: []Option{Attempts(0)}, errorMsg: "attempts must be positive"},
+		{name: "nil breaker", options: []Option{WithCircuitBreaker(nil)}, errorMsg: "circuit breaker cannot be nil"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.options...)
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errorMsg) {
+				t.Fatalf("error = %v, want %q", err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+func TestDo_RetriesUntilSuccess(t *testing.T) {
+	var calls int
+	var delays []time.Duration
+	err := Do(context.Background(), failing(3, &calls),
+		Exponential(time.Millisecond, 2, 0),
+		Attempts(5),
+		OnRetry(func(attempt int, err error, delay time.Duration) {
+			if !errors.Is(err, errTransient) {
+				t.Errorf("attempt %d: err = %v", attempt, err)
+			}
+			delays = append(delays, delay)
+		}),
+	)
+
+	if err != nil {
+		t.Fatalf("Do() = %v, want success", err)
+	}
+	if calls != 4 {
+		t.Errorf("calls = %d, want 4", calls)
+	}
+	want := []time.Duration{time.Millisecond, 2 * time.Millisecond, 4 * time.Millisecond}
+	if !slices.Equal(delays, want) {
+		t.Errorf("delays = %v, want %v", delays, want)
+	}
+}
+
+func TestDo_GivesUp(t *testing.T) {
+	var calls int
+	err := Do(context.Background(), failing(10, &calls), Fixed(0), Attempts(3))
+
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("Do() = %v, want the last error", err)
+	}
+	if !strings.Contains(err.Error(), "giving up after 3 attempts") {
+		t.Errorf("Do() = %v, want the attempt count", err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestDo_RetryIf(t *testing.T) {
+	errPermanent := errors.New("permanent")
+	calls := 0
+	err := Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errPermanent
+	}, Fixed(0), Attempts(5), RetryIf(func(err error) bool { return !errors.Is(err, errPermanent) }))
+
+	if err != errPermanent {
+		t.Fatalf("Do() = %v, want the permanent error unwrapped", err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestDo_CircuitBreakerAndFallback(t *testing.T) {
+	breaker := recovery.NewCircuitBreaker("api", 2, time.Hour, time.Hour)
+
+	var calls int
+	var fallbackErr error
+	err := Do(context.Background(), failing(10, &calls),
+		Fixed(0),
+		Attempts(5),
+		WithCircuitBreaker(breaker),
+		WithFallback(func(ctx context.Context, err error) error {
+			fallbackErr = err
+			return nil
+		}),
+	)
+
+	if err != nil {
+		t.Fatalf("Do() = %v, want the fallback's result", err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2 before the breaker opened", calls)
+	}
+	if !errors.Is(fallbackErr, recovery.ErrCircuitBreakerOpen) {
+		t.Errorf("fallback got %v, want ErrCircuitBreakerOpen", fallbackErr)
+	}
+	if breaker.State() != recovery.StateOpen {
+		t.Errorf("breaker state = %v, want open", breaker.State())
+	}
+
+	// An open breaker fails the next operation without calling it
+	err = Do(context.Background(), failing(0, &calls), WithCircuitBreaker(breaker))
+	if !errors.Is(err, recovery.ErrCircuitBreakerOpen) || calls != 2 {
+		t.Errorf("Do() = %v after %d calls, want ErrCircuitBreakerOpen without a call", err, calls)
+	}
+}
+
+func TestDo_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fallbackCalled := false
+
+	var calls int
+	err := Do(ctx, func(ctx context.Context) error {
+		calls++
+		cancel()
+		return errTransient
+	}, Fixed(time.Hour), WithFallback(func(context.Context, error) error {
+		fallbackCalled = true
+		return nil
+	}))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Do() = %v, want context.Canceled", err)
+	}
+	if calls != 1 || fallbackCalled {
+		t.Errorf("calls = %d, fallback called = %v; want 1 call and no fallback", calls, fallbackCalled)
+	}
+}
+
+func TestPolicy_BackoffIsFreshPerOperation(t *testing.T) {
+	policy, err := New(DecorrelatedJitter(time.Second, 3, time.Minute, 42), Attempts(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Name() != "decorrelated-jitter" || policy.Attempts() != 10 {
+		t.Errorf("policy = %s with %d attempts", policy.Name(), policy.Attempts())
+	}
+
+	sequence := func() []time.Duration {
+		backoff := policy.Backoff()
+		var delays []time.Duration
+		for retry := 1; backoff.Allows(retry + 1); retry++ {
+			delays = append(delays, backoff.Delay(retry, 0))
+		}
+		return delays
+	}
+
+	first, second := sequence(), sequence()
+	if len(first) != 9 {
+		t.Fatalf("got %d delays, want 9", len(first))
+	}
+	if !slices.Equal(first, second) {
+		t.Errorf("seeded sequences differ: %v vs %v", first, second)
+	}
+}
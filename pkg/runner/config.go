@@ -0,0 +1,407 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/swi/repeater/pkg/executor"
+	"github.com/swi/repeater/pkg/httpaware"
+	"github.com/swi/repeater/pkg/patterns"
+	"github.com/swi/repeater/pkg/probe"
+)
+
+// Config describes a run: what each execution runs, how executions are
+// scheduled, when the run stops and how results are judged. The CLI parses
+// flags into it (cli.Config is an alias); library users build it through
+// pkg/repeater.
+type Config struct {
+	Subcommand string
+	Every      time.Duration
+	Times      int64
+	For        time.Duration
+	Command    []string
+
+	// Probe runs in place of Command when set, e.g. a Go function wrapped by
+	// pkg/repeater. It takes precedence over the built-in probe fields below.
+	Probe executor.Probe
+
+	// Observers follow the run's lifecycle, after the built-in health, metrics
+	// and verbose observers; see Observer and Runner.AddObserver
+	Observers []Observer
+
+	// Command-line only fields; the runner ignores them
+	Help           bool
+	SubcommandHelp bool // help for specific subcommand
+	Version        bool
+	ConfigFile     string
+
+	// Rate limiting fields
+	RateSpec      string // e.g., "10/1h", "10/s,1000/hour"; throttles any subcommand
+	Burst         int64  // most executions admitted back to back (0 = algorithm default)
+	RateAlgorithm string // diophantine (default), token-bucket, gcra, sliding-log or sliding-counter
+	RetryPattern  string // e.g., "0,10m,30m"
+	ShowNext      bool   // show next allowed time
+
+	// Schedule composition fields
+	Delay    time.Duration // delay every scheduled execution by this offset
+	StartAt  time.Time     // hold back the first execution until this time
+	Deadline time.Time     // stop scheduling executions at this time
+	OrEvery  time.Duration // also fire every OrEvery beside the subcommand's schedule
+	OrCron   string        // also fire on this cron expression
+	AndCron  string        // fire only once this cron expression has fired too
+
+	// Interval timing fields
+	IntervalMode string  // fixed-rate (default) or fixed-delay
+	Align        bool    // start on a wall-clock multiple of --every
+	Jitter       float64 // randomize each interval by up to this fraction of --every
+
+	// Multi-phase scheduling fields
+	Phases []PhaseConfig // phases run in order by the phases subcommand
+
+	// Adaptive scheduling fields
+	BaseInterval     time.Duration // base interval for adaptation
+	MinInterval      time.Duration // minimum interval bound
+	MaxInterval      time.Duration // maximum interval bound
+	SlowThreshold    float64       // threshold for slow response (multiplier)
+	FastThreshold    float64       // threshold for fast response (multiplier)
+	FailureThreshold float64       // circuit breaker failure threshold
+	ShowMetrics      bool          // show adaptive metrics
+
+	// New unified strategy fields
+	BaseDelay  time.Duration // base/initial delay for all strategies
+	Increment  time.Duration // linear increment (linear strategy)
+	Multiplier float64       // exponential/jitter multiplier
+	Exponent   float64       // polynomial exponent
+	MaxDelay   time.Duration // maximum delay cap for all strategies
+
+	// Load-aware adaptive fields
+	TargetCPU    float64 // target CPU usage percentage (0-100)
+	TargetMemory float64 // target memory usage percentage (0-100)
+	TargetLoad   float64 // target load average
+
+	// Cron scheduling fields
+	CronExpression string // cron expression for scheduling
+	Timezone       string // timezone for cron scheduling
+
+	// Output control fields
+	Stream       bool   // stream command output in real-time
+	Quiet        bool   // suppress all output
+	Verbose      bool   // show detailed execution information
+	StatsOnly    bool   // show only statistics, suppress command output
+	OutputPrefix string // prefix for output lines
+
+	// Pattern matching fields
+	SuccessPattern    string   // regex pattern indicating success in output
+	FailurePattern    string   // regex pattern indicating failure in output
+	CaseInsensitive   bool     // make pattern matching case-insensitive
+	SuccessPatterns   []string // more success patterns, after SuccessPattern
+	FailurePatterns   []string // more failure patterns, after FailurePattern
+	RequireAbsent     []string // patterns whose presence fails an execution
+	FatalPatterns     []string // patterns whose presence aborts the run
+	MatchMode         string   // any or all of the success and failure patterns must match
+	MinMatches        int      // matches a success or failure pattern needs
+	FailFastOnPattern bool     // stop the command as soon as a failure or fatal pattern matches
+	SucceedOnPattern  bool     // stop the command as a success once the success patterns match
+	SuccessExpr       string   // condition an execution must meet to succeed, see pkg/expr
+	FailureExpr       string   // condition failing an execution, see pkg/expr
+	ExpectJSON        []string // PATH=VALUE assertions on JSON output, e.g. .replicas.ready=3
+	ExpectJSONExpr    string   // jq-style condition on JSON output, e.g. .status == "ok"
+
+	// Exit-code mapping fields, applied before pattern matching
+	SuccessCodes string // exit codes counted as success, e.g. 0,1
+	RetryCodes   string // exit codes worth retrying, e.g. 75,111
+	AbortCodes   string // exit codes that abort the run, e.g. 2,64-78
+
+	// HTTP status classification fields
+	HTTPSuccessCodes string // HTTP statuses counted as success, e.g. 2xx,304
+	HTTPRetryCodes   string // HTTP statuses counted as retryable failures, e.g. 429,5xx
+	HTTPFatalCodes   string // HTTP statuses that abort the run, e.g. 401,403
+
+	// Native HTTP probe fields; a probe runs in place of a command
+	HTTPMethod       string        // request method, set together with the URL by --http
+	HTTPURL          string        // request URL
+	HTTPHeaders      []string      // request headers as "Name: value"
+	HTTPData         string        // request body
+	HTTPTimeout      time.Duration // per-request timeout
+	HTTPInsecure     bool          // skip TLS certificate verification
+	HTTPFollow       bool          // follow redirects
+	HTTPExpectStatus string        // statuses the probe passes on, e.g. 2xx,304
+
+	// Socket and DNS probe fields; like --http they replace the command
+	TCPAddress   string        // host:port to connect to
+	UDPAddress   string        // host:port to send to
+	UnixSocket   string        // path of a Unix socket to connect to
+	DNSName      string        // name to resolve
+	DNSServer    string        // DNS server host[:port]; empty uses the system resolver
+	DNSType      string        // record type: A (default), AAAA or SRV
+	ProbeSend    string        // payload sent by TCP, UDP and Unix probes
+	ProbeExpect  string        // reply, banner or DNS answer the probe must see
+	ProbeTimeout time.Duration // per-probe timeout for socket and DNS probes
+
+	// HTTP-aware scheduling fields
+	HTTPAware        bool          // enable HTTP-aware intelligent scheduling
+	HTTPMaxDelay     time.Duration // maximum delay cap for HTTP timing
+	HTTPMinDelay     time.Duration // minimum delay floor for HTTP timing
+	HTTPParseJSON    bool          // parse JSON response bodies for timing
+	HTTPParseHeaders bool          // parse HTTP headers for timing
+	HTTPTrustClient  bool          // trust 4xx client error timing
+	HTTPCustomFields []string      // custom JSON fields to check for timing
+	Aware            []string      // response parsers to apply, e.g. grpc,aws,http
+
+	// Dry-run preview fields
+	DryRun       bool  // print the projected schedule instead of executing
+	PreviewCount int   // number of projected executions to print
+	Seed         int64 // random seed for jittered delays (0 = time-based)
+
+	// Config file fields (loaded from TOML)
+	Timeout        time.Duration // command execution timeout
+	MaxRetries     int           // maximum retry attempts
+	LogLevel       string        // logging level
+	MetricsEnabled bool          // enable metrics collection
+	MetricsPort    int           // metrics server port
+	HealthEnabled  bool          // enable health check endpoint
+	HealthPort     int           // health check server port
+}
+
+// GetPatternConfig returns the pattern matching configuration, or nil if
+// results are judged by exit code alone
+func (c *Config) GetPatternConfig() *patterns.PatternConfig {
+	if c.SuccessPattern == "" && c.FailurePattern == "" && len(c.SuccessPatterns) == 0 && len(c.FailurePatterns) == 0 &&
+		len(c.RequireAbsent) == 0 && len(c.FatalPatterns) == 0 &&
+		c.SuccessExpr == "" && c.FailureExpr == "" && len(c.ExpectJSON) == 0 && c.ExpectJSONExpr == "" &&
+		c.HTTPSuccessCodes == "" && c.HTTPRetryCodes == "" && c.HTTPFatalCodes == "" {
+		return nil
+	}
+
+	return &patterns.PatternConfig{
+		SuccessPattern:    c.SuccessPattern,
+		FailurePattern:    c.FailurePattern,
+		CaseInsensitive:   c.CaseInsensitive,
+		SuccessPatterns:   c.SuccessPatterns,
+		FailurePatterns:   c.FailurePatterns,
+		RequireAbsent:     c.RequireAbsent,
+		FatalPatterns:     c.FatalPatterns,
+		MatchMode:         patterns.MatchMode(c.MatchMode),
+		MinMatches:        c.MinMatches,
+		FailFastOnPattern: c.FailFastOnPattern,
+		SucceedOnPattern:  c.SucceedOnPattern,
+		SuccessExpr:       c.SuccessExpr,
+		FailureExpr:       c.FailureExpr,
+		ExpectJSON:        c.ExpectJSON,
+		ExpectJSONExpr:    c.ExpectJSONExpr,
+		HTTPSuccessCodes:  c.HTTPSuccessCodes,
+		HTTPRetryCodes:    c.HTTPRetryCodes,
+		HTTPFatalCodes:    c.HTTPFatalCodes,
+	}
+}
+
+// GetExitCodeConfig returns the exit-code mapping, or nil if exit codes are
+// taken as they are
+func (c *Config) GetExitCodeConfig() *executor.ExitCodeConfig {
+	if c.SuccessCodes == "" && c.RetryCodes == "" && c.AbortCodes == "" {
+		return nil
+	}
+	return &executor.ExitCodeConfig{
+		SuccessCodes: c.SuccessCodes,
+		RetryCodes:   c.RetryCodes,
+		AbortCodes:   c.AbortCodes,
+	}
+}
+
+// HasProbe reports whether a probe runs in place of a command
+func (c *Config) HasProbe() bool {
+	return c.Probe != nil || c.HTTPURL != "" || c.TCPAddress != "" ||
+		c.UDPAddress != "" || c.UnixSocket != "" || c.DNSName != ""
+}
+
+// ProbeTarget describes the configured probe, e.g. "tcp db:5432", or returns
+// "" if none is set
+func (c *Config) ProbeTarget() string {
+	switch {
+	case c.Probe != nil:
+		return c.Probe.Name()
+	case c.HTTPURL != "":
+		return strings.ToUpper(c.HTTPMethod) + " " + c.HTTPURL
+	case c.TCPAddress != "":
+		return "tcp " + c.TCPAddress
+	case c.UDPAddress != "":
+		return "udp " + c.UDPAddress
+	case c.UnixSocket != "":
+		return "unix " + c.UnixSocket
+	case c.DNSName != "":
+		return "dns " + c.DNSName
+	}
+	return ""
+}
+
+// GetHTTPProbeConfig returns the native HTTP probe configuration, or nil if
+// --http is not set
+func (c *Config) GetHTTPProbeConfig() *probe.HTTPConfig {
+	if c.HTTPURL == "" {
+		return nil
+	}
+
+	return &probe.HTTPConfig{
+		Method:       c.HTTPMethod,
+		URL:          c.HTTPURL,
+		Headers:      c.HTTPHeaders,
+		Data:         c.HTTPData,
+		Timeout:      c.HTTPTimeout,
+		Insecure:     c.HTTPInsecure,
+		Follow:       c.HTTPFollow,
+		ExpectStatus: c.HTTPExpectStatus,
+	}
+}
+
+// GetSocketProbeConfig returns the TCP, UDP or Unix socket probe
+// configuration, or nil if none of --tcp, --udp and --unix is set
+func (c *Config) GetSocketProbeConfig() *probe.SocketConfig {
+	config := &probe.SocketConfig{
+		Send:    c.ProbeSend,
+		Expect:  c.ProbeExpect,
+		Timeout: c.ProbeTimeout,
+	}
+
+	switch {
+	case c.TCPAddress != "":
+		config.Network, config.Address = "tcp", c.TCPAddress
+	case c.UDPAddress != "":
+		config.Network, config.Address = "udp", c.UDPAddress
+	case c.UnixSocket != "":
+		config.Network, config.Address = "unix", c.UnixSocket
+	default:
+		return nil
+	}
+	return config
+}
+
+// GetDNSProbeConfig returns the DNS probe configuration, or nil if --dns is
+// not set
+func (c *Config) GetDNSProbeConfig() *probe.DNSConfig {
+	if c.DNSName == "" {
+		return nil
+	}
+
+	return &probe.DNSConfig{
+		Name:    c.DNSName,
+		Server:  c.DNSServer,
+		Type:    c.DNSType,
+		Expect:  c.ProbeExpect,
+		Timeout: c.ProbeTimeout,
+	}
+}
+
+// GetHTTPAwareConfig returns the HTTP-aware scheduling configuration, or nil
+// if it is disabled
+func (c *Config) GetHTTPAwareConfig() *httpaware.HTTPAwareConfig {
+	if !c.HTTPAware && len(c.Aware) == 0 {
+		return nil
+	}
+
+	// Set defaults
+	config := &httpaware.HTTPAwareConfig{
+		MaxDelay:          c.HTTPMaxDelay,
+		MinDelay:          c.HTTPMinDelay,
+		ParseJSON:         c.HTTPParseJSON,
+		ParseHeaders:      c.HTTPParseHeaders,
+		TrustClientErrors: c.HTTPTrustClient,
+		JSONFields:        c.HTTPCustomFields,
+		Parsers:           c.awareParsers(),
+	}
+
+	// Apply defaults if not set
+	if config.MaxDelay == 0 {
+		config.MaxDelay = 30 * time.Minute // Default max delay
+	}
+	if config.MinDelay == 0 {
+		config.MinDelay = 1 * time.Second // Default min delay
+	}
+	if config.JSONFields == nil {
+		config.JSONFields = []string{"retry_after", "retryAfter"} // Default fields
+	}
+
+	return config
+}
+
+// awareParsers returns the response parsers selected by --aware, adding http
+// when --http-aware is also given. Nil means the http parser alone.
+func (c *Config) awareParsers() []string {
+	if len(c.Aware) == 0 {
+		return nil
+	}
+
+	parsers := slices.Clone(c.Aware)
+	if c.HTTPAware && !slices.Contains(parsers, httpaware.ParserHTTP) {
+		parsers = append(parsers, httpaware.ParserHTTP)
+	}
+	return parsers
+}
+
+// PhaseConfig describes one phase of the phases subcommand. Times and For end
+// the phase; the remaining fields configure the phase's scheduler.
+type PhaseConfig struct {
+	Name  string
+	Mode  string        // interval, exponential, adaptive or cron
+	Times int64         // executions before moving on (0 = unlimited)
+	For   time.Duration // time before moving on (0 = unlimited)
+
+	Every          time.Duration // interval
+	BaseDelay      time.Duration // exponential
+	Multiplier     float64       // exponential
+	MaxDelay       time.Duration // exponential
+	Attempts       int           // exponential
+	BaseInterval   time.Duration // adaptive
+	MinInterval    time.Duration // adaptive
+	MaxInterval    time.Duration // adaptive
+	CronExpression string        // cron
+	Timezone       string        // cron
+}
+
+// String summarises the phase, e.g. "interval every 1s for 1m0s"
+func (p PhaseConfig) String() string {
+	var b strings.Builder
+	b.WriteString(p.Mode)
+
+	switch p.Mode {
+	case "interval":
+		fmt.Fprintf(&b, " every %v", p.Every)
+	case "exponential":
+		fmt.Fprintf(&b, " from %v", p.BaseDelay)
+	case "adaptive":
+		fmt.Fprintf(&b, " around %v", p.BaseInterval)
+	case "cron":
+		fmt.Fprintf(&b, " %q", p.CronExpression)
+	}
+
+	if p.Times > 0 {
+		fmt.Fprintf(&b, " x%d", p.Times)
+	}
+	if p.For > 0 {
+		fmt.Fprintf(&b, " for %v", p.For)
+	}
+
+	return b.String()
+}
+
+// ForPhase returns a copy of the config that schedules like the given phase.
+// The run-wide --times and --for limits are cleared; the phase's own limits
+// are enforced by the phased scheduler.
+func (c *Config) ForPhase(phase PhaseConfig) *Config {
+	derived := *c
+	derived.Subcommand = phase.Mode
+	derived.Times = 0
+	derived.For = 0
+	derived.Every = phase.Every
+	derived.BaseDelay = phase.BaseDelay
+	derived.Multiplier = phase.Multiplier
+	derived.MaxDelay = phase.MaxDelay
+	derived.MaxRetries = phase.Attempts
+	derived.BaseInterval = phase.BaseInterval
+	derived.MinInterval = phase.MinInterval
+	derived.MaxInterval = phase.MaxInterval
+	derived.CronExpression = phase.CronExpression
+	derived.Timezone = phase.Timezone
+	return &derived
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/swi/repeater/pkg/cli"
 )
 
 func TestCronSchedulerIntegration(t *testing.T) {
@@ -62,7 +61,7 @@ func TestCronSchedulerIntegration(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create config with cron settings
-			config := &cli.Config{
+			config := &Config{
 				Subcommand:     "cron",
 				CronExpression: tt.cronExpression,
 				Timezone:       tt.timezone,
@@ -105,7 +104,7 @@ func TestCronSchedulerExecution(t *testing.T) {
 
 	t.Run("cron scheduler should execute command at scheduled time", func(t *testing.T) {
 		// Create config with a cron expression that triggers every minute
-		config := &cli.Config{
+		config := &Config{
 			Subcommand:     "cron",
 			CronExpression: "* * * * *", // Every minute
 			Timezone:       "UTC",
@@ -163,7 +162,7 @@ func TestCronSchedulerWithShortcuts(t *testing.T) {
 
 	for _, shortcut := range shortcuts {
 		t.Run("shortcut_"+shortcut, func(t *testing.T) {
-			config := &cli.Config{
+			config := &Config{
 				Subcommand:     "cron",
 				CronExpression: shortcut,
 				Timezone:       "UTC",
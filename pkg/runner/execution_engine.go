@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"github.com/swi/repeater/pkg/adaptive"
-	"github.com/swi/repeater/pkg/cli"
 	"github.com/swi/repeater/pkg/executor"
 	"github.com/swi/repeater/pkg/httpaware"
 	"github.com/swi/repeater/pkg/interfaces"
@@ -16,14 +15,14 @@ import (
 
 // ExecutionEngine handles the core execution loop and statistics
 type ExecutionEngine struct {
-	config             *cli.Config
+	config             *Config
 	httpAwareScheduler httpaware.HTTPAwareScheduler
 	executor           *executor.Executor
 	patternMatcher     *patterns.PatternMatcher
 }
 
 // NewExecutionEngine creates a new execution engine
-func NewExecutionEngine(config *cli.Config, httpAwareScheduler httpaware.HTTPAwareScheduler) (*ExecutionEngine, error) {
+func NewExecutionEngine(config *Config, httpAwareScheduler httpaware.HTTPAwareScheduler) (*ExecutionEngine, error) {
 	exec, err := executor.NewExecutor()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create executor: %w", err)
@@ -10,13 +10,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/swi/repeater/pkg/cli"
 	"github.com/swi/repeater/pkg/health"
 )
 
 func TestHealthServerEndToEnd(t *testing.T) {
 	// Create config with health enabled
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:    "count",
 		Times:         3,
 		Command:       []string{"echo", "health-e2e-test"},
@@ -131,7 +130,7 @@ func TestHealthServerEndToEnd(t *testing.T) {
 func TestHealthServerWithConfigFile(t *testing.T) {
 	// This test verifies that health server works with config file settings
 	// Create config that would come from config file
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:    "interval",
 		Every:         500 * time.Millisecond,
 		Times:         2,
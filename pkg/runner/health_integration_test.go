@@ -10,7 +10,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/swi/repeater/pkg/cli"
 	healthpkg "github.com/swi/repeater/pkg/health"
 )
 
@@ -41,7 +40,7 @@ func TestHealthServerIntegration(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create config with health settings
-			config := &cli.Config{
+			config := &Config{
 				Subcommand:    "count",
 				Times:         2,
 				Command:       []string{"echo", "health-test"},
@@ -79,7 +78,7 @@ func TestHealthServerStatsUpdate(t *testing.T) {
 	// It should test that execution stats are properly updated in the health endpoint
 
 	// Create config with health enabled
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:    "count",
 		Times:         3,
 		Command:       []string{"echo", "stats-test"},
@@ -11,12 +11,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/swi/repeater/pkg/cli"
 )
 
 func TestMetricsServerEndToEnd(t *testing.T) {
 	// Create config with metrics enabled
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:     "count",
 		Times:          3,
 		Command:        []string{"echo", "metrics-e2e-test"},
@@ -120,7 +119,7 @@ func TestMetricsServerEndToEnd(t *testing.T) {
 
 func TestMetricsServerWithAdaptiveScheduling(t *testing.T) {
 	// Test metrics server with adaptive scheduling to verify scheduler interval recording
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:     "adaptive",
 		BaseInterval:   100 * time.Millisecond,
 		MinInterval:    50 * time.Millisecond,
@@ -166,7 +165,7 @@ func TestMetricsServerWithAdaptiveScheduling(t *testing.T) {
 
 func TestMetricsServerWithConfigFile(t *testing.T) {
 	// This test verifies that metrics server works with config file settings
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:     "interval",
 		Every:          500 * time.Millisecond,
 		Times:          2,
@@ -10,7 +10,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/swi/repeater/pkg/cli"
 )
 
 func TestMetricsServerIntegration(t *testing.T) {
@@ -40,7 +39,7 @@ func TestMetricsServerIntegration(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create config with metrics settings
-			config := &cli.Config{
+			config := &Config{
 				Subcommand:     "count",
 				Times:          2,
 				Command:        []string{"echo", "metrics-test"},
@@ -78,7 +77,7 @@ func TestMetricsServerStatsUpdate(t *testing.T) {
 	// It should test that execution metrics are properly recorded in the metrics endpoint
 
 	// Create config with metrics enabled
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:     "count",
 		Times:          3,
 		Command:        []string{"echo", "metrics-stats-test"},
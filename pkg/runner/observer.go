@@ -0,0 +1,271 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/swi/repeater/pkg/adaptive"
+	"github.com/swi/repeater/pkg/health"
+	"github.com/swi/repeater/pkg/httpaware"
+	"github.com/swi/repeater/pkg/metrics"
+)
+
+// Observer follows the lifecycle of a run. The runner never overlaps calls to
+// its observers, but output lines and phase changes arrive from other
+// goroutines than Run's. Observers should return quickly: the run waits for
+// them. Embed BaseObserver to implement only some of the methods.
+type Observer interface {
+	// OnRunStart is called once the run is set up, before the first tick.
+	// ctx ends when the caller of Run cancels it.
+	OnRunStart(ctx context.Context, config *Config)
+
+	// OnTick is called for each tick that leads to an execution, with the
+	// time the scheduler fired it for and the time the runner received it
+	OnTick(scheduled, actual time.Time)
+
+	// OnExecutionStart is called just before an execution, numbered from 1
+	OnExecutionStart(number int, at time.Time)
+
+	// OnOutputLine is called for each line an execution writes, stream being
+	// "stdout" or "stderr"
+	OnOutputLine(number int, stream, line string)
+
+	// OnExecutionEnd is called with the record of each finished execution
+	OnExecutionEnd(record ExecutionRecord)
+
+	// OnSchedulerStateChange is called when the schedule changes course, such
+	// as a new phase or an adapted interval
+	OnSchedulerStateChange(change SchedulerStateChange)
+
+	// OnRunEnd is called once with Run's results; stats is never nil
+	OnRunEnd(stats *ExecutionStats, err error)
+}
+
+// BaseObserver implements every Observer method as a no-op
+type BaseObserver struct{}
+
+func (BaseObserver) OnRunStart(context.Context, *Config)                {}
+func (BaseObserver) OnTick(scheduled, actual time.Time)                 {}
+func (BaseObserver) OnExecutionStart(number int, at time.Time)          {}
+func (BaseObserver) OnOutputLine(number int, stream, line string)       {}
+func (BaseObserver) OnExecutionEnd(record ExecutionRecord)              {}
+func (BaseObserver) OnSchedulerStateChange(change SchedulerStateChange) {}
+func (BaseObserver) OnRunEnd(stats *ExecutionStats, err error)          {}
+
+// StateChangeKind identifies what changed in a SchedulerStateChange
+type StateChangeKind string
+
+const (
+	// PhaseStarted: a phased schedule moved on to Phase
+	PhaseStarted StateChangeKind = "phase-started"
+	// IntervalAdapted: the adaptive scheduler updated from an execution
+	IntervalAdapted StateChangeKind = "interval-adapted"
+	// ResponseDelayed: a response's retry timing postpones the next tick
+	ResponseDelayed StateChangeKind = "response-delayed"
+)
+
+// SchedulerStateChange describes a change in the schedule. The fields beyond
+// Kind and At are set as the kind describes.
+type SchedulerStateChange struct {
+	Kind StateChangeKind
+	At   time.Time
+
+	// PhaseStarted
+	Phase       int    // index of the phase, from 0
+	PhaseCount  int    // number of phases
+	PhaseName   string // name of the phase
+	PhaseDetail string // the phase's schedule, e.g. "interval every 1s, 10 times"
+
+	// IntervalAdapted and ResponseDelayed
+	Interval time.Duration // the adapted interval, or the response's delay
+
+	// IntervalAdapted
+	Adaptive *adaptive.AdaptiveMetrics
+
+	// ResponseDelayed
+	Source httpaware.TimingSource // where the delay came from, e.g. a Retry-After header
+}
+
+// observerSet fans lifecycle events out to the run's observers, serializing
+// them across goroutines
+type observerSet struct {
+	mu        sync.Mutex
+	observers []Observer
+	execution int // number of the execution in progress, for output lines
+}
+
+func (s *observerSet) add(observer Observer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.observers = append(s.observers, observer)
+}
+
+// each calls notify with every observer, holding the lock
+func (s *observerSet) each(notify func(Observer)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, observer := range s.observers {
+		notify(observer)
+	}
+}
+
+func (s *observerSet) empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.observers) == 0
+}
+
+func (s *observerSet) runStart(ctx context.Context, config *Config) {
+	s.each(func(o Observer) { o.OnRunStart(ctx, config) })
+}
+
+func (s *observerSet) tick(scheduled, actual time.Time) {
+	s.each(func(o Observer) { o.OnTick(scheduled, actual) })
+}
+
+func (s *observerSet) executionStart(number int, at time.Time) {
+	s.each(func(o Observer) { o.OnExecutionStart(number, at) })
+	s.mu.Lock()
+	s.execution = number
+	s.mu.Unlock()
+}
+
+// outputLine is the executor's line handler
+func (s *observerSet) outputLine(stream, line string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, observer := range s.observers {
+		observer.OnOutputLine(s.execution, stream, line)
+	}
+}
+
+func (s *observerSet) executionEnd(record ExecutionRecord) {
+	s.each(func(o Observer) { o.OnExecutionEnd(record) })
+}
+
+func (s *observerSet) stateChange(change SchedulerStateChange) {
+	s.each(func(o Observer) { o.OnSchedulerStateChange(change) })
+}
+
+func (s *observerSet) runEnd(stats *ExecutionStats, err error) {
+	s.each(func(o Observer) { o.OnRunEnd(stats, err) })
+}
+
+// healthObserver serves the run's progress on the health endpoints
+type healthObserver struct {
+	BaseObserver
+	server *health.HealthServer
+	log    io.Writer // receives server errors; nil drops them
+	stats  health.ExecutionStats
+}
+
+func (o *healthObserver) OnRunStart(ctx context.Context, config *Config) {
+	o.stats = health.ExecutionStats{}
+	go func() {
+		if err := o.server.Start(ctx); err != nil && o.log != nil {
+			fmt.Fprintf(o.log, "Health server error: %v\n", err)
+		}
+	}()
+	o.server.SetReady(true)
+}
+
+func (o *healthObserver) OnExecutionEnd(record ExecutionRecord) {
+	o.stats.TotalExecutions++
+	if record.Success {
+		o.stats.SuccessfulExecutions++
+	} else {
+		o.stats.FailedExecutions++
+	}
+	o.stats.LastExecution = record.EndTime
+	o.server.SetExecutionStats(o.stats)
+}
+
+// metricsObserver records the run on the Prometheus metrics endpoint
+type metricsObserver struct {
+	BaseObserver
+	server *metrics.MetricsServer
+	log    io.Writer // receives server errors; nil drops them
+}
+
+func (o *metricsObserver) OnRunStart(ctx context.Context, config *Config) {
+	go func() {
+		if err := o.server.Start(ctx); err != nil && o.log != nil {
+			fmt.Fprintf(o.log, "Metrics server error: %v\n", err)
+		}
+	}()
+}
+
+func (o *metricsObserver) OnExecutionEnd(record ExecutionRecord) {
+	o.server.RecordExecution(record.Success, record.Duration)
+}
+
+func (o *metricsObserver) OnSchedulerStateChange(change SchedulerStateChange) {
+	switch change.Kind {
+	case PhaseStarted:
+		o.server.RecordPhase(change.Phase, change.PhaseName)
+	case IntervalAdapted:
+		o.server.RecordSchedulerInterval(change.Interval)
+	}
+}
+
+// verbosePrinter prints the details --verbose asks for
+type verbosePrinter struct {
+	BaseObserver
+	out io.Writer
+}
+
+func (p *verbosePrinter) OnExecutionEnd(record ExecutionRecord) {
+	if record.Phases != nil {
+		fmt.Fprintf(p.out, "Probe: %s\n", record.Phases)
+	}
+	if !record.Success && record.Reason != "" {
+		fmt.Fprintf(p.out, "Execution %d failed with exit code %d: %s\n", record.ExecutionNumber, record.ExitCode, record.Reason)
+	}
+	if len(record.MatchedPatterns) > 0 {
+		fmt.Fprintf(p.out, "Execution %d matched: %s\n", record.ExecutionNumber, strings.Join(record.MatchedPatterns, ", "))
+	}
+}
+
+func (p *verbosePrinter) OnSchedulerStateChange(change SchedulerStateChange) {
+	switch change.Kind {
+	case PhaseStarted:
+		fmt.Fprintf(p.out, "🔀 Phase %d/%d (%s): %s\n", change.Phase+1, change.PhaseCount, change.PhaseName, change.PhaseDetail)
+	case ResponseDelayed:
+		fmt.Fprintf(p.out, "HTTP-aware: Found %s timing, delaying next execution by %v\n", change.Source, change.Interval)
+	}
+}
+
+// adaptiveMetricsPrinter prints the adaptive scheduler's state after every
+// execution, as --show-metrics asks
+type adaptiveMetricsPrinter struct {
+	BaseObserver
+	out io.Writer
+}
+
+func (p *adaptiveMetricsPrinter) OnSchedulerStateChange(change SchedulerStateChange) {
+	if change.Kind != IntervalAdapted || change.Adaptive == nil {
+		return
+	}
+	fmt.Fprintf(p.out, "📊 Adaptive Metrics: Interval=%v, Success=%.1f%%, Circuit=%s\n",
+		change.Adaptive.CurrentInterval.Round(time.Millisecond),
+		change.Adaptive.SuccessRate*100,
+		circuitStateString(change.Adaptive.CircuitState))
+}
+
+// circuitStateString converts circuit state to readable string
+func circuitStateString(state adaptive.CircuitState) string {
+	switch state {
+	case adaptive.CircuitClosed:
+		return "CLOSED"
+	case adaptive.CircuitOpen:
+		return "OPEN"
+	case adaptive.CircuitHalfOpen:
+		return "HALF-OPEN"
+	default:
+		return "UNKNOWN"
+	}
+}
@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingObserver logs every lifecycle event it sees
+type recordingObserver struct {
+	events  []string
+	records []ExecutionRecord
+	changes []SchedulerStateChange
+	stats   *ExecutionStats
+}
+
+func (o *recordingObserver) OnRunStart(ctx context.Context, config *Config) {
+	o.events = append(o.events, "run-start "+config.Subcommand)
+}
+
+func (o *recordingObserver) OnTick(scheduled, actual time.Time) {
+	o.events = append(o.events, "tick")
+}
+
+func (o *recordingObserver) OnExecutionStart(number int, at time.Time) {
+	o.events = append(o.events, fmt.Sprintf("start %d", number))
+}
+
+func (o *recordingObserver) OnOutputLine(number int, stream, line string) {
+	o.events = append(o.events, fmt.Sprintf("line %d %s %s", number, stream, line))
+}
+
+func (o *recordingObserver) OnExecutionEnd(record ExecutionRecord) {
+	o.events = append(o.events, fmt.Sprintf("end %d", record.ExecutionNumber))
+	o.records = append(o.records, record)
+}
+
+func (o *recordingObserver) OnSchedulerStateChange(change SchedulerStateChange) {
+	o.changes = append(o.changes, change)
+}
+
+func (o *recordingObserver) OnRunEnd(stats *ExecutionStats, err error) {
+	o.events = append(o.events, "run-end")
+	o.stats = stats
+}
+
+func TestRunner_Observers(t *testing.T) {
+	fromConfig, added := &recordingObserver{}, &recordingObserver{}
+	config := &Config{
+		Subcommand:     "count",
+		Times:          2,
+		Quiet:          true,
+		Command:        []string{"sh", "-c", "echo hello; echo oops >&2"},
+		FailurePattern: "oops",
+		Observers:      []Observer{fromConfig},
+	}
+
+	r, err := NewRunner(config)
+	require.NoError(t, err)
+	r.AddObserver(added)
+
+	stats, err := r.Run(context.Background())
+	require.NoError(t, err)
+
+	for _, observer := range []*recordingObserver{fromConfig, added} {
+		require.Len(t, observer.events, 12)
+		assert.Equal(t, []string{"run-start count", "tick", "start 1"}, observer.events[:3])
+		assert.ElementsMatch(t, []string{"line 1 stdout hello", "line 1 stderr oops"}, observer.events[3:5])
+		assert.Equal(t, []string{"end 1", "tick", "start 2"}, observer.events[5:8])
+		assert.ElementsMatch(t, []string{"line 2 stdout hello", "line 2 stderr oops"}, observer.events[8:10])
+		assert.Equal(t, []string{"end 2", "run-end"}, observer.events[10:])
+		assert.Same(t, stats, observer.stats)
+
+		require.Len(t, observer.records, 2)
+		assert.False(t, observer.records[0].Success)
+		assert.Equal(t, "failure pattern matched", observer.records[0].Reason)
+	}
+}
+
+func TestRunner_ObserverPhaseChanges(t *testing.T) {
+	observer := &recordingObserver{}
+	config := &Config{
+		Subcommand: "phases",
+		Phases: []PhaseConfig{
+			{Name: "warmup", Mode: "interval", Every: time.Millisecond, Times: 1},
+			{Name: "steady", Mode: "interval", Every: time.Millisecond},
+		},
+		Times:     2,
+		Quiet:     true,
+		Command:   []string{"true"},
+		Observers: []Observer{observer},
+	}
+
+	r, err := NewRunner(config)
+	require.NoError(t, err)
+	_, err = r.Run(context.Background())
+	require.NoError(t, err)
+
+	var phases []string
+	for _, change := range observer.changes {
+		if change.Kind == PhaseStarted {
+			assert.Equal(t, 2, change.PhaseCount)
+			phases = append(phases, change.PhaseName)
+		}
+	}
+	assert.Equal(t, []string{"warmup", "steady"}, phases)
+}
+
+func TestVerbosePrinter(t *testing.T) {
+	var out bytes.Buffer
+	printer := &verbosePrinter{out: &out}
+
+	printer.OnExecutionEnd(ExecutionRecord{ExecutionNumber: 1, Success: true, Reason: "exit code used"})
+	printer.OnExecutionEnd(ExecutionRecord{ExecutionNumber: 2, ExitCode: 1, Reason: "grpc parser reported UNAVAILABLE"})
+	printer.OnExecutionEnd(ExecutionRecord{ExecutionNumber: 3, ExitCode: 1, Reason: "failure pattern matched", MatchedPatterns: []string{"stderr:ERROR", "done"}})
+	printer.OnSchedulerStateChange(SchedulerStateChange{Kind: PhaseStarted, Phase: 1, PhaseCount: 3, PhaseName: "steady", PhaseDetail: "interval every 1s"})
+
+	assert.Equal(t, "Execution 2 failed with exit code 1: grpc parser reported UNAVAILABLE\n"+
+		"Execution 3 failed with exit code 1: failure pattern matched\n"+
+		"Execution 3 matched: stderr:ERROR, done\n"+
+		"🔀 Phase 2/3 (steady): interval every 1s\n", out.String())
+}
@@ -0,0 +1,396 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/swi/repeater/pkg/ratelimit"
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+// PreviewEntry represents one projected execution in a dry-run schedule preview
+type PreviewEntry struct {
+	ExecutionNumber int
+	Time            time.Time
+	Delay           time.Duration // wait since the previous entry, or since the preview start
+	Phase           string        // phase the execution falls in, for phased schedules
+}
+
+// Preview projects up to n execution times of the configured schedule starting
+// at start, without executing the command. Schedulers are stepped on a virtual
+// clock, so the result is deterministic for a given start time and --seed.
+func (r *Runner) Preview(start time.Time, n int) ([]PreviewEntry, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("preview count must be positive, got %d", n)
+	}
+
+	// Nothing fires before --start-at
+	clock := scheduler.NewVirtualClock(start)
+	if r.config.StartAt.After(start) {
+		clock.Set(r.config.StartAt)
+	}
+
+	var times []time.Time
+	var phases []string
+	if r.config.Subcommand == "rate-limit" {
+		var err error
+		times, err = r.previewRateLimit(clock, n)
+		if err != nil {
+			return nil, err
+		}
+		for i := range times {
+			times[i] = times[i].Add(r.config.Delay)
+		}
+	} else {
+		base, err := r.createStepper()
+		if err != nil {
+			return nil, err
+		}
+		stepper, err := r.composeStepper(base, clock)
+		if err != nil {
+			return nil, err
+		}
+		times = scheduler.Preview(stepper, clock, n)
+		if labeller, ok := base.(*phaseLabeller); ok {
+			phases = labeller.labels
+		}
+	}
+
+	times = r.applyPreviewStopConditions(start, times)
+
+	entries := make([]PreviewEntry, len(times))
+	previous := start
+	for i, t := range times {
+		entries[i] = PreviewEntry{
+			ExecutionNumber: i + 1,
+			Time:            t,
+			Delay:           t.Sub(previous),
+		}
+		if i < len(phases) {
+			entries[i].Phase = phases[i]
+		}
+		previous = t
+	}
+
+	return entries, nil
+}
+
+// createStepper creates a scheduler that can be stepped for a preview
+func (r *Runner) createStepper() (scheduler.Stepper, error) {
+	const immediateInterval = 1 * time.Millisecond
+	const noJitter = 0.0
+	const immediateStart = true
+
+	switch r.config.Subcommand {
+	case "exponential", "fibonacci", "linear", "polynomial", "decorrelated-jitter":
+		sched, err := r.createStrategyScheduler(r.config.Subcommand)
+		if err != nil {
+			return nil, err
+		}
+		stepper, ok := sched.(scheduler.Stepper)
+		if !ok {
+			return nil, fmt.Errorf("preview not supported for strategy: %s", r.config.Subcommand)
+		}
+		return stepper, nil
+	case "interval":
+		return scheduler.NewIntervalSchedulerWithOptions(r.config.Every, r.config.Jitter, immediateStart, r.intervalOptions(), scheduler.NewRealClock())
+	case "count", "duration":
+		interval := r.config.Every
+		if interval == 0 {
+			interval = immediateInterval
+		}
+		return scheduler.NewIntervalSchedulerWithOptions(interval, r.config.Jitter, immediateStart, r.intervalOptions(), scheduler.NewRealClock())
+	case "cron":
+		return scheduler.NewCronScheduler(r.config.CronExpression, r.config.Timezone)
+	case "adaptive", "load-adaptive":
+		// Adaptive intervals depend on live feedback, so project the base cadence
+		return scheduler.NewIntervalScheduler(r.config.BaseInterval, noJitter, immediateStart)
+	case "phases":
+		return r.createPhasedStepper()
+	default:
+		return nil, fmt.Errorf("preview not supported for subcommand: %s", r.config.Subcommand)
+	}
+}
+
+// createPhasedStepper steps each phase's preview scheduler in turn, labelling
+// every step with its phase
+func (r *Runner) createPhasedStepper() (scheduler.Stepper, error) {
+	phases, err := r.buildPhases(func(phaseRunner *Runner) (Scheduler, error) {
+		stepper, err := phaseRunner.createStepper()
+		if err != nil {
+			return nil, err
+		}
+		sched, ok := stepper.(Scheduler)
+		if !ok {
+			return nil, fmt.Errorf("preview not supported for phase mode: %s", phaseRunner.config.Subcommand)
+		}
+		return sched, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	phased, err := scheduler.NewPhasedScheduler(phases)
+	if err != nil {
+		return nil, err
+	}
+	return &phaseLabeller{phased: phased}, nil
+}
+
+// phaseLabeller records the phase each step of a phased preview fired in
+type phaseLabeller struct {
+	phased *scheduler.PhasedScheduler
+	labels []string
+}
+
+func (l *phaseLabeller) Step(now time.Time) (time.Time, bool) {
+	next, ok := l.phased.Step(now)
+	if ok {
+		_, phase := l.phased.StepPhase()
+		l.labels = append(l.labels, phase.Name)
+	}
+	return next, ok
+}
+
+// composeStepper applies the schedule operands and the --delay and --rate
+// modifiers to a preview, in the same order composeScheduler applies them at
+// runtime
+func (r *Runner) composeStepper(stepper scheduler.Stepper, clock *scheduler.VirtualClock) (scheduler.Stepper, error) {
+	if r.config.OrEvery > 0 || r.config.OrCron != "" {
+		union := &unionStepper{steppers: []scheduler.Stepper{stepper}}
+		if r.config.OrEvery > 0 {
+			every, err := scheduler.NewIntervalScheduler(r.config.OrEvery, 0, true)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --or-every: %w", err)
+			}
+			union.steppers = append(union.steppers, &delayedStepper{stepper: every, delay: r.config.OrEvery})
+		}
+		if r.config.OrCron != "" {
+			cron, err := scheduler.NewCronScheduler(r.config.OrCron, r.config.Timezone)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --or-cron: %w", err)
+			}
+			union.steppers = append(union.steppers, cron)
+		}
+		stepper = union
+	}
+
+	if r.config.AndCron != "" {
+		cron, err := scheduler.NewCronScheduler(r.config.AndCron, r.config.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --and-cron: %w", err)
+		}
+		stepper = &intersectStepper{steppers: []scheduler.Stepper{stepper, cron}}
+	}
+
+	if r.config.Delay > 0 {
+		stepper = &delayedStepper{stepper: stepper, delay: r.config.Delay}
+	}
+
+	if r.config.RateSpec != "" {
+		limiter, err := r.createRateLimiter(clock)
+		if err != nil {
+			return nil, err
+		}
+		stepper = &throttledStepper{stepper: stepper, limiter: limiter, clock: clock}
+	}
+
+	return stepper, nil
+}
+
+// delayedStepper previews scheduler.Delay. The wrapped stepper keeps stepping
+// from its own undelayed fire times, as the wrapped scheduler does at runtime.
+type delayedStepper struct {
+	stepper scheduler.Stepper
+	delay   time.Duration
+	last    time.Time // last undelayed fire time
+	started bool
+}
+
+func (s *delayedStepper) Step(now time.Time) (time.Time, bool) {
+	if s.started {
+		now = s.last
+	}
+
+	next, ok := s.stepper.Step(now)
+	if !ok {
+		return next, false
+	}
+
+	s.last, s.started = next, true
+	return next.Add(s.delay), true
+}
+
+// unionStepper previews scheduler.Union: each step is the earliest pending
+// fire time of any stepper, and the steppers that fire then move on
+type unionStepper struct {
+	steppers []scheduler.Stepper
+	next     []time.Time
+	ok       []bool
+}
+
+func (s *unionStepper) Step(now time.Time) (time.Time, bool) {
+	if s.next == nil {
+		s.next = make([]time.Time, len(s.steppers))
+		s.ok = make([]bool, len(s.steppers))
+		for i, stepper := range s.steppers {
+			s.next[i], s.ok[i] = stepper.Step(now)
+		}
+	}
+
+	earliest := -1
+	for i := range s.steppers {
+		if s.ok[i] && (earliest < 0 || s.next[i].Before(s.next[earliest])) {
+			earliest = i
+		}
+	}
+	if earliest < 0 {
+		return time.Time{}, false
+	}
+
+	t := s.next[earliest]
+	for i, stepper := range s.steppers {
+		if s.ok[i] && s.next[i].Equal(t) {
+			s.next[i], s.ok[i] = stepper.Step(t)
+		}
+	}
+	return t, true
+}
+
+// intersectStepper previews scheduler.Intersect: each step is the time the
+// last stepper fires, and earlier firings up to then are coalesced
+type intersectStepper struct {
+	steppers []scheduler.Stepper
+	next     []time.Time
+	done     bool
+}
+
+func (s *intersectStepper) Step(now time.Time) (time.Time, bool) {
+	if s.done {
+		return time.Time{}, false
+	}
+
+	if s.next == nil {
+		s.next = make([]time.Time, len(s.steppers))
+		for i, stepper := range s.steppers {
+			next, ok := stepper.Step(now)
+			if !ok {
+				s.done = true
+				return time.Time{}, false
+			}
+			s.next[i] = next
+		}
+	}
+
+	var t time.Time
+	for _, next := range s.next {
+		if next.After(t) {
+			t = next
+		}
+	}
+
+	// Every stepper has to fire again after t for the next step
+	for i, stepper := range s.steppers {
+		for !s.next[i].After(t) {
+			next, ok := stepper.Step(s.next[i])
+			if !ok {
+				s.done = true
+				break
+			}
+			s.next[i] = next
+		}
+	}
+	return t, true
+}
+
+// throttledStepper previews scheduler.Throttle by postponing each step until
+// the limiter, reading the virtual clock, admits it
+type throttledStepper struct {
+	stepper scheduler.Stepper
+	limiter scheduler.Limiter
+	clock   *scheduler.VirtualClock
+}
+
+func (s *throttledStepper) Step(now time.Time) (time.Time, bool) {
+	next, ok := s.stepper.Step(now)
+	if !ok {
+		return next, false
+	}
+
+	s.clock.Set(next)
+	for !s.limiter.Allow() {
+		s.clock.Set(s.limiter.NextAllowedTime())
+	}
+	return s.clock.Now(), true
+}
+
+// previewRateLimit simulates a saturated request stream against the rate limiter
+// and returns the times at which requests would be admitted
+func (r *Runner) previewRateLimit(clock *scheduler.VirtualClock, n int) ([]time.Time, error) {
+	limiter, err := r.createRateLimiter(clock)
+	if err != nil {
+		return nil, err
+	}
+
+	times := make([]time.Time, 0, n)
+	for len(times) < n {
+		if limiter.Allow() {
+			times = append(times, clock.Now())
+			continue
+		}
+		clock.Set(limiter.NextAllowedTime())
+	}
+
+	return times, nil
+}
+
+// applyPreviewStopConditions trims projected times to the --times, --for and
+// --deadline limits
+func (r *Runner) applyPreviewStopConditions(start time.Time, times []time.Time) []time.Time {
+	if r.config.Times > 0 && int64(len(times)) > r.config.Times {
+		times = times[:r.config.Times]
+	}
+
+	var deadline time.Time
+	if r.config.For > 0 {
+		deadline = start.Add(r.config.For)
+	}
+	if !r.config.Deadline.IsZero() && (deadline.IsZero() || r.config.Deadline.Before(deadline)) {
+		deadline = r.config.Deadline
+	}
+
+	if !deadline.IsZero() {
+		for i, t := range times {
+			if !t.Before(deadline) {
+				return times[:i]
+			}
+		}
+	}
+
+	return times
+}
+
+// createRateLimiter creates the rate limiter described by --rate, --burst,
+// --rate-algorithm and --retry-pattern, reading time from the given clock
+func (r *Runner) createRateLimiter(clock scheduler.Clock) (ratelimit.RateLimiter, error) {
+	windows, err := ratelimit.ParseRateWindows(r.config.RateSpec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rate spec: %w", err)
+	}
+
+	// Parse retry pattern if provided
+	retryPattern := []time.Duration{0} // Default: single attempt, no retries
+	if r.config.RetryPattern != "" {
+		retryPattern, err = r.parseRetryPattern(r.config.RetryPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry pattern: %w", err)
+		}
+	}
+
+	return ratelimit.NewRateLimiterWithClock(ratelimit.Spec{
+		Algorithm:    r.config.RateAlgorithm,
+		Windows:      windows,
+		Burst:        r.config.Burst,
+		RetryPattern: retryPattern,
+	}, clock)
+}
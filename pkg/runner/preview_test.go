@@ -0,0 +1,386 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var previewStart = time.Date(2025, 3, 10, 8, 30, 0, 0, time.UTC)
+
+func TestRunner_Preview_Interval(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand: "interval",
+		Every:      30 * time.Second,
+		Times:      3,
+		DryRun:     true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart, 10)
+	require.NoError(t, err)
+
+	require.Len(t, entries, 3, "preview should stop at --times")
+	for i, entry := range entries {
+		assert.Equal(t, i+1, entry.ExecutionNumber)
+		assert.Equal(t, previewStart.Add(time.Duration(i)*30*time.Second), entry.Time)
+	}
+	assert.Equal(t, time.Duration(0), entries[0].Delay)
+	assert.Equal(t, 30*time.Second, entries[1].Delay)
+}
+
+func TestRunner_Preview_DurationStopsAtFor(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand: "duration",
+		Every:      10 * time.Second,
+		For:        35 * time.Second,
+		DryRun:     true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart, 10)
+	require.NoError(t, err)
+	assert.Len(t, entries, 4)
+}
+
+func TestRunner_Preview_CronInTimezone(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand:     "cron",
+		CronExpression: "0 9 * * 1-5",
+		Timezone:       "Europe/Berlin",
+		DryRun:         true,
+	})
+	require.NoError(t, err)
+
+	// 2025-03-10 is a Monday; 08:30 UTC is already past 09:00 in Berlin
+	entries, err := r.Preview(previewStart, 5)
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+
+	for _, entry := range entries {
+		assert.Equal(t, "Europe/Berlin", entry.Time.Location().String())
+		assert.Equal(t, 9, entry.Time.Hour())
+		assert.NotEqual(t, time.Saturday, entry.Time.Weekday())
+		assert.NotEqual(t, time.Sunday, entry.Time.Weekday())
+	}
+	assert.Equal(t, 11, entries[0].Time.Day())
+	assert.Equal(t, 17, entries[4].Time.Day(), "weekend should be skipped")
+}
+
+func TestRunner_Preview_RateLimit(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand: "rate-limit",
+		RateSpec:   "3/1m",
+		DryRun:     true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart, 6)
+	require.NoError(t, err)
+	require.Len(t, entries, 6)
+
+	// No more than three executions may fall in any one-minute window
+	for i := 3; i < len(entries); i++ {
+		assert.GreaterOrEqual(t, entries[i].Time.Sub(entries[i-3].Time), time.Minute)
+	}
+}
+
+func TestRunner_Preview_SeededStrategyIsReproducible(t *testing.T) {
+	config := func() *Config {
+		return &Config{
+			Subcommand: "decorrelated-jitter",
+			BaseDelay:  time.Second,
+			Multiplier: 3.0,
+			MaxDelay:   time.Minute,
+			MaxRetries: 6,
+			Seed:       42,
+			DryRun:     true,
+		}
+	}
+
+	first, err := NewRunner(config())
+	require.NoError(t, err)
+	second, err := NewRunner(config())
+	require.NoError(t, err)
+
+	a, err := first.Preview(previewStart, 10)
+	require.NoError(t, err)
+	b, err := second.Preview(previewStart, 10)
+	require.NoError(t, err)
+
+	assert.Len(t, a, 6, "preview should stop at the attempt limit")
+	assert.Equal(t, a, b)
+}
+
+func TestRunner_Preview_SeededJitterIsReproducible(t *testing.T) {
+	config := func() *Config {
+		return &Config{
+			Subcommand: "interval",
+			Every:      time.Minute,
+			Jitter:     0.5,
+			Seed:       42,
+			Times:      6,
+			DryRun:     true,
+		}
+	}
+
+	first, err := NewRunner(config())
+	require.NoError(t, err)
+	second, err := NewRunner(config())
+	require.NoError(t, err)
+
+	a, err := first.Preview(previewStart, 10)
+	require.NoError(t, err)
+	b, err := second.Preview(previewStart, 10)
+	require.NoError(t, err)
+
+	require.Len(t, a, 6)
+	assert.Equal(t, a, b)
+
+	jittered := false
+	for _, entry := range a[1:] {
+		assert.InDelta(t, time.Minute, entry.Delay, float64(30*time.Second))
+		if entry.Delay != time.Minute {
+			jittered = true
+		}
+	}
+	assert.True(t, jittered, "--jitter should vary the previewed intervals")
+}
+
+func TestRunner_Preview_InvalidCount(t *testing.T) {
+	r, err := NewRunner(&Config{Subcommand: "interval", Every: time.Second, DryRun: true})
+	require.NoError(t, err)
+
+	_, err = r.Preview(previewStart, 0)
+	assert.Error(t, err)
+}
+
+func TestRunner_Preview_CronWithRate(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand:     "cron",
+		CronExpression: "*/5 * * * *",
+		Timezone:       "UTC",
+		RateSpec:       "3/1h",
+		DryRun:         true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart, 6)
+	require.NoError(t, err)
+	require.Len(t, entries, 6)
+
+	for i := 3; i < len(entries); i++ {
+		assert.GreaterOrEqual(t, entries[i].Time.Sub(entries[i-3].Time), time.Hour)
+	}
+	for _, entry := range entries {
+		assert.Zero(t, entry.Time.Minute()%5, "throttled firings stay on the cron grid")
+	}
+}
+
+func TestRunner_Preview_ScheduleOperands(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 3, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name     string
+		config   Config
+		expected []time.Time
+	}{
+		{
+			name:     "interval or cron",
+			config:   Config{Subcommand: "interval", Every: 20 * time.Minute, OrCron: "0 * * * *"},
+			expected: []time.Time{at(8, 30), at(8, 50), at(9, 0), at(9, 10), at(9, 30)},
+		},
+		{
+			name:     "cron or every",
+			config:   Config{Subcommand: "cron", CronExpression: "0 * * * *", Timezone: "UTC", OrEvery: 25 * time.Minute},
+			expected: []time.Time{at(8, 55), at(9, 0), at(9, 20), at(9, 45), at(10, 0)},
+		},
+		{
+			name:     "coinciding firings run once",
+			config:   Config{Subcommand: "cron", CronExpression: "*/30 * * * *", Timezone: "UTC", OrEvery: 30 * time.Minute},
+			expected: []time.Time{at(9, 0), at(9, 30), at(10, 0), at(10, 30), at(11, 0)},
+		},
+		{
+			name:     "interval and cron",
+			config:   Config{Subcommand: "interval", Every: 7 * time.Minute, AndCron: "*/15 * * * *"},
+			expected: []time.Time{at(8, 45), at(9, 0), at(9, 15), at(9, 30), at(9, 45)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.DryRun = true
+			r, err := NewRunner(&tt.config)
+			require.NoError(t, err)
+
+			entries, err := r.Preview(previewStart, 5)
+			require.NoError(t, err)
+
+			times := make([]time.Time, len(entries))
+			for i, entry := range entries {
+				times[i] = entry.Time
+			}
+			assert.Equal(t, tt.expected, times)
+		})
+	}
+}
+
+func TestRunner_Preview_Delay(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand: "interval",
+		Every:      time.Minute,
+		Delay:      30 * time.Second,
+		DryRun:     true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart, 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{
+		previewStart.Add(30 * time.Second),
+		previewStart.Add(90 * time.Second),
+		previewStart.Add(150 * time.Second),
+	}, []time.Time{entries[0].Time, entries[1].Time, entries[2].Time})
+}
+
+func TestRunner_Preview_Phases(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand: "phases",
+		Phases: []PhaseConfig{
+			{Name: "burst", Mode: "interval", Every: time.Second, For: 3 * time.Second},
+			{Name: "retry", Mode: "exponential", BaseDelay: time.Second, Multiplier: 2, MaxDelay: time.Minute, Attempts: 3, Times: 3},
+			{Name: "hourly", Mode: "cron", CronExpression: "@hourly", Timezone: "UTC"},
+		},
+		DryRun: true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart, 8)
+	require.NoError(t, err)
+	require.Len(t, entries, 8)
+
+	var offsets []time.Duration
+	var phases []string
+	for _, entry := range entries {
+		offsets = append(offsets, entry.Time.Sub(previewStart))
+		phases = append(phases, entry.Phase)
+	}
+
+	assert.Equal(t, []time.Duration{
+		0, time.Second, 2 * time.Second, // burst until its 3s deadline
+		3 * time.Second, 4 * time.Second, 6 * time.Second, // retry: immediate, +1s, +2s
+		30 * time.Minute, 90 * time.Minute, // next full hours after 08:30
+	}, offsets)
+	assert.Equal(t, []string{"burst", "burst", "burst", "retry", "retry", "retry", "hourly", "hourly"}, phases)
+}
+
+func TestRunner_Preview_Align(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand: "interval",
+		Every:      15 * time.Minute,
+		Align:      true,
+		DryRun:     true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart.Add(7*time.Minute), 3)
+	require.NoError(t, err)
+
+	assert.Equal(t, []time.Time{
+		previewStart.Add(15 * time.Minute),
+		previewStart.Add(30 * time.Minute),
+		previewStart.Add(45 * time.Minute),
+	}, []time.Time{entries[0].Time, entries[1].Time, entries[2].Time})
+	assert.Equal(t, 8*time.Minute, entries[0].Delay)
+}
+
+func TestRunner_Preview_StartAtAndDeadline(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand: "interval",
+		Every:      10 * time.Minute,
+		StartAt:    previewStart.Add(time.Hour),
+		Deadline:   previewStart.Add(time.Hour + 25*time.Minute),
+		DryRun:     true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart, 10)
+	require.NoError(t, err)
+
+	require.Len(t, entries, 3, "preview should stop at --deadline")
+	assert.Equal(t, previewStart.Add(time.Hour), entries[0].Time)
+	assert.Equal(t, time.Hour, entries[0].Delay)
+	assert.Equal(t, previewStart.Add(time.Hour+20*time.Minute), entries[2].Time)
+}
+
+func TestRunner_Preview_MultiWindowRate(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand: "interval",
+		Every:      time.Second,
+		RateSpec:   "2/10s, 3 per minute",
+		Times:      4,
+		DryRun:     true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart, 10)
+	require.NoError(t, err)
+
+	// Two per 10s holds back the third, three per minute the fourth
+	require.Len(t, entries, 4)
+	assert.Equal(t, []time.Time{
+		previewStart,
+		previewStart.Add(time.Second),
+		previewStart.Add(10 * time.Second),
+		previewStart.Add(time.Minute),
+	}, []time.Time{entries[0].Time, entries[1].Time, entries[2].Time, entries[3].Time})
+}
+
+func TestRunner_Preview_Burst(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand: "rate-limit",
+		RateSpec:   "10/minute",
+		Burst:      2,
+		DryRun:     true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart, 5)
+	require.NoError(t, err)
+
+	// 10/minute in bursts of 2 admits two every 12s
+	assert.Equal(t, []time.Time{
+		previewStart,
+		previewStart,
+		previewStart.Add(12 * time.Second),
+		previewStart.Add(12 * time.Second),
+		previewStart.Add(24 * time.Second),
+	}, []time.Time{entries[0].Time, entries[1].Time, entries[2].Time, entries[3].Time, entries[4].Time})
+}
+
+func TestRunner_Preview_RateAlgorithm(t *testing.T) {
+	r, err := NewRunner(&Config{
+		Subcommand:    "rate-limit",
+		RateSpec:      "6/minute",
+		RateAlgorithm: "gcra",
+		Burst:         2,
+		DryRun:        true,
+	})
+	require.NoError(t, err)
+
+	entries, err := r.Preview(previewStart, 4)
+	require.NoError(t, err)
+
+	// GCRA admits the burst of two, then one every 10s
+	assert.Equal(t, []time.Time{
+		previewStart,
+		previewStart,
+		previewStart.Add(10 * time.Second),
+		previewStart.Add(20 * time.Second),
+	}, []time.Time{entries[0].Time, entries[1].Time, entries[2].Time, entries[3].Time})
+}
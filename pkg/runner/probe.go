@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"github.com/swi/repeater/pkg/executor"
+	"github.com/swi/repeater/pkg/probe"
+)
+
+// createProbe returns the probe configured in place of a command, or nil if
+// the command runs
+func (r *Runner) createProbe() (executor.Probe, error) {
+	if r.config.Probe != nil {
+		return r.config.Probe, nil
+	}
+
+	if config := r.config.GetHTTPProbeConfig(); config != nil {
+		httpProbe, err := probe.NewHTTPProbe(*config)
+		if err != nil {
+			return nil, err
+		}
+		return httpProbe, nil
+	}
+
+	if config := r.config.GetSocketProbeConfig(); config != nil {
+		socketProbe, err := probe.NewSocketProbe(*config)
+		if err != nil {
+			return nil, err
+		}
+		return socketProbe, nil
+	}
+
+	if config := r.config.GetDNSProbeConfig(); config != nil {
+		dnsProbe, err := probe.NewDNSProbe(*config)
+		if err != nil {
+			return nil, err
+		}
+		return dnsProbe, nil
+	}
+
+	return nil, nil
+}
@@ -4,22 +4,29 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/swi/repeater/pkg/adaptive"
-	"github.com/swi/repeater/pkg/cli"
 	"github.com/swi/repeater/pkg/executor"
 	"github.com/swi/repeater/pkg/health"
 	"github.com/swi/repeater/pkg/httpaware"
 	"github.com/swi/repeater/pkg/interfaces"
 	"github.com/swi/repeater/pkg/metrics"
 	"github.com/swi/repeater/pkg/ratelimit"
+	"github.com/swi/repeater/pkg/retry"
 	"github.com/swi/repeater/pkg/scheduler"
-	"github.com/swi/repeater/pkg/strategies"
 )
 
+// ErrAborted is returned by Run when an execution's result stops the run, such
+// as an HTTP status in --http-fatal-codes. The stats cover every execution up
+// to and including that one.
+var ErrAborted = errors.New("run aborted")
+
 // ExecutionStats represents statistics from a complete execution run
 type ExecutionStats struct {
 	TotalExecutions      int
@@ -29,34 +36,59 @@ type ExecutionStats struct {
 	StartTime            time.Time
 	EndTime              time.Time
 	Executions           []ExecutionRecord
+	ExitCodes            map[int]int      // executions per exit code, as the command exited
+	Requests             []RequestOutcome // rate-limit requests with retries that have finished
 }
 
 // ExecutionRecord represents a single command execution
 type ExecutionRecord struct {
 	ExecutionNumber int
 	ExitCode        int
+	CommandExitCode int // as the command exited, before mapping and pattern matching; -1 if it did not exit
 	Duration        time.Duration
 	Stdout          string
 	Stderr          string
 	StartTime       time.Time
 	EndTime         time.Time
+	Success         bool                  // judged successful, after pattern matching and response parsing
+	Reason          string                // why the execution succeeded or failed
+	MatchedPatterns []string              // patterns found in the output, as written
+	Phases          *executor.PhaseTiming // network phases, for probes that time them
+	RequestNumber   int                   // logical rate-limit request, when retries are tracked
+	Attempt         int                   // attempt within that request, from 1
+}
+
+// RequestOutcome reports how a rate-limited request with retries ended
+type RequestOutcome struct {
+	RequestNumber int
+	RequestTime   time.Time // when the limiter admitted the request
+	Attempts      int       // attempts executed
+	Succeeded     bool      // the last attempt succeeded; otherwise every attempt failed
 }
 
 // Runner orchestrates the execution of commands using schedulers and executors
 type Runner struct {
-	config             *cli.Config
+	config             *Config
+	clock              scheduler.Clock
 	healthServer       *health.HealthServer
 	metricsServer      *metrics.MetricsServer
 	httpAwareScheduler httpaware.HTTPAwareScheduler // HTTP-aware scheduler if enabled
+	observers          observerSet
 }
 
 // NewRunner creates a new runner with the given configuration
-func NewRunner(config *cli.Config) (*Runner, error) {
+func NewRunner(config *Config) (*Runner, error) {
+	return NewRunnerWithClock(config, scheduler.NewRealClock())
+}
+
+// NewRunnerWithClock creates a runner whose schedulers, rate limiters and stop
+// conditions all read time from the given clock
+func NewRunnerWithClock(config *Config, clock scheduler.Clock) (*Runner, error) {
 	if config == nil {
 		return nil, errors.New("config cannot be nil")
 	}
 
-	if len(config.Command) == 0 {
+	if len(config.Command) == 0 && !config.DryRun && !config.HasProbe() {
 		return nil, errors.New("command cannot be empty")
 	}
 
@@ -95,6 +127,10 @@ func NewRunner(config *cli.Config) (*Runner, error) {
 		if config.BaseInterval == 0 {
 			return nil, errors.New("adaptive requires --base-interval")
 		}
+	case "phases":
+		if len(config.Phases) == 0 {
+			return nil, errors.New("phases requires --phase")
+		}
 
 	// RATE CONTROL
 	case "rate-limit":
@@ -122,16 +158,47 @@ func NewRunner(config *cli.Config) (*Runner, error) {
 		metricsServer = metrics.NewMetricsServer(config.MetricsPort)
 	}
 
-	return &Runner{
+	r := &Runner{
 		config:        config,
+		clock:         clock,
 		healthServer:  healthServer,
 		metricsServer: metricsServer,
-	}, nil
+	}
+
+	// Server errors are only reported in verbose mode
+	var serverLog io.Writer
+	if config.Verbose {
+		serverLog = os.Stderr
+	}
+	if healthServer != nil {
+		r.AddObserver(&healthObserver{server: healthServer, log: serverLog})
+	}
+	if metricsServer != nil {
+		r.AddObserver(&metricsObserver{server: metricsServer, log: serverLog})
+	}
+	if config.Verbose {
+		r.AddObserver(&verbosePrinter{out: os.Stderr})
+	}
+	if config.ShowMetrics {
+		r.AddObserver(&adaptiveMetricsPrinter{out: os.Stdout})
+	}
+	for _, observer := range config.Observers {
+		r.AddObserver(observer)
+	}
+
+	return r, nil
+}
+
+// AddObserver registers an observer of the run's lifecycle. Observers are
+// notified in the order they were added, after the built-in health, metrics
+// and verbose observers and those in Config.Observers.
+func (r *Runner) AddObserver(observer Observer) {
+	r.observers.add(observer)
 }
 
 // Run executes the configured command according to the scheduling rules
 func (r *Runner) Run(ctx context.Context) (*ExecutionStats, error) {
-	startTime := time.Now()
+	startTime := r.clock.Now()
 
 	// Create executor with configuration including pattern matching
 	executorConfig := executor.ExecutorConfig{
@@ -142,13 +209,26 @@ func (r *Runner) Run(ctx context.Context) (*ExecutionStats, error) {
 		VerboseMode:   r.config.Verbose,
 		OutputPrefix:  r.config.OutputPrefix,
 		PatternConfig: r.config.GetPatternConfig(),
+		ExitCodes:     r.config.GetExitCodeConfig(),
 	}
 
+	// A built-in probe runs in place of the command
+	probe, err := r.createProbe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create probe: %w", err)
+	}
+	executorConfig.Probe = probe
+
 	// Set default timeout if not specified
 	if executorConfig.Timeout <= 0 {
 		executorConfig.Timeout = 30 * time.Second
 	}
 
+	// Observers see every output line, streamed or not
+	if !r.observers.empty() {
+		executorConfig.OnLine = r.observers.outputLine
+	}
+
 	exec, err := executor.NewExecutorWithConfig(executorConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create executor: %w", err)
@@ -161,31 +241,16 @@ func (r *Runner) Run(ctx context.Context) (*ExecutionStats, error) {
 	}
 	defer sched.Stop()
 
-	// Start health server if enabled
-	if r.healthServer != nil {
-		go func() {
-			if err := r.healthServer.Start(ctx); err != nil {
-				// Log error but don't fail execution
-				if r.config.Verbose {
-					fmt.Fprintf(os.Stderr, "Health server error: %v\n", err)
-				}
-			}
-		}()
-		r.healthServer.SetReady(true)
-	}
+	// The health and metrics observers start their servers here
+	r.observers.runStart(ctx, r.config)
 
-	// Start metrics server if enabled
-	if r.metricsServer != nil {
-		go func() {
-			if err := r.metricsServer.Start(ctx); err != nil {
-				// Log error but don't fail execution
-				if r.config.Verbose {
-					fmt.Fprintf(os.Stderr, "Metrics server error: %v\n", err)
-				}
-			}
-		}()
-	}
+	stats, err := r.execute(ctx, exec, sched, startTime)
+	r.observers.runEnd(stats, err)
+	return stats, err
+}
 
+// execute runs the main execution loop until a stop condition or ctx ends it
+func (r *Runner) execute(ctx context.Context, exec *executor.Executor, sched Scheduler, startTime time.Time) (*ExecutionStats, error) {
 	// Create execution context with stop conditions
 	execCtx, cancel := r.createExecutionContext(ctx)
 	defer cancel()
@@ -194,18 +259,31 @@ func (r *Runner) Run(ctx context.Context) (*ExecutionStats, error) {
 	stats := &ExecutionStats{
 		StartTime:  startTime,
 		Executions: make([]ExecutionRecord, 0),
+		ExitCodes:  make(map[int]int),
+	}
+
+	// A schedule that runs out of ticks, such as a retry policy out of
+	// attempts, ends the run
+	var exhausted <-chan struct{}
+	if finite, ok := sched.(scheduler.Finite); ok {
+		exhausted = finite.Done()
 	}
 
 	// Main execution loop
 	executionNumber := 1
 	for {
 		select {
+		case <-exhausted:
+			stats.EndTime = r.clock.Now()
+			stats.Duration = stats.EndTime.Sub(stats.StartTime)
+			return stats, nil
+
 		case <-execCtx.Done():
 			// Context canceled (timeout, signal, or stop condition)
-			stats.EndTime = time.Now()
+			stats.EndTime = r.clock.Now()
 			stats.Duration = stats.EndTime.Sub(stats.StartTime)
 
-			if execCtx.Err() == context.Canceled {
+			if context.Cause(execCtx) == context.Canceled {
 				return stats, fmt.Errorf("execution stopped: %w", context.Canceled)
 			}
 			return stats, nil
@@ -213,15 +291,17 @@ func (r *Runner) Run(ctx context.Context) (*ExecutionStats, error) {
 		case tick := <-sched.Next():
 			// Check stop conditions before execution
 			if r.shouldStop(stats, startTime) {
-				stats.EndTime = time.Now()
+				stats.EndTime = r.clock.Now()
 				stats.Duration = stats.EndTime.Sub(stats.StartTime)
 				return stats, nil
 			}
 
 			// Execute command
-			execStart := time.Now()
+			execStart := r.clock.Now()
+			r.observers.tick(tick, execStart)
+			r.observers.executionStart(executionNumber, execStart)
 			result, execErr := exec.Execute(execCtx, r.config.Command)
-			execEnd := time.Now()
+			execEnd := r.clock.Now()
 
 			// Record execution
 			record := ExecutionRecord{
@@ -231,24 +311,39 @@ func (r *Runner) Run(ctx context.Context) (*ExecutionStats, error) {
 				Duration:        execEnd.Sub(execStart),
 			}
 
+			// Let the HTTP-aware scheduler see the response before the next
+			// tick is released
+			if r.httpAwareScheduler != nil && execCtx.Err() == nil {
+				r.analyzeResponse(result)
+			}
+
 			if execErr != nil {
 				// Command failed or was canceled
 				if execCtx.Err() != nil {
 					// Context was canceled during execution
-					stats.EndTime = time.Now()
+					stats.EndTime = r.clock.Now()
 					stats.Duration = stats.EndTime.Sub(stats.StartTime)
-					return stats, fmt.Errorf("execution canceled: %w", execCtx.Err())
+					return stats, fmt.Errorf("execution canceled: %w", context.Cause(execCtx))
 				}
 
 				// Command failed but we continue
 				record.ExitCode = 1 // Default failure code
+				record.CommandExitCode = -1
 				record.Stderr = execErr.Error()
+				record.Reason = execErr.Error()
 				stats.FailedExecutions++
 			} else {
 				// Command executed - use pattern matching result if available
 				record.ExitCode = result.ExitCode
+				record.CommandExitCode = result.CommandExitCode
 				record.Stdout = result.Stdout
 				record.Stderr = result.Stderr
+				record.Success = result.Success
+				record.Reason = result.Reason
+				record.MatchedPatterns = result.Matched
+				record.Phases = result.Phases
+
+				stats.ExitCodes[result.CommandExitCode]++
 
 				// Use the Success field from ExecutionResult which includes pattern matching
 				if result.Success {
@@ -258,66 +353,51 @@ func (r *Runner) Run(ctx context.Context) (*ExecutionStats, error) {
 				}
 			}
 
+			// Rate-limited requests retry at their reserved offsets until one succeeds
+			if limited := findRateLimitScheduler(sched); limited != nil {
+				success := (execErr == nil && result != nil && result.Success)
+				if attempt, ok := limited.AttemptFinished(success); ok {
+					record.RequestNumber = attempt.Request
+					record.Attempt = attempt.Attempt
+					if attempt.Done {
+						stats.Requests = append(stats.Requests, RequestOutcome{
+							RequestNumber: attempt.Request,
+							RequestTime:   attempt.RequestTime,
+							Attempts:      attempt.Attempt,
+							Succeeded:     success,
+						})
+					}
+				}
+			}
+
 			stats.Executions = append(stats.Executions, record)
 			stats.TotalExecutions++
 			executionNumber++
 
-			// Update health server stats if enabled
-			if r.healthServer != nil {
-				r.healthServer.SetExecutionStats(health.ExecutionStats{
-					TotalExecutions:      int64(stats.TotalExecutions),
-					SuccessfulExecutions: int64(stats.SuccessfulExecutions),
-					FailedExecutions:     int64(stats.FailedExecutions),
-					AverageResponseTime:  time.Duration(0), // Future: Calculate from execution history if needed
-					LastExecution:        time.Now(),
-				})
-			}
+			// Fixed-delay schedules wait from the end of this execution
+			notifyCompletion(sched, execEnd)
 
-			// Update metrics server if enabled
-			if r.metricsServer != nil {
-				success := (execErr == nil && result != nil && result.Success)
-				r.metricsServer.RecordExecution(success, record.Duration)
-			}
+			r.observers.executionEnd(record)
 
-			// Update adaptive scheduler if applicable
-			if adaptiveWrapper, ok := sched.(*AdaptiveSchedulerWrapper); ok {
-				// Determine success based on execution result
-				success := (execErr == nil && result != nil && result.Success)
-				adaptiveWrapper.UpdateFromExecution(record, success)
-
-				// Record scheduler interval in metrics if enabled
-				if r.metricsServer != nil {
-					metrics := adaptiveWrapper.GetMetrics()
-					r.metricsServer.RecordSchedulerInterval(metrics.CurrentInterval)
-				}
+			// Update adaptive scheduler if applicable. It is looked up per execution
+			// because a phased schedule switches schedulers between phases.
+			if adaptiveWrapper := findAdaptiveWrapper(sched); adaptiveWrapper != nil {
+				adaptiveWrapper.UpdateFromExecution(record, record.Success)
 
-				// Show metrics if requested
-				if r.config.ShowMetrics {
-					r.showAdaptiveMetrics(adaptiveWrapper.GetMetrics())
-				}
+				metrics := adaptiveWrapper.GetMetrics()
+				r.observers.stateChange(SchedulerStateChange{
+					Kind:     IntervalAdapted,
+					At:       r.clock.Now(),
+					Interval: metrics.CurrentInterval,
+					Adaptive: metrics,
+				})
 			}
 
-			// Update HTTP-aware scheduler if enabled
-			if r.httpAwareScheduler != nil && result != nil {
-				// Pass the full command output (stdout + stderr) to HTTP-aware scheduler
-				fullOutput := result.Stdout
-				if result.Stderr != "" {
-					if fullOutput != "" {
-						fullOutput += "\n"
-					}
-					fullOutput += result.Stderr
-				}
-
-				// Set the last response for HTTP-aware analysis
-				r.httpAwareScheduler.SetLastResponse(fullOutput)
-
-				// Show HTTP timing info if verbose mode is enabled
-				if r.config.Verbose {
-					if timingInfo := r.httpAwareScheduler.GetTimingInfo(); timingInfo != nil {
-						fmt.Fprintf(os.Stderr, "HTTP-aware: Found %s timing: %v\n",
-							timingInfo.Source, timingInfo.Delay)
-					}
-				}
+			// A fatal result, such as an HTTP fatal code, ends the run
+			if result != nil && result.Fatal {
+				stats.EndTime = r.clock.Now()
+				stats.Duration = stats.EndTime.Sub(stats.StartTime)
+				return stats, fmt.Errorf("%w: %s", ErrAborted, result.Reason)
 			}
 
 			// Update tick time for scheduler
@@ -329,35 +409,76 @@ func (r *Runner) Run(ctx context.Context) (*ExecutionStats, error) {
 // Use centralized Scheduler interface from pkg/interfaces
 type Scheduler = interfaces.Scheduler
 
-// RateLimitScheduler implements Scheduler using Diophantine rate limiting
+// RateLimitScheduler implements Scheduler using Diophantine rate limiting.
+//
+// When the limiter reserves retries (a ratelimit.RetryReserver with more than
+// one attempt in its pattern), every admitted request is tracked: a failed
+// attempt is retried at the next reserved offset, and a success releases the
+// attempts still reserved. The scheduler then waits for each attempt's outcome,
+// reported with AttemptFinished, before firing again.
 type RateLimitScheduler struct {
-	limiter  *ratelimit.DiophantineRateLimiter
+	limiter  scheduler.Limiter
 	showNext bool
+	clock    scheduler.Clock
 	nextChan chan time.Time
 	stopChan chan struct{}
 	stopped  bool
-	started  bool
+	started  sync.Once
+
+	// Retry tracking
+	reserver ratelimit.RetryReserver // nil unless the limiter reserves retries
+	mu       sync.Mutex
+	requests int              // logical requests admitted so far
+	due      []RequestAttempt // attempts waiting for their time, earliest first
+	inFlight *RequestAttempt  // attempt fired and awaiting its outcome
+	finished chan struct{}
+}
+
+// RequestAttempt is one execution of a logical rate-limited request
+type RequestAttempt struct {
+	Request     int       // logical request number, from 1
+	Attempt     int       // attempt number within the request, from 1
+	RequestTime time.Time // when the limiter admitted the request
+	At          time.Time // when the attempt is due
+	Done        bool      // set on completion once the request succeeded or ran out of retries
 }
 
 // NewRateLimitScheduler creates a new rate-limit aware scheduler
-func NewRateLimitScheduler(limiter *ratelimit.DiophantineRateLimiter, showNext bool) *RateLimitScheduler {
+func NewRateLimitScheduler(limiter scheduler.Limiter, showNext bool) *RateLimitScheduler {
+	return NewRateLimitSchedulerWithClock(limiter, showNext, scheduler.NewRealClock())
+}
+
+// NewRateLimitSchedulerWithClock creates a rate-limit aware scheduler that waits
+// for admission on the given clock; it should be the clock the limiter reads
+func NewRateLimitSchedulerWithClock(limiter scheduler.Limiter, showNext bool, clock scheduler.Clock) *RateLimitScheduler {
 	s := &RateLimitScheduler{
 		limiter:  limiter,
 		showNext: showNext,
+		clock:    clock,
 		nextChan: make(chan time.Time, 1),
 		stopChan: make(chan struct{}),
 		stopped:  false,
-		started:  false,
+		finished: make(chan struct{}, 1),
 	}
 
-	// Start the scheduling goroutine immediately
-	go s.scheduleLoop()
+	if reserver, ok := limiter.(ratelimit.RetryReserver); ok && len(reserver.RetryPattern()) > 1 {
+		s.reserver = reserver
+	}
 
 	return s
 }
 
-// Next returns a channel that delivers the next allowed execution time
+// Next returns a channel that delivers the next allowed execution time. The
+// limiter is first consulted on the first call, so requests are not admitted
+// before the schedule is read.
 func (s *RateLimitScheduler) Next() <-chan time.Time {
+	s.started.Do(func() {
+		if s.reserver != nil {
+			go s.retryLoop()
+		} else {
+			go s.scheduleLoop()
+		}
+	})
 	return s.nextChan
 }
 
@@ -371,7 +492,7 @@ func (s *RateLimitScheduler) scheduleLoop() {
 			if s.limiter.Allow() {
 				// Request is allowed now
 				select {
-				case s.nextChan <- time.Now():
+				case s.nextChan <- s.clock.Now():
 					// Successfully sent, continue to next iteration
 				case <-s.stopChan:
 					return
@@ -382,22 +503,155 @@ func (s *RateLimitScheduler) scheduleLoop() {
 				if s.showNext {
 					fmt.Printf("Next request allowed at: %s\n", nextTime.Format("15:04:05"))
 				}
-
-				// Wait until that time
-				waitDuration := time.Until(nextTime)
-				if waitDuration > 0 {
-					select {
-					case <-time.After(waitDuration):
-						// Continue loop to try again
-					case <-s.stopChan:
-						return
-					}
+				if !s.wait(nextTime) {
+					return
 				}
 			}
 		}
 	}
 }
 
+// retryLoop fires due retries and newly admitted requests one at a time,
+// waiting for each attempt's outcome before firing the next
+func (s *RateLimitScheduler) retryLoop() {
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		default:
+		}
+
+		now := s.clock.Now()
+		if s.takeDue(now) {
+			select {
+			case s.nextChan <- now:
+			case <-s.stopChan:
+				return
+			}
+
+			select {
+			case <-s.finished:
+			case <-s.stopChan:
+				return
+			}
+			continue
+		}
+
+		if requestTime, ok := s.reserver.Admit(); ok {
+			s.admitted(requestTime)
+			continue
+		}
+
+		// Wait for the next retry or the next admission, whichever comes first
+		next := s.limiter.NextAllowedTime()
+		if s.showNext {
+			fmt.Printf("Next request allowed at: %s\n", next.Format("15:04:05"))
+		}
+		s.mu.Lock()
+		if len(s.due) > 0 && s.due[0].At.Before(next) {
+			next = s.due[0].At
+		}
+		s.mu.Unlock()
+
+		if !s.wait(next) {
+			return
+		}
+	}
+}
+
+// wait blocks until the given time, reporting false if the scheduler stopped
+func (s *RateLimitScheduler) wait(until time.Time) bool {
+	waitDuration := until.Sub(s.clock.Now())
+	if waitDuration <= 0 {
+		return true
+	}
+
+	select {
+	case <-s.clock.After(waitDuration):
+		return true
+	case <-s.stopChan:
+		return false
+	}
+}
+
+// admitted starts a logical request admitted at requestTime, queueing its
+// first attempt at the first reserved offset
+func (s *RateLimitScheduler) admitted(requestTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	s.enqueueLocked(RequestAttempt{
+		Request:     s.requests,
+		Attempt:     1,
+		RequestTime: requestTime,
+		At:          requestTime.Add(s.reserver.RetryPattern()[0]),
+	})
+}
+
+// enqueueLocked inserts an attempt in due order. The caller must hold s.mu.
+func (s *RateLimitScheduler) enqueueLocked(attempt RequestAttempt) {
+	i := len(s.due)
+	for i > 0 && s.due[i-1].At.After(attempt.At) {
+		i--
+	}
+	s.due = slices.Insert(s.due, i, attempt)
+}
+
+// takeDue puts the earliest attempt due by now in flight, if there is one
+func (s *RateLimitScheduler) takeDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.due) == 0 || s.due[0].At.After(now) {
+		return false
+	}
+
+	attempt := s.due[0]
+	s.due = s.due[1:]
+	s.inFlight = &attempt
+	return true
+}
+
+// AttemptFinished reports the outcome of the attempt the last tick fired and
+// returns it. A failure schedules the next reserved retry; a success releases
+// the retries still reserved. ok is false when the scheduler does not track
+// retries or no attempt is in flight.
+func (s *RateLimitScheduler) AttemptFinished(success bool) (attempt RequestAttempt, ok bool) {
+	if s.reserver == nil {
+		return RequestAttempt{}, false
+	}
+
+	s.mu.Lock()
+	if s.inFlight == nil {
+		s.mu.Unlock()
+		return RequestAttempt{}, false
+	}
+	attempt = *s.inFlight
+	s.inFlight = nil
+
+	retries := len(s.reserver.RetryPattern())
+	switch {
+	case success:
+		attempt.Done = true
+		s.reserver.Release(attempt.RequestTime, attempt.Attempt)
+	case attempt.Attempt >= retries:
+		attempt.Done = true
+	default:
+		next := attempt
+		next.Attempt++
+		next.At = attempt.RequestTime.Add(s.reserver.RetryPattern()[next.Attempt-1])
+		s.enqueueLocked(next)
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.finished <- struct{}{}:
+	default:
+	}
+	return attempt, true
+}
+
 // Stop stops the scheduler
 func (s *RateLimitScheduler) Stop() {
 	if !s.stopped {
@@ -409,7 +663,6 @@ func (s *RateLimitScheduler) Stop() {
 // createScheduler creates the appropriate scheduler based on the subcommand
 func (r *Runner) createScheduler() (Scheduler, error) {
 	const immediateInterval = 1 * time.Millisecond
-	const noJitter = 0.0
 	const immediateStart = true
 
 	var baseScheduler Scheduler
@@ -430,17 +683,19 @@ func (r *Runner) createScheduler() (Scheduler, error) {
 
 	// EXISTING EXECUTION MODES
 	case "interval":
-		baseScheduler, err = scheduler.NewIntervalScheduler(r.config.Every, noJitter, immediateStart)
+		baseScheduler, err = scheduler.NewIntervalSchedulerWithOptions(r.config.Every, r.config.Jitter, immediateStart, r.intervalOptions(), r.clock)
 	case "count", "duration":
 		interval := r.config.Every
 		if interval == 0 {
 			interval = immediateInterval // Immediate execution for count/duration without --every
 		}
-		baseScheduler, err = scheduler.NewIntervalScheduler(interval, noJitter, immediateStart)
+		baseScheduler, err = scheduler.NewIntervalSchedulerWithOptions(interval, r.config.Jitter, immediateStart, r.intervalOptions(), r.clock)
 	case "cron":
 		baseScheduler, err = r.createCronScheduler()
 	case "adaptive":
 		baseScheduler, err = r.createAdaptiveScheduler()
+	case "phases":
+		baseScheduler, err = r.createPhasedScheduler()
 
 	// EXISTING RATE CONTROL
 	case "rate-limit":
@@ -456,50 +711,200 @@ func (r *Runner) createScheduler() (Scheduler, error) {
 		return nil, err
 	}
 
+	// Apply modifiers that compose with any subcommand
+	baseScheduler, err = r.composeScheduler(baseScheduler)
+	if err != nil {
+		return nil, err
+	}
+
 	// Wrap with HTTP-aware scheduler if enabled
 	return r.wrapWithHTTPAware(baseScheduler)
 }
 
-// wrapWithHTTPAware wraps a scheduler with HTTP-aware functionality if enabled
+// intervalOptions returns the --mode, --align and --seed settings for interval
+// schedules
+func (r *Runner) intervalOptions() scheduler.IntervalOptions {
+	options := scheduler.IntervalOptions{Align: r.config.Align, Seed: r.config.Seed}
+	if r.config.IntervalMode == "fixed-delay" {
+		options.Mode = scheduler.FixedDelay
+	}
+	return options
+}
+
+// composeScheduler combines a subcommand's scheduler with the --or-every,
+// --or-cron and --and-cron schedules and wraps it with the --start-at, --delay
+// and --rate combinators. The rate limit is applied last so it governs actual
+// executions.
+func (r *Runner) composeScheduler(base Scheduler) (Scheduler, error) {
+	sched := base
+
+	if r.config.OrEvery > 0 || r.config.OrCron != "" {
+		schedulers := []Scheduler{sched}
+		if r.config.OrEvery > 0 {
+			every, err := scheduler.NewIntervalSchedulerWithClock(r.config.OrEvery, 0, true, r.clock)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --or-every: %w", err)
+			}
+			// The subcommand's schedule covers the start, so the extra
+			// interval first fires one interval in
+			schedulers = append(schedulers, scheduler.DelayWithClock(every, r.config.OrEvery, r.clock))
+		}
+		if r.config.OrCron != "" {
+			cron, err := scheduler.NewCronSchedulerWithClock(r.config.OrCron, r.config.Timezone, r.clock)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --or-cron: %w", err)
+			}
+			schedulers = append(schedulers, cron)
+		}
+		sched = scheduler.Union(schedulers...)
+	}
+
+	if r.config.AndCron != "" {
+		cron, err := scheduler.NewCronSchedulerWithClock(r.config.AndCron, r.config.Timezone, r.clock)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --and-cron: %w", err)
+		}
+		sched = scheduler.Intersect(sched, cron)
+	}
+
+	if !r.config.StartAt.IsZero() {
+		sched = scheduler.StartAtWithClock(sched, r.config.StartAt, r.clock)
+	}
+
+	if r.config.Delay > 0 {
+		sched = scheduler.DelayWithClock(sched, r.config.Delay, r.clock)
+	}
+
+	// The rate-limit subcommand is already driven by the limiter
+	if r.config.RateSpec != "" && r.config.Subcommand != "rate-limit" {
+		limiter, err := r.createRateLimiter(r.clock)
+		if err != nil {
+			return nil, err
+		}
+		sched = scheduler.ThrottleWithClock(sched, limiter, r.clock)
+	}
+
+	return sched, nil
+}
+
+// findAdaptiveWrapper locates the adaptive scheduler, looking inside any
+// combinators wrapped around it
+func findAdaptiveWrapper(sched Scheduler) *AdaptiveSchedulerWrapper {
+	if wrapper, ok := sched.(*AdaptiveSchedulerWrapper); ok {
+		return wrapper
+	}
+
+	if composite, ok := sched.(interface{ Unwrap() []Scheduler }); ok {
+		for _, child := range composite.Unwrap() {
+			if wrapper := findAdaptiveWrapper(child); wrapper != nil {
+				return wrapper
+			}
+		}
+	}
+
+	return nil
+}
+
+// findRateLimitScheduler locates the rate-limit scheduler, looking inside any
+// combinators wrapped around it
+func findRateLimitScheduler(sched Scheduler) *RateLimitScheduler {
+	if limited, ok := sched.(*RateLimitScheduler); ok {
+		return limited
+	}
+
+	if composite, ok := sched.(interface{ Unwrap() []Scheduler }); ok {
+		for _, child := range composite.Unwrap() {
+			if limited := findRateLimitScheduler(child); limited != nil {
+				return limited
+			}
+		}
+	}
+
+	return nil
+}
+
+// notifyCompletion reports a finished execution to every scheduler that times
+// its next tick from it, looking inside any combinators
+func notifyCompletion(sched Scheduler, at time.Time) {
+	if aware, ok := sched.(scheduler.CompletionAware); ok {
+		aware.ExecutionCompleted(at)
+	}
+
+	if composite, ok := sched.(interface{ Unwrap() []Scheduler }); ok {
+		for _, child := range composite.Unwrap() {
+			notifyCompletion(child, at)
+		}
+	}
+}
+
+// analyzeResponse passes the command output (stdout + stderr) to the
+// HTTP-aware scheduler. With --aware, a response its parsers classify as a
+// failure fails the execution.
+func (r *Runner) analyzeResponse(result *executor.ExecutionResult) {
+	var fullOutput string
+	if result != nil {
+		fullOutput = result.Stdout
+		if result.Stderr != "" {
+			if fullOutput != "" {
+				fullOutput += "\n"
+			}
+			fullOutput += result.Stderr
+		}
+	}
+
+	// Set the last response for HTTP-aware analysis; the native HTTP probe
+	// hands over its response already parsed
+	if result != nil && result.HTTP != nil {
+		r.httpAwareScheduler.SetLastHTTPResponse(result.HTTP)
+	} else {
+		r.httpAwareScheduler.SetLastResponse(fullOutput)
+	}
+	parsed := r.httpAwareScheduler.GetParseResult()
+
+	if len(r.config.Aware) > 0 && parsed != nil && parsed.Outcome == httpaware.OutcomeFailure && result != nil && result.Success {
+		result.Success = false
+		result.ExitCode = max(result.ExitCode, 1)
+		result.Reason = fmt.Sprintf("%s parser reported %s", parsed.Parser, parsed.Detail)
+	}
+
+	if timingInfo := r.httpAwareScheduler.GetTimingInfo(); timingInfo != nil {
+		r.observers.stateChange(SchedulerStateChange{
+			Kind:     ResponseDelayed,
+			At:       r.clock.Now(),
+			Interval: timingInfo.Delay,
+			Source:   timingInfo.Source,
+		})
+	}
+}
+
+// wrapWithHTTPAware wraps a scheduler with HTTP-aware functionality if enabled.
+// The base scheduler keeps the cadence; a response carrying retry timing
+// postpones the next tick.
 func (r *Runner) wrapWithHTTPAware(baseScheduler Scheduler) (Scheduler, error) {
 	httpConfig := r.config.GetHTTPAwareConfig()
 	if httpConfig == nil {
 		return baseScheduler, nil
 	}
 
-	// Create HTTP-aware scheduler with the base scheduler as fallback
-	r.httpAwareScheduler = httpaware.NewHTTPAwareScheduler(*httpConfig)
+	parser, err := httpaware.DefaultParsers.New(httpConfig.Parsers, *httpConfig, r.clock)
+	if err != nil {
+		return nil, err
+	}
 
-	// For now, we'll use the base scheduler for timing and integrate HTTP-aware logic
-	// in the execution loop. This allows us to maintain compatibility with existing
-	// scheduler interfaces while adding HTTP-aware intelligence.
-	return baseScheduler, nil
+	r.httpAwareScheduler = httpaware.NewHTTPAwareSchedulerWithParser(*httpConfig, parser, r.clock)
+	r.httpAwareScheduler.SetFallbackScheduler(baseScheduler)
+	return r.httpAwareScheduler, nil
 }
 
 // createRateLimitScheduler creates a rate-limit aware scheduler
 func (r *Runner) createRateLimitScheduler() (Scheduler, error) {
-	// Parse rate specification
-	rate, period, err := ratelimit.ParseRateSpec(r.config.RateSpec)
+	limiter, err := r.createRateLimiter(r.clock)
 	if err != nil {
-		return nil, fmt.Errorf("invalid rate spec: %w", err)
-	}
-
-	// Parse retry pattern if provided
-	var retryPattern []time.Duration
-	if r.config.RetryPattern != "" {
-		retryPattern, err = r.parseRetryPattern(r.config.RetryPattern)
-		if err != nil {
-			return nil, fmt.Errorf("invalid retry pattern: %w", err)
-		}
-	} else {
-		retryPattern = []time.Duration{0} // Default: single attempt, no retries
+		return nil, err
 	}
 
-	// Create Diophantine rate limiter
-	limiter := ratelimit.NewDiophantineRateLimiter(rate, period, retryPattern)
-
 	// Create a scheduler that respects the rate limiter
-	return NewRateLimitScheduler(limiter, r.config.ShowNext), nil
+	return NewRateLimitSchedulerWithClock(limiter, r.config.ShowNext, r.clock), nil
 }
 
 // parseRetryPattern parses retry pattern string like "0,10m,30m"
@@ -518,6 +923,14 @@ func (r *Runner) parseRetryPattern(pattern string) ([]time.Duration, error) {
 			}
 			retryPattern[i] = duration
 		}
+
+		// Attempts run in pattern order
+		if retryPattern[i] < 0 {
+			return nil, fmt.Errorf("retry offset '%s' must not be negative", part)
+		}
+		if i > 0 && retryPattern[i] <= retryPattern[i-1] {
+			return nil, fmt.Errorf("retry offset '%s' must be later than the one before it", part)
+		}
 	}
 
 	return retryPattern, nil
@@ -525,9 +938,28 @@ func (r *Runner) parseRetryPattern(pattern string) ([]time.Duration, error) {
 
 // createExecutionContext creates a context with appropriate timeouts
 func (r *Runner) createExecutionContext(ctx context.Context) (context.Context, context.CancelFunc) {
-	if r.config.For > 0 {
-		// Duration-based timeout
-		return context.WithTimeout(ctx, r.config.For)
+	limit := r.config.For
+	if !r.config.Deadline.IsZero() {
+		untilDeadline := max(r.config.Deadline.Sub(r.clock.Now()), time.Nanosecond)
+		if limit == 0 || untilDeadline < limit {
+			limit = untilDeadline
+		}
+	}
+
+	if limit > 0 {
+		// Duration-based timeout, measured on the runner's clock. The cancel
+		// cause records the deadline so it can be told apart from an interrupt.
+		execCtx, cancel := context.WithCancelCause(ctx)
+		timer := r.clock.NewTimer(limit)
+		go func() {
+			defer timer.Stop()
+			select {
+			case <-timer.C():
+				cancel(context.DeadlineExceeded)
+			case <-execCtx.Done():
+			}
+		}()
+		return execCtx, func() { cancel(context.Canceled) }
 	}
 
 	// No timeout, use parent context
@@ -542,7 +974,12 @@ func (r *Runner) shouldStop(stats *ExecutionStats, startTime time.Time) bool {
 	}
 
 	// Check duration limit
-	if r.config.For > 0 && time.Since(startTime) >= r.config.For {
+	if r.config.For > 0 && r.clock.Now().Sub(startTime) >= r.config.For {
+		return true
+	}
+
+	// Check absolute deadline
+	if !r.config.Deadline.IsZero() && !r.clock.Now().Before(r.config.Deadline) {
 		return true
 	}
 
@@ -552,30 +989,39 @@ func (r *Runner) shouldStop(stats *ExecutionStats, startTime time.Time) bool {
 // AdaptiveSchedulerWrapper wraps adaptive.AdaptiveScheduler to implement Scheduler interface
 type AdaptiveSchedulerWrapper struct {
 	scheduler *adaptive.AdaptiveScheduler
-	config    *cli.Config
+	config    *Config
+	clock     scheduler.Clock
 	nextChan  chan time.Time
 	stopChan  chan struct{}
 	stopped   bool
+	start     sync.Once // starts scheduleLoop on the first Next
 }
 
 // NewAdaptiveSchedulerWrapper creates a new adaptive scheduler wrapper
-func NewAdaptiveSchedulerWrapper(scheduler *adaptive.AdaptiveScheduler, config *cli.Config) *AdaptiveSchedulerWrapper {
-	w := &AdaptiveSchedulerWrapper{
-		scheduler: scheduler,
+func NewAdaptiveSchedulerWrapper(adaptiveScheduler *adaptive.AdaptiveScheduler, config *Config) *AdaptiveSchedulerWrapper {
+	return NewAdaptiveSchedulerWrapperWithClock(adaptiveScheduler, config, scheduler.NewRealClock())
+}
+
+// NewAdaptiveSchedulerWrapperWithClock creates an adaptive scheduler wrapper that
+// waits out adaptive intervals on the given clock
+func NewAdaptiveSchedulerWrapperWithClock(adaptiveScheduler *adaptive.AdaptiveScheduler, config *Config, clock scheduler.Clock) *AdaptiveSchedulerWrapper {
+	return &AdaptiveSchedulerWrapper{
+		scheduler: adaptiveScheduler,
 		config:    config,
+		clock:     clock,
 		nextChan:  make(chan time.Time, 1),
 		stopChan:  make(chan struct{}),
 		stopped:   false,
 	}
-
-	// Start the scheduling goroutine
-	go w.scheduleLoop()
-
-	return w
 }
 
-// Next returns a channel that delivers the next execution time
+// Next returns a channel that delivers the next execution time. The first
+// call starts the schedule, so a wrapper built ahead of its phase does not
+// time its first tick from when it was built.
 func (w *AdaptiveSchedulerWrapper) Next() <-chan time.Time {
+	w.start.Do(func() {
+		go w.scheduleLoop()
+	})
 	return w.nextChan
 }
 
@@ -591,10 +1037,10 @@ func (w *AdaptiveSchedulerWrapper) scheduleLoop() {
 
 			// Wait for the interval
 			select {
-			case <-time.After(interval):
+			case <-w.clock.After(interval):
 				// Send next execution time
 				select {
-				case w.nextChan <- time.Now():
+				case w.nextChan <- w.clock.Now():
 					// Successfully sent, continue to next iteration
 				case <-w.stopChan:
 					return
@@ -636,28 +1082,6 @@ func (w *AdaptiveSchedulerWrapper) GetMetrics() *adaptive.AdaptiveMetrics {
 	return w.scheduler.GetMetrics()
 }
 
-// showAdaptiveMetrics displays current adaptive scheduling metrics
-func (r *Runner) showAdaptiveMetrics(metrics *adaptive.AdaptiveMetrics) {
-	fmt.Printf("📊 Adaptive Metrics: Interval=%v, Success=%.1f%%, Circuit=%s\n",
-		metrics.CurrentInterval.Round(time.Millisecond),
-		metrics.SuccessRate*100,
-		r.circuitStateString(metrics.CircuitState))
-}
-
-// circuitStateString converts circuit state to readable string
-func (r *Runner) circuitStateString(state adaptive.CircuitState) string {
-	switch state {
-	case adaptive.CircuitClosed:
-		return "CLOSED"
-	case adaptive.CircuitOpen:
-		return "OPEN"
-	case adaptive.CircuitHalfOpen:
-		return "HALF-OPEN"
-	default:
-		return "UNKNOWN"
-	}
-}
-
 // createAdaptiveScheduler creates an adaptive scheduler
 func (r *Runner) createAdaptiveScheduler() (Scheduler, error) {
 	// Create adaptive configuration from CLI config
@@ -686,18 +1110,89 @@ func (r *Runner) createAdaptiveScheduler() (Scheduler, error) {
 	}
 
 	// Wrap it to implement Scheduler interface
-	return NewAdaptiveSchedulerWrapper(scheduler, r.config), nil
+	return NewAdaptiveSchedulerWrapperWithClock(scheduler, r.config, r.clock), nil
+}
+
+// createPhasedScheduler creates a scheduler that runs each --phase in turn
+func (r *Runner) createPhasedScheduler() (Scheduler, error) {
+	phases, err := r.buildPhases((*Runner).createPhaseScheduler)
+	if err != nil {
+		return nil, err
+	}
+
+	phased, err := scheduler.NewPhasedSchedulerWithClock(phases, r.clock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create phased scheduler: %w", err)
+	}
+	phased.SetPhaseChangeHandler(r.reportPhase)
+
+	return phased, nil
+}
+
+// buildPhases creates the scheduler of every configured phase with build,
+// called on a runner whose config describes that phase
+func (r *Runner) buildPhases(build func(*Runner) (Scheduler, error)) ([]scheduler.Phase, error) {
+	phases := make([]scheduler.Phase, len(r.config.Phases))
+	for i, phase := range r.config.Phases {
+		name := phase.Name
+		if name == "" {
+			name = fmt.Sprintf("phase-%d", i+1)
+		}
+
+		phaseRunner := &Runner{config: r.config.ForPhase(phase), clock: r.clock}
+		sched, err := build(phaseRunner)
+		if err != nil {
+			return nil, fmt.Errorf("phase %d (%s): %w", i+1, name, err)
+		}
+
+		phases[i] = scheduler.Phase{
+			Name:      name,
+			Scheduler: sched,
+			Times:     int(phase.Times),
+			For:       phase.For,
+		}
+	}
+	return phases, nil
+}
+
+// createPhaseScheduler creates the scheduler for a single phase
+func (r *Runner) createPhaseScheduler() (Scheduler, error) {
+	switch r.config.Subcommand {
+	case "interval":
+		return scheduler.NewIntervalSchedulerWithOptions(r.config.Every, 0.0, true, r.intervalOptions(), r.clock)
+	case "exponential":
+		return r.createStrategyScheduler("exponential")
+	case "adaptive":
+		return r.createAdaptiveScheduler()
+	case "cron":
+		return r.createCronScheduler()
+	default:
+		return nil, fmt.Errorf("unknown phase mode: %s", r.config.Subcommand)
+	}
+}
+
+// reportPhase tells the observers a phase has started
+func (r *Runner) reportPhase(index int, phase scheduler.Phase) {
+	r.observers.stateChange(SchedulerStateChange{
+		Kind:        PhaseStarted,
+		At:          r.clock.Now(),
+		Phase:       index,
+		PhaseCount:  len(r.config.Phases),
+		PhaseName:   phase.Name,
+		PhaseDetail: r.config.Phases[index].String(),
+	})
 }
 
 // createLoadAdaptiveScheduler creates a load-aware adaptive scheduler
 func (r *Runner) createLoadAdaptiveScheduler() (Scheduler, error) {
-	return scheduler.NewLoadAwareSchedulerWithBounds(
+	return scheduler.NewLoadAwareSchedulerWithClock(
 		r.config.BaseInterval,
 		r.config.TargetCPU,
 		r.config.TargetMemory,
 		r.config.TargetLoad,
 		r.config.MinInterval,
 		r.config.MaxInterval,
+		r.clock,
 	), nil
 }
 
@@ -708,7 +1203,7 @@ func (r *Runner) createCronScheduler() (Scheduler, error) {
 	}
 
 	// Create cron scheduler with timezone
-	cronScheduler, err := scheduler.NewCronScheduler(r.config.CronExpression, r.config.Timezone)
+	cronScheduler, err := scheduler.NewCronSchedulerWithClock(r.config.CronExpression, r.config.Timezone, r.clock)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cron scheduler: %w", err)
 	}
@@ -716,73 +1211,35 @@ func (r *Runner) createCronScheduler() (Scheduler, error) {
 	return cronScheduler, nil
 }
 
-// createStrategyScheduler creates a strategy-based scheduler using mathematical retry algorithms
+// createStrategyScheduler creates a scheduler for the attempts of a retry
+// policy built from the strategy flags
 func (r *Runner) createStrategyScheduler(strategyName string) (Scheduler, error) {
-	// Create strategy configuration from CLI config
-	config := &strategies.StrategyConfig{
-		MaxAttempts:     r.config.MaxRetries,
-		Timeout:         r.config.Timeout,
-		SuccessPattern:  r.config.SuccessPattern,
-		FailurePattern:  r.config.FailurePattern,
-		CaseInsensitive: r.config.CaseInsensitive,
-	}
-
-	// Set default values if not specified
-	if config.MaxAttempts <= 0 {
-		config.MaxAttempts = 3 // Default retry attempts
+	attempts := r.config.MaxRetries
+	if attempts <= 0 {
+		attempts = 3 // Default retry attempts
 	}
-
-	// Create the specific strategy based on the strategy name
-	var strategy strategies.Strategy
+	options := []retry.Option{retry.Attempts(attempts)}
 
 	switch strategyName {
 	case "exponential":
-		baseDelay := r.getBaseDelay()
-		multiplier := r.getMultiplier()
-		maxDelay := r.getMaxDelay()
-		config.BaseDelay = baseDelay
-		config.Multiplier = multiplier
-		config.MaxDelay = maxDelay
-		strategy = strategies.NewExponentialStrategy(baseDelay, multiplier, maxDelay)
-
+		options = append(options, retry.Exponential(r.getBaseDelay(), r.getMultiplier(), r.getMaxDelay()))
 	case "fibonacci":
-		baseDelay := r.getBaseDelay()
-		maxDelay := r.getMaxDelay()
-		config.BaseDelay = baseDelay
-		config.MaxDelay = maxDelay
-		strategy = strategies.NewFibonacciStrategy(baseDelay, maxDelay)
-
+		options = append(options, retry.Fibonacci(r.getBaseDelay(), r.getMaxDelay()))
 	case "linear":
-		increment := r.getIncrement()
-		maxDelay := r.getMaxDelay()
-		config.Increment = increment
-		config.MaxDelay = maxDelay
-		strategy = strategies.NewLinearStrategy(increment, maxDelay)
-
+		options = append(options, retry.Linear(r.getIncrement(), r.getMaxDelay()))
 	case "polynomial":
-		baseDelay := r.getBaseDelay()
-		exponent := r.getExponent()
-		maxDelay := r.getMaxDelay()
-		config.BaseDelay = baseDelay
-		config.Exponent = exponent
-		config.MaxDelay = maxDelay
-		strategy = strategies.NewPolynomialStrategy(baseDelay, exponent, maxDelay)
-
+		options = append(options, retry.Polynomial(r.getBaseDelay(), r.getExponent(), r.getMaxDelay()))
 	case "decorrelated-jitter":
-		baseDelay := r.getBaseDelay()
-		multiplier := r.getMultiplier()
-		maxDelay := r.getMaxDelay()
-		config.BaseDelay = baseDelay
-		config.Multiplier = multiplier
-		config.MaxDelay = maxDelay
-		strategy = strategies.NewDecorrelatedJitterStrategy(baseDelay, multiplier, maxDelay)
-
+		options = append(options, retry.DecorrelatedJitter(r.getBaseDelay(), r.getMultiplier(), r.getMaxDelay(), r.config.Seed))
 	default:
 		return nil, fmt.Errorf("unknown strategy: %s", strategyName)
 	}
 
-	// Create and return the strategy scheduler
-	return scheduler.NewStrategyScheduler(strategy, config)
+	policy, err := retry.New(options...)
+	if err != nil {
+		return nil, err
+	}
+	return scheduler.NewRetrySchedulerWithClock(policy, r.clock), nil
 }
 
 // Helper functions to get strategy parameters with defaults
@@ -0,0 +1,424 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+// runOnVirtualClock runs r to completion, advancing the clock to the next
+// pending timer whenever the run is waiting on one
+func runOnVirtualClock(t *testing.T, r *Runner, clock *scheduler.VirtualClock) *ExecutionStats {
+	t.Helper()
+
+	type outcome struct {
+		stats *ExecutionStats
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		stats, err := r.Run(context.Background())
+		done <- outcome{stats, err}
+	}()
+
+	deadline := time.After(10 * time.Second)
+	for {
+		select {
+		case out := <-done:
+			require.NoError(t, out.err)
+			return out.stats
+		case <-deadline:
+			t.Fatal("run on virtual clock did not finish")
+		default:
+		}
+
+		if !clock.AdvanceToNext() {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+func TestRunner_VirtualClock_Cron(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand:     "cron",
+		CronExpression: "@hourly",
+		Timezone:       "UTC",
+		Times:          3,
+		Quiet:          true,
+		Command:        []string{"true"},
+	}, clock)
+	require.NoError(t, err)
+
+	began := time.Now()
+	stats := runOnVirtualClock(t, r, clock)
+
+	assert.Equal(t, 3, stats.TotalExecutions)
+	assert.Equal(t, start, stats.StartTime)
+	assert.GreaterOrEqual(t, stats.Duration, 3*time.Hour, "duration is measured on the virtual clock")
+	assert.Less(t, time.Since(began), 5*time.Second)
+	for i, record := range stats.Executions {
+		assert.False(t, record.StartTime.Before(start.Add(time.Duration(i+1)*time.Hour)))
+	}
+}
+
+func TestRunner_VirtualClock_RateLimit(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand: "rate-limit",
+		RateSpec:   "2/1h",
+		Times:      4,
+		Quiet:      true,
+		Command:    []string{"true"},
+	}, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	assert.Equal(t, 4, stats.TotalExecutions)
+	// Only two executions fit in the first hour, so the third has to wait for the next one
+	assert.GreaterOrEqual(t, stats.Duration, time.Hour)
+}
+
+func TestRunner_VirtualClock_ForDeadline(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand: "duration",
+		Every:      10 * time.Minute,
+		For:        24 * time.Hour,
+		Quiet:      true,
+		Command:    []string{"true"},
+	}, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	assert.GreaterOrEqual(t, stats.TotalExecutions, 1)
+	assert.LessOrEqual(t, stats.TotalExecutions, 144)
+	assert.GreaterOrEqual(t, stats.Duration, 24*time.Hour)
+}
+
+func TestRunner_VirtualClock_RateLimitRetries(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand:   "rate-limit",
+		RateSpec:     "6/1h",
+		RetryPattern: "0,10m,30m",
+		Times:        6,
+		Quiet:        true,
+		Command:      []string{"false"},
+	}, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	// Two requests fill the hour; each is retried at +10m and +30m
+	require.Equal(t, 6, stats.TotalExecutions)
+	for i, want := range []struct{ request, attempt int }{{1, 1}, {2, 1}, {1, 2}, {2, 2}, {1, 3}, {2, 3}} {
+		assert.Equal(t, want.request, stats.Executions[i].RequestNumber, "execution %d", i+1)
+		assert.Equal(t, want.attempt, stats.Executions[i].Attempt, "execution %d", i+1)
+	}
+	assert.False(t, stats.Executions[2].StartTime.Before(start.Add(10*time.Minute)))
+	assert.False(t, stats.Executions[4].StartTime.Before(start.Add(30*time.Minute)))
+
+	require.Len(t, stats.Requests, 2)
+	for _, request := range stats.Requests {
+		assert.False(t, request.Succeeded)
+		assert.Equal(t, 3, request.Attempts)
+	}
+}
+
+func TestRunner_VirtualClock_RateLimitRetrySucceeds(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	// Fails the first time it runs, then succeeds
+	marker := filepath.Join(t.TempDir(), "ran")
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand:   "rate-limit",
+		RateSpec:     "3/1h",
+		RetryPattern: "0,10m,30m",
+		Times:        3,
+		Quiet:        true,
+		Command:      []string{"sh", "-c", fmt.Sprintf("test -f %[1]s || { touch %[1]s; exit 1; }", marker)},
+	}, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	require.Equal(t, 3, stats.TotalExecutions)
+	require.GreaterOrEqual(t, len(stats.Requests), 1)
+	assert.Equal(t, RequestOutcome{RequestNumber: 1, RequestTime: start, Attempts: 2, Succeeded: true}, stats.Requests[0])
+	assert.False(t, stats.Executions[1].StartTime.Before(start.Add(10*time.Minute)))
+
+	// The unused +30m attempt was released, so the next request fits before the hour is out
+	assert.Equal(t, 2, stats.Executions[2].RequestNumber)
+	assert.Less(t, stats.Executions[2].StartTime.Sub(start), time.Hour)
+}
+
+func TestRunner_VirtualClock_RateThrottlesInterval(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand: "interval",
+		Every:      time.Minute,
+		RateSpec:   "2/1h",
+		Times:      3,
+		Quiet:      true,
+		Command:    []string{"true"},
+	}, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	assert.Equal(t, 3, stats.TotalExecutions)
+	// Every minute, but only two per hour
+	assert.GreaterOrEqual(t, stats.Duration, time.Hour)
+}
+
+func TestRunner_VirtualClock_Phases(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand: "phases",
+		Phases: []PhaseConfig{
+			{Name: "warmup", Mode: "interval", Every: time.Minute, Times: 2},
+			{Name: "steady", Mode: "interval", Every: time.Hour},
+		},
+		Times:   4,
+		Quiet:   true,
+		Command: []string{"true"},
+	}, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	assert.Equal(t, 4, stats.TotalExecutions)
+	// Two executions a minute apart, then the hourly phase takes over
+	assert.GreaterOrEqual(t, stats.Duration, time.Hour)
+	assert.False(t, stats.Executions[3].StartTime.Before(start.Add(61*time.Minute)))
+}
+
+func TestRunner_VirtualClock_PhasesEndWhenAttemptsRunOut(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	// Neither phase sets times or for: each ends after its attempts
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand: "phases",
+		Phases: []PhaseConfig{
+			{Name: "fast", Mode: "exponential", BaseDelay: time.Second, Multiplier: 2, MaxDelay: time.Minute, Attempts: 2},
+			{Name: "slow", Mode: "exponential", BaseDelay: time.Minute, Multiplier: 2, MaxDelay: time.Hour, Attempts: 3},
+		},
+		Quiet:   true,
+		Command: []string{"false"},
+	}, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	assert.Equal(t, 5, stats.TotalExecutions)
+}
+
+func TestRunner_VirtualClock_AdaptiveWaitsForFirstNext(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand:   "adaptive",
+		BaseInterval: time.Minute,
+		MinInterval:  time.Second,
+		MaxInterval:  time.Hour,
+		Command:      []string{"true"},
+	}, clock)
+	require.NoError(t, err)
+
+	sched, err := r.createAdaptiveScheduler()
+	require.NoError(t, err)
+	defer sched.Stop()
+
+	// A later phase is built before its turn; time passing meanwhile must
+	// not leave a stale tick waiting for it
+	time.Sleep(10 * time.Millisecond)
+	clock.Advance(time.Hour)
+
+	next := sched.Next()
+	select {
+	case tick := <-next:
+		t.Fatalf("stale tick from %v", tick)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	select {
+	case tick := <-next:
+		assert.Equal(t, start.Add(61*time.Minute), tick)
+	case <-time.After(time.Second):
+		t.Fatal("no tick after the adaptive interval")
+	}
+}
+
+func TestRunner_VirtualClock_FixedDelay(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand:   "interval",
+		Every:        time.Minute,
+		IntervalMode: "fixed-delay",
+		Times:        3,
+		Quiet:        true,
+		Command:      []string{"true"},
+	}, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	// Each tick waits for the previous execution to be reported complete
+	assert.Equal(t, 3, stats.TotalExecutions)
+	assert.False(t, stats.Executions[2].StartTime.Before(start.Add(2*time.Minute)))
+}
+
+func TestRunner_VirtualClock_StartAt(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand: "interval",
+		Every:      10 * time.Minute,
+		StartAt:    start.Add(time.Hour),
+		Times:      2,
+		Quiet:      true,
+		Command:    []string{"true"},
+	}, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	require.Equal(t, 2, stats.TotalExecutions)
+	assert.False(t, stats.Executions[0].StartTime.Before(start.Add(time.Hour)))
+}
+
+func TestRunner_VirtualClock_Deadline(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	r, err := NewRunnerWithClock(&Config{
+		Subcommand: "interval",
+		Every:      10 * time.Minute,
+		Deadline:   start.Add(time.Hour),
+		Quiet:      true,
+		Command:    []string{"true"},
+	}, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	assert.GreaterOrEqual(t, stats.TotalExecutions, 1)
+	assert.LessOrEqual(t, stats.TotalExecutions, 7)
+	assert.GreaterOrEqual(t, stats.Duration, time.Hour)
+}
+
+func TestRunner_VirtualClock_HTTPAwareRetryAfter(t *testing.T) {
+	response := []string{"sh", "-c", "printf 'HTTP/1.1 429 Too Many Requests\\r\\nRetry-After: 600\\r\\n\\r\\n'"}
+
+	tests := []struct {
+		name   string
+		config Config
+	}{
+		{"interval", Config{Subcommand: "interval", Every: time.Minute}},
+		{"count", Config{Subcommand: "count"}},
+		{"rate-limit", Config{Subcommand: "rate-limit", RateSpec: "100/1h"}},
+		{"exponential", Config{Subcommand: "exponential", BaseDelay: time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			clock := scheduler.NewVirtualClock(start)
+
+			config := tt.config
+			config.HTTPAware = true
+			config.HTTPParseHeaders = true
+			config.Times = 2
+			config.Quiet = true
+			config.Command = response
+			r, err := NewRunnerWithClock(&config, clock)
+			require.NoError(t, err)
+
+			stats := runOnVirtualClock(t, r, clock)
+
+			// The second execution waits out the Retry-After, whatever the base cadence
+			require.Equal(t, 2, stats.TotalExecutions)
+			gap := stats.Executions[1].StartTime.Sub(stats.Executions[0].EndTime)
+			assert.GreaterOrEqual(t, gap, 10*time.Minute)
+		})
+	}
+}
+
+func TestRunner_VirtualClock_AwareParsers(t *testing.T) {
+	grpcThrottled := []string{"sh", "-c", `printf 'ERROR:\n  Code: ResourceExhausted\n  Details: {"retryDelay": "300s"}\n'`}
+	httpUnavailable := []string{"sh", "-c", "printf 'HTTP/1.1 503 Service Unavailable\\r\\n\\r\\n'"}
+
+	tests := []struct {
+		name          string
+		aware         []string
+		httpAware     bool
+		command       []string
+		expectedFails int
+		minGap        time.Duration
+	}{
+		{"grpc retry info fails and delays", []string{"grpc"}, false, grpcThrottled, 2, 5 * time.Minute},
+		{"http status fails with --aware", []string{"grpc", "http"}, false, httpUnavailable, 2, 0},
+		{"http status is timing only with --http-aware", nil, true, httpUnavailable, 0, 0},
+		{"unrecognized output keeps exit status", []string{"aws"}, false, []string{"echo", "ok"}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			clock := scheduler.NewVirtualClock(start)
+
+			config := Config{
+				Subcommand:       "interval",
+				Every:            time.Second,
+				Times:            2,
+				Quiet:            true,
+				Aware:            tt.aware,
+				HTTPAware:        tt.httpAware,
+				HTTPParseHeaders: true,
+				Command:          tt.command,
+			}
+			r, err := NewRunnerWithClock(&config, clock)
+			require.NoError(t, err)
+
+			stats := runOnVirtualClock(t, r, clock)
+
+			require.Equal(t, 2, stats.TotalExecutions)
+			assert.Equal(t, tt.expectedFails, stats.FailedExecutions)
+			gap := stats.Executions[1].StartTime.Sub(stats.Executions[0].EndTime)
+			assert.GreaterOrEqual(t, gap, tt.minGap)
+			for _, record := range stats.Executions {
+				assert.Equal(t, record.Success, record.ExitCode == 0, "a failed execution reports a non-zero exit code")
+			}
+		})
+	}
+}
@@ -9,19 +9,19 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/swi/repeater/pkg/cli"
+	"github.com/swi/repeater/pkg/scheduler"
 )
 
 // TestRateLimitSchedulerIntegration tests the rate limit scheduler functionality
 func TestRateLimitSchedulerIntegration(t *testing.T) {
 	tests := []struct {
 		name          string
-		config        *cli.Config
+		config        *Config
 		expectedError string
 	}{
 		{
 			name: "rate_limit_with_valid_rate_spec",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "rate-limit",
 				RateSpec:   "10/1h",
 				Command:    []string{"echo", "test"},
@@ -30,7 +30,7 @@ func TestRateLimitSchedulerIntegration(t *testing.T) {
 		},
 		{
 			name: "rate_limit_with_retry_pattern",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:   "rate-limit",
 				RateSpec:     "5/1m",
 				RetryPattern: "0,10s,30s",
@@ -40,7 +40,7 @@ func TestRateLimitSchedulerIntegration(t *testing.T) {
 		},
 		{
 			name: "rate_limit_missing_rate_spec",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "rate-limit",
 				Command:    []string{"echo", "test"},
 			},
@@ -48,7 +48,7 @@ func TestRateLimitSchedulerIntegration(t *testing.T) {
 		},
 		{
 			name: "rate_limit_invalid_rate_spec",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "rate-limit",
 				RateSpec:   "invalid",
 				Command:    []string{"echo", "test"},
@@ -57,7 +57,7 @@ func TestRateLimitSchedulerIntegration(t *testing.T) {
 		},
 		{
 			name: "rate_limit_invalid_retry_pattern",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:   "rate-limit",
 				RateSpec:     "10/1h",
 				RetryPattern: "invalid,pattern",
@@ -89,7 +89,7 @@ func TestRateLimitSchedulerIntegration(t *testing.T) {
 
 // TestRateLimitSchedulerExecution tests actual execution with rate limiting
 func TestRateLimitSchedulerExecution(t *testing.T) {
-	config := &cli.Config{
+	config := &Config{
 		Subcommand: "rate-limit",
 		RateSpec:   "2/10s", // 2 executions per 10 seconds
 		Times:      3,       // Try to execute 3 times (should hit rate limit)
@@ -120,7 +120,7 @@ func TestAdaptiveMetricsDisplay(t *testing.T) {
 	r, w, _ := os.Pipe()
 	os.Stdout = w
 
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:   "adaptive",
 		BaseInterval: 1 * time.Second,
 		MinInterval:  500 * time.Millisecond,
@@ -158,7 +158,7 @@ func TestAdaptiveMetricsDisplay(t *testing.T) {
 // TestCircuitStateString tests the circuitStateString helper function
 func TestCircuitStateString(t *testing.T) {
 	// This is a private function, but we can test it through the adaptive metrics display
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:   "adaptive",
 		BaseInterval: 100 * time.Millisecond,
 		MinInterval:  50 * time.Millisecond,
@@ -226,7 +226,7 @@ func TestParseRetryPatternFunction(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create a config that will trigger parseRetryPattern
-			config := &cli.Config{
+			config := &Config{
 				Subcommand:   "rate-limit",
 				RateSpec:     "10/1h",
 				RetryPattern: tt.pattern,
@@ -250,12 +250,12 @@ func TestParseRetryPatternFunction(t *testing.T) {
 func TestSchedulerCreationEdgeCases(t *testing.T) {
 	tests := []struct {
 		name          string
-		config        *cli.Config
+		config        *Config
 		expectedError string
 	}{
 		{
 			name: "load_adaptive_with_valid_config",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:   "load-adaptive",
 				BaseInterval: 1 * time.Second,
 				TargetCPU:    70.0,
@@ -267,7 +267,7 @@ func TestSchedulerCreationEdgeCases(t *testing.T) {
 		},
 		{
 			name: "load_adaptive_missing_base_interval",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "load-adaptive",
 				Command:    []string{"echo", "test"},
 			},
@@ -275,7 +275,7 @@ func TestSchedulerCreationEdgeCases(t *testing.T) {
 		},
 		{
 			name: "exponential_strategy_with_defaults",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "exponential",
 				MaxRetries: 3,
 				Command:    []string{"echo", "test"},
@@ -284,7 +284,7 @@ func TestSchedulerCreationEdgeCases(t *testing.T) {
 		},
 		{
 			name: "fibonacci_strategy_with_defaults",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "fibonacci",
 				MaxRetries: 5,
 				Command:    []string{"echo", "test"},
@@ -293,7 +293,7 @@ func TestSchedulerCreationEdgeCases(t *testing.T) {
 		},
 		{
 			name: "linear_strategy_with_defaults",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "linear",
 				MaxRetries: 4,
 				Command:    []string{"echo", "test"},
@@ -302,7 +302,7 @@ func TestSchedulerCreationEdgeCases(t *testing.T) {
 		},
 		{
 			name: "polynomial_strategy_with_defaults",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "polynomial",
 				MaxRetries: 3,
 				Command:    []string{"echo", "test"},
@@ -311,7 +311,7 @@ func TestSchedulerCreationEdgeCases(t *testing.T) {
 		},
 		{
 			name: "decorrelated_jitter_strategy_with_defaults",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "decorrelated-jitter",
 				MaxRetries: 6,
 				Command:    []string{"echo", "test"},
@@ -343,7 +343,7 @@ func TestMathematicalStrategyExecution(t *testing.T) {
 
 	for _, strategy := range strategies {
 		t.Run("strategy_"+strategy, func(t *testing.T) {
-			config := &cli.Config{
+			config := &Config{
 				Subcommand: strategy,
 				BaseDelay:  100 * time.Millisecond,
 				MaxDelay:   1 * time.Second,
@@ -389,11 +389,11 @@ func TestMathematicalStrategyExecution(t *testing.T) {
 func TestServerIntegrationCombinations(t *testing.T) {
 	tests := []struct {
 		name   string
-		config *cli.Config
+		config *Config
 	}{
 		{
 			name: "health_and_metrics_servers_enabled",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:     "count",
 				Times:          1,
 				Command:        []string{"echo", "test"},
@@ -406,7 +406,7 @@ func TestServerIntegrationCombinations(t *testing.T) {
 		},
 		{
 			name: "only_health_server_enabled",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:    "count",
 				Times:         1,
 				Command:       []string{"echo", "test"},
@@ -417,7 +417,7 @@ func TestServerIntegrationCombinations(t *testing.T) {
 		},
 		{
 			name: "only_metrics_server_enabled",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:     "count",
 				Times:          1,
 				Command:        []string{"echo", "test"},
@@ -428,7 +428,7 @@ func TestServerIntegrationCombinations(t *testing.T) {
 		},
 		{
 			name: "no_servers_enabled",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      1,
 				Command:    []string{"echo", "test"},
@@ -460,12 +460,12 @@ func TestServerIntegrationCombinations(t *testing.T) {
 func TestErrorHandlingInExecution(t *testing.T) {
 	tests := []struct {
 		name     string
-		config   *cli.Config
+		config   *Config
 		expectOK bool
 	}{
 		{
 			name: "command_failure_handling",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      2,
 				Command:    []string{"sh", "-c", "exit 1"}, // Command that fails
@@ -475,7 +475,7 @@ func TestErrorHandlingInExecution(t *testing.T) {
 		},
 		{
 			name: "nonexistent_command",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      1,
 				Command:    []string{"nonexistent-command-12345"},
@@ -540,6 +540,16 @@ func TestParseRetryPatternDirect(t *testing.T) {
 			pattern: "0,invalid,10m",
 			wantErr: true,
 		},
+		{
+			name:    "negative offset",
+			pattern: "-1m,0",
+			wantErr: true,
+		},
+		{
+			name:    "offsets out of order",
+			pattern: "0,30m,10m",
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -557,7 +567,7 @@ func TestParseRetryPatternDirect(t *testing.T) {
 
 // TestCreateLoadAdaptiveSchedulerDirect tests createLoadAdaptiveScheduler function
 func TestCreateLoadAdaptiveSchedulerDirect(t *testing.T) {
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:   "load-adaptive",
 		BaseInterval: 1 * time.Second,
 		TargetCPU:    75.0,
@@ -567,18 +577,19 @@ func TestCreateLoadAdaptiveSchedulerDirect(t *testing.T) {
 		MaxInterval:  10 * time.Second,
 	}
 
-	runner := &Runner{config: config}
-	scheduler, err := runner.createLoadAdaptiveScheduler()
+	runner := &Runner{config: config, clock: scheduler.NewRealClock()}
+	sched, err := runner.createLoadAdaptiveScheduler()
 
 	assert.NoError(t, err)
-	assert.NotNil(t, scheduler)
+	assert.NotNil(t, sched)
+	sched.Stop()
 }
 
 // TestGetterFunctionEdgeCases tests the getter functions for default values
 func TestGetterFunctionEdgeCases(t *testing.T) {
 	// Test default values when config has zero values
 	runner := &Runner{
-		config: &cli.Config{
+		config: &Config{
 			BaseDelay: 0,
 			Increment: 0,
 			Exponent:  0,
@@ -602,3 +613,28 @@ func TestGetterFunctionEdgeCases(t *testing.T) {
 	assert.Equal(t, 1.5, runner.getExponent())
 	assert.Equal(t, 30*time.Second, runner.getMaxDelay())
 }
+
+// TestFindAdaptiveWrapperThroughCombinators tests that adaptive feedback still
+// reaches an adaptive scheduler wrapped by --rate or --delay
+func TestFindAdaptiveWrapperThroughCombinators(t *testing.T) {
+	config := &Config{
+		Subcommand:   "adaptive",
+		BaseInterval: time.Second,
+		MinInterval:  100 * time.Millisecond,
+		MaxInterval:  10 * time.Second,
+		RateSpec:     "10/1m",
+		Delay:        time.Second,
+		Command:      []string{"true"},
+	}
+
+	r, err := NewRunner(config)
+	require.NoError(t, err)
+
+	sched, err := r.createScheduler()
+	require.NoError(t, err)
+	defer sched.Stop()
+
+	_, isWrapper := sched.(*AdaptiveSchedulerWrapper)
+	assert.False(t, isWrapper, "adaptive scheduler should be wrapped")
+	assert.NotNil(t, findAdaptiveWrapper(sched))
+}
@@ -7,19 +7,18 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/swi/repeater/pkg/cli"
 )
 
 func TestRunner_HTTPAwareIntegration(t *testing.T) {
 	tests := []struct {
 		name          string
-		config        *cli.Config
+		config        *Config
 		expectedStats func(*ExecutionStats) bool
 		description   string
 	}{
 		{
 			name: "http-aware with retry-after header",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      2,
 				HTTPAware:  true,
@@ -33,7 +32,7 @@ func TestRunner_HTTPAwareIntegration(t *testing.T) {
 		},
 		{
 			name: "http-aware with json response",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      2,
 				HTTPAware:  true,
@@ -46,7 +45,7 @@ func TestRunner_HTTPAwareIntegration(t *testing.T) {
 		},
 		{
 			name: "http-aware with non-http response",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      2,
 				HTTPAware:  true,
@@ -59,7 +58,7 @@ func TestRunner_HTTPAwareIntegration(t *testing.T) {
 		},
 		{
 			name: "http-aware disabled",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      2,
 				HTTPAware:  false, // Disabled
@@ -90,7 +89,7 @@ func TestRunner_HTTPAwareIntegration(t *testing.T) {
 }
 
 func TestRunner_HTTPAwareWithAdaptiveScheduler(t *testing.T) {
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:   "adaptive",
 		BaseInterval: 100 * time.Millisecond,
 		MinInterval:  50 * time.Millisecond,  // Required for adaptive
@@ -119,11 +118,11 @@ func TestRunner_HTTPAwareWithAdaptiveScheduler(t *testing.T) {
 func TestRunner_HTTPAwareConfigurationOptions(t *testing.T) {
 	tests := []struct {
 		name   string
-		config *cli.Config
+		config *Config
 	}{
 		{
 			name: "http-aware with custom delays",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:   "count",
 				Times:        1,
 				HTTPAware:    true,
@@ -134,7 +133,7 @@ func TestRunner_HTTPAwareConfigurationOptions(t *testing.T) {
 		},
 		{
 			name: "http-aware with parsing options",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:       "count",
 				Times:            1,
 				HTTPAware:        true,
@@ -146,7 +145,7 @@ func TestRunner_HTTPAwareConfigurationOptions(t *testing.T) {
 		},
 		{
 			name: "http-aware with custom fields",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:       "count",
 				Times:            1,
 				HTTPAware:        true,
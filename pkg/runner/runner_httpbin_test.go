@@ -8,7 +8,6 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/swi/repeater/pkg/cli"
 	httpbinTest "github.com/swi/repeater/pkg/testing"
 )
 
@@ -23,14 +22,14 @@ func TestRunner_HTTPBin_RealWorldIntegration(t *testing.T) {
 	tests := []struct {
 		name           string
 		scenario       httpbinTest.TestScenario
-		config         *cli.Config
+		config         *Config
 		expectedResult func(*ExecutionStats) bool
 		description    string
 	}{
 		{
 			name:     "exponential_backoff_with_503_errors",
 			scenario: scenarios[0], // service_unavailable_503
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:     "exponential",
 				BaseDelay:      500 * time.Millisecond,
 				MaxDelay:       5 * time.Second,
@@ -49,7 +48,7 @@ func TestRunner_HTTPBin_RealWorldIntegration(t *testing.T) {
 		{
 			name:     "rate_limiting_429_with_adaptive",
 			scenario: scenarios[1], // rate_limited_429
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:     "adaptive",
 				BaseInterval:   2 * time.Second,
 				MinInterval:    1 * time.Second,
@@ -66,7 +65,7 @@ func TestRunner_HTTPBin_RealWorldIntegration(t *testing.T) {
 		{
 			name:     "success_pattern_matching_json",
 			scenario: scenarios[4], // json_response_parsing
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:     "count",
 				Times:          2,
 				HTTPAware:      true,
@@ -80,7 +79,7 @@ func TestRunner_HTTPBin_RealWorldIntegration(t *testing.T) {
 		{
 			name:     "delayed_response_timing",
 			scenario: scenarios[5], // delayed_response
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      2,
 				HTTPAware:  true,
@@ -145,7 +144,7 @@ func TestRunner_HTTPBin_HTTPAwareScheduling(t *testing.T) {
 	t.Run("retry_after_header_timing", func(t *testing.T) {
 		// HTTPBin doesn't naturally provide Retry-After headers, so we'll test with 503 status
 		// and verify that our HTTP-aware parsing doesn't break with real responses
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      2,
 			HTTPAware:  true,
@@ -170,7 +169,7 @@ func TestRunner_HTTPBin_HTTPAwareScheduling(t *testing.T) {
 
 	t.Run("json_timing_extraction", func(t *testing.T) {
 		// Test with JSON endpoint to ensure JSON parsing doesn't interfere with timing
-		config := &cli.Config{
+		config := &Config{
 			Subcommand:     "count",
 			Times:          2,
 			HTTPAware:      true,
@@ -214,7 +213,7 @@ func TestRunner_HTTPBin_ErrorHandling(t *testing.T) {
 
 	for _, scenario := range errorScenarios {
 		t.Run(scenario.name, func(t *testing.T) {
-			config := &cli.Config{
+			config := &Config{
 				Subcommand:     scenario.strategy,
 				BaseDelay:      500 * time.Millisecond,
 				Times:          2,
@@ -256,7 +255,7 @@ func TestRunner_HTTPBin_PerformanceValidation(t *testing.T) {
 		}
 
 		// Test with 1-second delay endpoint
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "interval",
 			Every:      2 * time.Second,
 			Times:      3,
@@ -297,7 +296,7 @@ func TestRunner_HTTPBin_ConcurrentSafety(t *testing.T) {
 	helper.ValidatePrerequisites(t)
 
 	t.Run("concurrent_http_aware_execution", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      5,
 			HTTPAware:  true,
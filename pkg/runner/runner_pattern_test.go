@@ -7,19 +7,18 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/swi/repeater/pkg/cli"
 )
 
 func TestRunner_WithPatternMatching(t *testing.T) {
 	tests := []struct {
 		name          string
-		config        *cli.Config
+		config        *Config
 		expectedStats func(*ExecutionStats) bool
 		description   string
 	}{
 		{
 			name: "success pattern should override exit code",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:     "interval",
 				Every:          100 * time.Millisecond,
 				Times:          3,
@@ -33,7 +32,7 @@ func TestRunner_WithPatternMatching(t *testing.T) {
 		},
 		{
 			name: "failure pattern should override exit code",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:     "interval",
 				Every:          100 * time.Millisecond,
 				Times:          3,
@@ -46,7 +45,7 @@ func TestRunner_WithPatternMatching(t *testing.T) {
 		},
 		{
 			name: "case insensitive pattern matching",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:      "count",
 				Times:           2,
 				SuccessPattern:  "SUCCESS",
@@ -60,7 +59,7 @@ func TestRunner_WithPatternMatching(t *testing.T) {
 		},
 		{
 			name: "pattern precedence - failure overrides success",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand:     "count",
 				Times:          2,
 				SuccessPattern: "completed",
@@ -74,7 +73,7 @@ func TestRunner_WithPatternMatching(t *testing.T) {
 		},
 		{
 			name: "no patterns should use exit code",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      2,
 				Command:    []string{"echo", "test"},
@@ -104,7 +103,7 @@ func TestRunner_WithPatternMatching(t *testing.T) {
 }
 
 func TestRunner_PatternMatchingWithAdaptiveScheduler(t *testing.T) {
-	config := &cli.Config{
+	config := &Config{
 		Subcommand:     "adaptive",
 		BaseInterval:   100 * time.Millisecond,
 		MinInterval:    50 * time.Millisecond,
@@ -128,3 +127,171 @@ func TestRunner_PatternMatchingWithAdaptiveScheduler(t *testing.T) {
 	assert.Equal(t, 5, stats.TotalExecutions)
 	assert.Equal(t, 5, stats.SuccessfulExecutions+stats.FailedExecutions)
 }
+
+func TestRunner_HTTPStatusCodes(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		successful int
+		failed     int
+	}{
+		{name: "success code", output: `HTTP/1.1 204 No Content\r\n\r\n`, successful: 3},
+		{name: "retry code", output: `HTTP/1.1 503 Service Unavailable\r\n\r\n`, failed: 3},
+		{name: "unlisted code", output: `HTTP/1.1 404 Not Found\r\n\r\n`, failed: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{
+				Subcommand:       "count",
+				Times:            3,
+				HTTPSuccessCodes: "2xx",
+				HTTPRetryCodes:   "5xx",
+				HTTPFatalCodes:   "401",
+				Command:          []string{"printf", tt.output},
+			}
+
+			runner, err := NewRunner(config)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			stats, err := runner.Run(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, tt.successful, stats.SuccessfulExecutions)
+			assert.Equal(t, tt.failed, stats.FailedExecutions)
+		})
+	}
+}
+
+func TestRunner_HTTPFatalCodeAbortsRun(t *testing.T) {
+	config := &Config{
+		Subcommand:     "count",
+		Times:          5,
+		HTTPFatalCodes: "401,403",
+		Command:        []string{"printf", `HTTP/1.1 403 Forbidden\r\n\r\n`},
+	}
+
+	runner, err := NewRunner(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, err := runner.Run(ctx)
+	require.ErrorIs(t, err, ErrAborted)
+	assert.Contains(t, err.Error(), "HTTP 403 matched fatal codes")
+	assert.Equal(t, 1, stats.TotalExecutions)
+	assert.Equal(t, 1, stats.FailedExecutions)
+}
+
+func TestRunner_FatalPatternAbortsRun(t *testing.T) {
+	config := &Config{
+		Subcommand:     "count",
+		Times:          5,
+		FailurePattern: "stderr:WARN",
+		FatalPatterns:  []string{"stderr:panic:"},
+		Command:        []string{"sh", "-c", "echo started; echo 'WARN slow' >&2; echo 'panic: nil map' >&2"},
+	}
+
+	runner, err := NewRunner(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, err := runner.Run(ctx)
+	require.ErrorIs(t, err, ErrAborted)
+	assert.Contains(t, err.Error(), `fatal pattern "stderr:panic:" matched`)
+	assert.Equal(t, 1, stats.TotalExecutions)
+	assert.Equal(t, []string{"stderr:panic:", "stderr:WARN"}, stats.Executions[0].MatchedPatterns)
+}
+
+func TestRunner_ExitCodeMapping(t *testing.T) {
+	config := &Config{
+		Subcommand:   "count",
+		Times:        3,
+		SuccessCodes: "0,1",
+		Command:      []string{"grep", "-q", "ready", "/dev/null"},
+	}
+
+	runner, err := NewRunner(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, err := runner.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.SuccessfulExecutions)
+	assert.Equal(t, map[int]int{1: 3}, stats.ExitCodes)
+	assert.Equal(t, 0, stats.Executions[0].ExitCode)
+	assert.Equal(t, 1, stats.Executions[0].CommandExitCode)
+}
+
+func TestRunner_RetryStopsOnAbortCode(t *testing.T) {
+	config := &Config{
+		Subcommand: "exponential",
+		BaseDelay:  time.Millisecond,
+		MaxRetries: 5,
+		RetryCodes: "75",
+		AbortCodes: "64-74",
+		Command:    []string{"sh", "-c", "exit 70"},
+	}
+
+	runner, err := NewRunner(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, err := runner.Run(ctx)
+	require.ErrorIs(t, err, ErrAborted)
+	assert.Contains(t, err.Error(), "exit code 70 matched abort codes")
+	assert.Equal(t, 1, stats.TotalExecutions)
+	assert.Equal(t, map[int]int{70: 1}, stats.ExitCodes)
+}
+
+func TestRunner_FatalPatternStopsRetryCodes(t *testing.T) {
+	config := &Config{
+		Subcommand:    "exponential",
+		BaseDelay:     time.Millisecond,
+		MaxRetries:    5,
+		RetryCodes:    "75",
+		FatalPatterns: []string{"license expired"},
+		Command:       []string{"sh", "-c", "echo 'license expired'; exit 75"},
+	}
+
+	runner, err := NewRunner(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, err := runner.Run(ctx)
+	require.ErrorIs(t, err, ErrAborted)
+	assert.Contains(t, err.Error(), `fatal pattern "license expired" matched`)
+	assert.Equal(t, 1, stats.TotalExecutions)
+	assert.Equal(t, []string{"license expired"}, stats.Executions[0].MatchedPatterns)
+}
+
+func TestRunner_RetryCodesLeaveOtherFailuresRunning(t *testing.T) {
+	config := &Config{
+		Subcommand: "count",
+		Times:      3,
+		RetryCodes: "75",
+		Command:    []string{"sh", "-c", "exit 1"},
+	}
+
+	runner, err := NewRunner(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, err := runner.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.TotalExecutions)
+	assert.Equal(t, 3, stats.FailedExecutions)
+}
@@ -0,0 +1,126 @@
+package runner
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/swi/repeater/pkg/scheduler"
+)
+
+func TestRunner_HTTPProbe(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Every other request is throttled
+		if requests.Add(1)%2 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status": "ok"}`))
+	}))
+	defer server.Close()
+
+	config := &Config{
+		Subcommand: "count",
+		Times:      4,
+		Quiet:      true,
+		HTTPMethod: "GET",
+		HTTPURL:    server.URL,
+	}
+
+	r, err := NewRunner(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, err := r.Run(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(4), requests.Load())
+	assert.Equal(t, 2, stats.SuccessfulExecutions)
+	assert.Equal(t, 2, stats.FailedExecutions)
+	assert.Contains(t, stats.Executions[0].Stdout, "HTTP/1.1 200 OK")
+	assert.Contains(t, stats.Executions[0].Stdout, `{"status": "ok"}`)
+}
+
+func TestRunner_VirtualClock_HTTPProbeRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := scheduler.NewVirtualClock(start)
+
+	config := &Config{
+		Subcommand:       "interval",
+		Every:            time.Minute,
+		Times:            2,
+		Quiet:            true,
+		HTTPAware:        true,
+		HTTPParseHeaders: true,
+		HTTPMethod:       "GET",
+		HTTPURL:          server.URL,
+	}
+	r, err := NewRunnerWithClock(config, clock)
+	require.NoError(t, err)
+
+	stats := runOnVirtualClock(t, r, clock)
+
+	// The probe's response reaches the HTTP-aware scheduler without a command
+	require.Equal(t, 2, stats.TotalExecutions)
+	gap := stats.Executions[1].StartTime.Sub(stats.Executions[0].EndTime)
+	assert.GreaterOrEqual(t, gap, 10*time.Minute)
+}
+
+func TestRunner_TCPProbe(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = listener.Close() }()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("+OK ready\r\n"))
+			_ = conn.Close()
+		}
+	}()
+
+	config := &Config{
+		Subcommand:     "count",
+		Times:          3,
+		Quiet:          true,
+		TCPAddress:     listener.Addr().String(),
+		ProbeExpect:    "ready",
+		SuccessPattern: `^\+OK`,
+	}
+
+	r, err := NewRunner(config)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stats, err := r.Run(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, stats.SuccessfulExecutions)
+	for _, execution := range stats.Executions {
+		assert.Contains(t, execution.Stdout, "+OK ready")
+		// The connect latency is the execution duration
+		assert.Greater(t, execution.Duration, time.Duration(0))
+		assert.Less(t, execution.Duration, time.Second)
+	}
+}
@@ -7,13 +7,12 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"github.com/swi/repeater/pkg/cli"
 )
 
 func TestRunner_EndToEndExecution(t *testing.T) {
 	tests := []struct {
 		name           string
-		config         *cli.Config
+		config         *Config
 		expectedRuns   int
 		maxDuration    time.Duration
 		expectSuccess  bool
@@ -21,7 +20,7 @@ func TestRunner_EndToEndExecution(t *testing.T) {
 	}{
 		{
 			name: "interval execution with times limit",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "interval",
 				Every:      100 * time.Millisecond,
 				Times:      3,
@@ -39,7 +38,7 @@ func TestRunner_EndToEndExecution(t *testing.T) {
 		},
 		{
 			name: "interval execution with duration limit",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "interval",
 				Every:      50 * time.Millisecond,
 				For:        200 * time.Millisecond,
@@ -56,7 +55,7 @@ func TestRunner_EndToEndExecution(t *testing.T) {
 		},
 		{
 			name: "count execution",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      5,
 				Every:      10 * time.Millisecond,
@@ -73,7 +72,7 @@ func TestRunner_EndToEndExecution(t *testing.T) {
 		},
 		{
 			name: "duration execution",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "duration",
 				For:        150 * time.Millisecond,
 				Every:      30 * time.Millisecond,
@@ -89,7 +88,7 @@ func TestRunner_EndToEndExecution(t *testing.T) {
 		},
 		{
 			name: "execution with command failures",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Times:      3,
 				Command:    []string{"sh", "-c", "exit 1"}, // Always fails
@@ -132,14 +131,14 @@ func TestRunner_EndToEndExecution(t *testing.T) {
 func TestRunner_StopConditions(t *testing.T) {
 	tests := []struct {
 		name        string
-		config      *cli.Config
+		config      *Config
 		stopAfter   time.Duration
 		expectStop  bool
 		description string
 	}{
 		{
 			name: "times limit stops execution",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "interval",
 				Every:      10 * time.Millisecond,
 				Times:      2,
@@ -151,7 +150,7 @@ func TestRunner_StopConditions(t *testing.T) {
 		},
 		{
 			name: "duration limit stops execution",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "interval",
 				Every:      10 * time.Millisecond,
 				For:        50 * time.Millisecond,
@@ -163,7 +162,7 @@ func TestRunner_StopConditions(t *testing.T) {
 		},
 		{
 			name: "both limits - times reached first",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "interval",
 				Every:      10 * time.Millisecond,
 				Times:      2,
@@ -176,7 +175,7 @@ func TestRunner_StopConditions(t *testing.T) {
 		},
 		{
 			name: "both limits - duration reached first",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "interval",
 				Every:      10 * time.Millisecond,
 				Times:      100,                   // Much more than can run in 50ms
@@ -214,7 +213,7 @@ func TestRunner_StopConditions(t *testing.T) {
 
 func TestRunner_SignalHandling(t *testing.T) {
 	t.Run("graceful shutdown on SIGINT", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "interval",
 			Every:      50 * time.Millisecond,
 			Command:    []string{"echo", "signal-test"},
@@ -260,7 +259,7 @@ func TestRunner_SignalHandling(t *testing.T) {
 
 func TestRunner_ExecutionStatistics(t *testing.T) {
 	t.Run("statistics collection", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      3,
 			Every:      20 * time.Millisecond,
@@ -302,7 +301,7 @@ func TestRunner_ExecutionStatistics(t *testing.T) {
 	})
 
 	t.Run("statistics with mixed success/failure", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      4,
 			Command:    []string{"sh", "-c", "if [ $((RANDOM % 2)) -eq 0 ]; then echo 'success'; else echo 'failure' >&2; exit 1; fi"},
@@ -336,7 +335,7 @@ func TestRunner_ExecutionStatistics(t *testing.T) {
 func TestRunner_ConfigurationValidation(t *testing.T) {
 	tests := []struct {
 		name    string
-		config  *cli.Config
+		config  *Config
 		wantErr bool
 		errMsg  string
 	}{
@@ -348,7 +347,7 @@ func TestRunner_ConfigurationValidation(t *testing.T) {
 		},
 		{
 			name: "empty command",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "interval",
 				Every:      1 * time.Second,
 				Command:    []string{},
@@ -358,7 +357,7 @@ func TestRunner_ConfigurationValidation(t *testing.T) {
 		},
 		{
 			name: "invalid subcommand",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "invalid",
 				Command:    []string{"echo", "test"},
 			},
@@ -367,7 +366,7 @@ func TestRunner_ConfigurationValidation(t *testing.T) {
 		},
 		{
 			name: "interval without every",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "interval",
 				Command:    []string{"echo", "test"},
 			},
@@ -376,7 +375,7 @@ func TestRunner_ConfigurationValidation(t *testing.T) {
 		},
 		{
 			name: "count without times",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "count",
 				Command:    []string{"echo", "test"},
 			},
@@ -385,7 +384,7 @@ func TestRunner_ConfigurationValidation(t *testing.T) {
 		},
 		{
 			name: "duration without for",
-			config: &cli.Config{
+			config: &Config{
 				Subcommand: "duration",
 				Command:    []string{"echo", "test"},
 			},
@@ -412,7 +411,7 @@ func TestRunner_ConfigurationValidation(t *testing.T) {
 
 func TestRunner_StreamingIntegration(t *testing.T) {
 	t.Run("runner with streaming enabled", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      2,
 			Command:    []string{"echo", "streaming test"},
@@ -435,7 +434,7 @@ func TestRunner_StreamingIntegration(t *testing.T) {
 	})
 
 	t.Run("runner with quiet mode", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      2,
 			Command:    []string{"echo", "quiet test"},
@@ -458,7 +457,7 @@ func TestRunner_StreamingIntegration(t *testing.T) {
 	})
 
 	t.Run("runner with verbose mode", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      2,
 			Command:    []string{"echo", "verbose test"},
@@ -481,7 +480,7 @@ func TestRunner_StreamingIntegration(t *testing.T) {
 	})
 
 	t.Run("runner with output prefix", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand:   "count",
 			Times:        2,
 			Command:      []string{"echo", "prefix test"},
@@ -508,7 +507,7 @@ func TestRunner_StreamingIntegration(t *testing.T) {
 func TestRunner_UnixPipelineOutput(t *testing.T) {
 	t.Run("default mode should only output command results", func(t *testing.T) {
 		// This test should fail initially - we expect only command output
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      2,
 			Command:    []string{"echo", "hello"},
@@ -532,7 +531,7 @@ func TestRunner_UnixPipelineOutput(t *testing.T) {
 	})
 
 	t.Run("quiet mode should suppress all output except tool errors", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      2,
 			Command:    []string{"echo", "should be quiet"},
@@ -556,7 +555,7 @@ func TestRunner_UnixPipelineOutput(t *testing.T) {
 	})
 
 	t.Run("verbose mode should show command tracing and statistics", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      2,
 			Command:    []string{"echo", "verbose test"},
@@ -584,7 +583,7 @@ func TestRunner_UnixPipelineOutput(t *testing.T) {
 
 func TestRunner_StatsOnlyMode(t *testing.T) {
 	t.Run("stats-only mode should suppress output and show statistics", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      3,
 			Command:    []string{"echo", "stats-only test"},
@@ -608,7 +607,7 @@ func TestRunner_StatsOnlyMode(t *testing.T) {
 	})
 
 	t.Run("stats-only mode should work with command failures", func(t *testing.T) {
-		config := &cli.Config{
+		config := &Config{
 			Subcommand: "count",
 			Times:      2,
 			Command:    []string{"false"},
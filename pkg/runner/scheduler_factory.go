@@ -6,22 +6,21 @@ import (
 	"time"
 
 	"github.com/swi/repeater/pkg/adaptive"
-	"github.com/swi/repeater/pkg/cli"
 	"github.com/swi/repeater/pkg/httpaware"
 	"github.com/swi/repeater/pkg/interfaces"
 	"github.com/swi/repeater/pkg/ratelimit"
+	"github.com/swi/repeater/pkg/retry"
 	"github.com/swi/repeater/pkg/scheduler"
-	"github.com/swi/repeater/pkg/strategies"
 )
 
 // SchedulerFactory handles creation of all scheduler types
 type SchedulerFactory struct {
-	config             *cli.Config
+	config             *Config
 	httpAwareScheduler httpaware.HTTPAwareScheduler
 }
 
 // NewSchedulerFactory creates a new scheduler factory
-func NewSchedulerFactory(config *cli.Config) *SchedulerFactory {
+func NewSchedulerFactory(config *Config) *SchedulerFactory {
 	return &SchedulerFactory{
 		config: config,
 	}
@@ -184,23 +183,13 @@ func (f *SchedulerFactory) createLoadAdaptiveScheduler() (interfaces.Scheduler,
 
 // createStrategyScheduler creates a strategy-based scheduler for mathematical retry patterns
 func (f *SchedulerFactory) createStrategyScheduler(strategyName string) (interfaces.Scheduler, error) {
-	var strategy strategies.Strategy
-	var err error
-
-	// Get configuration values
 	baseDelay := f.getBaseDelay()
 	maxDelay := f.getMaxDelay()
 	maxAttempts := int(f.config.Times)
 	if maxAttempts <= 0 {
 		maxAttempts = 3 // Default attempts
 	}
-
-	// Create strategy config
-	strategyConfig := &strategies.StrategyConfig{
-		BaseDelay:   baseDelay,
-		MaxDelay:    maxDelay,
-		MaxAttempts: maxAttempts,
-	}
+	options := []retry.Option{retry.Attempts(maxAttempts)}
 
 	// Create the appropriate strategy
 	switch strategyName {
@@ -209,45 +198,42 @@ func (f *SchedulerFactory) createStrategyScheduler(strategyName string) (interfa
 		if multiplier <= 1.0 {
 			multiplier = 2.0 // Default exponential multiplier
 		}
-		strategyConfig.Multiplier = multiplier
-		strategy = strategies.NewExponentialStrategy(baseDelay, multiplier, maxDelay)
+		options = append(options, retry.Exponential(baseDelay, multiplier, maxDelay))
 
 	case "fibonacci":
-		strategy = strategies.NewFibonacciStrategy(baseDelay, maxDelay)
+		options = append(options, retry.Fibonacci(baseDelay, maxDelay))
 
 	case "linear":
 		increment := f.getIncrement()
 		if increment == 0 {
 			increment = baseDelay // Default increment
 		}
-		strategyConfig.Increment = increment
-		strategy = strategies.NewLinearStrategy(increment, maxDelay)
+		options = append(options, retry.Linear(increment, maxDelay))
 
 	case "polynomial":
 		exponent := f.getExponent()
 		if exponent <= 1.0 {
 			exponent = 2.0 // Default quadratic growth
 		}
-		strategyConfig.Exponent = exponent
-		strategy = strategies.NewPolynomialStrategy(baseDelay, exponent, maxDelay)
+		options = append(options, retry.Polynomial(baseDelay, exponent, maxDelay))
 
 	case "decorrelated-jitter":
 		multiplier := f.getMultiplier()
 		if multiplier <= 1.0 {
 			multiplier = 3.0 // Default decorrelated jitter multiplier
 		}
-		strategyConfig.Multiplier = multiplier
-		strategy = strategies.NewDecorrelatedJitterStrategy(baseDelay, multiplier, maxDelay)
+		options = append(options, retry.DecorrelatedJitter(baseDelay, multiplier, maxDelay, f.config.Seed))
 
 	default:
 		return nil, fmt.Errorf("unknown strategy: %s", strategyName)
 	}
 
+	policy, err := retry.New(options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create %s strategy: %w", strategyName, err)
 	}
 
-	return scheduler.NewStrategyScheduler(strategy, strategyConfig)
+	return scheduler.NewRetryScheduler(policy), nil
 }
 
 // Configuration helper methods
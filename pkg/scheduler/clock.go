@@ -0,0 +1,254 @@
+package scheduler
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/swi/repeater/pkg/interfaces"
+)
+
+// Use centralized Clock interfaces from pkg/interfaces
+type (
+	Clock  = interfaces.Clock
+	Timer  = interfaces.Timer
+	Ticker = interfaces.Ticker
+)
+
+// RealClock implements Clock using the wall clock and the time package
+type RealClock struct{}
+
+// NewRealClock returns a Clock backed by the wall clock
+func NewRealClock() Clock {
+	return RealClock{}
+}
+
+// Now returns the current wall clock time
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse and then sends the current time
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// NewTimer creates a wall clock timer
+func (RealClock) NewTimer(d time.Duration) Timer {
+	return &realTimer{timer: time.NewTimer(d)}
+}
+
+// NewTicker creates a wall clock ticker
+func (RealClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{ticker: time.NewTicker(d)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t *realTimer) C() <-chan time.Time        { return t.timer.C }
+func (t *realTimer) Stop() bool                 { return t.timer.Stop() }
+func (t *realTimer) Reset(d time.Duration) bool { return t.timer.Reset(d) }
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t *realTicker) C() <-chan time.Time { return t.ticker.C }
+func (t *realTicker) Stop()               { t.ticker.Stop() }
+
+// VirtualClock is a manually advanced Clock. Time only moves when Advance or
+// Set is called, and every timer or ticker whose deadline is crossed fires in
+// deadline order with the clock reading its deadline. This lets schedulers be
+// stepped deterministically in tests and dry-run previews.
+type VirtualClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*virtualWaiter
+}
+
+// virtualWaiter is a pending timer or ticker on a VirtualClock
+type virtualWaiter struct {
+	clock    *VirtualClock
+	deadline time.Time
+	period   time.Duration // non-zero for tickers
+	ch       chan time.Time
+}
+
+// NewVirtualClock creates a virtual clock reading the given start time
+func NewVirtualClock(start time.Time) *VirtualClock {
+	c := &VirtualClock{now: start}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the current virtual time
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the virtual time once d has elapsed
+func (c *VirtualClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// NewTimer creates a timer that fires once the clock has advanced by d
+func (c *VirtualClock) NewTimer(d time.Duration) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &virtualWaiter{clock: c, ch: make(chan time.Time, 1)}
+	c.schedule(w, d)
+	return w
+}
+
+// NewTicker creates a ticker that fires every d of virtual time
+func (c *VirtualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for VirtualClock.NewTicker")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &virtualWaiter{clock: c, period: d, ch: make(chan time.Time, 1)}
+	c.schedule(w, d)
+	return &virtualTicker{waiter: w}
+}
+
+// Advance moves the clock forward by d, firing any timers that come due
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t, firing any timers with deadlines at or before t.
+// Setting a time earlier than the current reading moves the clock backwards
+// without firing anything.
+func (c *VirtualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.waiters) > 0 && !c.waiters[0].deadline.After(t) {
+		w := c.waiters[0]
+		c.waiters = c.waiters[1:]
+		c.now = w.deadline
+
+		// Like the time package, drop ticks nobody has received yet
+		select {
+		case w.ch <- w.deadline:
+		default:
+		}
+
+		if w.period > 0 {
+			c.insert(w, w.deadline.Add(w.period))
+		}
+	}
+
+	c.now = t
+}
+
+// AdvanceToNext moves the clock to the earliest pending deadline and fires
+// it. It reports false when no timers or tickers are pending.
+func (c *VirtualClock) AdvanceToNext() bool {
+	c.mu.Lock()
+	if len(c.waiters) == 0 {
+		c.mu.Unlock()
+		return false
+	}
+	next := c.waiters[0].deadline
+	c.mu.Unlock()
+
+	c.Set(next)
+	return true
+}
+
+// PendingTimers returns the number of active timers and tickers
+func (c *VirtualClock) PendingTimers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil waits until at least n timers or tickers are pending. Tests use
+// it to make sure a scheduler goroutine is waiting before advancing time.
+func (c *VirtualClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
+
+// schedule registers w to fire after d; callers must hold c.mu
+func (c *VirtualClock) schedule(w *virtualWaiter, d time.Duration) {
+	if d <= 0 {
+		// Due immediately, matching time.NewTimer(0)
+		select {
+		case w.ch <- c.now:
+		default:
+		}
+		if w.period == 0 {
+			return
+		}
+		d = w.period
+	}
+	c.insert(w, c.now.Add(d))
+}
+
+// insert adds w in deadline order; callers must hold c.mu
+func (c *VirtualClock) insert(w *virtualWaiter, deadline time.Time) {
+	w.deadline = deadline
+	i := sort.Search(len(c.waiters), func(i int) bool {
+		return c.waiters[i].deadline.After(deadline)
+	})
+	c.waiters = append(c.waiters, nil)
+	copy(c.waiters[i+1:], c.waiters[i:])
+	c.waiters[i] = w
+	c.cond.Broadcast()
+}
+
+// remove deletes w from the pending list and reports whether it was pending;
+// callers must hold c.mu
+func (c *VirtualClock) remove(w *virtualWaiter) bool {
+	for i, pending := range c.waiters {
+		if pending == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// C returns the channel on which the timer fires
+func (w *virtualWaiter) C() <-chan time.Time {
+	return w.ch
+}
+
+// Stop cancels the timer and reports whether it was pending
+func (w *virtualWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.remove(w)
+}
+
+// Reset reschedules the timer to fire d after the current virtual time
+func (w *virtualWaiter) Reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+
+	active := w.clock.remove(w)
+	w.clock.schedule(w, d)
+	return active
+}
+
+// virtualTicker adapts a periodic virtualWaiter to the Ticker interface
+type virtualTicker struct {
+	waiter *virtualWaiter
+}
+
+func (t *virtualTicker) C() <-chan time.Time { return t.waiter.ch }
+func (t *virtualTicker) Stop()               { t.waiter.Stop() }
@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var clockEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestVirtualClock_NowOnlyMovesWhenAdvanced(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	assert.Equal(t, clockEpoch, clock.Now())
+
+	clock.Advance(90 * time.Second)
+	assert.Equal(t, clockEpoch.Add(90*time.Second), clock.Now())
+
+	clock.Set(clockEpoch.Add(time.Hour))
+	assert.Equal(t, clockEpoch.Add(time.Hour), clock.Now())
+}
+
+func TestVirtualClock_TimerFiresAtDeadline(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	timer := clock.NewTimer(10 * time.Second)
+
+	clock.Advance(9 * time.Second)
+	select {
+	case <-timer.C():
+		t.Fatal("timer fired before its deadline")
+	default:
+	}
+
+	clock.Advance(time.Second)
+	select {
+	case fired := <-timer.C():
+		assert.Equal(t, clockEpoch.Add(10*time.Second), fired)
+	default:
+		t.Fatal("timer did not fire at its deadline")
+	}
+	assert.Equal(t, 0, clock.PendingTimers())
+}
+
+func TestVirtualClock_TimerStopAndReset(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	timer := clock.NewTimer(time.Second)
+
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+	clock.Advance(time.Minute)
+	assert.Empty(t, timer.C())
+
+	assert.False(t, timer.Reset(5*time.Second))
+	clock.Advance(5 * time.Second)
+	require.Len(t, timer.C(), 1)
+	assert.Equal(t, clockEpoch.Add(time.Minute+5*time.Second), <-timer.C())
+}
+
+func TestVirtualClock_TickerFiresEachPeriod(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	ticker := clock.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for i := 1; i <= 3; i++ {
+		assert.True(t, clock.AdvanceToNext())
+		assert.Equal(t, clockEpoch.Add(time.Duration(i)*time.Second), <-ticker.C())
+	}
+
+	ticker.Stop()
+	assert.False(t, clock.AdvanceToNext())
+}
+
+func TestVirtualClock_SetFiresInDeadlineOrder(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	late := clock.NewTimer(3 * time.Second)
+	early := clock.NewTimer(1 * time.Second)
+
+	clock.Set(clockEpoch.Add(10 * time.Second))
+
+	assert.Equal(t, clockEpoch.Add(1*time.Second), <-early.C())
+	assert.Equal(t, clockEpoch.Add(3*time.Second), <-late.C())
+	assert.Equal(t, clockEpoch.Add(10*time.Second), clock.Now())
+}
+
+func TestVirtualClock_BlockUntilWaitsForTimers(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	fired := make(chan time.Time, 1)
+
+	go func() {
+		fired <- <-clock.After(time.Minute)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+
+	select {
+	case got := <-fired:
+		assert.Equal(t, clockEpoch.Add(time.Minute), got)
+	case <-time.After(time.Second):
+		t.Fatal("goroutine waiting on virtual clock was not released")
+	}
+}
+
+func TestRealClock_Now(t *testing.T) {
+	clock := NewRealClock()
+	before := time.Now()
+	now := clock.Now()
+	assert.False(t, now.Before(before))
+
+	timer := clock.NewTimer(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Fatal("real timer did not fire")
+	}
+}
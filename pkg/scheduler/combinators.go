@@ -0,0 +1,327 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter is the admission check consulted by Throttle. The rate limiters in
+// pkg/ratelimit satisfy it.
+type Limiter interface {
+	Allow() bool
+	NextAllowedTime() time.Time
+}
+
+// combinator holds the plumbing shared by the scheduler combinators: a
+// forwarding goroutine started on the first Next call, an unbuffered output
+// channel, and a Stop that also stops every child scheduler.
+type combinator struct {
+	children  []Scheduler
+	run       func()
+	out       chan time.Time
+	pulls     chan struct{} // signalled by Next, see pull
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+func newCombinator(children ...Scheduler) *combinator {
+	return &combinator{
+		children: children,
+		out:      make(chan time.Time), // unbuffered, so a delivered tick has been taken
+		pulls:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+}
+
+// Next returns a channel that delivers the combined schedule's ticks. The
+// consumer calls it once per tick, once it is ready for the next one.
+func (c *combinator) Next() <-chan time.Time {
+	c.startOnce.Do(func() {
+		go c.run()
+	})
+
+	select {
+	case c.pulls <- struct{}{}:
+	default:
+	}
+	return c.out
+}
+
+// Stop stops the combinator and all of its children
+func (c *combinator) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.done)
+		for _, child := range c.children {
+			child.Stop()
+		}
+	})
+}
+
+// Unwrap returns the schedulers this combinator is built from
+func (c *combinator) Unwrap() []Scheduler {
+	return c.children
+}
+
+// emit delivers t downstream and reports false once the combinator is stopped
+func (c *combinator) emit(t time.Time) bool {
+	select {
+	case c.out <- t:
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// pull waits until the consumer asks for a tick and then for the next tick of
+// s, and reports false once the combinator is stopped. A child is not asked
+// for a tick while the previous execution is still running, so a
+// StrategyScheduler times its delay from the end of that execution.
+func (c *combinator) pull(s Scheduler) (time.Time, bool) {
+	select {
+	case <-c.pulls:
+	case <-c.done:
+		return time.Time{}, false
+	}
+	return c.receive(s)
+}
+
+// receive waits for the next tick of s and reports false once the combinator
+// is stopped. Next is called once per tick, which is what StrategyScheduler
+// expects from its consumer.
+func (c *combinator) receive(s Scheduler) (time.Time, bool) {
+	select {
+	case t := <-s.Next():
+		return t, true
+	case <-c.done:
+		return time.Time{}, false
+	}
+}
+
+// wait blocks for d on the clock and reports false once the combinator is stopped
+func (c *combinator) wait(clock Clock, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := clock.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return true
+	case <-c.done:
+		return false
+	}
+}
+
+// Union fires whenever any of the given schedulers fires, e.g. "the earlier of
+// adaptive and every 5m". A tick for the same time as the previous one, as
+// when two schedulers fire together, fires once; other ticks fire in the order
+// they arrive, even if dated earlier.
+func Union(schedulers ...Scheduler) Scheduler {
+	c := newCombinator(schedulers...)
+	c.run = func() {
+		arrivals := make(chan time.Time)
+		for _, s := range schedulers {
+			go func(s Scheduler) {
+				for {
+					t, ok := c.receive(s)
+					if !ok {
+						return
+					}
+					select {
+					case arrivals <- t:
+					case <-c.done:
+						return
+					}
+				}
+			}(s)
+		}
+
+		var last time.Time
+		for {
+			select {
+			case t := <-arrivals:
+				if t.Equal(last) {
+					continue
+				}
+				if !c.emit(t) {
+					return
+				}
+				last = t
+			case <-c.done:
+				return
+			}
+		}
+	}
+	return c
+}
+
+// Intersect fires only once every given scheduler has fired since the previous
+// tick, at the time the last of them fired. Repeated ticks from one scheduler
+// while waiting for the others are coalesced.
+func Intersect(schedulers ...Scheduler) Scheduler {
+	c := newCombinator(schedulers...)
+	c.run = func() {
+		type arrival struct {
+			index int
+			t     time.Time
+		}
+		arrivals := make(chan arrival)
+
+		for i, s := range schedulers {
+			go func(i int, s Scheduler) {
+				for {
+					t, ok := c.receive(s)
+					if !ok {
+						return
+					}
+					select {
+					case arrivals <- arrival{index: i, t: t}:
+					case <-c.done:
+						return
+					}
+				}
+			}(i, s)
+		}
+
+		ready := make([]bool, len(schedulers))
+		pending := len(schedulers)
+		var latest time.Time
+		for {
+			select {
+			case a := <-arrivals:
+				if !ready[a.index] {
+					ready[a.index] = true
+					pending--
+				}
+				if a.t.After(latest) {
+					latest = a.t
+				}
+				if pending > 0 {
+					continue
+				}
+				if !c.emit(latest) {
+					return
+				}
+				for i := range ready {
+					ready[i] = false
+				}
+				pending = len(schedulers)
+				latest = time.Time{}
+			case <-c.done:
+				return
+			}
+		}
+	}
+	return c
+}
+
+// Throttle postpones ticks of s until the limiter admits them, e.g. "fire on
+// this cron, but never faster than 100/1h". The limiter is consulted only once
+// the consumer asks for a tick, so each admission is an execution.
+func Throttle(s Scheduler, limiter Limiter) Scheduler {
+	return ThrottleWithClock(s, limiter, NewRealClock())
+}
+
+// ThrottleWithClock is Throttle waiting on the given clock; it should be the
+// clock the limiter reads
+func ThrottleWithClock(s Scheduler, limiter Limiter, clock Clock) Scheduler {
+	c := newCombinator(s)
+	c.run = func() {
+		for {
+			if _, ok := c.pull(s); !ok {
+				return
+			}
+
+			for !limiter.Allow() {
+				wait := limiter.NextAllowedTime().Sub(clock.Now())
+				if wait <= 0 {
+					// Guard against a limiter that denies at its own predicted time
+					wait = time.Millisecond
+				}
+				if !c.wait(clock, wait) {
+					return
+				}
+			}
+
+			if !c.emit(clock.Now()) {
+				return
+			}
+		}
+	}
+	return c
+}
+
+// Delay shifts every tick of s later by d
+func Delay(s Scheduler, d time.Duration) Scheduler {
+	return DelayWithClock(s, d, NewRealClock())
+}
+
+// DelayWithClock is Delay waiting on the given clock
+func DelayWithClock(s Scheduler, d time.Duration, clock Clock) Scheduler {
+	c := newCombinator(s)
+	c.run = func() {
+		for {
+			t, ok := c.pull(s)
+			if !ok {
+				return
+			}
+
+			// Measure from the tick itself so consecutive delays don't accumulate
+			if !c.wait(clock, t.Add(d).Sub(clock.Now())) {
+				return
+			}
+
+			if !c.emit(clock.Now()) {
+				return
+			}
+		}
+	}
+	return c
+}
+
+// Limit passes through the first n ticks of s and then stops s. The returned
+// scheduler never fires again afterwards.
+func Limit(s Scheduler, n int) Scheduler {
+	c := newCombinator(s)
+	c.run = func() {
+		for i := 0; i < n; i++ {
+			t, ok := c.pull(s)
+			if !ok || !c.emit(t) {
+				return
+			}
+		}
+		s.Stop()
+	}
+	return c
+}
+
+// StartAt holds s back until at: s is first consulted then, so lazily started
+// schedulers begin their schedule at that time, and ticks s dated earlier are
+// dropped
+func StartAt(s Scheduler, at time.Time) Scheduler {
+	return StartAtWithClock(s, at, NewRealClock())
+}
+
+// StartAtWithClock is StartAt waiting on the given clock
+func StartAtWithClock(s Scheduler, at time.Time, clock Clock) Scheduler {
+	c := newCombinator(s)
+	c.run = func() {
+		if !c.wait(clock, at.Sub(clock.Now())) {
+			return
+		}
+
+		for {
+			t, ok := c.pull(s)
+			// Ticks dated before the start don't count as the consumer's tick
+			for ok && t.Before(at) {
+				t, ok = c.receive(s)
+			}
+			if !ok || !c.emit(t) {
+				return
+			}
+		}
+	}
+	return c
+}
@@ -0,0 +1,266 @@
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/swi/repeater/pkg/strategies"
+)
+
+// manualScheduler fires only when a test sends on its channel
+type manualScheduler struct {
+	ticks   chan time.Time
+	mu      sync.Mutex
+	stopped bool
+}
+
+func newManualScheduler() *manualScheduler {
+	return &manualScheduler{ticks: make(chan time.Time)}
+}
+
+func (m *manualScheduler) Next() <-chan time.Time { return m.ticks }
+
+func (m *manualScheduler) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+}
+
+func (m *manualScheduler) isStopped() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stopped
+}
+
+// spacingLimiter admits one request per interval of the given clock
+type spacingLimiter struct {
+	clock    Clock
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *spacingLimiter) Allow() bool {
+	now := l.clock.Now()
+	if now.Before(l.next) {
+		return false
+	}
+	l.next = now.Add(l.interval)
+	return true
+}
+
+func (l *spacingLimiter) NextAllowedTime() time.Time {
+	return l.next
+}
+
+func receiveTick(t *testing.T, s Scheduler) time.Time {
+	t.Helper()
+	select {
+	case tick := <-s.Next():
+		return tick
+	case <-time.After(time.Second):
+		t.Fatal("expected a tick")
+		return time.Time{}
+	}
+}
+
+func assertNoTick(t *testing.T, s Scheduler) {
+	t.Helper()
+	select {
+	case tick := <-s.Next():
+		t.Fatalf("unexpected tick at %v", tick)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestUnion_FiresWhenAnyFires(t *testing.T) {
+	a, b := newManualScheduler(), newManualScheduler()
+	union := Union(a, b)
+	defer union.Stop()
+	union.Next()
+
+	a.ticks <- clockEpoch
+	assert.Equal(t, clockEpoch, receiveTick(t, union))
+
+	b.ticks <- clockEpoch.Add(time.Minute)
+	assert.Equal(t, clockEpoch.Add(time.Minute), receiveTick(t, union))
+
+	// A tick for the same time from the other scheduler fires once
+	a.ticks <- clockEpoch.Add(time.Minute)
+	assertNoTick(t, union)
+	a.ticks <- clockEpoch.Add(2 * time.Minute)
+	assert.Equal(t, clockEpoch.Add(2*time.Minute), receiveTick(t, union))
+
+	// A slower scheduler's tick that arrives late still fires
+	b.ticks <- clockEpoch.Add(90 * time.Second)
+	assert.Equal(t, clockEpoch.Add(90*time.Second), receiveTick(t, union))
+}
+
+func TestIntersect_FiresWhenAllHaveFired(t *testing.T) {
+	a, b := newManualScheduler(), newManualScheduler()
+	intersect := Intersect(a, b)
+	defer intersect.Stop()
+	intersect.Next()
+
+	// Repeated ticks from one side are coalesced while waiting for the other
+	a.ticks <- clockEpoch
+	a.ticks <- clockEpoch.Add(time.Second)
+	assertNoTick(t, intersect)
+
+	b.ticks <- clockEpoch.Add(time.Minute)
+	assert.Equal(t, clockEpoch.Add(time.Minute), receiveTick(t, intersect))
+
+	// Both sides have to fire again before the next tick
+	b.ticks <- clockEpoch.Add(2 * time.Minute)
+	assertNoTick(t, intersect)
+	a.ticks <- clockEpoch.Add(3 * time.Minute)
+	assert.Equal(t, clockEpoch.Add(3*time.Minute), receiveTick(t, intersect))
+}
+
+func TestThrottle_PostponesTicksUntilAllowed(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	source, err := NewIntervalSchedulerWithClock(time.Minute, 0, true, clock)
+	require.NoError(t, err)
+
+	throttled := ThrottleWithClock(source, &spacingLimiter{clock: clock, interval: 10 * time.Minute}, clock)
+	defer throttled.Stop()
+
+	assert.Equal(t, clockEpoch, receiveTick(t, throttled))
+
+	// The next source tick arrives after a minute but is held for ten
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	throttled.Next()
+	clock.BlockUntil(2) // source timer plus the throttle's wait
+	assertNoTick(t, throttled)
+
+	clock.Set(clockEpoch.Add(10 * time.Minute))
+	assert.Equal(t, clockEpoch.Add(10*time.Minute), receiveTick(t, throttled))
+}
+
+func TestThrottle_AdmitsOnlyWhenPulled(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	source := newManualScheduler()
+	throttled := ThrottleWithClock(source, &spacingLimiter{clock: clock, interval: 10 * time.Minute}, clock)
+	defer throttled.Stop()
+
+	next := throttled.Next()
+	source.ticks <- clockEpoch
+	assert.Equal(t, clockEpoch, <-next)
+
+	// The consumer is busy for 30 minutes while the source fires. No permit
+	// is taken until it asks for the next tick.
+	go func() { source.ticks <- clockEpoch.Add(time.Minute) }()
+	time.Sleep(10 * time.Millisecond)
+	clock.Set(clockEpoch.Add(10 * time.Minute))
+	time.Sleep(10 * time.Millisecond)
+	clock.Set(clockEpoch.Add(30 * time.Minute))
+	assert.Equal(t, clockEpoch.Add(30*time.Minute), receiveTick(t, throttled))
+
+	// So the following tick keeps its distance from that execution
+	go func() { source.ticks <- clockEpoch.Add(31 * time.Minute) }()
+	assertNoTick(t, throttled)
+	clock.BlockUntil(1)
+	clock.Set(clockEpoch.Add(40 * time.Minute))
+	assert.Equal(t, clockEpoch.Add(40*time.Minute), receiveTick(t, throttled))
+}
+
+func TestDelay_ShiftsEachTick(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	source := newManualScheduler()
+	delayed := DelayWithClock(source, 30*time.Second, clock)
+	defer delayed.Stop()
+	delayed.Next()
+
+	source.ticks <- clockEpoch
+	clock.BlockUntil(1)
+	assertNoTick(t, delayed)
+
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, clockEpoch.Add(30*time.Second), receiveTick(t, delayed))
+}
+
+func TestDelay_StrategyWaitsFromEndOfExecution(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	strategy := strategies.NewExponentialStrategy(time.Minute, 2.0, time.Hour)
+	source, err := NewStrategySchedulerWithClock(strategy, &strategies.StrategyConfig{
+		MaxAttempts: 3,
+		BaseDelay:   time.Minute,
+		Multiplier:  2.0,
+		MaxDelay:    time.Hour,
+	}, clock)
+	require.NoError(t, err)
+	delayed := DelayWithClock(source, time.Second, clock)
+	defer delayed.Stop()
+
+	next := delayed.Next()
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	assert.Equal(t, clockEpoch.Add(time.Second), <-next)
+
+	// The retry delay starts once the consumer, busy for an hour, asks for
+	// the next attempt
+	clock.Set(clockEpoch.Add(time.Hour))
+	next = delayed.Next()
+	clock.BlockUntil(1)
+	clock.Advance(strategy.NextDelay(1, 0))
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	assert.Equal(t, clockEpoch.Add(time.Hour+strategy.NextDelay(1, 0)+time.Second), <-next)
+}
+
+func TestStartAt_HoldsBackUntilStart(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	source := newManualScheduler()
+	started := StartAtWithClock(source, clockEpoch.Add(time.Hour), clock)
+	defer started.Stop()
+	started.Next()
+
+	clock.BlockUntil(1)
+	assertNoTick(t, started)
+
+	// Ticks dated before the start are dropped
+	clock.Advance(time.Hour)
+	source.ticks <- clockEpoch.Add(30 * time.Minute)
+	source.ticks <- clockEpoch.Add(time.Hour)
+	assert.Equal(t, clockEpoch.Add(time.Hour), receiveTick(t, started))
+}
+
+func TestLimit_StopsAfterN(t *testing.T) {
+	source := newManualScheduler()
+	limited := Limit(source, 2)
+	defer limited.Stop()
+	limited.Next()
+
+	for i := 0; i < 2; i++ {
+		source.ticks <- clockEpoch.Add(time.Duration(i) * time.Minute)
+		assert.Equal(t, clockEpoch.Add(time.Duration(i)*time.Minute), receiveTick(t, limited))
+		limited.Next()
+	}
+
+	assert.Eventually(t, source.isStopped, time.Second, time.Millisecond)
+	assertNoTick(t, limited)
+}
+
+func TestCombinators_StopPropagatesToChildren(t *testing.T) {
+	a, b := newManualScheduler(), newManualScheduler()
+	combined := Delay(Union(a, b), time.Second)
+
+	combined.Next()
+	combined.Stop()
+	combined.Stop() // idempotent
+
+	assert.True(t, a.isStopped())
+	assert.True(t, b.isStopped())
+}
+
+func TestCombinators_Unwrap(t *testing.T) {
+	inner := newManualScheduler()
+	combined := Limit(inner, 1)
+
+	unwrapper, ok := combined.(interface{ Unwrap() []Scheduler })
+	require.True(t, ok)
+	assert.Equal(t, []Scheduler{inner}, unwrapper.Unwrap())
+}
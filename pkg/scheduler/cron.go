@@ -16,15 +16,22 @@ type Scheduler = interfaces.Scheduler
 type CronScheduler struct {
 	expression *cron.CronExpression
 	timezone   *time.Location
+	clock      Clock
 	nextChan   chan time.Time
 	stopChan   chan struct{}
 	stopped    bool
 	mu         sync.RWMutex // Protects stopped field
+	startOnce  sync.Once    // Ensures only one scheduling goroutine runs
 	stopOnce   sync.Once    // Ensures Stop() is idempotent
 }
 
 // NewCronScheduler creates a new cron scheduler
 func NewCronScheduler(expression, timezone string) (*CronScheduler, error) {
+	return NewCronSchedulerWithClock(expression, timezone, NewRealClock())
+}
+
+// NewCronSchedulerWithClock creates a cron scheduler that waits on the given clock
+func NewCronSchedulerWithClock(expression, timezone string, clock Clock) (*CronScheduler, error) {
 	// Parse the cron expression
 	cronExpr, err := cron.ParseCron(expression)
 	if err != nil {
@@ -40,6 +47,7 @@ func NewCronScheduler(expression, timezone string) (*CronScheduler, error) {
 	return &CronScheduler{
 		expression: cronExpr,
 		timezone:   tz,
+		clock:      clock,
 		nextChan:   make(chan time.Time, 1),
 		stopChan:   make(chan struct{}),
 		stopped:    false,
@@ -48,7 +56,9 @@ func NewCronScheduler(expression, timezone string) (*CronScheduler, error) {
 
 // Next returns a channel that will receive the next execution time
 func (c *CronScheduler) Next() <-chan time.Time {
-	go c.schedule()
+	c.startOnce.Do(func() {
+		go c.schedule()
+	})
 	return c.nextChan
 }
 
@@ -66,7 +76,7 @@ func (c *CronScheduler) Stop() {
 func (c *CronScheduler) schedule() {
 	for {
 		// Calculate next execution time
-		now := time.Now().In(c.timezone)
+		now := c.clock.Now().In(c.timezone)
 		next := c.expression.NextExecution(now)
 
 		// Wait until the next execution time
@@ -78,7 +88,7 @@ func (c *CronScheduler) schedule() {
 		}
 
 		select {
-		case <-time.After(waitDuration):
+		case <-c.clock.After(waitDuration):
 			// Time to execute
 			select {
 			case c.nextChan <- next:
@@ -93,3 +103,13 @@ func (c *CronScheduler) schedule() {
 		}
 	}
 }
+
+// Step implements Stepper, returning the next cron match after now
+func (c *CronScheduler) Step(now time.Time) (time.Time, bool) {
+	return c.expression.NextExecution(now.In(c.timezone)), true
+}
+
+// Location returns the timezone the cron expression is evaluated in
+func (c *CronScheduler) Location() *time.Location {
+	return c.timezone
+}
@@ -262,3 +262,39 @@ func TestCronScheduler_Timezone(t *testing.T) {
 		})
 	}
 }
+
+func TestCronScheduler_VirtualClockYearOfFirings(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	scheduler, err := NewCronSchedulerWithClock("30 6 * * *", "UTC", clock)
+	require.NoError(t, err)
+	defer scheduler.Stop()
+
+	ticks := scheduler.Next()
+	for day := 0; day < 365; day++ {
+		clock.BlockUntil(1)
+		require.True(t, clock.AdvanceToNext())
+
+		select {
+		case tick := <-ticks:
+			expected := clockEpoch.AddDate(0, 0, day).Add(6*time.Hour + 30*time.Minute)
+			require.Equal(t, expected, tick)
+		case <-time.After(time.Second):
+			t.Fatalf("firing for day %d not delivered", day)
+		}
+	}
+}
+
+func TestCronScheduler_NextStartsSingleLoop(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	scheduler, err := NewCronSchedulerWithClock("@hourly", "UTC", clock)
+	require.NoError(t, err)
+	defer scheduler.Stop()
+
+	// Repeated Next calls must not start competing scheduling loops
+	for i := 0; i < 3; i++ {
+		scheduler.Next()
+	}
+	clock.BlockUntil(1)
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, 1, clock.PendingTimers())
+}
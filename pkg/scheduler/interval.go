@@ -7,20 +7,63 @@ import (
 	"time"
 )
 
+// IntervalMode selects how an IntervalScheduler spaces its ticks
+type IntervalMode int
+
+const (
+	// FixedRate fires on a fixed grid of start + k*interval. A tick the
+	// consumer is too busy to take is delivered late once; further missed
+	// slots are skipped.
+	FixedRate IntervalMode = iota
+	// FixedDelay fires one interval after the previous execution completed,
+	// as reported through ExecutionCompleted
+	FixedDelay
+)
+
+// IntervalOptions tune an IntervalScheduler beyond its interval and jitter
+type IntervalOptions struct {
+	Mode  IntervalMode
+	Align bool  // start on a wall-clock multiple of the interval, e.g. :00/:15/:30/:45 for 15m
+	Seed  int64 // makes jittered intervals reproducible; zero seeds from the time
+}
+
+// CompletionAware is implemented by schedulers that time their next tick
+// from the end of the previous execution rather than from the previous tick
+type CompletionAware interface {
+	ExecutionCompleted(at time.Time)
+}
+
 type IntervalScheduler struct {
 	interval    time.Duration
 	jitter      float64
 	immediate   bool
-	ticker      *time.Ticker
+	options     IntervalOptions
+	clock       Clock
+	completed   chan time.Time // execution completions, for FixedDelay
 	done        chan struct{}
 	stopped     bool
 	initialized bool
 	tickCh      chan time.Time
 	mu          sync.RWMutex // Protects stopped and initialized fields
 	stopOnce    sync.Once    // Ensures Stop() is idempotent
+	steps       int          // Number of Step() calls, used for previews
+	rng         *rand.Rand   // jitter source, seeded from options.Seed
+	rngMu       sync.Mutex   // Protects rng, used by the run loop and Step
 }
 
 func NewIntervalScheduler(interval time.Duration, jitter float64, immediate bool) (*IntervalScheduler, error) {
+	return NewIntervalSchedulerWithClock(interval, jitter, immediate, NewRealClock())
+}
+
+// NewIntervalSchedulerWithClock creates an interval scheduler whose ticks are
+// driven by the given clock
+func NewIntervalSchedulerWithClock(interval time.Duration, jitter float64, immediate bool, clock Clock) (*IntervalScheduler, error) {
+	return NewIntervalSchedulerWithOptions(interval, jitter, immediate, IntervalOptions{}, clock)
+}
+
+// NewIntervalSchedulerWithOptions creates an interval scheduler with the given
+// mode and alignment, driven by the given clock
+func NewIntervalSchedulerWithOptions(interval time.Duration, jitter float64, immediate bool, options IntervalOptions, clock Clock) (*IntervalScheduler, error) {
 	if interval <= 0 {
 		return nil, errors.New("interval must be positive")
 	}
@@ -29,12 +72,23 @@ func NewIntervalScheduler(interval time.Duration, jitter float64, immediate bool
 		return nil, errors.New("jitter must be between 0 and 1.0")
 	}
 
+	seed := options.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	// Ticks are handed over unbuffered, so a busy consumer holds back the
+	// schedule instead of finding stale ticks queued
 	return &IntervalScheduler{
+		rng:       rand.New(rand.NewSource(seed)),
 		interval:  interval,
 		jitter:    jitter,
 		immediate: immediate,
+		options:   options,
+		clock:     clock,
+		completed: make(chan time.Time, 1),
 		done:      make(chan struct{}),
-		tickCh:    make(chan time.Time, 1),
+		tickCh:    make(chan time.Time),
 	}, nil
 }
 
@@ -59,36 +113,7 @@ func (s *IntervalScheduler) Next() <-chan time.Time {
 		if !s.initialized {
 			s.initialized = true
 			s.mu.Unlock()
-
-			// Always send immediate first tick
-			s.tickCh <- time.Now()
-
-			// Start ticker for subsequent ticks
-			actualInterval := s.calculateInterval()
-			s.ticker = time.NewTicker(actualInterval)
-
-			// Start goroutine to forward ticker ticks
-			go func() {
-				defer s.ticker.Stop()
-				for {
-					select {
-					case t := <-s.ticker.C:
-						s.mu.RLock()
-						stopped := s.stopped
-						s.mu.RUnlock()
-						if stopped {
-							return
-						}
-						select {
-						case s.tickCh <- t:
-						case <-s.done:
-							return
-						}
-					case <-s.done:
-						return
-					}
-				}
-			}()
+			go s.run()
 		} else {
 			s.mu.Unlock()
 		}
@@ -99,11 +124,137 @@ func (s *IntervalScheduler) Next() <-chan time.Time {
 	return s.tickCh
 }
 
+// run drives the scheduler, firing in FixedRate or FixedDelay mode
+func (s *IntervalScheduler) run() {
+	slot := s.firstFireTime(s.clock.Now())
+	if !s.waitUntil(slot) {
+		return
+	}
+
+	for {
+		// Completions reported before this tick belong to other executions
+		select {
+		case <-s.completed:
+		default:
+		}
+
+		// Fixed rate: arm the next tick before handing this one over, so the
+		// schedule stays pending while the execution runs
+		var timer Timer
+		if s.options.Mode == FixedRate {
+			slot = s.nextSlot(slot)
+			timer = s.clock.NewTimer(s.jittered(slot).Sub(s.clock.Now()))
+		}
+
+		select {
+		case s.tickCh <- s.clock.Now():
+		case <-s.done:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+		}
+
+		if s.options.Mode == FixedDelay {
+			select {
+			case completedAt := <-s.completed:
+				if !s.waitUntil(completedAt.Add(s.calculateInterval())) {
+					return
+				}
+			case <-s.done:
+				return
+			}
+			continue
+		}
+
+		// A consumer that took the tick late has missed the armed slot too
+		if now := s.clock.Now(); !slot.After(now) {
+			timer.Stop()
+			slot = s.nextSlot(slot)
+			timer = s.clock.NewTimer(s.jittered(slot).Sub(now))
+		}
+
+		select {
+		case <-timer.C():
+		case <-s.done:
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// nextSlot returns the first grid slot after both slot and the current time,
+// skipping any the consumer was too busy to take
+func (s *IntervalScheduler) nextSlot(slot time.Time) time.Time {
+	slot = slot.Add(s.interval)
+	if now := s.clock.Now(); !slot.After(now) {
+		slot = slot.Add((now.Sub(slot)/s.interval + 1) * s.interval)
+	}
+	return slot
+}
+
+// jittered shifts a grid slot by the configured jitter without moving the grid
+func (s *IntervalScheduler) jittered(slot time.Time) time.Time {
+	return slot.Add(s.calculateInterval() - s.interval)
+}
+
+// waitUntil blocks until t on the scheduler's clock and reports false if the
+// scheduler was stopped first
+func (s *IntervalScheduler) waitUntil(t time.Time) bool {
+	wait := t.Sub(s.clock.Now())
+	if wait <= 0 {
+		return true
+	}
+
+	timer := s.clock.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C():
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// firstFireTime returns when the first tick fires if the schedule starts at now
+func (s *IntervalScheduler) firstFireTime(now time.Time) time.Time {
+	if !s.options.Align {
+		return now
+	}
+
+	aligned := now.Truncate(s.interval)
+	if aligned.Before(now) {
+		aligned = aligned.Add(s.interval)
+	}
+	return aligned
+}
+
+// ExecutionCompleted implements CompletionAware. In FixedDelay mode the next
+// tick fires one interval after at; in FixedRate mode it is ignored.
+func (s *IntervalScheduler) ExecutionCompleted(at time.Time) {
+	if s.options.Mode != FixedDelay {
+		return
+	}
+
+	// Keep only the latest completion
+	select {
+	case <-s.completed:
+	default:
+	}
+	select {
+	case s.completed <- at:
+	default:
+	}
+}
+
 func (s *IntervalScheduler) calculateInterval() time.Duration {
 	actualInterval := s.interval
 	if s.jitter > 0 {
 		maxJitter := time.Duration(float64(s.interval) * s.jitter)
-		jitterAmount := time.Duration(rand.Int63n(int64(maxJitter*2))) - maxJitter
+		s.rngMu.Lock()
+		jitterAmount := time.Duration(s.rng.Int63n(int64(maxJitter*2))) - maxJitter
+		s.rngMu.Unlock()
 		actualInterval += jitterAmount
 		if actualInterval <= 0 {
 			actualInterval = s.interval
@@ -117,10 +268,20 @@ func (s *IntervalScheduler) Stop() {
 		s.mu.Lock()
 		s.stopped = true
 		s.mu.Unlock()
-
-		if s.ticker != nil {
-			s.ticker.Stop()
-		}
 		close(s.done)
 	})
 }
+
+// Step implements Stepper. The first step fires immediately, or on the next
+// aligned time, and each later step fires one interval after the current
+// time. Executions are assumed to take no time, so both modes step alike.
+func (s *IntervalScheduler) Step(now time.Time) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.steps++
+	if s.steps == 1 {
+		return s.firstFireTime(now), true
+	}
+	return now.Add(s.calculateInterval()), true
+}
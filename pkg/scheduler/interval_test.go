@@ -213,3 +213,135 @@ func TestIntervalScheduler_Jitter(t *testing.T) {
 	// Should have some variance due to jitter
 	assert.Greater(t, variance, 0.0, "jitter should create timing variance")
 }
+
+func TestIntervalScheduler_VirtualClock(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	scheduler, err := NewIntervalSchedulerWithClock(time.Minute, 0, true, clock)
+	require.NoError(t, err)
+	defer scheduler.Stop()
+
+	ticks := scheduler.Next()
+	assert.Equal(t, clockEpoch, <-ticks, "first tick should be immediate")
+
+	// An hour of ticks without waiting an hour
+	clock.BlockUntil(1)
+	for i := 1; i <= 60; i++ {
+		clock.Advance(time.Minute)
+		select {
+		case tick := <-ticks:
+			assert.Equal(t, clockEpoch.Add(time.Duration(i)*time.Minute), tick)
+		case <-time.After(time.Second):
+			t.Fatalf("tick %d not delivered", i)
+		}
+	}
+}
+
+func TestIntervalScheduler_FixedDelayWaitsForCompletion(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	scheduler, err := NewIntervalSchedulerWithOptions(time.Minute, 0, true, IntervalOptions{Mode: FixedDelay}, clock)
+	require.NoError(t, err)
+	defer scheduler.Stop()
+
+	assert.Equal(t, clockEpoch, receiveTick(t, scheduler))
+
+	// No tick while the execution is still running
+	clock.Advance(5 * time.Minute)
+	assertNoTick(t, scheduler)
+
+	// The next tick fires one interval after the execution completed
+	scheduler.ExecutionCompleted(clock.Now())
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	assert.Equal(t, clockEpoch.Add(6*time.Minute), receiveTick(t, scheduler))
+}
+
+func TestIntervalScheduler_AlignsToWallClock(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch.Add(7 * time.Minute))
+	scheduler, err := NewIntervalSchedulerWithOptions(15*time.Minute, 0, true, IntervalOptions{Align: true}, clock)
+	require.NoError(t, err)
+	defer scheduler.Stop()
+
+	scheduler.Next()
+	clock.BlockUntil(1)
+	assertNoTick(t, scheduler)
+
+	clock.Advance(8 * time.Minute)
+	assert.Equal(t, clockEpoch.Add(15*time.Minute), receiveTick(t, scheduler))
+
+	clock.BlockUntil(1)
+	clock.Advance(15 * time.Minute)
+	assert.Equal(t, clockEpoch.Add(30*time.Minute), receiveTick(t, scheduler))
+}
+
+func TestIntervalScheduler_FixedRateSkipsMissedSlots(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	scheduler, err := NewIntervalSchedulerWithOptions(time.Minute, 0, true, IntervalOptions{Align: true}, clock)
+	require.NoError(t, err)
+	defer scheduler.Stop()
+
+	assert.Equal(t, clockEpoch, receiveTick(t, scheduler))
+
+	// A slow consumer misses the 1m, 2m and 3m slots; the late tick is
+	// delivered once and the schedule resumes on the grid
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	clock.Advance(150 * time.Second)
+	receiveTick(t, scheduler)
+
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, clockEpoch.Add(4*time.Minute), receiveTick(t, scheduler))
+}
+
+func TestIntervalScheduler_StepAligned(t *testing.T) {
+	scheduler, err := NewIntervalSchedulerWithOptions(15*time.Minute, 0, true, IntervalOptions{Align: true}, NewRealClock())
+	require.NoError(t, err)
+
+	first, ok := scheduler.Step(clockEpoch.Add(7 * time.Minute))
+	require.True(t, ok)
+	assert.Equal(t, clockEpoch.Add(15*time.Minute), first)
+
+	second, ok := scheduler.Step(first)
+	require.True(t, ok)
+	assert.Equal(t, clockEpoch.Add(30*time.Minute), second)
+}
+
+func TestIntervalScheduler_FixedRateJitterStaysNearGrid(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	scheduler, err := NewIntervalSchedulerWithOptions(time.Minute, 0.2, true, IntervalOptions{Align: true, Seed: 42}, clock)
+	require.NoError(t, err)
+	defer scheduler.Stop()
+
+	assert.Equal(t, clockEpoch, receiveTick(t, scheduler))
+
+	// Each tick lands within 12s of its grid slot
+	for slot := 1; slot <= 3; slot++ {
+		clock.BlockUntil(1)
+		clock.Advance(clockEpoch.Add(time.Duration(slot)*time.Minute - 12*time.Second).Sub(clock.Now()))
+		assertNoTick(t, scheduler)
+
+		clock.BlockUntil(1)
+		clock.Advance(24 * time.Second)
+		receiveTick(t, scheduler)
+	}
+}
+
+func TestIntervalScheduler_DefaultModeSkipsMissedSlots(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	scheduler, err := NewIntervalSchedulerWithClock(time.Minute, 0, true, clock)
+	require.NoError(t, err)
+	defer scheduler.Stop()
+
+	assert.Equal(t, clockEpoch, receiveTick(t, scheduler))
+
+	// Without options the schedule keeps its grid: a consumer busy for 3.5
+	// slots gets one late tick, then the 4m slot
+	clock.BlockUntil(1)
+	clock.Advance(210 * time.Second)
+	assert.Equal(t, clockEpoch.Add(210*time.Second), receiveTick(t, scheduler))
+	assertNoTick(t, scheduler)
+
+	clock.BlockUntil(1)
+	clock.Advance(30 * time.Second)
+	assert.Equal(t, clockEpoch.Add(4*time.Minute), receiveTick(t, scheduler))
+}
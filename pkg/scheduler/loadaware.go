@@ -135,6 +135,7 @@ type LoadAwareScheduler struct {
 	targetLoad      float64
 	currentInterval time.Duration
 	monitor         *SystemResourceMonitor
+	clock           Clock
 	metricsHistory  []*SystemMetrics
 	maxHistorySize  int
 	nextChan        chan time.Time
@@ -157,6 +158,12 @@ func NewLoadAwareScheduler(baseInterval time.Duration, targetCPU, targetMemory,
 
 // NewLoadAwareSchedulerWithBounds creates a load-aware scheduler with custom bounds
 func NewLoadAwareSchedulerWithBounds(baseInterval time.Duration, targetCPU, targetMemory, targetLoad float64, minInterval, maxInterval time.Duration) *LoadAwareScheduler {
+	return NewLoadAwareSchedulerWithClock(baseInterval, targetCPU, targetMemory, targetLoad, minInterval, maxInterval, NewRealClock())
+}
+
+// NewLoadAwareSchedulerWithClock creates a load-aware scheduler with custom bounds
+// whose intervals and metric refreshes are timed by the given clock
+func NewLoadAwareSchedulerWithClock(baseInterval time.Duration, targetCPU, targetMemory, targetLoad float64, minInterval, maxInterval time.Duration, clock Clock) *LoadAwareScheduler {
 	s := &LoadAwareScheduler{
 		baseInterval:    baseInterval,
 		minInterval:     minInterval,
@@ -166,6 +173,7 @@ func NewLoadAwareSchedulerWithBounds(baseInterval time.Duration, targetCPU, targ
 		targetLoad:      targetLoad,
 		currentInterval: baseInterval,
 		monitor:         NewSystemResourceMonitor(),
+		clock:           clock,
 		metricsHistory:  make([]*SystemMetrics, 0),
 		maxHistorySize:  100,
 		nextChan:        make(chan time.Time, 1),
@@ -259,14 +267,14 @@ func (s *LoadAwareScheduler) Next() <-chan time.Time {
 
 // scheduleLoop continuously schedules the next execution based on load-aware intervals
 func (s *LoadAwareScheduler) scheduleLoop() {
-	ticker := time.NewTicker(5 * time.Second) // Update metrics every 5 seconds
+	ticker := s.clock.NewTicker(5 * time.Second) // Update metrics every 5 seconds
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-s.stopChan:
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			// Update metrics and adjust interval
 			_ = s.UpdateFromMetrics()
 		default:
@@ -274,9 +282,9 @@ func (s *LoadAwareScheduler) scheduleLoop() {
 			interval := s.GetCurrentInterval()
 
 			select {
-			case <-time.After(interval):
+			case <-s.clock.After(interval):
 				select {
-				case s.nextChan <- time.Now():
+				case s.nextChan <- s.clock.Now():
 					// Successfully sent
 				case <-s.stopChan:
 					return
@@ -263,3 +263,20 @@ func TestLoadAwareSchedulerMetricsHistory(t *testing.T) {
 
 	scheduler.Stop()
 }
+
+func TestLoadAwareScheduler_VirtualClock(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	scheduler := NewLoadAwareSchedulerWithClock(time.Second, 70, 80, 1.0, 100*time.Millisecond, 10*time.Second, clock)
+	defer scheduler.Stop()
+
+	// Metrics refresh ticker plus the wait for the first interval
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+
+	select {
+	case tick := <-scheduler.Next():
+		assert.Equal(t, clockEpoch.Add(time.Second), tick)
+	case <-time.After(time.Second):
+		t.Fatal("tick not delivered")
+	}
+}
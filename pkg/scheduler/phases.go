@@ -0,0 +1,229 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Phase is one stage of a PhasedScheduler. A phase ends after Times ticks or
+// once For has elapsed since it began, whichever comes first, or when a Finite
+// scheduler runs out of ticks. Only the last phase may leave Times and For
+// unset and run as long as its scheduler does.
+type Phase struct {
+	Name      string
+	Scheduler Scheduler
+	Times     int           // ticks before moving on (0 = unlimited)
+	For       time.Duration // time before moving on (0 = unlimited)
+}
+
+// PhaseChangeHandler is called with a phase's 0-based index once the phase's
+// first tick has been handed to the consumer, so announcements line up with
+// the executions they describe
+type PhaseChangeHandler func(index int, phase Phase)
+
+// PhasedScheduler runs a sequence of schedulers one after another, e.g. "every
+// 1s for 1 minute, then every 10s for an hour, then every 5m forever"
+type PhasedScheduler struct {
+	phases   []Phase
+	clock    Clock
+	onChange PhaseChangeHandler
+	current  int
+	out      chan time.Time
+	done     chan struct{}
+	ended    chan struct{} // closed once the last phase has ended
+	mu       sync.RWMutex  // Protects onChange and current
+	start    sync.Once
+	stopOnce sync.Once
+
+	// Preview stepping state
+	stepPhase   int
+	stepStart   time.Time
+	stepCount   int
+	stepStarted bool
+}
+
+// NewPhasedScheduler creates a scheduler that runs the given phases in order
+func NewPhasedScheduler(phases []Phase) (*PhasedScheduler, error) {
+	return NewPhasedSchedulerWithClock(phases, NewRealClock())
+}
+
+// NewPhasedSchedulerWithClock creates a phased scheduler that times phases on
+// the given clock
+func NewPhasedSchedulerWithClock(phases []Phase, clock Clock) (*PhasedScheduler, error) {
+	if len(phases) == 0 {
+		return nil, errors.New("at least one phase is required")
+	}
+
+	for i, phase := range phases {
+		if phase.Scheduler == nil {
+			return nil, fmt.Errorf("phase %d (%s) has no scheduler", i+1, phase.Name)
+		}
+		if phase.Times < 0 || phase.For < 0 {
+			return nil, fmt.Errorf("phase %d (%s) has a negative limit", i+1, phase.Name)
+		}
+		_, finite := phase.Scheduler.(Finite)
+		if i < len(phases)-1 && phase.Times == 0 && phase.For == 0 && !finite {
+			return nil, fmt.Errorf("phase %d (%s) never ends; only the last phase may run forever", i+1, phase.Name)
+		}
+	}
+
+	return &PhasedScheduler{
+		phases: phases,
+		clock:  clock,
+		out:    make(chan time.Time), // unbuffered, so a delivered tick has been taken
+		done:   make(chan struct{}),
+		ended:  make(chan struct{}),
+	}, nil
+}
+
+// SetPhaseChangeHandler registers a callback invoked as each phase delivers
+// its first tick. It must be set before the first call to Next.
+func (p *PhasedScheduler) SetPhaseChangeHandler(handler PhaseChangeHandler) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onChange = handler
+}
+
+// Next returns a channel that delivers the active phase's ticks
+func (p *PhasedScheduler) Next() <-chan time.Time {
+	p.start.Do(func() {
+		go p.run()
+	})
+	return p.out
+}
+
+// Done implements Finite: the channel closes once the last phase has ended
+func (p *PhasedScheduler) Done() <-chan struct{} {
+	return p.ended
+}
+
+// Stop stops the phased scheduler and every phase's scheduler
+func (p *PhasedScheduler) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.done)
+		for _, phase := range p.phases {
+			phase.Scheduler.Stop()
+		}
+	})
+}
+
+// CurrentPhase returns the index and definition of the phase being run
+func (p *PhasedScheduler) CurrentPhase() (int, Phase) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.current, p.phases[p.current]
+}
+
+// Unwrap returns the active phase's scheduler, so callers looking for a
+// particular scheduler (such as the adaptive one) find the one in use
+func (p *PhasedScheduler) Unwrap() []Scheduler {
+	_, phase := p.CurrentPhase()
+	return []Scheduler{phase.Scheduler}
+}
+
+// run drives each phase in turn and stops its scheduler when it ends. Once
+// the last phase ends no further ticks are delivered.
+func (p *PhasedScheduler) run() {
+	for i, phase := range p.phases {
+		p.mu.Lock()
+		p.current = i
+		p.mu.Unlock()
+
+		completed := p.runPhase(i, phase)
+		phase.Scheduler.Stop()
+		if !completed {
+			return
+		}
+	}
+	close(p.ended)
+}
+
+// runPhase forwards ticks of one phase until its limits are reached or its
+// scheduler runs out, and reports false if the phased scheduler was stopped
+// first
+func (p *PhasedScheduler) runPhase(index int, phase Phase) bool {
+	exhausted := finished(phase.Scheduler)
+	var deadline <-chan time.Time
+	if phase.For > 0 {
+		timer := p.clock.NewTimer(phase.For)
+		defer timer.Stop()
+		deadline = timer.C()
+	}
+
+	for fired := 0; phase.Times == 0 || fired < phase.Times; fired++ {
+		select {
+		case t := <-phase.Scheduler.Next():
+			select {
+			case p.out <- t:
+			case <-p.done:
+				return false
+			}
+			if fired == 0 {
+				p.announce(index, phase)
+			}
+		case <-deadline:
+			return true
+		case <-exhausted:
+			return true
+		case <-p.done:
+			return false
+		}
+	}
+
+	return true
+}
+
+// announce calls the phase change handler, if any
+func (p *PhasedScheduler) announce(index int, phase Phase) {
+	p.mu.RLock()
+	handler := p.onChange
+	p.mu.RUnlock()
+
+	if handler != nil {
+		handler(index, phase)
+	}
+}
+
+// Step implements Stepper when every phase's scheduler does. Each phase
+// starts where the previous one ended: at its last tick when it ran out of
+// ticks, or at its deadline when it ran out of time.
+func (p *PhasedScheduler) Step(now time.Time) (time.Time, bool) {
+	for p.stepPhase < len(p.phases) {
+		phase := p.phases[p.stepPhase]
+		stepper, ok := phase.Scheduler.(Stepper)
+		if !ok {
+			return time.Time{}, false
+		}
+
+		if !p.stepStarted {
+			p.stepStart, p.stepCount, p.stepStarted = now, 0, true
+		}
+
+		if phase.Times == 0 || p.stepCount < phase.Times {
+			next, ok := stepper.Step(now)
+			if ok && (phase.For == 0 || next.Before(p.stepStart.Add(phase.For))) {
+				p.stepCount++
+				return next, true
+			}
+			if phase.For > 0 {
+				now = p.stepStart.Add(phase.For)
+			}
+		}
+
+		p.stepPhase++
+		p.stepStarted = false
+	}
+
+	return time.Time{}, false
+}
+
+// StepPhase returns the phase the most recent Step fired in
+func (p *PhasedScheduler) StepPhase() (int, Phase) {
+	index := p.stepPhase
+	if index >= len(p.phases) {
+		index = len(p.phases) - 1
+	}
+	return index, p.phases[index]
+}
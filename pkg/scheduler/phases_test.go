@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhasedScheduler_MovesOnAfterTimes(t *testing.T) {
+	a, b := newManualScheduler(), newManualScheduler()
+	phased, err := NewPhasedScheduler([]Phase{
+		{Name: "warmup", Scheduler: a, Times: 2},
+		{Name: "steady", Scheduler: b},
+	})
+	require.NoError(t, err)
+	defer phased.Stop()
+
+	changes := make(chan string, 2)
+	phased.SetPhaseChangeHandler(func(index int, phase Phase) {
+		changes <- phase.Name
+	})
+	phased.Next()
+
+	// A phase is announced once its first tick has been taken
+	a.ticks <- clockEpoch
+	assert.Equal(t, clockEpoch, receiveTick(t, phased))
+	assert.Equal(t, "warmup", <-changes)
+	a.ticks <- clockEpoch.Add(time.Second)
+	assert.Equal(t, clockEpoch.Add(time.Second), receiveTick(t, phased))
+
+	// The second phase takes over once the first has fired twice
+	b.ticks <- clockEpoch.Add(time.Minute)
+	assert.True(t, a.isStopped())
+	index, phase := phased.CurrentPhase()
+	assert.Equal(t, 1, index)
+	assert.Equal(t, "steady", phase.Name)
+	assert.Equal(t, []Scheduler{b}, phased.Unwrap())
+
+	assert.Equal(t, clockEpoch.Add(time.Minute), receiveTick(t, phased))
+	assert.Equal(t, "steady", <-changes)
+}
+
+func TestPhasedScheduler_MovesOnAfterFor(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	a, b := newManualScheduler(), newManualScheduler()
+	phased, err := NewPhasedSchedulerWithClock([]Phase{
+		{Name: "burst", Scheduler: a, For: time.Minute},
+		{Name: "tail", Scheduler: b},
+	}, clock)
+	require.NoError(t, err)
+	defer phased.Stop()
+	phased.Next()
+
+	clock.BlockUntil(1)
+	a.ticks <- clockEpoch
+	assert.Equal(t, clockEpoch, receiveTick(t, phased))
+
+	// The phase ends on its deadline even if its scheduler has not fired
+	clock.Advance(time.Minute)
+	b.ticks <- clockEpoch.Add(time.Minute)
+	assert.Equal(t, clockEpoch.Add(time.Minute), receiveTick(t, phased))
+	assert.True(t, a.isStopped())
+}
+
+func TestPhasedScheduler_LastPhaseEnds(t *testing.T) {
+	a := newManualScheduler()
+	phased, err := NewPhasedScheduler([]Phase{{Name: "once", Scheduler: a, Times: 1}})
+	require.NoError(t, err)
+	defer phased.Stop()
+	phased.Next()
+
+	a.ticks <- clockEpoch
+	assert.Equal(t, clockEpoch, receiveTick(t, phased))
+	assertNoTick(t, phased)
+	assert.True(t, a.isStopped())
+}
+
+// finiteScheduler is a manualScheduler that runs out when a test closes done
+type finiteScheduler struct {
+	*manualScheduler
+	done chan struct{}
+}
+
+func newFiniteScheduler() *finiteScheduler {
+	return &finiteScheduler{manualScheduler: newManualScheduler(), done: make(chan struct{})}
+}
+
+func (f *finiteScheduler) Done() <-chan struct{} { return f.done }
+
+func TestPhasedScheduler_MovesOnWhenSchedulerRunsOut(t *testing.T) {
+	a, b := newFiniteScheduler(), newFiniteScheduler()
+	phased, err := NewPhasedScheduler([]Phase{
+		{Name: "retry", Scheduler: a},
+		{Name: "last", Scheduler: b},
+	})
+	require.NoError(t, err)
+	defer phased.Stop()
+	phased.Next()
+
+	a.ticks <- clockEpoch
+	assert.Equal(t, clockEpoch, receiveTick(t, phased))
+
+	// An unbounded phase ends once its scheduler runs out
+	close(a.done)
+	b.ticks <- clockEpoch.Add(time.Minute)
+	assert.Equal(t, clockEpoch.Add(time.Minute), receiveTick(t, phased))
+	assert.True(t, a.isStopped())
+
+	// and the phased scheduler runs out with its last phase
+	close(b.done)
+	select {
+	case <-phased.Done():
+	case <-time.After(time.Second):
+		t.Fatal("phased scheduler did not run out")
+	}
+}
+
+func TestPhasedScheduler_StopStopsEveryPhase(t *testing.T) {
+	a, b := newManualScheduler(), newManualScheduler()
+	phased, err := NewPhasedScheduler([]Phase{
+		{Scheduler: a, Times: 1},
+		{Scheduler: b},
+	})
+	require.NoError(t, err)
+	phased.Next()
+
+	phased.Stop()
+	phased.Stop() // idempotent
+	assert.True(t, a.isStopped())
+	assert.True(t, b.isStopped())
+}
+
+func TestPhasedScheduler_Validation(t *testing.T) {
+	a, b := newManualScheduler(), newManualScheduler()
+
+	tests := []struct {
+		name    string
+		phases  []Phase
+		wantErr string
+	}{
+		{"no phases", nil, "at least one phase"},
+		{"missing scheduler", []Phase{{Name: "x"}}, "has no scheduler"},
+		{"negative times", []Phase{{Name: "x", Scheduler: a, Times: -1}}, "negative limit"},
+		{"endless middle phase", []Phase{{Name: "x", Scheduler: a}, {Scheduler: b}}, "never ends"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewPhasedScheduler(tt.phases)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestPhasedScheduler_Step(t *testing.T) {
+	newInterval := func(d time.Duration) Scheduler {
+		s, err := NewIntervalScheduler(d, 0, true)
+		require.NoError(t, err)
+		return s
+	}
+
+	phased, err := NewPhasedScheduler([]Phase{
+		{Name: "warmup", Scheduler: newInterval(time.Second), Times: 3},
+		{Name: "steady", Scheduler: newInterval(10 * time.Second), For: 30 * time.Second},
+		{Name: "idle", Scheduler: newInterval(time.Minute)},
+	})
+	require.NoError(t, err)
+
+	clock := NewVirtualClock(clockEpoch)
+	var offsets []time.Duration
+	var names []string
+	for len(offsets) < 8 {
+		next, ok := phased.Step(clock.Now())
+		require.True(t, ok)
+		clock.Set(next)
+		offsets = append(offsets, next.Sub(clockEpoch))
+		_, phase := phased.StepPhase()
+		names = append(names, phase.Name)
+	}
+
+	// Each phase starts where the previous one ended: after its last tick, or
+	// at its deadline
+	assert.Equal(t, []time.Duration{
+		0, time.Second, 2 * time.Second,
+		2 * time.Second, 12 * time.Second, 22 * time.Second,
+		32 * time.Second, 92 * time.Second,
+	}, offsets)
+	assert.Equal(t, []string{
+		"warmup", "warmup", "warmup",
+		"steady", "steady", "steady",
+		"idle", "idle",
+	}, names)
+}
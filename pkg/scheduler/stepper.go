@@ -0,0 +1,27 @@
+package scheduler
+
+import "time"
+
+// Stepper is implemented by schedulers whose fire times can be computed
+// instead of waited for. Step advances the schedule by one firing, given the
+// current time on the caller's clock, and reports false once the schedule is
+// exhausted. Stepping is independent of Next(), so a scheduler used for a
+// preview should not also be run.
+type Stepper interface {
+	Step(now time.Time) (time.Time, bool)
+}
+
+// Preview steps s on the virtual clock and returns up to n fire times. The
+// clock is moved to each fire time so later steps observe it as now.
+func Preview(s Stepper, clock *VirtualClock, n int) []time.Time {
+	times := make([]time.Time, 0, n)
+	for len(times) < n {
+		next, ok := s.Step(clock.Now())
+		if !ok {
+			break
+		}
+		clock.Set(next)
+		times = append(times, next)
+	}
+	return times
+}
@@ -0,0 +1,58 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/swi/repeater/pkg/strategies"
+)
+
+func TestPreview_IntervalScheduler(t *testing.T) {
+	s, err := NewIntervalScheduler(30*time.Second, 0, true)
+	require.NoError(t, err)
+
+	times := Preview(s, NewVirtualClock(clockEpoch), 3)
+
+	assert.Equal(t, []time.Time{
+		clockEpoch,
+		clockEpoch.Add(30 * time.Second),
+		clockEpoch.Add(60 * time.Second),
+	}, times)
+}
+
+func TestPreview_CronSchedulerUsesTimezone(t *testing.T) {
+	s, err := NewCronScheduler("0 9 * * *", "America/New_York")
+	require.NoError(t, err)
+
+	times := Preview(s, NewVirtualClock(clockEpoch), 2)
+	require.Len(t, times, 2)
+
+	for _, got := range times {
+		assert.Equal(t, "America/New_York", got.Location().String())
+		assert.Equal(t, 9, got.Hour())
+		assert.Equal(t, 0, got.Minute())
+	}
+	assert.Equal(t, 24*time.Hour, times[1].Sub(times[0]))
+}
+
+func TestPreview_StrategySchedulerStopsAtMaxAttempts(t *testing.T) {
+	strategy := strategies.NewExponentialStrategy(time.Second, 2.0, time.Minute)
+	s, err := NewStrategyScheduler(strategy, &strategies.StrategyConfig{
+		MaxAttempts: 4,
+		BaseDelay:   time.Second,
+		Multiplier:  2.0,
+		MaxDelay:    time.Minute,
+	})
+	require.NoError(t, err)
+
+	times := Preview(s, NewVirtualClock(clockEpoch), 10)
+
+	require.Len(t, times, 4)
+	assert.Equal(t, clockEpoch, times[0])
+	for i := 1; i < len(times); i++ {
+		assert.True(t, times[i].After(times[i-1]), "attempt %d should follow attempt %d", i+1, i)
+	}
+}
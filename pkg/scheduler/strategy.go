@@ -4,39 +4,79 @@ import (
 	"sync"
 	"time"
 
+	"github.com/swi/repeater/pkg/retry"
 	"github.com/swi/repeater/pkg/strategies"
 )
 
-// StrategyScheduler implements retry scheduling using mathematical strategies
+// Finite is implemented by schedulers that can run out of ticks. Done is
+// closed once the scheduler will not fire again.
+type Finite interface {
+	Done() <-chan struct{}
+}
+
+// finished returns the Done channel of s, or nil, which is never ready, for a
+// scheduler that does not run out
+func finished(s Scheduler) <-chan struct{} {
+	if f, ok := s.(Finite); ok {
+		return f.Done()
+	}
+	return nil
+}
+
+// StrategyScheduler schedules the attempts of a retry policy: the first fires
+// at once and each retry after the policy's backoff delay, until an attempt
+// succeeds or the policy's attempts run out
 type StrategyScheduler struct {
-	strategy       strategies.Strategy
-	config         *strategies.StrategyConfig
+	policy         *retry.Policy
+	backoff        *retry.Backoff
 	currentAttempt int
 	lastDuration   time.Duration
-	maxAttempts    int
+	clock          Clock
 	nextChan       chan time.Time
 	stopChan       chan struct{}
 	stopped        bool
 	mu             sync.RWMutex // Protects stopped and currentAttempt fields
 	stopOnce       sync.Once    // Ensures Stop() is idempotent
+	steps          int          // Number of Step() calls, used for previews
 }
 
 // NewStrategyScheduler creates a new strategy-based scheduler
 func NewStrategyScheduler(strategy strategies.Strategy, config *strategies.StrategyConfig) (*StrategyScheduler, error) {
+	return NewStrategySchedulerWithClock(strategy, config, NewRealClock())
+}
+
+// NewStrategySchedulerWithClock creates a strategy-based scheduler that waits
+// out retry delays on the given clock
+func NewStrategySchedulerWithClock(strategy strategies.Strategy, config *strategies.StrategyConfig, clock Clock) (*StrategyScheduler, error) {
 	// Validate the strategy configuration
 	if err := strategy.ValidateConfig(config); err != nil {
 		return nil, err
 	}
 
+	policy, err := retry.New(retry.WithStrategy(strategy, *config))
+	if err != nil {
+		return nil, err
+	}
+	return NewRetrySchedulerWithClock(policy, clock), nil
+}
+
+// NewRetryScheduler creates a scheduler for the attempts of a retry policy
+func NewRetryScheduler(policy *retry.Policy) *StrategyScheduler {
+	return NewRetrySchedulerWithClock(policy, NewRealClock())
+}
+
+// NewRetrySchedulerWithClock creates a scheduler for the attempts of a retry
+// policy that waits out its delays on the given clock
+func NewRetrySchedulerWithClock(policy *retry.Policy, clock Clock) *StrategyScheduler {
 	return &StrategyScheduler{
-		strategy:       strategy,
-		config:         config,
+		policy:         policy,
+		backoff:        policy.Backoff(),
 		currentAttempt: 0,
-		maxAttempts:    config.MaxAttempts,
+		clock:          clock,
 		nextChan:       make(chan time.Time, 1),
 		stopChan:       make(chan struct{}),
 		stopped:        false,
-	}, nil
+	}
 }
 
 // Next returns a channel that delivers the next execution time
@@ -56,7 +96,7 @@ func (s *StrategyScheduler) Next() <-chan time.Time {
 		s.mu.Unlock()
 
 		// Check if we've exceeded max attempts
-		if s.maxAttempts > 0 && currentAttempt > s.maxAttempts {
+		if !s.backoff.Allows(currentAttempt) {
 			s.Stop()
 			return
 		}
@@ -67,23 +107,23 @@ func (s *StrategyScheduler) Next() <-chan time.Time {
 			delay = 0
 		} else {
 			// Calculate retry delay using the strategy
-			delay = s.strategy.NextDelay(currentAttempt-1, s.lastDuration)
+			delay = s.backoff.Delay(currentAttempt-1, s.lastDuration)
 		}
 
 		// Schedule the next execution
 		go func() {
 			if delay > 0 {
-				timer := time.NewTimer(delay)
+				timer := s.clock.NewTimer(delay)
 				defer timer.Stop()
 
 				select {
-				case <-timer.C:
+				case <-timer.C():
 					s.mu.RLock()
 					stopped := s.stopped
 					s.mu.RUnlock()
 					if !stopped {
 						select {
-						case s.nextChan <- time.Now():
+						case s.nextChan <- s.clock.Now():
 						case <-s.stopChan:
 							return
 						}
@@ -98,7 +138,7 @@ func (s *StrategyScheduler) Next() <-chan time.Time {
 				s.mu.RUnlock()
 				if !stopped {
 					select {
-					case s.nextChan <- time.Now():
+					case s.nextChan <- s.clock.Now():
 					case <-s.stopChan:
 						return
 					}
@@ -110,6 +150,12 @@ func (s *StrategyScheduler) Next() <-chan time.Time {
 	return s.nextChan
 }
 
+// Done implements Finite: the channel closes once the policy's attempts have
+// run out, or the scheduler is stopped
+func (s *StrategyScheduler) Done() <-chan struct{} {
+	return s.stopChan
+}
+
 // Stop stops the scheduler
 func (s *StrategyScheduler) Stop() {
 	s.stopOnce.Do(func() {
@@ -145,5 +191,26 @@ func (s *StrategyScheduler) GetAttemptNumber() int {
 
 // GetStrategy returns the underlying strategy
 func (s *StrategyScheduler) GetStrategy() strategies.Strategy {
-	return s.strategy
+	return s.backoff.Strategy()
+}
+
+// GetPolicy returns the retry policy being scheduled
+func (s *StrategyScheduler) GetPolicy() *retry.Policy {
+	return s.policy
+}
+
+// Step implements Stepper. The first attempt fires immediately, each retry
+// fires after the backoff delay, and the schedule ends with the policy's attempts.
+func (s *StrategyScheduler) Step(now time.Time) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.steps++
+	if !s.backoff.Allows(s.steps) {
+		return time.Time{}, false
+	}
+	if s.steps == 1 {
+		return now, true
+	}
+	return now.Add(s.backoff.Delay(s.steps-1, s.lastDuration)), true
 }
@@ -89,10 +89,10 @@ func TestStrategyScheduler_Creation(t *testing.T) {
 			if tt.expectedError == "" {
 				require.NoError(t, err)
 				require.NotNil(t, scheduler)
-				assert.Equal(t, tt.strategy, scheduler.strategy)
-				assert.Equal(t, tt.config, scheduler.config)
+				assert.Equal(t, tt.strategy, scheduler.GetStrategy())
+				assert.Equal(t, tt.strategy.Name(), scheduler.GetPolicy().Name())
 				assert.Equal(t, 0, scheduler.currentAttempt)
-				assert.Equal(t, tt.config.MaxAttempts, scheduler.maxAttempts)
+				assert.Equal(t, tt.config.MaxAttempts, scheduler.GetPolicy().Attempts())
 				assert.False(t, scheduler.stopped)
 			} else {
 				require.Error(t, err)
@@ -496,3 +496,33 @@ func TestStrategyScheduler_AllStrategiesIntegration(t *testing.T) {
 		})
 	}
 }
+
+func TestStrategyScheduler_VirtualClock(t *testing.T) {
+	clock := NewVirtualClock(clockEpoch)
+	strategy := strategies.NewExponentialStrategy(time.Minute, 2.0, time.Hour)
+	scheduler, err := NewStrategySchedulerWithClock(strategy, &strategies.StrategyConfig{
+		MaxAttempts: 4,
+		BaseDelay:   time.Minute,
+		Multiplier:  2.0,
+		MaxDelay:    time.Hour,
+	}, clock)
+	require.NoError(t, err)
+	defer scheduler.Stop()
+
+	assert.Equal(t, clockEpoch, <-scheduler.Next(), "first attempt should be immediate")
+
+	expected := clockEpoch
+	for attempt := 1; attempt < 4; attempt++ {
+		ticks := scheduler.Next()
+		clock.BlockUntil(1)
+		require.True(t, clock.AdvanceToNext())
+
+		expected = expected.Add(strategy.NextDelay(attempt, 0))
+		select {
+		case tick := <-ticks:
+			assert.Equal(t, expected, tick)
+		case <-time.After(time.Second):
+			t.Fatalf("retry %d not delivered", attempt)
+		}
+	}
+}
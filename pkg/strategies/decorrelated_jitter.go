@@ -19,12 +19,18 @@ type DecorrelatedJitterStrategy struct {
 
 // NewDecorrelatedJitterStrategy creates a new decorrelated jitter backoff strategy
 func NewDecorrelatedJitterStrategy(baseDelay time.Duration, multiplier float64, maxDelay time.Duration) *DecorrelatedJitterStrategy {
+	return NewDecorrelatedJitterStrategyWithSeed(baseDelay, multiplier, maxDelay, time.Now().UnixNano())
+}
+
+// NewDecorrelatedJitterStrategyWithSeed creates a decorrelated jitter strategy whose
+// random delays are reproducible for a given seed
+func NewDecorrelatedJitterStrategyWithSeed(baseDelay time.Duration, multiplier float64, maxDelay time.Duration, seed int64) *DecorrelatedJitterStrategy {
 	return &DecorrelatedJitterStrategy{
 		baseDelay:     baseDelay,
 		multiplier:    multiplier,
 		maxDelay:      maxDelay,
 		previousDelay: baseDelay,
-		rng:           rand.New(rand.NewSource(time.Now().UnixNano())),
+		rng:           rand.New(rand.NewSource(seed)),
 	}
 }
 
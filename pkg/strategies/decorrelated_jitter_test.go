@@ -125,6 +125,19 @@ func TestDecorrelatedJitterStrategy_NextDelay_Deterministic(t *testing.T) {
 	}
 }
 
+func TestDecorrelatedJitterStrategy_NextDelay_SeededIsReproducible(t *testing.T) {
+	first := NewDecorrelatedJitterStrategyWithSeed(1*time.Second, 3.0, time.Minute, 42)
+	second := NewDecorrelatedJitterStrategyWithSeed(1*time.Second, 3.0, time.Minute, 42)
+
+	for attempt := 1; attempt <= 10; attempt++ {
+		a := first.NextDelay(attempt, 0)
+		b := second.NextDelay(attempt, 0)
+		if a != b {
+			t.Fatalf("Attempt %d: same seed produced different delays %v and %v", attempt, a, b)
+		}
+	}
+}
+
 func TestDecorrelatedJitterStrategy_NextDelay_MaxDelayRespected(t *testing.T) {
 	strategy := NewDecorrelatedJitterStrategy(100*time.Millisecond, 10.0, 2*time.Second)
 
@@ -0,0 +1,47 @@
+package strategies
+
+import (
+	"errors"
+	"time"
+)
+
+// FixedStrategy waits the same delay before every retry: 2s, 2s, 2s...
+// Suited to polling a resource that recovers on its own schedule.
+type FixedStrategy struct {
+	delay time.Duration
+}
+
+// NewFixedStrategy creates a new fixed delay strategy
+func NewFixedStrategy(delay time.Duration) *FixedStrategy {
+	return &FixedStrategy{delay: delay}
+}
+
+// Name returns the strategy name
+func (f *FixedStrategy) Name() string {
+	return "fixed"
+}
+
+// NextDelay returns the fixed delay for every attempt
+func (f *FixedStrategy) NextDelay(attempt int, lastDuration time.Duration) time.Duration {
+	return f.delay
+}
+
+// ShouldRetry determines if we should continue retrying
+func (f *FixedStrategy) ShouldRetry(attempt int, err error, output string) bool {
+	// This is handled by the main retry logic based on MaxAttempts
+	// Strategy just provides the delay calculation
+	return true
+}
+
+// ValidateConfig validates the fixed strategy configuration
+func (f *FixedStrategy) ValidateConfig(config *StrategyConfig) error {
+	if config.BaseDelay < 0 {
+		return errors.New("delay must not be negative")
+	}
+
+	if config.MaxAttempts <= 0 {
+		return errors.New("attempts must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package strategies
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFixedStrategy(t *testing.T) {
+	strategy := NewFixedStrategy(2 * time.Second)
+
+	if strategy.Name() != "fixed" {
+		t.Errorf("Name() = %q, want fixed", strategy.Name())
+	}
+	for attempt := 1; attempt <= 5; attempt++ {
+		if delay := strategy.NextDelay(attempt, time.Minute); delay != 2*time.Second {
+			t.Errorf("NextDelay(%d) = %v, want 2s", attempt, delay)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		config  StrategyConfig
+		wantErr bool
+	}{
+		{name: "valid", config: StrategyConfig{BaseDelay: time.Second, MaxAttempts: 3}},
+		{name: "no delay", config: StrategyConfig{MaxAttempts: 3}},
+		{name: "negative delay", config: StrategyConfig{BaseDelay: -time.Second, MaxAttempts: 3}, wantErr: true},
+		{name: "no attempts", config: StrategyConfig{BaseDelay: time.Second}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := strategy.ValidateConfig(&tt.config)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}